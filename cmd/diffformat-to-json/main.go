@@ -32,7 +32,8 @@ Examples:
   # Convert and save to file
   diffformat-to-json my_outline.txt my_outline.json
 
-  # Round-trip test: JSON → diff → JSON
+  # Round-trip test: JSON → diff → JSON, the items keep their IDs
+  # (fields tuo doesn't know are not kept)
   tuo-to-diffformat original.json temp.txt
   diffformat-to-json temp.txt restored.json
   diff <(jq -S . original.json) <(jq -S . restored.json)
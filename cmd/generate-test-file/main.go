@@ -4,24 +4,75 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"math/rand/v2"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/pstuifzand/tui-outliner/internal/model"
 )
 
+// generateOptions controls the shape and metadata of the generated outline
+type generateOptions struct {
+	MaxDepth  int
+	Branching int     // Children per internal node, 0 picks a count from the remaining nodes
+	Attrs     float64 // Probability that a node gets a date attribute
+	Tags      float64 // Probability that a node gets one to three tags
+	Todos     float64 // Probability that a node becomes a todo with a random status
+	Rand      *rand.Rand
+	Today     time.Time // Creation time of all nodes, dates are spread over 60 days around it
+}
+
+var (
+	generatedTags     = []string{"work", "home", "urgent", "later", "review", "waiting", "project", "idea"}
+	generatedStatuses = []string{"todo", "doing", "done"}
+)
+
 func main() {
 	numNodes := flag.Int("nodes", 1000, "Number of nodes to generate")
 	output := flag.String("output", "large_test.json", "Output file path")
 	depth := flag.Int("depth", 3, "Maximum nesting depth")
+	branching := flag.Int("branching", 0, "Children per internal node (0 = automatic)")
+	attrs := flag.Float64("attrs", 0, "Probability (0-1) that a node gets a date attribute")
+	tags := flag.Float64("tags", 0, "Probability (0-1) that a node gets random tags")
+	todos := flag.Float64("todos", 0, "Probability (0-1) that a node becomes a todo with a random status")
+	seed := flag.Uint64("seed", 0, "Random seed for reproducible output (0 = random)")
+	date := flag.String("date", time.Now().Format("2006-01-02"), "Day the nodes are created on (YYYY-MM-DD)")
 	flag.Parse()
 
 	if *numNodes < 1 {
 		fmt.Fprintf(os.Stderr, "nodes must be at least 1\n")
 		os.Exit(1)
 	}
+	if *depth < 0 || *branching < 0 {
+		fmt.Fprintf(os.Stderr, "depth and branching must not be negative\n")
+		os.Exit(1)
+	}
+	for name, p := range map[string]float64{"attrs": *attrs, "tags": *tags, "todos": *todos} {
+		if p < 0 || p > 1 {
+			fmt.Fprintf(os.Stderr, "%s must be a probability between 0 and 1\n", name)
+			os.Exit(1)
+		}
+	}
 
-	outline := generateOutline(*numNodes, *depth)
+	today, err := time.Parse("2006-01-02", *date)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "date must be in the form YYYY-MM-DD\n")
+		os.Exit(1)
+	}
+	if *seed == 0 {
+		*seed = rand.Uint64()
+	}
+	opts := generateOptions{
+		MaxDepth:  *depth,
+		Branching: *branching,
+		Attrs:     *attrs,
+		Tags:      *tags,
+		Todos:     *todos,
+		Rand:      rand.New(rand.NewPCG(*seed, *seed)),
+		Today:     today,
+	}
+	outline := generateOutline(*numNodes, opts)
 
 	// Marshal to JSON with nice formatting
 	data, err := json.MarshalIndent(outline, "", "  ")
@@ -47,12 +98,12 @@ func main() {
 	}
 
 	countNodes := countAllNodes(&outline)
-	fmt.Printf("Generated outline with %d nodes\n", countNodes)
+	fmt.Printf("Generated outline with %d nodes (seed %d, date %s)\n", countNodes, *seed, *date)
 	fmt.Printf("Saved to: %s\n", *output)
 	fmt.Printf("File size: %.2f MB\n", float64(len(data))/(1024*1024))
 }
 
-func generateOutline(totalNodes int, maxDepth int) model.Outline {
+func generateOutline(totalNodes int, opts generateOptions) model.Outline {
 	outline := model.Outline{
 		Items: []*model.Item{},
 	}
@@ -62,7 +113,7 @@ func generateOutline(totalNodes int, maxDepth int) model.Outline {
 
 	// Create a balanced tree structure
 	for remaining > 0 {
-		item := generateItemRecursive(&remaining, depth, maxDepth)
+		item := generateItemRecursive(&remaining, depth, opts)
 		if item.Text != "" {
 			outline.Items = append(outline.Items, item)
 		}
@@ -71,21 +122,33 @@ func generateOutline(totalNodes int, maxDepth int) model.Outline {
 	return outline
 }
 
-func generateItemRecursive(remaining *int, currentDepth int, maxDepth int) *model.Item {
+func generateItemRecursive(remaining *int, currentDepth int, opts generateOptions) *model.Item {
 	if *remaining <= 0 {
 		return nil
 	}
 
-	item := model.NewItem(generateUniqueText(*remaining))
+	item := &model.Item{
+		ID:   generateID(opts),
+		Text: generateUniqueText(*remaining),
+		Metadata: &model.Metadata{
+			Attributes: make(map[string]string),
+			Created:    opts.Today,
+			Modified:   opts.Today,
+		},
+	}
 	*remaining--
+	addGeneratedMetadata(item, opts)
 
 	// Add children if we haven't reached max depth and still have nodes left
-	if currentDepth < maxDepth && *remaining > 0 {
-		numChildren := getChildCount(*remaining, maxDepth-currentDepth)
+	if currentDepth < opts.MaxDepth && *remaining > 0 {
+		numChildren := opts.Branching
+		if numChildren == 0 {
+			numChildren = getChildCount(*remaining, opts.MaxDepth-currentDepth)
+		}
 		item.Children = make([]*model.Item, 0, numChildren)
 
 		for i := 0; i < numChildren && *remaining > 0; i++ {
-			child := generateItemRecursive(remaining, currentDepth+1, maxDepth)
+			child := generateItemRecursive(remaining, currentDepth+1, opts)
 			if child != nil {
 				item.Children = append(item.Children, child)
 			}
@@ -95,6 +158,38 @@ func generateItemRecursive(remaining *int, currentDepth int, maxDepth int) *mode
 	return item
 }
 
+// generateID returns an ID in the form of model.NewItem, but taken from the
+// random source of opts, so the same seed gives the same IDs
+func generateID(opts generateOptions) string {
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+	suffix := make([]byte, 26)
+	for i := range suffix {
+		suffix[i] = alphabet[opts.Rand.IntN(len(alphabet))]
+	}
+	return "item_" + opts.Today.Format("20060102150405") + "_" + string(suffix)
+}
+
+// addGeneratedMetadata randomly makes item a todo and adds tags and a date,
+// each with the probability from opts
+func addGeneratedMetadata(item *model.Item, opts generateOptions) {
+	rng := opts.Rand
+	if rng.Float64() < opts.Todos {
+		item.Metadata.Attributes["type"] = "todo"
+		item.Metadata.Attributes["status"] = generatedStatuses[rng.IntN(len(generatedStatuses))]
+	}
+	if rng.Float64() < opts.Tags {
+		for range 1 + rng.IntN(3) {
+			item.AddTag(generatedTags[rng.IntN(len(generatedTags))])
+		}
+	}
+	if rng.Float64() < opts.Attrs {
+		date := opts.Today.AddDate(0, 0, rng.IntN(61)-30)
+		item.Metadata.Attributes["date"] = date.Format("2006-01-02")
+	}
+	// AddTag updated the modification time
+	item.Metadata.Modified = opts.Today
+}
+
 func getChildCount(remaining int, depthLeft int) int {
 	// Distribute nodes across children based on remaining nodes
 	if depthLeft == 1 {
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+func generateJSON(t *testing.T, seed uint64) []byte {
+	t.Helper()
+	opts := generateOptions{
+		MaxDepth: 3,
+		Attrs:    0.5,
+		Tags:     0.5,
+		Todos:    0.5,
+		Rand:     rand.New(rand.NewPCG(seed, seed)),
+		Today:    time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	outline := generateOutline(200, opts)
+	data, err := json.MarshalIndent(outline, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestGenerateOutlineSeed(t *testing.T) {
+	first := generateJSON(t, 42)
+	if second := generateJSON(t, 42); !bytes.Equal(first, second) {
+		t.Error("Expected the same seed to give the same output")
+	}
+	if other := generateJSON(t, 43); bytes.Equal(first, other) {
+		t.Error("Expected another seed to give other output")
+	}
+}
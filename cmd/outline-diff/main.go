@@ -52,9 +52,18 @@ type ItemChange struct {
 	OldModified    string
 }
 
+// DiffFilter restricts the reported changes to matching items
+type DiffFilter struct {
+	AttrKey   string // Only items with this attribute, empty for any item
+	AttrValue string // Required attribute value, empty matches any value
+	UnderID   string // Only this item and its descendants, empty for any item
+}
+
 func main() {
 	verbose := flag.Bool("v", false, "Verbose output (show full details)")
 	summary := flag.Bool("s", false, "Summary only (no item-level details)")
+	attr := flag.String("attr", "", "Only show changes to items with this attribute (key or key=value)")
+	under := flag.String("under", "", "Only show changes to this item and its descendants")
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, `Usage: outline-diff [options] <file.json>
        outline-diff [options] <file1.json> <file2.json>
@@ -67,6 +76,10 @@ Two-file mode: Shows changes between two specific files
 Options:
   -v   Verbose output (show full details)
   -s   Summary only (counts without item-level details)
+  -attr key[=value]
+       Only show changes to items with the attribute (and value)
+  -under <id>
+       Only show changes to the item with this ID and its descendants
 
 Examples:
   # Compare a file against its backup history
@@ -78,6 +91,9 @@ Examples:
   # Just show summary of changes
   outline-diff -s backup1.json backup2.json
 
+  # Only show changes to todo items in one project
+  outline-diff -attr type=todo -under abc123 my_outline.json
+
 Output shows:
   - New items added to the outline
   - Deleted items removed from the outline
@@ -94,17 +110,20 @@ Output shows:
 		os.Exit(1)
 	}
 
+	filter := DiffFilter{UnderID: *under}
+	filter.AttrKey, filter.AttrValue, _ = strings.Cut(*attr, "=")
+
 	if len(args) == 1 {
 		// Single-file mode: show history across backups
-		handleSingleFileMode(args[0], *verbose, *summary)
+		handleSingleFileMode(args[0], *verbose, *summary, filter)
 	} else {
 		// Two-file mode: compare two specific files
-		handleTwoFileMode(args[0], args[1], *verbose, *summary)
+		handleTwoFileMode(args[0], args[1], *verbose, *summary, filter)
 	}
 }
 
 // handleTwoFileMode compares two specific files
-func handleTwoFileMode(file1Path, file2Path string, verbose, summary bool) {
+func handleTwoFileMode(file1Path, file2Path string, verbose, summary bool, filter DiffFilter) {
 	// Open and parse first file
 	file1, err := os.Open(file1Path)
 	if err != nil {
@@ -150,7 +169,7 @@ func handleTwoFileMode(file1Path, file2Path string, verbose, summary bool) {
 	data2 := parseDiffFormat(buf2.String())
 
 	// Analyze changes
-	result := analyzeChanges(data1, data2)
+	result := filterChanges(analyzeChanges(data1, data2), data1, data2, filter)
 
 	// Output header
 	fmt.Printf("=== Outline Diff: %s → %s ===\n\n", file1Path, file2Path)
@@ -183,7 +202,7 @@ func handleTwoFileMode(file1Path, file2Path string, verbose, summary bool) {
 }
 
 // handleSingleFileMode finds backups for a file and shows the diff history
-func handleSingleFileMode(filePath string, verbose, summaryOnly bool) {
+func handleSingleFileMode(filePath string, verbose, summaryOnly bool, filter DiffFilter) {
 	// Get absolute path
 	absPath, err := filepath.Abs(filePath)
 	if err != nil {
@@ -226,7 +245,7 @@ func handleSingleFileMode(filePath string, verbose, summaryOnly bool) {
 		// Load outlines from backups
 		var outline1, outline2 model.Outline
 
-		data1, err := os.ReadFile(backup1.FilePath)
+		data1, err := storage.ReadOutlineFile(backup1.FilePath)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error reading backup 1: %v\n", err)
 			continue
@@ -236,7 +255,7 @@ func handleSingleFileMode(filePath string, verbose, summaryOnly bool) {
 			continue
 		}
 
-		data2, err := os.ReadFile(backup2.FilePath)
+		data2, err := storage.ReadOutlineFile(backup2.FilePath)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error reading backup 2: %v\n", err)
 			continue
@@ -260,7 +279,7 @@ func handleSingleFileMode(filePath string, verbose, summaryOnly bool) {
 		// Parse and analyze
 		data1Map := parseDiffFormat(buf1.String())
 		data2Map := parseDiffFormat(buf2.String())
-		result := analyzeChanges(data1Map, data2Map)
+		result := filterChanges(analyzeChanges(data1Map, data2Map), data1Map, data2Map, filter)
 
 		// Print diff header
 		fmt.Printf("--- %s (backup %d)\n", formatBackupTime(backup1.Timestamp), i+1)
@@ -347,10 +366,7 @@ func parseDiffFormat(content string) map[string]*ItemData {
 
 		case "[TAGS SECTION]":
 			if value != "" {
-				items[id].Tags = strings.Split(value, ",")
-				for i := range items[id].Tags {
-					items[id].Tags[i] = strings.TrimSpace(items[id].Tags[i])
-				}
+				items[id].Tags = decodeTagsValue(value)
 			}
 
 		case "[ATTRIBUTES SECTION]":
@@ -369,6 +385,46 @@ func parseDiffFormat(content string) map[string]*ItemData {
 	return items
 }
 
+// decodeTagsValue splits a tags value on unescaped commas and decodes each tag.
+// Unescaped spaces around a tag are dropped, escaped spaces (\s) are kept.
+func decodeTagsValue(value string) []string {
+	var tags []string
+	var current strings.Builder
+	escaped := 0 // Length of current up to the last escaped character
+	finish := func() {
+		tag := current.String()
+		tags = append(tags, tag[:escaped]+strings.TrimRight(tag[escaped:], " "))
+		current.Reset()
+		escaped = 0
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			switch value[i+1] {
+			case 'n':
+				current.WriteByte('\n')
+				i++
+			case 's':
+				current.WriteByte(' ')
+				i++
+			case '\\', ',':
+				current.WriteByte(value[i+1])
+				i++
+			default:
+				current.WriteByte('\\')
+			}
+			escaped = current.Len()
+		} else if value[i] == ',' {
+			finish()
+		} else if value[i] == ' ' && current.Len() == 0 {
+			// Skip the spaces before a tag
+		} else {
+			current.WriteByte(value[i])
+		}
+	}
+	finish()
+	return tags
+}
+
 // decodeTextValue decodes escaped text values
 func decodeTextValue(text string) string {
 	var result strings.Builder
@@ -437,6 +493,61 @@ func analyzeChanges(data1, data2 map[string]*ItemData) *DiffResult {
 	return result
 }
 
+// filterChanges keeps the changes to items matching filter, so the summary
+// counts only the filtered changes. New items are matched in the new version,
+// deleted items in the old version and modified items in either version.
+func filterChanges(result *DiffResult, data1, data2 map[string]*ItemData, filter DiffFilter) *DiffResult {
+	if filter.AttrKey == "" && filter.UnderID == "" {
+		return result
+	}
+
+	filtered := &DiffResult{
+		NewItems:      make(map[string]*ItemData),
+		DeletedItems:  make(map[string]*ItemData),
+		ModifiedItems: make(map[string]*ItemChange),
+	}
+	for id, item := range result.NewItems {
+		if filter.matches(item, data2) {
+			filtered.NewItems[id] = item
+		}
+	}
+	for id, item := range result.DeletedItems {
+		if filter.matches(item, data1) {
+			filtered.DeletedItems[id] = item
+		}
+	}
+	for id, change := range result.ModifiedItems {
+		if filter.matches(change.Item, data2) || filter.matches(change.OldItem, data1) {
+			filtered.ModifiedItems[id] = change
+		}
+	}
+	return filtered
+}
+
+// matches checks if item passes the filter, using data to find its ancestors
+func (f DiffFilter) matches(item *ItemData, data map[string]*ItemData) bool {
+	if f.AttrKey != "" {
+		value, exists := item.Attributes[f.AttrKey]
+		if !exists || (f.AttrValue != "" && value != f.AttrValue) {
+			return false
+		}
+	}
+
+	if f.UnderID != "" {
+		// Walk up the parents, the limit guards against cycles in broken files
+		current := item
+		for depth := 0; current != nil && depth <= len(data); depth++ {
+			if current.ID == f.UnderID {
+				return true
+			}
+			current = data[current.ParentID]
+		}
+		return false
+	}
+
+	return true
+}
+
 // compareItems checks if an item changed and returns the changes
 func compareItems(old, new *ItemData) *ItemChange {
 	change := &ItemChange{
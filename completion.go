@@ -0,0 +1,247 @@
+package main
+
+// Shell completion scripts printed by 'tuo completion <shell>'. They are
+// written by hand, so update them when subcommands or flags change.
+
+const bashCompletion = `# bash completion for tuo
+_tuo() {
+    local cur prev subcommand flags
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]}"
+
+    if [[ $COMP_CWORD -eq 1 ]]; then
+        if [[ "$cur" == -* ]]; then
+            COMPREPLY=($(compgen -W "--debug" -- "$cur"))
+        else
+            COMPREPLY=($(compgen -W "add export search stats todo dump watch merge hash completion help" -- "$cur"))
+        fi
+        return
+    fi
+
+    subcommand="${COMP_WORDS[1]}"
+    case "$prev" in
+        -f|-i|-o)
+            # Fall back to file name completion
+            COMPREPLY=()
+            return
+            ;;
+        -ff)
+            case "$subcommand" in
+                export) COMPREPLY=($(compgen -W "markdown md opml html org text txt" -- "$cur")) ;;
+                search) COMPREPLY=($(compgen -W "text fields json jsonl markdown list" -- "$cur")) ;;
+                stats) COMPREPLY=($(compgen -W "text json" -- "$cur")) ;;
+            esac
+            return
+            ;;
+        --fields)
+            local prefix=""
+            if [[ "$cur" == *,* ]]; then
+                prefix="${cur%,*},"
+            fi
+            COMPREPLY=($(compgen -P "$prefix" -W "id text attributes created modified tags depth path parent_id" -- "${cur##*,}"))
+            compopt -o nospace 2>/dev/null
+            return
+            ;;
+        --sort)
+            COMPREPLY=($(compgen -W "id text created modified depth" -- "$cur"))
+            return
+            ;;
+    esac
+
+    case "$subcommand" in
+        add) flags="-r -f -a --attr -t --parent --parent-query --stdin" ;;
+        export) flags="-f -o -ff --node --checkboxes --attrs --frontmatter" ;;
+        search) flags="-r -f -ff --fields --sort --limit --json" ;;
+        stats) flags="-f -ff --attr" ;;
+        todo) flags="-r --id" ;;
+        dump) flags="-r --query" ;;
+        watch) flags="-r --json" ;;
+        merge) flags="-f -i -o --parent" ;;
+        hash) flags="-f" ;;
+        completion)
+            if [[ $COMP_CWORD -eq 2 ]]; then
+                COMPREPLY=($(compgen -W "bash zsh fish" -- "$cur"))
+            fi
+            return
+            ;;
+    esac
+
+    if [[ "$cur" == -* ]]; then
+        COMPREPLY=($(compgen -W "$flags" -- "$cur"))
+    fi
+}
+complete -o default -F _tuo tuo
+`
+
+const zshCompletion = `#compdef tuo
+# zsh completion for tuo
+_tuo() {
+    local -a subcommands
+    subcommands=(
+        'add:Add a node to a running instance or a file'
+        'export:Export an outline file'
+        'search:Search for nodes'
+        'stats:Print outline metrics'
+        'todo:Rotate the status of a todo in a running instance'
+        'dump:Print the outline of a running instance as JSON'
+        'watch:Print the changes of a running instance'
+        'merge:Merge another outline file into a file'
+        'hash:Print a hash of the outline content'
+        'completion:Print a shell completion script'
+        'help:Show the help message'
+    )
+
+    if (( CURRENT == 2 )); then
+        _alternative \
+            'subcommands:subcommand:_describe -t subcommands subcommand subcommands' \
+            'options:option:(--debug)' \
+            'files:outline file:_files'
+        return
+    fi
+
+    local subcommand=$words[2]
+    shift words
+    (( CURRENT-- ))
+
+    case $subcommand in
+        add)
+            _arguments \
+                '-r[Add to running tuo instance]' \
+                '-f[Add to file]:file:_files' \
+                '*-a[Set an attribute]:key=value:' \
+                '*--attr[Set an attribute]:key=value:' \
+                '-t[Add as todo item]' \
+                '--parent[Add under the item with this ID]:id:' \
+                '--parent-query[Add under the first item matching the query]:query:' \
+                '--stdin[Add every line of standard input]' \
+                '*:text:'
+            ;;
+        export)
+            _arguments \
+                '-f[Input outline file]:file:_files' \
+                '-o[Output file]:file:_files' \
+                '-ff[Output format]:format:(markdown md opml html org text txt)' \
+                '--node[Export only this item and its descendants]:id:' \
+                '--checkboxes[Render todo items as checkboxes]' \
+                '--attrs[Attributes to append to items]:attributes:' \
+                '--frontmatter[Emit YAML front matter]'
+            ;;
+        search)
+            _arguments \
+                '-r[Search in running tuo instance]' \
+                '-f[Search in file]:file:_files' \
+                '-ff[Output format]:format:(text fields json jsonl markdown list)' \
+                '--fields[Fields to output]:fields:_sequence compadd - id text attributes created modified tags depth path parent_id' \
+                '--sort[Sort results by field]:field:(id text created modified depth)' \
+                '--limit[Maximum number of results]:limit:' \
+                '--json[Output results as JSON]' \
+                '*:query:'
+            ;;
+        stats)
+            _arguments \
+                '-f[Outline file to analyze]:file:_files' \
+                '-ff[Output format]:format:(text json)' \
+                '--attr[Break down counts by this attribute]:attribute:'
+            ;;
+        todo)
+            _arguments \
+                '-r[Change a todo in the running tuo instance]' \
+                '--id[ID of the item to change]:id:'
+            ;;
+        dump)
+            _arguments \
+                '-r[Dump the outline of the running tuo instance]' \
+                '--query[Only dump the subtrees of matching items]:query:'
+            ;;
+        watch)
+            _arguments \
+                '-r[Watch the running tuo instance]' \
+                '--json[Print each event as a line of JSON]'
+            ;;
+        merge)
+            _arguments \
+                '-f[Base outline file]:file:_files' \
+                '-i[Outline file to merge]:file:_files' \
+                '-o[Output file]:file:_files' \
+                '--parent[Merge under the item with this ID]:id:'
+            ;;
+        hash)
+            _arguments '-f[Outline file to hash]:file:_files'
+            ;;
+        completion)
+            _arguments '1:shell:(bash zsh fish)'
+            ;;
+    esac
+}
+
+if [ "$funcstack[1]" = "_tuo" ]; then
+    _tuo "$@"
+else
+    compdef _tuo tuo
+fi
+`
+
+const fishCompletion = `# fish completion for tuo
+set -l subcommands add export search stats todo dump watch merge hash completion help
+
+complete -c tuo -f
+complete -c tuo -n "not __fish_seen_subcommand_from $subcommands" -F
+complete -c tuo -n "not __fish_seen_subcommand_from $subcommands" -l debug -d 'Enable debug mode'
+complete -c tuo -n "not __fish_seen_subcommand_from $subcommands" -a add -d 'Add a node to a running instance or a file'
+complete -c tuo -n "not __fish_seen_subcommand_from $subcommands" -a export -d 'Export an outline file'
+complete -c tuo -n "not __fish_seen_subcommand_from $subcommands" -a search -d 'Search for nodes'
+complete -c tuo -n "not __fish_seen_subcommand_from $subcommands" -a stats -d 'Print outline metrics'
+complete -c tuo -n "not __fish_seen_subcommand_from $subcommands" -a todo -d 'Rotate the status of a todo in a running instance'
+complete -c tuo -n "not __fish_seen_subcommand_from $subcommands" -a dump -d 'Print the outline of a running instance as JSON'
+complete -c tuo -n "not __fish_seen_subcommand_from $subcommands" -a watch -d 'Print the changes of a running instance'
+complete -c tuo -n "not __fish_seen_subcommand_from $subcommands" -a merge -d 'Merge another outline file into a file'
+complete -c tuo -n "not __fish_seen_subcommand_from $subcommands" -a hash -d 'Print a hash of the outline content'
+complete -c tuo -n "not __fish_seen_subcommand_from $subcommands" -a completion -d 'Print a shell completion script'
+complete -c tuo -n "not __fish_seen_subcommand_from $subcommands" -a help -d 'Show the help message'
+
+complete -c tuo -n "__fish_seen_subcommand_from add" -o r -d 'Add to running tuo instance'
+complete -c tuo -n "__fish_seen_subcommand_from add" -o f -r -F -d 'Add to file'
+complete -c tuo -n "__fish_seen_subcommand_from add" -o a -l attr -x -d 'Set an attribute (key=value)'
+complete -c tuo -n "__fish_seen_subcommand_from add" -o t -d 'Add as todo item'
+complete -c tuo -n "__fish_seen_subcommand_from add" -l parent -x -d 'Add under the item with this ID'
+complete -c tuo -n "__fish_seen_subcommand_from add" -l parent-query -x -d 'Add under the first item matching the query'
+complete -c tuo -n "__fish_seen_subcommand_from add" -l stdin -d 'Add every line of standard input'
+
+complete -c tuo -n "__fish_seen_subcommand_from export" -o f -r -F -d 'Input outline file'
+complete -c tuo -n "__fish_seen_subcommand_from export" -o o -r -F -d 'Output file'
+complete -c tuo -n "__fish_seen_subcommand_from export" -o ff -x -a 'markdown md opml html org text txt' -d 'Output format'
+complete -c tuo -n "__fish_seen_subcommand_from export" -l node -x -d 'Export only this item and its descendants'
+complete -c tuo -n "__fish_seen_subcommand_from export" -l checkboxes -d 'Render todo items as checkboxes'
+complete -c tuo -n "__fish_seen_subcommand_from export" -l attrs -x -d 'Attributes to append to items'
+complete -c tuo -n "__fish_seen_subcommand_from export" -l frontmatter -d 'Emit YAML front matter'
+
+complete -c tuo -n "__fish_seen_subcommand_from search" -o r -d 'Search in running tuo instance'
+complete -c tuo -n "__fish_seen_subcommand_from search" -o f -r -F -d 'Search in file'
+complete -c tuo -n "__fish_seen_subcommand_from search" -o ff -x -a 'text fields json jsonl markdown list' -d 'Output format'
+complete -c tuo -n "__fish_seen_subcommand_from search" -l fields -x -a 'id text attributes created modified tags depth path parent_id' -d 'Fields to output'
+complete -c tuo -n "__fish_seen_subcommand_from search" -l sort -x -a 'id text created modified depth' -d 'Sort results by field'
+complete -c tuo -n "__fish_seen_subcommand_from search" -l limit -x -d 'Maximum number of results'
+complete -c tuo -n "__fish_seen_subcommand_from search" -l json -d 'Output results as JSON'
+
+complete -c tuo -n "__fish_seen_subcommand_from stats" -o f -r -F -d 'Outline file to analyze'
+complete -c tuo -n "__fish_seen_subcommand_from stats" -o ff -x -a 'text json' -d 'Output format'
+complete -c tuo -n "__fish_seen_subcommand_from stats" -l attr -x -d 'Break down counts by this attribute'
+
+complete -c tuo -n "__fish_seen_subcommand_from todo" -o r -d 'Change a todo in the running tuo instance'
+complete -c tuo -n "__fish_seen_subcommand_from todo" -l id -x -d 'ID of the item to change'
+
+complete -c tuo -n "__fish_seen_subcommand_from dump" -o r -d 'Dump the outline of the running tuo instance'
+complete -c tuo -n "__fish_seen_subcommand_from dump" -l query -x -d 'Only dump the subtrees of matching items'
+
+complete -c tuo -n "__fish_seen_subcommand_from watch" -o r -d 'Watch the running tuo instance'
+complete -c tuo -n "__fish_seen_subcommand_from watch" -l json -d 'Print each event as a line of JSON'
+
+complete -c tuo -n "__fish_seen_subcommand_from merge" -o f -r -F -d 'Base outline file'
+complete -c tuo -n "__fish_seen_subcommand_from merge" -o i -r -F -d 'Outline file to merge'
+complete -c tuo -n "__fish_seen_subcommand_from merge" -o o -r -F -d 'Output file'
+complete -c tuo -n "__fish_seen_subcommand_from merge" -l parent -x -d 'Merge under the item with this ID'
+
+complete -c tuo -n "__fish_seen_subcommand_from hash" -o f -r -F -d 'Outline file to hash'
+
+complete -c tuo -n "__fish_seen_subcommand_from completion" -x -a 'bash zsh fish'
+`
@@ -2,10 +2,13 @@ module github.com/pstuifzand/tui-outliner
 
 go 1.24.7
 
-require github.com/pelletier/go-toml/v2 v2.2.4
+require (
+	github.com/ncruces/go-strftime v1.0.0
+	github.com/pelletier/go-toml/v2 v2.2.4
+	golang.org/x/crypto v0.41.0
+)
 
 require (
-	github.com/ncruces/go-strftime v1.0.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -16,10 +19,10 @@ require (
 	github.com/gdamore/tcell/v2 v2.9.0
 	github.com/lithammer/fuzzysearch v1.1.8
 	github.com/lucasb-eyer/go-colorful v1.2.0
-	github.com/mattn/go-runewidth v0.0.16 // indirect
+	github.com/mattn/go-runewidth v0.0.16
 	github.com/rivo/uniseg v0.4.3 // indirect
 	github.com/stretchr/testify v1.11.1
 	golang.org/x/sys v0.35.0 // indirect
-	golang.org/x/term v0.34.0 // indirect
-	golang.org/x/text v0.28.0 // indirect
+	golang.org/x/term v0.34.0
+	golang.org/x/text v0.28.0
 )
@@ -0,0 +1,150 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+	"github.com/pstuifzand/tui-outliner/internal/search"
+	tmpl "github.com/pstuifzand/tui-outliner/internal/template"
+	"github.com/pstuifzand/tui-outliner/internal/ui"
+)
+
+// agendaDateKeys are the attributes that always count as dates in the agenda,
+// attributes with a date type definition are added to them
+var agendaDateKeys = []string{"date", "deadline"}
+
+// agendaEntry is a single dated item in the agenda
+type agendaEntry struct {
+	Item    *model.Item
+	Key     string    // Attribute the date came from
+	Date    time.Time // Midnight of the date, in local time
+	Overdue bool      // Open todo with a date before today
+}
+
+// handleAgendaCommand shows the items dated within today, this week, month or
+// year in the messages view, grouped by date. Open todos with a date before
+// today are included and flagged as overdue.
+func (a *App) handleAgendaCommand(parts []string) {
+	rangeName := "week"
+	if len(parts) > 1 {
+		rangeName = parts[1]
+	}
+
+	interval := rangeName
+	if rangeName == "today" {
+		interval = "day"
+	}
+	now := time.Now()
+	start, end, ok := ui.DateIntervalRange(interval, now)
+	if !ok {
+		a.SetStatus("Usage: :agenda [today|week|month|year]")
+		return
+	}
+
+	// Sync outline with tree so new and edited items are included
+	a.outline.Items = a.tree.GetItems()
+	a.outline.BuildIndex()
+
+	statuses := a.todoStatuses()
+	entries := collectAgenda(a.outline.Items, a.agendaDateKeys(), start, end, now, statuses[len(statuses)-1])
+	if len(entries) == 0 {
+		a.SetStatus(fmt.Sprintf("No dated items for %s", rangeName))
+		return
+	}
+
+	var messages []*ui.Message
+	var items []*model.Item
+	for i, entry := range entries {
+		messages = append(messages, &ui.Message{
+			Text:      formatAgendaEntry(entry, i == 0 || !entries[i-1].Date.Equal(entry.Date)),
+			Timestamp: entry.Date,
+		})
+		items = append(items, entry.Item)
+	}
+
+	a.messagesViewActive = true
+	a.messagesViewTitle = "Agenda: " + rangeName
+	a.messagesViewMessages = messages
+	a.messagesViewItems = items
+	a.messagesViewScroll = 0
+}
+
+// agendaDateKeys returns the attribute keys that hold dates, including the keys
+// with a date type definition
+func (a *App) agendaDateKeys() []string {
+	keys := slices.Clone(agendaDateKeys)
+
+	registry := tmpl.NewTypeRegistry()
+	if err := registry.LoadFromOutline(a.outline); err != nil {
+		return keys
+	}
+	for key, spec := range registry.GetAll() {
+		if spec.Kind == "date" && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// collectAgenda returns the items with a date in one of keys between start and
+// end (exclusive), plus the open todos dated before today. Entries are sorted
+// by date and keep the outline order within a date.
+func collectAgenda(items []*model.Item, keys []string, start, end, now time.Time, doneStatus string) []agendaEntry {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+	var entries []agendaEntry
+	var walk func(items []*model.Item)
+	walk = func(items []*model.Item) {
+		for _, item := range items {
+			if item.Metadata != nil {
+				for _, key := range keys {
+					value, exists := item.Metadata.Attributes[key]
+					if !exists {
+						continue
+					}
+					date := search.ParseDate(value)
+					if date.IsZero() {
+						continue
+					}
+					// Compare whole days in local time
+					date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local)
+
+					overdue := item.Metadata.Attributes["type"] == "todo" &&
+						item.Metadata.Attributes["status"] != doneStatus &&
+						date.Before(today)
+					if overdue || (!date.Before(start) && date.Before(end)) {
+						entries = append(entries, agendaEntry{Item: item, Key: key, Date: date, Overdue: overdue})
+					}
+				}
+			}
+			walk(item.Children)
+		}
+	}
+	walk(items)
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Date.Before(entries[j].Date)
+	})
+	return entries
+}
+
+// formatAgendaEntry formats an entry as a line of the agenda view. The date is
+// only shown on the first entry of each date, so the entries read as groups.
+func formatAgendaEntry(entry agendaEntry, showDate bool) string {
+	date := "              "
+	if showDate {
+		date = entry.Date.Format("Mon 2006-01-02")
+	}
+
+	text := entry.Item.Text
+	if entry.Overdue {
+		text = "OVERDUE " + text
+	}
+	if entry.Key != "date" {
+		text += " (" + entry.Key + ")"
+	}
+	return date + "  " + text
+}
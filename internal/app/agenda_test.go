@@ -0,0 +1,92 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pstuifzand/tui-outliner/internal/config"
+	"github.com/pstuifzand/tui-outliner/internal/model"
+	"github.com/pstuifzand/tui-outliner/internal/ui"
+)
+
+func TestCollectAgenda(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.Local) // A Wednesday
+	start, end, _ := ui.DateIntervalRange("week", now)
+
+	dated := func(text string, attrs ...string) *model.Item {
+		item := model.NewItem(text)
+		for i := 0; i+1 < len(attrs); i += 2 {
+			item.Metadata.Attributes[attrs[i]] = attrs[i+1]
+		}
+		return item
+	}
+
+	friday := dated("Review", "date", "2026-10-16")
+	monday := dated("Standup", "date", "2026-10-12")
+	report := dated("Report", "deadline", "2026-10-16")
+	overdue := dated("Taxes", "type", "todo", "status", "todo", "date", "2026-09-30")
+	done := dated("Groceries", "type", "todo", "status", "done", "date", "2026-10-01")
+	nextWeek := dated("Trip", "date", "2026-10-20")
+	notADate := dated("Notes", "date", "someday")
+
+	daily := dated("Daily notes")
+	daily.AddChild(friday)
+	daily.AddChild(monday)
+	items := []*model.Item{daily, report, overdue, done, nextWeek, notADate}
+
+	entries := collectAgenda(items, agendaDateKeys, start, end, now, "done")
+
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Item.Text)
+	}
+	want := []string{"Taxes", "Standup", "Review", "Report"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("Expected agenda %v, got %v", want, got)
+	}
+	if !entries[0].Overdue || entries[1].Overdue {
+		t.Errorf("Expected only Taxes to be overdue")
+	}
+
+	if line := formatAgendaEntry(entries[0], true); line != "Wed 2026-09-30  OVERDUE Taxes" {
+		t.Errorf("Unexpected overdue line %q", line)
+	}
+	if line := formatAgendaEntry(entries[3], false); line != "                Report (deadline)" {
+		t.Errorf("Unexpected grouped line %q", line)
+	}
+}
+
+func TestAgendaCommand(t *testing.T) {
+	today := time.Now().Format("2006-01-02")
+	meeting := model.NewItem("Meeting")
+	meeting.Metadata.Attributes["due"] = today
+	other := model.NewItem("Other")
+
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{other, meeting}
+	outline.TypeDefinitions["due"] = "date"
+	app := &App{
+		outline: outline,
+		tree:    ui.NewTreeView(outline.Items),
+		cfg:     &config.Config{},
+	}
+
+	app.handleAgendaCommand([]string{"agenda", "decade"})
+	if !strings.HasPrefix(app.statusMsg, "Usage") {
+		t.Errorf("Expected usage for an unknown range, got %q", app.statusMsg)
+	}
+
+	app.handleAgendaCommand([]string{"agenda", "today"})
+	if !app.messagesViewActive || len(app.messagesViewItems) != 1 || app.messagesViewItems[0] != meeting {
+		t.Fatalf("Expected the agenda to list Meeting, got %v", app.messagesViewMessages)
+	}
+	if app.messagesViewTitle != "Agenda: today" {
+		t.Errorf("Unexpected title %q", app.messagesViewTitle)
+	}
+
+	app.jumpToMessagesViewItem()
+	if app.messagesViewActive || app.messagesViewTitle != "" || app.tree.GetSelected() != meeting {
+		t.Errorf("Expected Enter to close the agenda and select Meeting")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -67,26 +68,50 @@ type App struct {
 	messagesViewActive     bool                // Whether messages view is currently displayed
 	messagesViewMessages   []*ui.Message       // Messages to display
 	messagesViewScroll     int                 // Scroll position for messages view
+	messagesViewItems      []*model.Item       // Items to jump to with Enter, one per message (nil for plain messages)
+	messagesViewTitle      string              // Title of views like the agenda, their messages are shown without timestamps
 	mode                   Mode                // Current editor mode (NormalMode, InsertMode, or VisualMode)
-	clipboard              *model.Item         // For cut/paste operations
+	clipboard              []*model.Item       // For cut/paste operations (sibling order)
+	undoStack              []*undoState        // Outline snapshots for undo (u)
+	redoStack              []*undoState        // Outline snapshots for redo (Ctrl-R)
 	visualAnchor           int                 // For visual mode selection (index in filteredView, -1 when not in visual mode)
 	lastSendDestination    *model.Item         // Last destination node used with 'ss' for repeat with 's.'
+	lastAction             func(a *App)        // Last mutating normal mode action, repeated with '.'
+	confirmAction          func(a *App)        // Action waiting for a yes in the command line
 	keybindings            []KeyBinding        // All keybindings
 	pendingKeybindings     []PendingKeyBinding // Pending key definitions (g, z, etc)
 	pendingKeySeq          rune                // Current pending key waiting for second character
+	marks                  map[rune]string     // Mark name to item ID, set with m<letter>
+	markRows               map[rune]int        // Mark name to the screen row of the item when it was set
 	hasFile                bool                // Whether a file was provided in arguments
+
+	// Parsed search node queries by query text, see parseSearchNodeQuery
+	searchQueryCache map[string]search.FilterExpr
+
+	// Running :clock session, clockItemID is empty when not clocked in
+	clockItemID   string
+	clockItemText string
+	clockStart    time.Time
+
+	// Open files, the active buffer's state lives in the fields above
+	buffers   []*buffer
+	bufferIdx int
+
+	// Count typed before a normal mode key, like the 5 of 5j, 0 when none
+	pendingCount int
+
+	// Matches of the last search, for n and N after the search bar is closed
+	searchMatches  []*model.Item
+	searchMatchIdx int
+
+	// Item hashes at the last changed event, nil when no client is subscribed.
+	// changesPending is set by markDirty, see publishChanges.
+	itemHashes     map[string]uint64
+	changesPending bool
 }
 
 // NewApp creates a new App instance
 func NewApp(filePath string) (*App, error) {
-	screen, err := ui.NewScreen()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create screen: %w", err)
-	}
-
-	// Enable mouse support if available
-	screen.EnableMouse()
-
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
@@ -105,15 +130,48 @@ func NewApp(filePath string) (*App, error) {
 		cfg.Set("showprogress", "true")
 	}
 
+	// Initialize history manager
+	historyManager, err := history.NewManager()
+	if err != nil {
+		log.Printf("Warning: Failed to initialize history manager: %v\n", err)
+		historyManager = nil
+	}
+
+	// Reopen the last file when started without one
+	var session lastSession
+	restoreSession := false
+	if filePath == "" && cfg.Get("restorelast") == "true" {
+		if session, restoreSession = loadLastSession(historyManager); restoreSession {
+			filePath = session.FilePath
+		}
+	}
+
 	store := storage.NewJSONStore(filePath)
 	sessionID := generateSessionID()
 	store.SetSessionID(sessionID)
+	store.SetBackupCompression(cfg.Get("backupcompression") == "true")
+	if cfg.Get("encrypt") == "true" && filePath != "" && !store.ReadOnly {
+		store.Encrypted = true
+	}
+
+	// Ask for the passphrase before the screen takes over the terminal
+	if err := PromptPassphrase(store); err != nil {
+		return nil, err
+	}
 
 	outline, err := store.Load()
 	if err != nil {
-		screen.Close()
 		return nil, fmt.Errorf("failed to load outline: %w", err)
 	}
+	outlineProblems, repaired := checkOutline(outline, cfg)
+
+	screen, err := ui.NewScreen()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create screen: %w", err)
+	}
+
+	// Enable mouse support if available
+	screen.EnableMouse()
 
 	tree := ui.NewTreeView(outline.Items)
 	help := ui.NewHelpScreen()
@@ -135,13 +193,6 @@ func NewApp(filePath string) (*App, error) {
 		}
 	}
 
-	// Initialize history manager
-	historyManager, err := history.NewManager()
-	if err != nil {
-		log.Printf("Warning: Failed to initialize history manager: %v\n", err)
-		historyManager = nil
-	}
-
 	// Initialize command mode with history persistence
 	var command *ui.CommandMode
 	if historyManager != nil {
@@ -227,7 +278,7 @@ func NewApp(filePath string) (*App, error) {
 
 	// Set callback for attribute editor modifications
 	attributeEditor.SetOnModified(func() {
-		app.dirty = true
+		app.markDirty()
 	})
 
 	// Set validation callback for attribute editor
@@ -292,6 +343,7 @@ func NewApp(filePath string) (*App, error) {
 		// Now hoist the selected item
 		if found {
 			if app.tree.Hoist() {
+				app.searchScopeChanged()
 				app.SetStatus(fmt.Sprintf("Hoisted: %s", item.Text))
 			} else {
 				app.SetStatus("Cannot hoist (no children)")
@@ -306,7 +358,7 @@ func NewApp(filePath string) (*App, error) {
 		// Insert the link into the editor
 		if app.editor != nil && app.editor.IsActive() {
 			app.editor.InsertLink(item.ID, item.Text)
-			app.dirty = true
+			app.markDirty()
 		}
 	})
 
@@ -335,7 +387,7 @@ func NewApp(filePath string) (*App, error) {
 					selected.Metadata.Attributes = make(map[string]string)
 				}
 				selected.Metadata.Attributes[app.calendarWidget.GetAttributeName()] = dateStr
-				app.dirty = true
+				app.markDirty()
 				app.SetStatus(fmt.Sprintf("Set %s to %s", app.calendarWidget.GetAttributeName(), dateStr))
 			} else {
 				app.SetStatus("No item selected")
@@ -386,7 +438,7 @@ func NewApp(filePath string) (*App, error) {
 
 				app.tree.AddItemAsChild(newItem)
 
-				app.dirty = true
+				app.markDirty()
 				app.SetStatus(fmt.Sprintf("Created: %s", formattedDate))
 			}
 		}
@@ -395,17 +447,16 @@ func NewApp(filePath string) (*App, error) {
 	// Initialize keybindings
 	app.keybindings = app.InitializeKeybindings()
 	app.pendingKeybindings = app.InitializePendingKeybindings()
+	app.applyKeyMappings()
+	app.updateHelpKeybindings()
+	app.reportOutlineProblems(outlineProblems, repaired)
 
-	// Convert keybindings to KeyBindingInfo for help screen
-	var helpKeybindings []ui.KeyBindingInfo
-	for i := range app.keybindings {
-		helpKeybindings = append(helpKeybindings, &app.keybindings[i])
+	app.loadMarks()
+	app.loadViewState()
+	app.restoreClock()
+	if restoreSession {
+		app.restoreSessionView(session)
 	}
-	// Add pending keybindings to help
-	for i := range app.pendingKeybindings {
-		helpKeybindings = append(helpKeybindings, &app.pendingKeybindings[i])
-	}
-	app.help.SetKeybindings(helpKeybindings)
 
 	// Initialize socket server for external commands
 	socketServer, err := socket.NewServer(os.Getpid())
@@ -464,10 +515,15 @@ func (a *App) Run() error {
 		case msg := <-socketChan:
 			a.handleSocketMessage(msg)
 		case <-ticker.C:
+			// Match the items a search on a large outline couldn't match while typing
+			if a.search.IsActive() && a.search.Continue() && !a.search.IsScanning() {
+				a.showSearchMatch()
+			}
 			a.render()
+			a.publishChanges()
 
-			// Auto-save every 5 seconds if dirty (skip for readonly files)
-			if a.dirty && !a.readOnly && time.Since(a.autoSaveTime) > 5*time.Second {
+			// Auto-save if dirty (skip for readonly files or when disabled)
+			if interval := a.autoSaveInterval(); interval > 0 && a.dirty && !a.readOnly && time.Since(a.autoSaveTime) > interval {
 				if err := a.Save(); err != nil {
 					a.SetStatus("Failed to save: " + err.Error())
 				} else {
@@ -482,6 +538,9 @@ func (a *App) Run() error {
 
 // Close closes the application
 func (a *App) Close() error {
+	a.saveLastSession()
+	a.saveViewState()
+
 	// Stop socket server if running
 	if a.socketServer != nil {
 		a.socketServer.Stop()
@@ -585,12 +644,9 @@ func (a *App) render() {
 					itemY := treeStartY + screenIdx
 					// Calculate X position after the tree prefix (indentation + arrow + space)
 					depth := a.tree.GetSelectedDepth()
-					editorX := depth*3 + 3 // indentation + arrow + attribute indicator + space
-					// Use same max width calculation as tree view for consistent wrapping
-					maxWidth := width - 21 // 6 levels * 3 + 3 for arrow area
-					if maxWidth < 20 {
-						maxWidth = 20 // Minimum wrap width
-					}
+					editorX := a.tree.TextX(depth) // indentation + arrow + attribute indicator + space
+					// Use the wrap width of the tree view for consistent wrapping
+					maxWidth := a.tree.WrapWidth(depth)
 					// Render editor (may span multiple lines)
 					// Render call will call SetMaxWidth internally
 					a.editor.Render(a.screen, editorX, itemY, maxWidth)
@@ -676,6 +732,26 @@ func (a *App) render() {
 		lineX += len(readonly)
 	}
 
+	// Show the running clock, the todo progress and the child counts of the
+	// selected item, the file name and the time at the right, as far as they fit
+	now := time.Now()
+	right := fitStatusParts([]statusPart{
+		{a.clockStatusText(now), 2},
+		{a.selectionProgressText(), 1},
+		{a.selectionCountsText(), 0},
+		{a.fileStatusText(), 3},
+		{a.timeStatusText(now), 4},
+	}, width-lineX-2)
+	if rightWidth := ui.StringWidth(right); right != "" && lineX+rightWidth+2 <= width {
+		rightX := width - rightWidth - 1
+		for lineX < rightX {
+			a.screen.SetCell(lineX, height-1, ' ', modeStyle)
+			lineX++
+		}
+		a.screen.DrawString(lineX, height-1, right, modeStyle)
+		lineX += rightWidth
+	}
+
 	// Clear remainder of status line
 	for lineX < width {
 		a.screen.SetCell(lineX, height-1, ' ', modeStyle)
@@ -703,6 +779,121 @@ func (a *App) render() {
 	a.screen.Show()
 }
 
+// statusPart is a text at the right of the status line
+type statusPart struct {
+	text     string
+	priority int // Parts with a lower priority are left out first when space runs out
+}
+
+// fitStatusParts joins the texts of parts that are not empty with " | ". When
+// they are wider than width, parts are left out by priority until they fit.
+func fitStatusParts(parts []statusPart, width int) string {
+	for {
+		var texts []string
+		lowest := -1
+		for i, part := range parts {
+			if part.text == "" {
+				continue
+			}
+			texts = append(texts, part.text)
+			if lowest == -1 || part.priority < parts[lowest].priority {
+				lowest = i
+			}
+		}
+		text := strings.Join(texts, " | ")
+		if lowest == -1 || ui.StringWidth(text) <= width {
+			return text
+		}
+		parts[lowest].text = ""
+	}
+}
+
+// fileStatusText returns the base name of the file for the status line, or
+// the time the backup was made when viewing a backup. Returns an empty string
+// when 'showfilename' is false.
+func (a *App) fileStatusText() string {
+	if a.cfg != nil && a.cfg.Get("showfilename") == "false" {
+		return ""
+	}
+	if a.readOnly && a.currentBackupPath != "" {
+		if timestamp, err := storage.ParseBackupTimestamp(a.currentBackupPath); err == nil {
+			return "backup " + timestamp.Format("2006-01-02 15:04:05")
+		}
+	}
+	if a.store == nil || a.store.FilePath == "" {
+		return ""
+	}
+	return filepath.Base(a.store.FilePath)
+}
+
+// timeStatusText returns the time of day for the status line. Returns an empty
+// string when 'showclock' is false.
+func (a *App) timeStatusText(now time.Time) string {
+	if a.cfg != nil && a.cfg.Get("showclock") == "false" {
+		return ""
+	}
+	return now.Format("15:04")
+}
+
+// selectionCountsText describes the children of the selected item for the
+// status line: nothing for leaves, and whether they are hidden when collapsed.
+// Returns an empty string when 'showcounts' is false.
+func (a *App) selectionCountsText() string {
+	if a.cfg != nil && a.cfg.Get("showcounts") == "false" {
+		return ""
+	}
+	selected := a.tree.GetSelected()
+	if selected == nil {
+		return ""
+	}
+
+	children := len(selected.Children)
+	if children == 0 {
+		return "no children"
+	}
+	text := fmt.Sprintf("%s, %s", pluralize(children, "child", "children"),
+		pluralize(selected.CountDescendants(), "descendant", "descendants"))
+	if !selected.Expanded {
+		text += " (collapsed)"
+	}
+	return text
+}
+
+// selectionProgressText returns the todo progress of the selected item, like
+// "3/7 (42%)", when it is a todo with todo children. Returns an empty string
+// when 'showprogresspct' is false.
+func (a *App) selectionProgressText() string {
+	if a.cfg != nil && a.cfg.Get("showprogresspct") == "false" {
+		return ""
+	}
+	selected := a.tree.GetSelected()
+	if selected == nil || selected.Metadata == nil || selected.Metadata.Attributes["type"] != "todo" {
+		return ""
+	}
+	total, done, _ := ui.CalculateProgressFromChildren(selected, a.todoStatuses())
+	if total == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d (%d%%)", done, total, done*100/total)
+}
+
+// pluralize formats a count with the singular or plural noun
+func pluralize(count int, singular, plural string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, singular)
+	}
+	return fmt.Sprintf("%d %s", count, plural)
+}
+
+// treeViewportHeight returns the viewport height the tree is rendered with
+func (a *App) treeViewportHeight() int {
+	treeEndY := a.screen.GetHeight() - 2
+	if a.search.IsActive() {
+		treeEndY -= 3
+	}
+	return max(treeEndY, 1)
+}
+
 // renderMessagesView renders the message history view on the screen
 func (a *App) renderMessagesView() {
 	width := a.screen.GetWidth()
@@ -711,6 +902,9 @@ func (a *App) renderMessagesView() {
 	// Draw title area
 	titleStyle := a.screen.HelpTitleStyle()
 	title := " Message History "
+	if a.messagesViewTitle != "" {
+		title = " " + a.messagesViewTitle + " "
+	}
 	a.screen.DrawString(0, 0, title, titleStyle)
 
 	// Clear rest of title line
@@ -742,13 +936,22 @@ func (a *App) renderMessagesView() {
 		// Format message with timestamp
 		timestamp := msg.Timestamp.Format("15:04:05")
 		msgText := fmt.Sprintf("[%s] %s", timestamp, msg.Text)
+		if a.messagesViewTitle != "" {
+			msgText = msg.Text
+		}
 
 		// Truncate to screen width if needed
 		if len(msgText) > width-2 {
 			msgText = msgText[:width-2]
 		}
 
-		a.screen.DrawString(1, currentY, msgText, contentStyle)
+		// Highlight the message Enter will jump to
+		style := contentStyle
+		if a.messagesViewItems != nil && i == a.messagesViewScroll {
+			style = a.screen.TreeSelectedStyle()
+		}
+
+		a.screen.DrawString(1, currentY, msgText, style)
 		currentY++
 	}
 
@@ -761,6 +964,9 @@ func (a *App) renderMessagesView() {
 	// Draw status bar showing help and scroll position
 	statusStyle := a.screen.StatusMessageStyle()
 	statusMsg := fmt.Sprintf("Messages: %d | [q]Close  [j/k]Scroll", len(a.messagesViewMessages))
+	if a.messagesViewItems != nil {
+		statusMsg += "  [Enter]Jump"
+	}
 	a.screen.DrawString(0, height-1, statusMsg, statusStyle)
 
 	// Fill rest of status line
@@ -792,12 +998,17 @@ func (a *App) handleRawEvent(ev tcell.Event) {
 	// Handle messages view input
 	if a.messagesViewActive {
 		if keyEv, ok := ev.(*tcell.EventKey); ok {
+			if keyEv.Key() == tcell.KeyEnter && a.messagesViewItems != nil {
+				a.jumpToMessagesViewItem()
+				return
+			}
 			ch := keyEv.Rune()
 			switch ch {
 			case 'q':
 				// Close messages view
 				a.messagesViewActive = false
 				a.messagesViewScroll = 0
+				a.messagesViewTitle = ""
 				return
 			case 'j':
 				// Scroll down
@@ -814,6 +1025,7 @@ func (a *App) handleRawEvent(ev tcell.Event) {
 			case ':':
 				// Allow command mode even in messages view
 				a.messagesViewActive = false
+				a.messagesViewTitle = ""
 				a.command.Start()
 				return
 			}
@@ -824,8 +1036,11 @@ func (a *App) handleRawEvent(ev tcell.Event) {
 	// Handle command mode input
 	if a.command.IsActive() {
 		if keyEv, ok := ev.(*tcell.EventKey); ok {
+			confirming := a.command.IsConfirming()
 			cmd, done := a.command.HandleKey(keyEv)
-			if done {
+			if done && confirming {
+				a.answerConfirm(cmd == "y")
+			} else if done {
 				a.handleCommand(cmd)
 			}
 		}
@@ -889,32 +1104,15 @@ func (a *App) handleRawEvent(ev tcell.Event) {
 	// Handle search input
 	if a.search.IsActive() {
 		if keyEv, ok := ev.(*tcell.EventKey); ok {
-			if keyEv.Key() == tcell.KeyEscape {
+			if keyEv.Key() == tcell.KeyEscape && !a.search.IsReverseSearching() {
 				a.search.Stop()
 			} else {
 				a.search.HandleKey(keyEv)
 				// After handling any search key, navigate to first match if there are results
-				if a.search.HasResults() {
-					currentMatch := a.search.GetCurrentMatch()
-					if currentMatch != nil {
-						// Expand all parent nodes of the match so it becomes visible
-						a.tree.ExpandParents(currentMatch)
-						// Find and select this item in the main tree
-						items := a.tree.GetDisplayItems()
-						for idx, dispItem := range items {
-							if dispItem.Item.ID == currentMatch.ID {
-								a.tree.SelectItem(idx)
-								break
-							}
-						}
-						matchNum := a.search.GetCurrentMatchNumber()
-						totalMatches := a.search.GetMatchCount()
-						a.SetStatus(fmt.Sprintf("Match %d of %d", matchNum, totalMatches))
-					}
-				} else if a.search.GetQuery() != "" {
-					// Query is not empty but no matches
-					a.SetStatus("No matches")
-				}
+				a.showSearchMatch()
+			}
+			if !a.search.IsActive() {
+				a.keepSearchMatches()
 			}
 		}
 		return
@@ -945,13 +1143,30 @@ func (a *App) handleRawEvent(ev tcell.Event) {
 				indentPressed := a.editor.WasIndentPressed()
 				outdentPressed := a.editor.WasOutdentPressed()
 				editedItem := a.editor.GetItem()
+				cursorPos := a.editor.GetCursorPos()
+
+				oldText := editedItem.Text
+
+				// Enter before the end of the text splits the item at the cursor
+				splitting := enterPressed && cursorPos < len(a.editor.GetText())
+
+				// Record the edit for undo when the text was changed
+				if a.editor.GetText() != editedItem.Text || splitting {
+					a.saveUndoState()
+				}
 
 				// Exit edit mode (except for indent/outdent which continue in insert mode)
 				a.editor.Stop()
 				a.editor = nil
-				a.dirty = true
+				a.markDirty()
 				a.mode = NormalMode
 				a.SetStatus("Modified")
+
+				var splitText string
+				if splitting {
+					splitText = splitItemText(editedItem, cursorPos)
+				}
+
 				// Refresh any expanded search nodes with new/updated items
 				a.refreshSearchNodes()
 
@@ -998,6 +1213,14 @@ func (a *App) handleRawEvent(ev tcell.Event) {
 					a.tree.RebuildView()
 				}
 
+				// Keep the display text of links to the renamed item in sync
+				if editedItem.Text != oldText {
+					if updated := a.updateLinkTexts(editedItem); updated > 0 {
+						a.SetStatus(fmt.Sprintf("Modified (updated links in %d items)", updated))
+						a.tree.RebuildView()
+					}
+				}
+
 				// If Escape was pressed and item is empty (and has no children), delete it
 				if escapePressed && editedItem.Text == "" && len(editedItem.Children) == 0 {
 					// Move to previous item before deleting
@@ -1008,7 +1231,7 @@ func (a *App) handleRawEvent(ev tcell.Event) {
 						a.tree.SelectItem(currentIdx - 1)
 					}
 					a.SetStatus("Deleted empty item")
-					a.dirty = true
+					a.markDirty()
 				} else if backspaceOnEmpty {
 					// Backspace pressed on empty item - merge with previous item
 					prevIdx := a.tree.GetSelectedIndex() - 1
@@ -1016,7 +1239,7 @@ func (a *App) handleRawEvent(ev tcell.Event) {
 						a.tree.DeleteItem(editedItem)
 						a.tree.SelectItem(prevIdx)
 						a.SetStatus("Merged with previous item")
-						a.dirty = true
+						a.markDirty()
 
 						// Enter insert mode on previous item with cursor at end
 						prevItem := a.tree.GetSelected()
@@ -1030,7 +1253,7 @@ func (a *App) handleRawEvent(ev tcell.Event) {
 					// Tab pressed - indent the current item
 					if a.tree.Indent() {
 						a.SetStatus("Indented")
-						a.dirty = true
+						a.markDirty()
 					} else {
 						a.SetStatus("Cannot indent (no previous item)")
 					}
@@ -1042,7 +1265,7 @@ func (a *App) handleRawEvent(ev tcell.Event) {
 					// Shift+Tab pressed - outdent the current item
 					if a.tree.Outdent() {
 						a.SetStatus("Outdented")
-						a.dirty = true
+						a.markDirty()
 					} else {
 						a.SetStatus("Cannot outdent (already at root level)")
 					}
@@ -1051,16 +1274,22 @@ func (a *App) handleRawEvent(ev tcell.Event) {
 					a.editor.Start()
 					a.mode = InsertMode
 				} else if enterPressed {
-					// If Enter was pressed, create new node below and enter insert mode
-					item := model.NewItem("")
+					// If Enter was pressed, create new node below and enter insert mode.
+					// When splitting, the new node gets the text after the cursor.
+					item := model.NewItem(splitText)
 					a.tree.AddItemAfter(item)
-					a.SetStatus("Created new item below")
-					a.dirty = true
+					if splitting {
+						a.SetStatus("Split item")
+					} else {
+						a.SetStatus("Created new item below")
+					}
+					a.markDirty()
 					// Enter insert mode for the new item
 					selected := a.tree.GetSelected()
 					if selected != nil {
 						a.editor = ui.NewMultiLineEditor(selected)
 						a.editor.Start()
+						a.editor.SetCursorToStart()
 						a.mode = InsertMode
 					}
 				}
@@ -1106,14 +1335,22 @@ func (a *App) handleKeypress(ev *tcell.EventKey) {
 		log.Printf("Key: %v | Rune: %q | Modifiers: %v", ev.Key(), ev.Rune(), ev.Modifiers())
 	}
 
+	// Every key uses up the count, digits add to it again below
+	count := a.pendingCount
+	a.pendingCount = 0
+
 	// Handle special keys first
 	switch ev.Key() {
 	case tcell.KeyDown:
-		a.tree.SelectNext()
+		for range max(count, 1) {
+			a.tree.SelectNext()
+		}
 		a.pendingKeySeq = 0 // Clear pending sequence on other keys
 		return
 	case tcell.KeyUp:
-		a.tree.SelectPrev()
+		for range max(count, 1) {
+			a.tree.SelectPrev()
+		}
 		a.pendingKeySeq = 0
 		return
 	case tcell.KeyLeft:
@@ -1125,10 +1362,7 @@ func (a *App) handleKeypress(ev *tcell.EventKey) {
 		a.pendingKeySeq = 0
 		return
 	case tcell.KeyCtrlI:
-		if a.tree.Indent() {
-			a.SetStatus("Indented")
-			a.dirty = true
-		}
+		a.indentSelected()
 		a.pendingKeySeq = 0
 		return
 	case tcell.KeyCtrlU:
@@ -1167,6 +1401,10 @@ func (a *App) handleKeypress(ev *tcell.EventKey) {
 		}
 		a.pendingKeySeq = 0
 		return
+	case tcell.KeyCtrlR:
+		a.redo()
+		a.pendingKeySeq = 0
+		return
 	case tcell.KeyCtrlK:
 		a.pendingKeySeq = 0
 		// Collect all searchable items
@@ -1201,31 +1439,35 @@ func (a *App) handleKeypress(ev *tcell.EventKey) {
 		a.pendingKeySeq = 0
 	}
 
+	// Digits build a count for the next key, 0 only continues a count
+	if (r >= '1' && r <= '9') || (r == '0' && count > 0) {
+		a.pendingCount = min(count*10+int(r-'0'), maxCount)
+		return
+	}
+
 	// Check if this is a pending key prefix
 	if a.IsPendingKeyPrefix(r) {
 		a.pendingKeySeq = r
 		return
 	}
 
-	// Check for regular keybinding (also handle . and , as alternates for > and <)
+	// Check for regular keybinding
 	kb := a.GetKeybindingByKey(r)
 	if kb != nil {
-		kb.Handler(a)
+		switch {
+		case count > 0 && kb.CountHandler != nil:
+			kb.CountHandler(a, count)
+		case count > 0 && kb.Repeat:
+			repeatCount(kb.Handler)(a, count)
+		default:
+			kb.Handler(a)
+		}
 		return
 	}
 
-	// Handle alternate keybindings for indent/outdent
-	switch r {
-	case '.': // . as alternate for indent
-		if a.tree.Indent() {
-			a.SetStatus("Indented")
-			a.dirty = true
-		}
-	case ',': // , as alternate for outdent
-		if a.tree.Outdent() {
-			a.SetStatus("Outdented")
-			a.dirty = true
-		}
+	// Handle , as alternate for outdent
+	if r == ',' {
+		a.outdentSelected()
 	}
 }
 
@@ -1306,6 +1548,8 @@ func (a *App) handleCommand(cmd string) {
 	case "q", "quit":
 		if a.dirty {
 			a.SetStatus("Unsaved changes! Use :q! to force quit or :w to save")
+		} else if name := a.dirtyBuffer(); name != "" {
+			a.SetStatus(fmt.Sprintf("Unsaved changes in %s! Use :q! to force quit or :b to switch to it", name))
 		} else {
 			a.quit = true
 		}
@@ -1316,14 +1560,24 @@ func (a *App) handleCommand(cmd string) {
 			a.SetStatus(":edit <filename>")
 		} else {
 			filename := parts[1]
-			if err := a.Load(parts[1]); err != nil {
+			if err := a.openBuffer(filename); err != nil {
 				a.SetStatus(fmt.Sprintf("Failed to edit %s: %s", filename, err.Error()))
 			} else {
-				a.SetStatus(fmt.Sprintf("Opened %s", filename))
-				a.splash.Hide()
-				a.hasFile = true
+				a.SetStatus(fmt.Sprintf("Opened %s (%s)", filename, a.bufferStatus()))
 			}
 		}
+	case "bnext", "bn":
+		a.cycleBuffer(1)
+	case "bprev", "bp", "bprevious":
+		a.cycleBuffer(-1)
+	case "buffers", "ls":
+		a.handleBuffersCommand()
+	case "b", "buffer":
+		a.handleBufferCommand(parts)
+	case "bd", "bdelete":
+		a.closeBuffer(false)
+	case "bd!", "bdelete!":
+		a.closeBuffer(true)
 	case "w", "write":
 		var filename string
 		if len(parts) > 1 {
@@ -1341,6 +1595,8 @@ func (a *App) handleCommand(cmd string) {
 	case "wq":
 		if err := a.Save(); err != nil {
 			a.SetStatus("Failed to save: " + err.Error())
+		} else if name := a.dirtyBuffer(); name != "" {
+			a.SetStatus(fmt.Sprintf("Saved, but %s has unsaved changes! Use :q! to force quit", name))
 		} else {
 			a.quit = true
 		}
@@ -1356,57 +1612,25 @@ func (a *App) handleCommand(cmd string) {
 	case "messages":
 		a.handleMessagesCommand()
 	case "export":
-		if len(parts) < 3 {
-			a.SetStatus("Usage: :export <format> <filename>")
-			return
-		}
-		format := parts[1]
-		filename := parts[2]
-		// Sync tree items back to outline before exporting
-		a.outline.Items = a.tree.GetItems()
-
-		switch format {
-		case "markdown":
-			// Full markdown format with headers as # ## ###
-			if err := export.ExportToMarkdown(a.outline, filename); err != nil {
-				a.SetStatus("Failed to export: " + err.Error())
-			} else {
-				a.SetStatus("Exported to " + filename + " (markdown with headers)")
-			}
-		case "list":
-			// List format with all items as bullets
-			if err := export.ExportToMarkdownList(a.outline, filename); err != nil {
-				a.SetStatus("Failed to export: " + err.Error())
-			} else {
-				a.SetStatus("Exported to " + filename + " (list format)")
-			}
-		default:
-			a.SetStatus("Unknown export format: " + format + " (use 'markdown' or 'list')")
-		}
+		a.handleExportCommand(parts)
 	case "import":
 		if a.readOnly {
 			a.SetStatus("Cannot modify readonly file")
 			return
 		}
 		if len(parts) < 2 {
-			a.SetStatus("Usage: :import <filename> [format]")
+			a.SetStatus("Usage: :import <filename> [format] [--ids] [--title column] [--hierarchy depth|parent]")
 			return
 		}
 		filename := parts[1]
-		format := import_parser.FormatAuto
-		if len(parts) >= 3 {
-			switch parts[2] {
-			case "markdown", "md":
-				format = import_parser.FormatMarkdown
-			case "indented", "text", "txt":
-				format = import_parser.FormatIndentedText
-			default:
-				a.SetStatus("Unknown import format: " + parts[2] + " (use 'markdown' or 'indented')")
-				return
-			}
-		} else {
+		opts, err := parseImportArgs(parts[2:])
+		if err != nil {
+			a.SetStatus(err.Error())
+			return
+		}
+		if opts.Format == import_parser.FormatAuto {
 			// Auto-detect format from extension
-			format = import_parser.DetectFormat(filename)
+			opts.Format = import_parser.DetectFormat(filename)
 		}
 
 		// Read file content
@@ -1417,7 +1641,7 @@ func (a *App) handleCommand(cmd string) {
 		}
 
 		// Parse content
-		items, err := import_parser.ImportFile(string(content), format)
+		items, err := import_parser.ImportFileWithOptions(string(content), opts)
 		if err != nil {
 			a.SetStatus("Failed to import: " + err.Error())
 			return
@@ -1428,6 +1652,8 @@ func (a *App) handleCommand(cmd string) {
 			return
 		}
 
+		a.saveUndoState()
+
 		// Add items to tree at current position
 		currentIdx := a.tree.GetSelectedIndex()
 		if currentIdx < 0 {
@@ -1439,9 +1665,15 @@ func (a *App) handleCommand(cmd string) {
 			// Add as children of current item
 			selectedItem := a.tree.GetSelected()
 			if selectedItem != nil {
-				for _, item := range items {
-					selectedItem.Children = append(selectedItem.Children, item)
-					item.Parent = selectedItem
+				if opts.KeepIDs {
+					// Imported items with the ID of an existing item get a new ID
+					a.outline.Items = a.tree.GetItems()
+					a.outline.Merge(&model.Outline{Items: items}, selectedItem)
+				} else {
+					for _, item := range items {
+						selectedItem.Children = append(selectedItem.Children, item)
+						item.Parent = selectedItem
+					}
 				}
 				selectedItem.Expanded = true // Auto-expand to show imported items
 			}
@@ -1449,93 +1681,99 @@ func (a *App) handleCommand(cmd string) {
 
 		// Sync outline and update tree
 		a.outline.Items = a.tree.GetItems()
+		if opts.KeepIDs {
+			// The file itself may have an ID twice
+			a.outline.DeduplicateIDs()
+		}
 		a.tree.SetItems(a.outline.Items) // Update tree's items and rebuild view
-		a.dirty = true
+		a.markDirty()
 
 		// Force a complete redraw
 		a.screen.Clear()
 
 		a.SetStatus(fmt.Sprintf("Imported %d items from %s", len(items), filename))
 	case "dailynote":
-		if a.readOnly {
-			a.SetStatus("Cannot modify readonly file")
-			return
-		}
-		// Create or navigate to today's daily note
-		now := time.Now()
-		today := now.Format("2006-01-02")               // ISO format for attribute storage
-		formattedDate := now.Format("Mon, Jan 2, 2006") // Short day name format for display
-
-		// Look for existing daily note with today's date (search by date attribute)
-		var foundItem *model.Item
-		for _, item := range a.tree.GetItems() {
-			if item.Metadata != nil && item.Metadata.Attributes != nil {
-				if item.Metadata.Attributes["date"] == today {
-					foundItem = item
-					break
-				}
-			}
-		}
-
-		// If not found, create new item with formatted date
-		if foundItem == nil {
-			item := model.NewItem(formattedDate)
-			a.tree.AddItemAfter(item)
-			// Find the newly created item
-			for _, dispItem := range a.tree.GetDisplayItems() {
-				if dispItem.Item.Text == formattedDate {
-					foundItem = dispItem.Item
-					break
-				}
-			}
-			if foundItem != nil {
-				// Add type and date attributes to the daily note
-				if foundItem.Metadata == nil {
-					foundItem.Metadata = &model.Metadata{
-						Attributes: make(map[string]string),
-						Created:    time.Now(),
-						Modified:   time.Now(),
-					}
-				}
-				if foundItem.Metadata.Attributes == nil {
-					foundItem.Metadata.Attributes = make(map[string]string)
-				}
-				foundItem.Metadata.Attributes["type"] = "day"
-				foundItem.Metadata.Attributes["date"] = today
-			}
-			a.dirty = true
-			a.SetStatus("Created daily note for " + formattedDate)
-		} else {
-			// Navigate to existing daily note
-			for idx, dispItem := range a.tree.GetDisplayItems() {
-				if dispItem.Item.ID == foundItem.ID {
-					a.tree.SelectItem(idx)
-					break
-				}
-			}
-			a.SetStatus("Navigated to daily note for " + formattedDate)
-		}
+		a.handleDailyNoteCommand(parts)
 	case "attr":
 		a.handleAttrCommand(parts)
 	case "tag":
 		a.handleTagCommand(parts)
 	case "set":
 		a.handleSetCommand(parts)
+	case "theme":
+		a.handleThemeCommand(parts)
 	case "search":
 		a.handleSearchCommand(parts)
+	case "duplicate", "dup":
+		a.handleDuplicateCommand()
+	case "fold":
+		a.handleFoldCommand(parts)
+	case "sort":
+		a.handleSortCommand(parts)
 	case "calendar":
 		a.handleCalendarCommand(parts)
 	case "links":
 		a.handleLinksCommand(parts)
+	case "backlinks":
+		a.handleBacklinksCommand()
+	case "goto":
+		a.handleGotoCommand(parts)
+	case "marks":
+		a.handleMarksCommand()
+	case "agenda":
+		a.handleAgendaCommand(parts)
+	case "clock":
+		a.handleClockCommand(parts)
+	case "join":
+		a.handleJoinCommand()
+	case "fix":
+		a.handleFixCommand(parts)
+	case "merge":
+		a.handleMergeCommand(parts)
+	case "promote", "transpose":
+		a.handlePromoteCommand()
+	case "move":
+		a.handleMoveCommand(parts)
+	case "yank":
+		a.handleYankCommand(parts)
+	case "paste":
+		a.handlePasteCommand(parts)
+	case "map":
+		a.handleMapCommand(parts)
+	case "wordcount":
+		a.handleWordcountCommand(parts)
 	case "diff":
 		a.handleDiffCommand(parts)
+	case "backups":
+		a.handleBackupsCommand(parts)
 	case "typedef":
 		a.handleTypedefCommand(parts)
+	case "edit-tree", "edittree":
+		a.handleExternalTreeEdit()
 	default:
 		a.SetStatus("Unknown command: " + parts[0])
 	}
 }
 
+// defaultAutoSaveInterval is the autosave delay used when 'autosave' is not set
+const defaultAutoSaveInterval = 5 * time.Second
+
+// autoSaveInterval returns the delay between autosaves, 0 when autosave is disabled
+func (a *App) autoSaveInterval() time.Duration {
+	if a.cfg == nil {
+		return defaultAutoSaveInterval
+	}
+	value := a.cfg.Get("autosave")
+	if value == "" {
+		return defaultAutoSaveInterval
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return defaultAutoSaveInterval
+	}
+	return time.Duration(seconds) * time.Second
+}
+
 // Save saves the outline to disk
 func (a *App) Save() error {
 	// Sync tree items back to outline before saving
@@ -1546,6 +1784,8 @@ func (a *App) Save() error {
 	}
 	a.dirty = false
 	a.autoSaveTime = time.Now()
+	a.autoPruneBackups()
+	a.publishSaved()
 	return nil
 }
 
@@ -1557,13 +1797,17 @@ func (a *App) Load(filename string) error {
 	if err != nil {
 		return err
 	}
+	problems, repaired := checkOutline(outline, a.cfg)
 
 	a.outline = outline
 	a.tree = ui.NewTreeView(outline.Items)
 	a.dirty = false
+	// Report the items that changed on disk to subscribed clients
+	a.changesPending = true
 	a.autoSaveTime = time.Now()
 	// Update the app's readonly flag based on the store's status
 	a.readOnly = a.store.ReadOnly
+	a.reportOutlineProblems(problems, repaired)
 	return nil
 }
 
@@ -1594,6 +1838,7 @@ func (a *App) SaveAs(filename string) error {
 
 	a.dirty = false
 	a.autoSaveTime = time.Now()
+	a.publishSaved()
 	return nil
 }
 
@@ -1672,6 +1917,18 @@ func (a *App) deleteVisualSelection() {
 		return
 	}
 
+	a.confirmDelete(func() int { return countNodes(selectionRoots(items)) }, func(a *App) {
+		a.deleteItems(items)
+	})
+}
+
+// deleteItems deletes the items of the visual selection and leaves visual mode
+func (a *App) deleteItems(items []*model.Item) {
+	a.saveUndoState()
+
+	// Keep the deleted items so they can be pasted
+	a.clipboard = cloneItems(selectionRoots(items))
+
 	// Delete each item
 	for _, item := range items {
 		a.tree.DeleteItem(item)
@@ -1679,8 +1936,9 @@ func (a *App) deleteVisualSelection() {
 
 	a.mode = NormalMode
 	a.visualAnchor = -1
+	a.searchMatches = nil
 	a.SetStatus(fmt.Sprintf("Deleted %d items", len(items)))
-	a.dirty = true
+	a.markDirty()
 }
 
 // yankVisualSelection yanks (copies) all items in the visual selection range
@@ -1698,11 +1956,8 @@ func (a *App) yankVisualSelection() {
 		return
 	}
 
-	// For now, store just the first item in clipboard
-	// TODO: Extend clipboard to support multiple items
-	if len(items) > 0 {
-		a.clipboard = items[0]
-	}
+	// Store copies so later edits don't change the clipboard
+	a.clipboard = cloneItems(selectionRoots(items))
 
 	a.mode = NormalMode
 	a.visualAnchor = -1
@@ -1724,6 +1979,8 @@ func (a *App) indentVisualSelection() {
 		return
 	}
 
+	a.saveUndoState()
+
 	// Indent each item
 	count := 0
 	for _, item := range items {
@@ -1735,7 +1992,7 @@ func (a *App) indentVisualSelection() {
 	a.mode = NormalMode
 	a.visualAnchor = -1
 	a.SetStatus(fmt.Sprintf("Indented %d items", count))
-	a.dirty = true
+	a.markDirty()
 }
 
 // outdentVisualSelection outdents all items in the visual selection range
@@ -1753,6 +2010,8 @@ func (a *App) outdentVisualSelection() {
 		return
 	}
 
+	a.saveUndoState()
+
 	// Outdent each item
 	count := 0
 	for _, item := range items {
@@ -1764,48 +2023,280 @@ func (a *App) outdentVisualSelection() {
 	a.mode = NormalMode
 	a.visualAnchor = -1
 	a.SetStatus(fmt.Sprintf("Outdented %d items", count))
-	a.dirty = true
+	a.markDirty()
 }
 
-// getVisualSelectionRange returns the start and end indices of the visual selection
-// Returns -1, -1 if not in visual selection
-func (a *App) getVisualSelectionRange() (int, int) {
-	if a.visualAnchor < 0 {
-		return -1, -1
-	}
-
-	current := a.tree.GetSelectedIndex()
-	start := a.visualAnchor
-	end := current
-
-	if start > end {
-		start, end = end, start
+// attrVisualSelection sets or deletes an attribute on all items in the visual
+// selection range. parts is a :attr set or :attr del command.
+func (a *App) attrVisualSelection(parts []string) {
+	start, end := a.getVisualSelectionRange()
+	if start < 0 || end < 0 {
+		a.SetStatus("No selection")
+		return
 	}
 
-	return start, end
-}
-
-// handleTreeMouseClick handles mouse clicks in the tree view
-func (a *App) handleTreeMouseClick(mouseEv *tcell.EventMouse) {
-	// Only handle left mouse button
-	if mouseEv.Buttons()&tcell.Button1 == 0 {
+	items := a.tree.GetItemsInRange(start, end)
+	if len(items) == 0 {
+		a.SetStatus("No selection")
 		return
 	}
 
-	x, y := mouseEv.Position()
+	switch parts[1] {
+	case "add", "set":
+		if len(parts) < 4 {
+			a.SetStatus("Usage: :attr add <key> <value>")
+			return
+		}
+		key := parts[2]
+		value := strings.Join(parts[3:], " ")
 
-	// FIXME: Tree starts at Y = 0
-	treeStartY := 0
+		// Validate attribute value against type definitions if they exist
+		if !a.validateAttributeValue(key, value) {
+			return
+		}
 
-	// Check if click is within tree area
-	if y < treeStartY {
-		return
-	}
+		a.saveUndoState()
+		for _, item := range items {
+			if item.Metadata == nil {
+				item.Metadata = &model.Metadata{Created: time.Now()}
+			}
+			if item.Metadata.Attributes == nil {
+				item.Metadata.Attributes = make(map[string]string)
+			}
+			item.Metadata.Attributes[key] = value
+			item.Metadata.Modified = time.Now()
+			// Setting the node type fills in the type's defaults and template
+			if key == "type" {
+				a.applyTypeDefaults(item)
+			}
+		}
+		if key == "type" {
+			a.tree.RebuildView()
+		}
+		a.SetStatus(fmt.Sprintf("Set %s on %d items", key, len(items)))
 
-	// Calculate which display line was clicked
-	// Need to add viewport offset to account for scrolling
-	viewportOffset := a.tree.GetViewportOffset()
-	displayLineIdx := y - treeStartY + viewportOffset
+	default:
+		if len(parts) < 3 {
+			a.SetStatus("Usage: :attr del <key>")
+			return
+		}
+		key := parts[2]
+
+		var found []*model.Item
+		for _, item := range items {
+			if item.Metadata == nil {
+				continue
+			}
+			if _, exists := item.Metadata.Attributes[key]; exists {
+				found = append(found, item)
+			}
+		}
+		if len(found) == 0 {
+			a.SetStatus(fmt.Sprintf("Attribute '%s' not found", key))
+			return
+		}
+
+		a.saveUndoState()
+		for _, item := range found {
+			delete(item.Metadata.Attributes, key)
+			item.Metadata.Modified = time.Now()
+		}
+		a.SetStatus(fmt.Sprintf("Deleted %s from %d items", key, len(found)))
+	}
+
+	a.mode = NormalMode
+	a.visualAnchor = -1
+	a.markDirty()
+}
+
+// tagVisualSelection adds or removes tags on all items in the visual selection
+// range. parts is a :tag add or :tag remove command.
+func (a *App) tagVisualSelection(parts []string) {
+	start, end := a.getVisualSelectionRange()
+	if start < 0 || end < 0 {
+		a.SetStatus("No selection")
+		return
+	}
+
+	items := a.tree.GetItemsInRange(start, end)
+	if len(items) == 0 {
+		a.SetStatus("No selection")
+		return
+	}
+
+	if parts[1] == "add" {
+		if len(parts) < 3 {
+			a.SetStatus("Usage: :tag add <tag1> [tag2] ...")
+			return
+		}
+		tags := parts[2:]
+		a.saveUndoState()
+		for _, item := range items {
+			for _, tag := range tags {
+				item.AddTag(tag)
+			}
+		}
+		if len(tags) == 1 {
+			a.SetStatus(fmt.Sprintf("Tag '%s' added to %d items", tags[0], len(items)))
+		} else {
+			a.SetStatus(fmt.Sprintf("%d tags added to %d items", len(tags), len(items)))
+		}
+	} else {
+		if len(parts) < 3 {
+			a.SetStatus("Usage: :tag remove <tag>")
+			return
+		}
+		tag := parts[2]
+
+		var found []*model.Item
+		for _, item := range items {
+			if item.HasTag(tag) {
+				found = append(found, item)
+			}
+		}
+		if len(found) == 0 {
+			a.SetStatus(fmt.Sprintf("Tag '%s' not found", tag))
+			return
+		}
+
+		a.saveUndoState()
+		for _, item := range found {
+			item.RemoveTag(tag)
+		}
+		a.SetStatus(fmt.Sprintf("Tag '%s' removed from %d items", tag, len(found)))
+	}
+
+	a.mode = NormalMode
+	a.visualAnchor = -1
+	a.markDirty()
+}
+
+// pasteClipboard inserts copies of all clipboard items after (or before) the selected item.
+// The items keep their order, and every paste makes new copies so pasted nodes are never shared.
+func (a *App) pasteClipboard(before bool) {
+	if len(a.clipboard) == 0 {
+		return
+	}
+
+	state := a.captureUndoState()
+	var pasted []*model.Item
+	if before {
+		// Paste in reverse, each item goes before the previously pasted one
+		for i := len(a.clipboard) - 1; i >= 0; i-- {
+			pastedItem := a.tree.PasteBefore(model.CloneItemTree(a.clipboard[i]))
+			if pastedItem == nil {
+				break
+			}
+			a.tree.SelectItemByID(pastedItem.ID)
+			pasted = append(pasted, pastedItem)
+		}
+	} else {
+		for _, item := range a.clipboard {
+			pastedItem := a.tree.PasteAfter(model.CloneItemTree(item))
+			if pastedItem == nil {
+				break
+			}
+			a.tree.SelectItemByID(pastedItem.ID)
+			pasted = append(pasted, pastedItem)
+		}
+	}
+	if len(pasted) == 0 {
+		return
+	}
+
+	a.pushUndoState(state)
+	a.markDirty()
+	a.refreshSearchNodes()
+	a.tree.SelectItemByID(pasted[len(pasted)-1].ID)
+	if len(pasted) == 1 {
+		a.SetStatus("Pasted item")
+	} else {
+		a.SetStatus(fmt.Sprintf("Pasted %d items", len(pasted)))
+	}
+}
+
+// selectionRoots returns the items that don't have an ancestor in the same list,
+// so a selected parent and its visible children are only copied once
+func selectionRoots(items []*model.Item) []*model.Item {
+	selected := make(map[*model.Item]bool, len(items))
+	for _, item := range items {
+		selected[item] = true
+	}
+
+	var roots []*model.Item
+	for _, item := range items {
+		isRoot := true
+		for parent := item.Parent; parent != nil; parent = parent.Parent {
+			if selected[parent] {
+				isRoot = false
+				break
+			}
+		}
+		if isRoot {
+			roots = append(roots, item)
+		}
+	}
+	return roots
+}
+
+// cloneItems deep-copies a list of items
+func cloneItems(items []*model.Item) []*model.Item {
+	clones := make([]*model.Item, 0, len(items))
+	for _, item := range items {
+		clones = append(clones, model.CloneItemTree(item))
+	}
+	return clones
+}
+
+// splitItemText keeps the text before pos in the item and returns the rest
+func splitItemText(item *model.Item, pos int) string {
+	rest := item.Text[pos:]
+	item.Text = item.Text[:pos]
+	if item.Metadata != nil {
+		item.Metadata.Modified = time.Now()
+	}
+	return rest
+}
+
+// getVisualSelectionRange returns the start and end indices of the visual selection
+// Returns -1, -1 if not in visual selection
+func (a *App) getVisualSelectionRange() (int, int) {
+	if a.visualAnchor < 0 {
+		return -1, -1
+	}
+
+	current := a.tree.GetSelectedIndex()
+	start := a.visualAnchor
+	end := current
+
+	if start > end {
+		start, end = end, start
+	}
+
+	return start, end
+}
+
+// handleTreeMouseClick handles mouse clicks in the tree view
+func (a *App) handleTreeMouseClick(mouseEv *tcell.EventMouse) {
+	// Only handle left mouse button
+	if mouseEv.Buttons()&tcell.Button1 == 0 {
+		return
+	}
+
+	x, y := mouseEv.Position()
+
+	// FIXME: Tree starts at Y = 0
+	treeStartY := 0
+
+	// Check if click is within tree area
+	if y < treeStartY {
+		return
+	}
+
+	// Calculate which display line was clicked
+	// Need to add viewport offset to account for scrolling
+	viewportOffset := a.tree.GetViewportOffset()
+	displayLineIdx := y - treeStartY + viewportOffset
 
 	// Check if we're in search mode
 	if a.search.IsActive() {
@@ -1838,7 +2329,7 @@ func (a *App) handleTreeMouseClick(mouseEv *tcell.EventMouse) {
 	displayLine := a.tree.GetDisplayLines()[displayLineIdx]
 	if displayLine.ItemStartLine {
 		dispItem := displayItems[itemIdx]
-		arrowX := dispItem.Depth * 3
+		arrowX := a.tree.ArrowX(dispItem.Depth)
 
 		// Arrow is at position arrowX, click is on it if within those bounds
 		if x >= arrowX && x < arrowX+1 && len(dispItem.Item.Children) > 0 {
@@ -1880,7 +2371,7 @@ func (a *App) handleEditorMouseClick(mouseEv *tcell.EventMouse) {
 	}
 
 	depth := a.tree.GetSelectedDepth()
-	editorX := depth*3 + 3 // indentation + arrow + space
+	editorX := a.tree.TextX(depth) // indentation + arrow + space
 
 	// Calculate cursor position from click
 	if x >= editorX {
@@ -1925,9 +2416,113 @@ func (a *App) handleMessagesCommand() {
 	// Store messages in a temporary state for rendering
 	a.messagesViewActive = true
 	a.messagesViewMessages = messages
+	a.messagesViewItems = nil
 	a.messagesViewScroll = 0
 }
 
+// searchItems returns the items the search bar searches, only the hoisted subtree
+// when hoisted, unless the searchscope setting is "all"
+func (a *App) searchItems() []*model.Item {
+	if hoistedItem := a.tree.GetHoistedItem(); hoistedItem != nil && a.searchScope() != "all" {
+		// Get all items within the hoisted subtree
+		return ui.GetAllItemsRecursive(hoistedItem)
+	}
+	return a.outline.GetAllItems()
+}
+
+// searchNodeItems returns the items search nodes match against, only the
+// hoisted subtree when hoisted and the searchscope setting is "hoist"
+func (a *App) searchNodeItems() []*model.Item {
+	if hoistedItem := a.tree.GetHoistedItem(); hoistedItem != nil && a.searchScope() == "hoist" {
+		return ui.GetAllItemsRecursive(hoistedItem)
+	}
+	return a.outline.GetAllItems()
+}
+
+// searchScope returns the searchscope setting: "hoist", "all" or empty for
+// the default, where only the search bar is limited to the hoisted subtree
+func (a *App) searchScope() string {
+	if a.cfg == nil {
+		return ""
+	}
+	return a.cfg.Get("searchscope")
+}
+
+// searchScopeChanged updates the search and search nodes after hoisting,
+// unhoisting or changing searchscope
+func (a *App) searchScopeChanged() {
+	if a.search != nil && a.search.IsActive() {
+		a.search.SetAllItems(a.searchItems())
+	}
+	if a.searchScope() == "hoist" {
+		a.refreshSearchNodes()
+	}
+}
+
+// keepSearchMatches remembers the matches of the search bar when it is closed,
+// so n and N can go through them in normal mode
+func (a *App) keepSearchMatches() {
+	a.searchMatches = a.search.Matches()
+	a.searchMatchIdx = max(a.search.GetCurrentMatchNumber()-1, 0)
+}
+
+// jumpToSearchMatch selects the next (1) or previous (-1) match of the last
+// search, wrapping around at the ends
+func (a *App) jumpToSearchMatch(offset int) {
+	if len(a.searchMatches) == 0 {
+		a.SetStatus("No search matches")
+		return
+	}
+
+	idx := a.searchMatchIdx + offset
+	wrapped := ""
+	if idx >= len(a.searchMatches) {
+		idx = 0
+		wrapped = ", search hit bottom, continuing at top"
+	} else if idx < 0 {
+		idx = len(a.searchMatches) - 1
+		wrapped = ", search hit top, continuing at bottom"
+	}
+	a.searchMatchIdx = idx
+
+	if !a.jumpToItem(a.searchMatches[idx]) {
+		return
+	}
+	a.SetStatus(fmt.Sprintf("Match %d of %d%s", idx+1, len(a.searchMatches), wrapped))
+}
+
+// showSearchMatch selects the current search match and shows the match count.
+// While a search on a large outline is still matching, a missing match isn't reported yet.
+func (a *App) showSearchMatch() {
+	if a.search.HasResults() {
+		currentMatch := a.search.GetCurrentMatch()
+		if currentMatch != nil {
+			// Expand all parent nodes of the match so it becomes visible
+			a.tree.ExpandParents(currentMatch)
+			// Find and select this item in the main tree
+			items := a.tree.GetDisplayItems()
+			for idx, dispItem := range items {
+				if dispItem.Item.ID == currentMatch.ID {
+					a.tree.SelectItem(idx)
+					break
+				}
+			}
+			matchNum := a.search.GetCurrentMatchNumber()
+			totalMatches := a.search.GetMatchCount()
+			if a.search.IsScanning() {
+				a.SetStatus(fmt.Sprintf("Match %d of %d+", matchNum, totalMatches))
+			} else {
+				a.SetStatus(fmt.Sprintf("Match %d of %d", matchNum, totalMatches))
+			}
+		}
+	} else if a.search.IsScanning() {
+		a.SetStatus("Searching...")
+	} else if a.search.GetQuery() != "" {
+		// Query is not empty but no matches
+		a.SetStatus("No matches")
+	}
+}
+
 // handleAttrCommand processes attribute-related commands
 func (a *App) handleAttrCommand(parts []string) {
 	// Check if trying to modify attributes on a readonly file
@@ -1938,6 +2533,15 @@ func (a *App) handleAttrCommand(parts []string) {
 		}
 	}
 
+	// In visual mode set and del apply to every selected item
+	if a.mode == VisualMode && len(parts) > 1 {
+		switch parts[1] {
+		case "add", "set", "del", "delete", "remove":
+			a.attrVisualSelection(parts)
+			return
+		}
+	}
+
 	selected := a.tree.GetSelected()
 	if selected == nil {
 		a.SetStatus("No item selected")
@@ -1979,11 +2583,23 @@ func (a *App) handleAttrCommand(parts []string) {
 			return
 		}
 
+		a.saveUndoState()
 		selected.Metadata.Attributes[key] = value
 		selected.Metadata.Modified = time.Now()
-		a.dirty = true
+		a.recordAction(func(a *App) {
+			a.handleAttrCommand([]string{"attr", "set", key, value})
+		})
+		a.markDirty()
 		a.SetStatus(fmt.Sprintf("Attribute '%s' set to '%s'", key, value))
 
+		// Setting the node type fills in the type's defaults and template
+		if key == "type" {
+			if set, added := a.applyTypeDefaults(selected); set > 0 || added > 0 {
+				a.tree.RebuildView()
+				a.SetStatus(fmt.Sprintf("Type set to '%s': %d default attributes, %d template items", value, set, added))
+			}
+		}
+
 	case "del", "delete", "remove":
 		if len(parts) < 3 {
 			a.SetStatus("Usage: :attr del <key>")
@@ -1994,9 +2610,13 @@ func (a *App) handleAttrCommand(parts []string) {
 			a.SetStatus(fmt.Sprintf("Attribute '%s' not found", key))
 			return
 		}
+		a.saveUndoState()
 		delete(selected.Metadata.Attributes, key)
 		selected.Metadata.Modified = time.Now()
-		a.dirty = true
+		a.recordAction(func(a *App) {
+			a.handleAttrCommand([]string{"attr", "del", key})
+		})
+		a.markDirty()
 		a.SetStatus(fmt.Sprintf("Attribute '%s' deleted", key))
 
 	case "list", "show", "view":
@@ -2017,6 +2637,15 @@ func (a *App) handleTagCommand(parts []string) {
 		}
 	}
 
+	// In visual mode add and remove apply to every selected item
+	if a.mode == VisualMode && len(parts) > 1 {
+		switch parts[1] {
+		case "add", "del", "delete", "remove", "rm":
+			a.tagVisualSelection(parts)
+			return
+		}
+	}
+
 	selected := a.tree.GetSelected()
 	if selected == nil {
 		a.SetStatus("No item selected")
@@ -2048,7 +2677,7 @@ func (a *App) handleTagCommand(parts []string) {
 		for _, tag := range tags {
 			selected.AddTag(tag)
 		}
-		a.dirty = true
+		a.markDirty()
 		if len(tags) == 1 {
 			a.SetStatus(fmt.Sprintf("Tag '%s' added", tags[0]))
 		} else {
@@ -2066,7 +2695,7 @@ func (a *App) handleTagCommand(parts []string) {
 			return
 		}
 		selected.RemoveTag(tag)
-		a.dirty = true
+		a.markDirty()
 		a.SetStatus(fmt.Sprintf("Tag '%s' removed", tag))
 
 	case "list", "show", "view":
@@ -2078,7 +2707,7 @@ func (a *App) handleTagCommand(parts []string) {
 			return
 		}
 		selected.ClearTags()
-		a.dirty = true
+		a.markDirty()
 		a.SetStatus("All tags cleared")
 
 	default:
@@ -2242,78 +2871,631 @@ func (a *App) handleFollowLinkCommand() {
 	a.SetStatus("Could not navigate to linked item")
 }
 
-// handleLinksCommand displays all links in the current item
-func (a *App) handleLinksCommand(parts []string) {
-	selected := a.tree.GetSelected()
-	if selected == nil {
-		a.SetStatus("No item selected")
+// changeTree runs change with undo and marks the outline as modified when it
+// returns true. The status becomes done or failed.
+func (a *App) changeTree(change func() bool, done, failed string) {
+	if a.readOnly {
+		a.SetStatus("File is readonly")
 		return
 	}
 
-	// Parse links from the selected item's text
-	itemLinks := links.ParseLinks(selected.Text)
-	if len(itemLinks) == 0 {
-		a.SetStatus("No links found in current item")
+	state := a.captureUndoState()
+	if !change() {
+		a.SetStatus(failed)
 		return
 	}
+	a.pushUndoState(state)
+	a.SetStatus(done)
+	a.markDirty()
+}
 
-	// Build a list of link descriptions
-	var linkDescs []string
-	for i, link := range itemLinks {
-		displayText := link.GetDisplayText()
-		linkDescs = append(linkDescs, fmt.Sprintf("%d. %s -> %s", i+1, displayText, link.ID))
+// moveWithinSiblings moves the selected item to the top or bottom of its siblings
+func (a *App) moveWithinSiblings(toTop bool) {
+	if toTop {
+		a.changeTree(a.tree.MoveToTopOfSiblings, "Moved item to the top", "Item is already there")
+		return
 	}
+	a.changeTree(a.tree.MoveToBottomOfSiblings, "Moved item to the bottom", "Item is already there")
+}
 
-	// Show the first few links in status (for longer lists, show just count)
-	if len(linkDescs) == 1 {
-		a.SetStatus("Found 1 link: " + linkDescs[0])
-	} else if len(linkDescs) <= 3 {
-		a.SetStatus(fmt.Sprintf("Found %d links: %s", len(linkDescs), strings.Join(linkDescs, " | ")))
-	} else {
-		a.SetStatus(fmt.Sprintf("Found %d links (use gf to follow first one)", len(linkDescs)))
+// swapWithSibling swaps the selected item with its previous or next sibling
+func (a *App) swapWithSibling(prev bool) {
+	if prev {
+		a.changeTree(a.tree.SwapWithPrevSibling, "Swapped with previous sibling", "No previous sibling")
+		return
 	}
+	a.changeTree(a.tree.SwapWithNextSibling, "Swapped with next sibling", "No next sibling")
 }
 
-// handlePasteAsChildCommand pastes the clipboard item as a child of the selected item
-func (a *App) handlePasteAsChildCommand() {
-	if a.clipboard == nil {
-		a.SetStatus("Nothing to paste (clipboard is empty)")
+// handleJoinCommand joins the selected item with its next sibling
+func (a *App) handleJoinCommand() {
+	a.changeTree(a.tree.JoinWithNext, "Joined with next sibling", "No next sibling to join")
+}
+
+// handleFixCommand repairs problems in the outline
+// Usage: :fix ids - give items with a duplicate ID a new ID
+// Usage: :fix outline - repair the problems that are reported on load
+func (a *App) handleFixCommand(parts []string) {
+	if len(parts) != 2 || (parts[1] != "ids" && parts[1] != "outline") {
+		a.SetStatus("Usage: :fix ids|outline")
+		return
+	}
+	if a.readOnly {
+		a.SetStatus("File is readonly")
+		return
+	}
+	if parts[1] == "outline" {
+		a.fixOutline()
 		return
 	}
 
-	newItem := model.NewItemFrom(a.clipboard)
+	// Sync outline with tree so new items are checked
+	a.outline.Items = a.tree.GetItems()
 
-	a.tree.AddItemAsChild(newItem)
-	a.dirty = true
-	a.SetStatus(fmt.Sprintf("Pasted as child: %s", newItem.Text))
+	state := a.captureUndoState()
+	count := a.outline.DeduplicateIDs()
+	if count == 0 {
+		a.SetStatus("No duplicate IDs")
+		return
+	}
+	a.pushUndoState(state)
+	a.outline.ResolveVirtualChildren()
+	a.refreshSearchNodes()
+	a.SetStatus(fmt.Sprintf("Reassigned %d duplicate IDs", count))
+	a.markDirty()
 }
 
-// handleSendToNode opens the node search widget to select a destination and sends the current item there
-func (a *App) handleSendToNode() {
+// handleMergeCommand adds the items of another outline file under a new
+// "Merged <date>" root item, or under the item with the given ID
+// Usage: :merge <file> [parent-id]
+func (a *App) handleMergeCommand(parts []string) {
+	if len(parts) < 2 || len(parts) > 3 {
+		a.SetStatus("Usage: :merge <file> [parent-id]")
+		return
+	}
 	if a.readOnly {
 		a.SetStatus("File is readonly")
 		return
 	}
 
-	selected := a.tree.GetSelected()
-	if selected == nil {
-		a.SetStatus("No item selected")
+	store := storage.NewJSONStore(parts[1])
+	if store.NeedsPassphrase() {
+		a.SetStatus("Encrypted files can only be merged from the command line")
+		return
+	}
+	other, err := store.Load()
+	if err != nil {
+		a.SetStatus("Failed to load " + parts[1] + ": " + err.Error())
+		return
+	}
+	if len(other.Items) == 0 {
+		a.SetStatus("No items found in file")
 		return
 	}
 
-	// Set up the node search widget
-	a.nodeSearchWidget.SetItems(a.outline.GetAllItems())
-	a.nodeSearchWidget.SetOnSelect(func(destination *model.Item) {
-		if destination == nil {
-			a.SetStatus("No destination selected")
+	// Sync outline with tree so new items can be found
+	a.outline.Items = a.tree.GetItems()
+	a.outline.BuildIndex()
+
+	var parent *model.Item
+	if len(parts) == 3 {
+		parent = a.outline.FindItemByID(parts[2])
+		if parent == nil {
+			a.SetStatus(fmt.Sprintf("Item not found: %s", parts[2]))
 			return
 		}
+	}
 
-		// Attempt to send the item
-		if a.tree.SendItemToNode(destination) {
-			a.lastSendDestination = destination
-			a.dirty = true
-			// Truncate destination text if it's too long for status display
+	state := a.captureUndoState()
+	if parent == nil {
+		// The new root item is only visible in the full tree
+		if a.tree.Unhoist() {
+			a.searchScopeChanged()
+		}
+		parent = model.NewItem("Merged " + time.Now().Format("2006-01-02"))
+		a.outline.Items = append(a.outline.Items, parent)
+		a.tree.SetItems(a.outline.Items)
+	}
+	count := a.outline.Merge(other, parent)
+	a.pushUndoState(state)
+
+	parent.Expanded = true
+	a.tree.RebuildView()
+	a.jumpToItem(parent)
+	a.refreshSearchNodes()
+	a.markDirty()
+
+	status := fmt.Sprintf("Merged %d items from %s", len(other.Items), parts[1])
+	if count > 0 {
+		status += fmt.Sprintf(", reassigned %d duplicate IDs", count)
+	}
+	a.SetStatus(status)
+}
+
+// handlePromoteCommand swaps the selected item with its parent
+func (a *App) handlePromoteCommand() {
+	if a.readOnly {
+		a.SetStatus("File is readonly")
+		return
+	}
+
+	state := a.captureUndoState()
+	if !a.tree.PromoteAboveParent(a.tree.GetSelected()) {
+		a.SetStatus("Cannot promote: item has no parent")
+		return
+	}
+	a.pushUndoState(state)
+	a.SetStatus("Promoted above parent")
+	a.markDirty()
+}
+
+// handleMoveCommand moves the selected item to a position under another item
+// Usage: :move <parent-id> <position>, where position 1 is the first child
+func (a *App) handleMoveCommand(parts []string) {
+	if a.readOnly {
+		a.SetStatus("File is readonly")
+		return
+	}
+	if len(parts) != 3 {
+		a.SetStatus("Usage: :move <parent-id> <position>")
+		return
+	}
+	position, err := strconv.Atoi(parts[2])
+	if err != nil || position < 1 {
+		a.SetStatus(fmt.Sprintf("Invalid position: %s (use 1 for the first child)", parts[2]))
+		return
+	}
+
+	selected := a.tree.GetSelected()
+	if selected == nil {
+		a.SetStatus("No item selected")
+		return
+	}
+
+	// Sync outline with tree so new items can be found
+	a.outline.Items = a.tree.GetItems()
+	a.outline.BuildIndex()
+
+	parent := a.outline.FindItemByID(parts[1])
+	if parent == nil {
+		a.SetStatus(fmt.Sprintf("Item not found: %s", parts[1]))
+		return
+	}
+
+	state := a.captureUndoState()
+	if !a.tree.MoveItemTo(selected, parent, position-1) {
+		a.SetStatus("Cannot move item (circular reference or invalid parent)")
+		return
+	}
+	a.pushUndoState(state)
+	a.markDirty()
+	a.SetStatus(fmt.Sprintf("Moved to position %d under: %s", slices.Index(parent.Children, selected)+1, parent.Text))
+}
+
+// handleGotoCommand selects the item with the given ID
+// Usage: :goto <id>
+func (a *App) handleGotoCommand(parts []string) {
+	if len(parts) < 2 {
+		a.SetStatus("Usage: :goto <id>")
+		return
+	}
+	id := parts[1]
+
+	// Sync outline with tree so new items can be found
+	a.outline.Items = a.tree.GetItems()
+	a.outline.BuildIndex()
+
+	target := a.outline.FindItemByID(id)
+	if target == nil {
+		a.SetStatus(fmt.Sprintf("Item not found: %s", id))
+		return
+	}
+
+	if a.jumpToItem(target) {
+		a.SetStatus(fmt.Sprintf("Jumped to: %s", target.Text))
+	}
+}
+
+// jumpToItem selects target, expanding its parents and unhoisting when the
+// target is outside the hoisted subtree. Returns false when it failed.
+func (a *App) jumpToItem(target *model.Item) bool {
+	if hoisted := a.tree.GetHoistedItem(); hoisted != nil && !isDescendantOf(target, hoisted) {
+		a.tree.Unhoist()
+		a.searchScopeChanged()
+	}
+
+	a.tree.ExpandParents(target)
+	a.tree.SelectItemByID(target.ID)
+	if selected := a.tree.GetSelected(); selected == nil || selected.ID != target.ID {
+		a.SetStatus("Could not navigate to item")
+		return false
+	}
+	return true
+}
+
+// isDescendantOf reports whether item is below ancestor in the tree
+func isDescendantOf(item, ancestor *model.Item) bool {
+	for parent := item.Parent; parent != nil; parent = parent.Parent {
+		if parent == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// handleLinksCommand displays all links in the current item
+func (a *App) handleLinksCommand(parts []string) {
+	selected := a.tree.GetSelected()
+	if selected == nil {
+		a.SetStatus("No item selected")
+		return
+	}
+
+	// Parse links from the selected item's text
+	itemLinks := links.ParseLinks(selected.Text)
+	if len(itemLinks) == 0 {
+		a.SetStatus("No links found in current item")
+		return
+	}
+
+	// Build a list of link descriptions
+	var linkDescs []string
+	for i, link := range itemLinks {
+		displayText := link.GetDisplayText()
+		linkDescs = append(linkDescs, fmt.Sprintf("%d. %s -> %s", i+1, displayText, link.ID))
+	}
+
+	// Show the first few links in status (for longer lists, show just count)
+	if len(linkDescs) == 1 {
+		a.SetStatus("Found 1 link: " + linkDescs[0])
+	} else if len(linkDescs) <= 3 {
+		a.SetStatus(fmt.Sprintf("Found %d links: %s", len(linkDescs), strings.Join(linkDescs, " | ")))
+	} else {
+		a.SetStatus(fmt.Sprintf("Found %d links (use gf to follow first one)", len(linkDescs)))
+	}
+}
+
+// handlePasteAsChildCommand pastes the clipboard item as a child of the selected item
+func (a *App) handlePasteAsChildCommand() {
+	if a.clipboard == nil {
+		a.SetStatus("Nothing to paste (clipboard is empty)")
+		return
+	}
+
+	a.saveUndoState()
+	for _, item := range a.clipboard {
+		a.tree.AddItemAsChild(model.CloneItemTree(item))
+	}
+	a.markDirty()
+	if len(a.clipboard) == 1 {
+		a.SetStatus(fmt.Sprintf("Pasted as child: %s", a.clipboard[0].Text))
+	} else {
+		a.SetStatus(fmt.Sprintf("Pasted %d items as children", len(a.clipboard)))
+	}
+}
+
+// handleFoldCommand folds the tree so only items up to the given level are expanded
+// Usage: :fold <n>
+func (a *App) handleFoldCommand(parts []string) {
+	if len(parts) < 2 {
+		a.SetStatus("Usage: :fold <level>")
+		return
+	}
+
+	level, err := strconv.Atoi(parts[1])
+	if err != nil || level < 0 {
+		a.SetStatus(fmt.Sprintf("Invalid fold level '%s'. Use a number >= 0", parts[1]))
+		return
+	}
+
+	a.tree.CollapseToLevel(level)
+	a.SetStatus(fmt.Sprintf("Folded to level %d", level))
+	a.markDirty()
+}
+
+// handleSortCommand sorts the children of the selected item (or the root items)
+// Usage: :sort [text|created|modified|attr:<name>] [asc|desc]
+func (a *App) handleSortCommand(parts []string) {
+	if a.readOnly {
+		a.SetStatus("File is readonly")
+		return
+	}
+
+	key := "text"
+	descending := false
+	if len(parts) >= 2 {
+		key = parts[1]
+	}
+	if len(parts) >= 3 {
+		switch parts[2] {
+		case "asc":
+			descending = false
+		case "desc":
+			descending = true
+		default:
+			a.SetStatus("Usage: :sort [key] [asc|desc]")
+			return
+		}
+	}
+	if !ui.IsValidSortKey(key) {
+		a.SetStatus(fmt.Sprintf("Unknown sort key '%s' (use text, created, modified or attr:<name>)", key))
+		return
+	}
+
+	selected := a.tree.GetSelected()
+	var parent *model.Item
+	if selected != nil {
+		if len(selected.Children) == 0 {
+			a.SetStatus("Item has no children to sort")
+			return
+		}
+		parent = selected
+	} else if a.tree.IsHoisted() {
+		parent = a.tree.GetHoistedItem()
+	}
+
+	a.saveUndoState()
+	if key == "attr:priority" {
+		// Sort priorities by importance instead of alphabetically
+		a.tree.SortChildrenByPriority(parent, ui.PriorityLevelsFromConfig(a.cfg), descending)
+	} else {
+		a.tree.SortChildren(parent, key, descending)
+	}
+	if selected != nil {
+		a.tree.SelectItemByID(selected.ID)
+	}
+	a.markDirty()
+	a.SetStatus(fmt.Sprintf("Sorted by %s", key))
+}
+
+// parseImportArgs parses the arguments of :import after the filename: an optional
+// format, --ids to keep the IDs of markdown and text files, and the CSV options
+// --title <column> and --hierarchy depth|parent
+func parseImportArgs(args []string) (import_parser.ImportOptions, error) {
+	opts := import_parser.ImportOptions{Format: import_parser.FormatAuto}
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; arg {
+		case "--ids":
+			opts.KeepIDs = true
+		case "--title", "--hierarchy":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("Missing value for %s", arg)
+			}
+			i++
+			if arg == "--title" {
+				opts.TitleColumn = args[i]
+			} else {
+				opts.Hierarchy = args[i]
+			}
+		case "markdown", "md":
+			opts.Format = import_parser.FormatMarkdown
+		case "indented", "text", "txt":
+			opts.Format = import_parser.FormatIndentedText
+		case "opml":
+			opts.Format = import_parser.FormatOPML
+		case "org":
+			opts.Format = import_parser.FormatOrg
+		case "csv":
+			opts.Format = import_parser.FormatCSV
+		default:
+			return opts, fmt.Errorf("Unknown import format: %s (use 'markdown', 'indented', 'opml', 'org' or 'csv')", arg)
+		}
+	}
+	return opts, nil
+}
+
+// handleExportCommand exports the outline with :export <format> <filename> [options]
+func (a *App) handleExportCommand(parts []string) {
+	if len(parts) < 3 {
+		a.SetStatus("Usage: :export <format> <filename> [--subtree] [--checkboxes] [--attrs] [--frontmatter] [--ids]")
+		return
+	}
+	format := parts[1]
+	filename := parts[2]
+
+	subtree := false
+	var mdOpts export.MarkdownOptions
+	var textOpts export.TextOptions
+	for _, option := range parts[3:] {
+		switch option {
+		case "--subtree":
+			subtree = true
+		case "--checkboxes":
+			mdOpts.TodoCheckboxes = true
+			if statuses := a.cfg.Get("todostatuses"); statuses != "" {
+				mdOpts.TodoStatuses = strings.Split(statuses, ",")
+			}
+		case "--attrs":
+			for name := range strings.SplitSeq(a.cfg.Get("visattr"), ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					mdOpts.Attributes = append(mdOpts.Attributes, name)
+				}
+			}
+		case "--frontmatter":
+			mdOpts.Frontmatter = true
+		case "--ids":
+			mdOpts.IDs = true
+			textOpts.IDs = true
+		default:
+			a.SetStatus("Unknown export option: " + option)
+			return
+		}
+	}
+
+	// Sync tree items back to outline before exporting
+	a.outline.Items = a.tree.GetItems()
+
+	exportOutline := a.exportSource(subtree)
+	if exportOutline == nil {
+		return
+	}
+
+	switch format {
+	case "markdown":
+		// Full markdown format with headers as # ## ###
+		if err := export.ExportToMarkdownWithOptions(exportOutline, filename, mdOpts); err != nil {
+			a.SetStatus("Failed to export: " + err.Error())
+		} else {
+			a.SetStatus("Exported to " + filename + " (markdown with headers)")
+		}
+	case "list":
+		// List format with all items as bullets
+		if err := export.ExportToMarkdownList(exportOutline, filename); err != nil {
+			a.SetStatus("Failed to export: " + err.Error())
+		} else {
+			a.SetStatus("Exported to " + filename + " (list format)")
+		}
+	case "opml":
+		// OPML 2.0 outline format
+		if err := export.ExportToOPML(exportOutline, filename); err != nil {
+			a.SetStatus("Failed to export: " + err.Error())
+		} else {
+			a.SetStatus("Exported to " + filename + " (opml)")
+		}
+	case "html":
+		// Self-contained web page with collapsible subtrees
+		var htmlOpts export.HTMLOptions
+		if statuses := a.cfg.Get("todostatuses"); statuses != "" {
+			htmlOpts.TodoStatuses = strings.Split(statuses, ",")
+		}
+		if err := export.ExportToHTMLWithOptions(exportOutline, filename, htmlOpts); err != nil {
+			a.SetStatus("Failed to export: " + err.Error())
+		} else {
+			a.SetStatus("Exported to " + filename + " (html)")
+		}
+	case "org":
+		// Org-mode headings with todo keywords and property drawers
+		var orgOpts export.OrgOptions
+		if statuses := a.cfg.Get("todostatuses"); statuses != "" {
+			orgOpts.TodoStatuses = strings.Split(statuses, ",")
+		}
+		if err := export.ExportToOrgWithOptions(exportOutline, filename, orgOpts); err != nil {
+			a.SetStatus("Failed to export: " + err.Error())
+		} else {
+			a.SetStatus("Exported to " + filename + " (org)")
+		}
+	case "text", "txt":
+		// Plain text indented by two spaces per level
+		if err := export.ExportToTextWithOptions(exportOutline, filename, textOpts); err != nil {
+			a.SetStatus("Failed to export: " + err.Error())
+		} else {
+			a.SetStatus("Exported to " + filename + " (text)")
+		}
+	default:
+		a.SetStatus("Unknown export format: " + format + " (use 'markdown', 'list', 'opml', 'html', 'org' or 'text')")
+	}
+}
+
+// exportSource returns the outline to export. With subtree set the selected item
+// and its descendants are exported. Otherwise the whole outline is exported, or the
+// hoisted subtree when the 'exporthoist' setting is true.
+// Returns nil after setting the status when there is nothing to export.
+func (a *App) exportSource(subtree bool) *model.Outline {
+	var root *model.Item
+	if subtree {
+		root = a.tree.GetSelected()
+		if root == nil {
+			a.SetStatus("No item selected")
+			return nil
+		}
+	} else if a.cfg != nil && a.cfg.Get("exporthoist") == "true" {
+		root = a.tree.GetHoistedItem()
+	}
+	if root == nil {
+		return a.outline
+	}
+
+	return &model.Outline{
+		Items:            []*model.Item{root},
+		OriginalFilename: a.outline.OriginalFilename,
+	}
+}
+
+// handleWordcountCommand shows the number of words, characters and nodes.
+// Like :export it counts the whole outline, or the selected subtree with --subtree.
+// Usage: :wordcount [--subtree]
+func (a *App) handleWordcountCommand(parts []string) {
+	subtree := false
+	for _, option := range parts[1:] {
+		if option != "--subtree" {
+			a.SetStatus("Usage: :wordcount [--subtree]")
+			return
+		}
+		subtree = true
+	}
+
+	// Sync tree items back to outline before counting
+	a.outline.Items = a.tree.GetItems()
+
+	source := a.exportSource(subtree)
+	if source == nil {
+		return
+	}
+
+	counts := model.CountText(source.Items)
+	a.SetStatus(fmt.Sprintf("%s, %s, %s",
+		pluralize(counts.Words, "word", "words"),
+		pluralize(counts.Characters, "character", "characters"),
+		pluralize(counts.Nodes, "node", "nodes")))
+}
+
+// handleDuplicateCommand inserts a deep copy of the selected subtree as the next sibling
+func (a *App) handleDuplicateCommand() {
+	if a.readOnly {
+		a.SetStatus("File is readonly")
+		return
+	}
+
+	selected := a.tree.GetSelected()
+	if selected == nil {
+		a.SetStatus("No item selected")
+		return
+	}
+
+	state := a.captureUndoState()
+	clone := model.CloneItemTree(selected)
+	if a.tree.PasteAfter(clone) == nil {
+		a.SetStatus("Cannot duplicate item")
+		return
+	}
+
+	a.pushUndoState(state)
+	a.recordAction((*App).handleDuplicateCommand)
+	a.markDirty()
+	a.refreshSearchNodes()
+	a.tree.SelectItemByID(clone.ID)
+	a.SetStatus("Duplicated item")
+}
+
+// handleSendToNode opens the node search widget to select a destination and sends the current item there
+func (a *App) handleSendToNode() {
+	if a.readOnly {
+		a.SetStatus("File is readonly")
+		return
+	}
+
+	selected := a.tree.GetSelected()
+	if selected == nil {
+		a.SetStatus("No item selected")
+		return
+	}
+
+	// Set up the node search widget
+	a.nodeSearchWidget.SetItems(a.outline.GetAllItems())
+	a.nodeSearchWidget.SetOnSelect(func(destination *model.Item) {
+		if destination == nil {
+			a.SetStatus("No destination selected")
+			return
+		}
+
+		// Attempt to send the item
+		state := a.captureUndoState()
+		if a.tree.SendItemToNode(destination) {
+			a.pushUndoState(state)
+			a.lastSendDestination = destination
+			a.recordAction((*App).handleSendToLastNode)
+			a.markDirty()
+			// Truncate destination text if it's too long for status display
 			destText := destination.Text
 			if len(destText) > 40 {
 				destText = destText[:37] + "..."
@@ -2346,8 +3528,11 @@ func (a *App) handleSendToLastNode() {
 	}
 
 	// Attempt to send the item
+	state := a.captureUndoState()
 	if a.tree.SendItemToNode(a.lastSendDestination) {
-		a.dirty = true
+		a.pushUndoState(state)
+		a.recordAction((*App).handleSendToLastNode)
+		a.markDirty()
 		// Truncate destination text if it's too long for status display
 		destText := a.lastSendDestination.Text
 		if len(destText) > 40 {
@@ -2384,8 +3569,9 @@ func (a *App) handleSearchAndCopy() {
 		copiedItem := model.NewItemFrom(sourceItem)
 
 		// Add as sibling after current item
+		a.saveUndoState()
 		a.tree.AddItemAfter(copiedItem)
-		a.dirty = true
+		a.markDirty()
 
 		// Truncate source text if too long
 		sourceText := sourceItem.Text
@@ -2423,12 +3609,13 @@ func (a *App) handleSearchTemplate() {
 		copiedItem := model.NewItemFromWithout(sourceItem, "type")
 
 		// Add as sibling after current item
+		a.saveUndoState()
 		a.tree.AddItemAfter(copiedItem)
 
 		// Process template expressions in the copied item and all children
 		a.processTemplateItem(copiedItem)
 
-		a.dirty = true
+		a.markDirty()
 
 		// Truncate source text if too long
 		sourceText := sourceItem.Text
@@ -2481,7 +3668,7 @@ func (a *App) handleTemplateInteraction(targetItem *model.Item, interaction *tmp
 		// Show input dialog for prompt
 		a.showInputPrompt(interaction.Question, func(response string) {
 			// Replace the interaction placeholder with the response
-			a.dirty = true
+			a.markDirty()
 		})
 
 	case "search":
@@ -2492,14 +3679,14 @@ func (a *App) handleTemplateInteraction(targetItem *model.Item, interaction *tmp
 				// The result will be used to replace the expression in the template
 				interaction.OnResult(selectedItem.Text)
 			}
-			a.dirty = true
+			a.markDirty()
 		})
 
 	case "select":
 		// Show selection modal with options
 		a.showTemplateSelect(interaction.Options, func(selected string) {
 			interaction.OnResult(selected)
-			a.dirty = true
+			a.markDirty()
 		})
 	}
 }
@@ -2542,27 +3729,10 @@ func (a *App) handleExternalEdit() {
 	// Suspend tcell to release terminal control for the editor
 	a.screen.Suspend()
 
-	// Create validation callback for external editor
-	validateAttrs := func(attributes map[string]string) string {
-		// Load type registry from outline
-		registry := tmpl.NewTypeRegistry()
-		if err := registry.LoadFromOutline(a.outline); err != nil {
-			// If we can't load types, allow the attributes (type system is optional)
-			return ""
-		}
-
-		// Validate each attribute
-		for key, value := range attributes {
-			if err := registry.Validate(key, value); err != nil {
-				return err.Error()
-			}
-		}
-
-		return ""
-	}
+	a.saveUndoState()
 
 	// Edit the item in external editor (editor now has full terminal control)
-	err := ui.EditItemInExternalEditor(selected, a.cfg, validateAttrs)
+	err := ui.EditItemInExternalEditor(selected, a.cfg, a.validateAttributes)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Editor error: %v\n", err)
 	}
@@ -2581,10 +3751,94 @@ func (a *App) handleExternalEdit() {
 	a.screen.Show()
 
 	// Mark as modified and update status
-	a.dirty = true
+	a.markDirty()
 	a.SetStatus("Item updated from external editor")
 }
 
+// handleExternalTreeEdit opens the selected item and its descendants in an
+// external editor as an Org outline and replaces them with the result
+func (a *App) handleExternalTreeEdit() {
+	if a.readOnly {
+		a.SetStatus("Cannot modify readonly file")
+		return
+	}
+
+	selected := a.tree.GetSelected()
+	if selected == nil {
+		a.SetStatus("No item selected")
+		return
+	}
+
+	// Suspend tcell to release terminal control for the editor
+	a.screen.Suspend()
+
+	edited, err := ui.EditSubtreeInExternalEditor(selected, a.cfg, a.todoStatuses(), a.validateAttributes)
+
+	// Resume tcell and restore terminal control
+	if err := a.screen.Resume(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to resume terminal: %v\n", err)
+		os.Exit(1)
+	}
+	a.screen.Clear()
+	a.screen.Show()
+
+	if err != nil {
+		a.SetStatus(fmt.Sprintf("Editor error: %v", err))
+		return
+	}
+	if edited == nil {
+		a.SetStatus("Subtree unchanged")
+		return
+	}
+
+	a.replaceSubtree(selected, edited)
+}
+
+// replaceSubtree puts the items edited in the external editor in the place of item
+func (a *App) replaceSubtree(item *model.Item, edited []*model.Item) {
+	state := a.captureUndoState()
+	merged := ui.MergeEditedSubtree(item, edited, a.todoStatuses())
+	if !a.tree.ReplaceItem(item, merged) {
+		a.SetStatus("Cannot replace subtree")
+		return
+	}
+	a.pushUndoState(state)
+
+	// Sync outline with tree so the new items can be found
+	a.outline.Items = a.tree.GetItems()
+	a.outline.BuildIndex()
+	if len(merged) > 0 {
+		a.tree.SelectItemByID(merged[0].ID)
+	}
+
+	count := 0
+	for _, root := range merged {
+		count += 1 + root.CountDescendants()
+	}
+	a.markDirty()
+	a.SetStatus(fmt.Sprintf("Subtree updated from external editor (%d items)", count))
+}
+
+// validateAttributes checks attributes against the type definitions of the
+// outline and returns an error message, or "" when they are valid
+func (a *App) validateAttributes(attributes map[string]string) string {
+	// Load type registry from outline
+	registry := tmpl.NewTypeRegistry()
+	if err := registry.LoadFromOutline(a.outline); err != nil {
+		// If we can't load types, allow the attributes (type system is optional)
+		return ""
+	}
+
+	// Validate each attribute
+	for key, value := range attributes {
+		if err := registry.Validate(key, value); err != nil {
+			return err.Error()
+		}
+	}
+
+	return ""
+}
+
 // handleSetCommand processes :set configuration commands
 // Examples:
 //
@@ -2642,58 +3896,388 @@ func (a *App) handleSetCommand(parts []string) {
 		} else {
 			a.SetStatus(fmt.Sprintf("Invalid weekstart value '%s'. Use 0-6 (0=Sunday, 1=Monday, ...)", value))
 		}
+	} else if key == "backupcompression" {
+		if a.store != nil {
+			a.store.SetBackupCompression(value == "true")
+		}
+		a.SetStatus(fmt.Sprintf("Set %s = %s", key, value))
+	} else if key == "duesoon" {
+		if days, err := strconv.Atoi(value); err == nil && days >= 0 {
+			a.SetStatus(fmt.Sprintf("Set %s = %d (items due within %d days are highlighted)", key, days, days))
+		} else {
+			a.SetStatus(fmt.Sprintf("Invalid duesoon value '%s'. Use a number of days", value))
+		}
+	} else if key == "searchscope" {
+		if value == "hoist" || value == "all" {
+			if value == "all" {
+				a.refreshSearchNodes()
+			}
+			a.searchScopeChanged()
+			a.SetStatus(fmt.Sprintf("Set %s = %s", key, value))
+		} else {
+			a.SetStatus(fmt.Sprintf("Invalid searchscope value '%s'. Use hoist or all", value))
+		}
+	} else if key == "prioritycolors" {
+		if _, err := ui.ParsePriorityColors(value); err == nil {
+			a.SetStatus(fmt.Sprintf("Set %s = %s", key, value))
+		} else {
+			a.SetStatus(fmt.Sprintf("Invalid prioritycolors value: %v", err))
+		}
+	} else if key == "progresschars" || key == "progresscolors" {
+		var err error
+		if key == "progresschars" {
+			_, err = ui.ParseProgressChars(value, a.todoStatuses())
+		} else {
+			_, err = ui.ParseProgressColors(value, a.todoStatuses())
+		}
+		if err == nil {
+			a.SetStatus(fmt.Sprintf("Set %s = %s", key, value))
+		} else {
+			a.SetStatus(fmt.Sprintf("Invalid %s value: %v", key, err))
+		}
+	} else if key == "indentwidth" {
+		if width, err := strconv.Atoi(value); err == nil && width >= 1 && width <= ui.MaxIndentWidth {
+			a.tree.SetIndentWidth(width)
+			a.SetStatus(fmt.Sprintf("Set %s = %d", key, width))
+		} else {
+			a.SetStatus(fmt.Sprintf("Invalid indentwidth value '%s'. Use a number from 1 to %d", value, ui.MaxIndentWidth))
+		}
+	} else if key == "backupretention" {
+		if value == "off" || value == "false" {
+			a.SetStatus(fmt.Sprintf("Set %s = %s (automatic pruning disabled)", key, value))
+		} else if _, err := storage.ParseRetentionPolicy(value); err == nil {
+			a.SetStatus(fmt.Sprintf("Set %s = %s (backups are pruned after each save)", key, value))
+		} else {
+			a.SetStatus(fmt.Sprintf("Invalid backupretention value '%s'. Use default, off or <per-session>,<days>,<weeks>", value))
+		}
+	} else if key == "autosave" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds == 0 {
+			a.SetStatus("Autosave disabled, use :w to save your changes")
+		} else if err == nil && seconds > 0 {
+			a.SetStatus(fmt.Sprintf("Set %s = %d (save every %d seconds)", key, seconds, seconds))
+		} else {
+			a.SetStatus(fmt.Sprintf("Invalid autosave value '%s'. Use a number of seconds >= 0 (0 disables autosave)", value))
+		}
+	} else if key == "undolevels" {
+		if levels, err := strconv.Atoi(value); err == nil && levels >= 0 {
+			a.trimUndoStack()
+			a.SetStatus(fmt.Sprintf("Set %s = %d", key, levels))
+		} else {
+			a.SetStatus(fmt.Sprintf("Invalid undolevels value '%s'. Use a number >= 0", value))
+		}
 	} else {
 		a.SetStatus(fmt.Sprintf("Set %s = %s", key, value))
 	}
 }
 
 // handleSearchCommand creates a new search node with the given query
-// Usage: :search <query>
+// Usage: :search <query> [--sort field[:desc]] [--limit n]
 func (a *App) handleSearchCommand(parts []string) {
 	if len(parts) < 2 {
-		a.SetStatus("Usage: :search <query>")
+		a.SetStatus("Usage: :search <query> [--sort field[:desc]] [--limit n]")
+		return
+	}
+
+	switch parts[1] {
+	case "save", "save!":
+		a.handleSearchSaveCommand(parts)
+		return
+	case "run":
+		a.handleSearchRunCommand(parts)
+		return
+	case "list":
+		a.handleSearchListCommand()
 		return
 	}
 
-	// Combine all parts after the command to form the query
-	query := strings.Join(parts[1:], " ")
+	a.createSearchNode(parts[1:])
+}
+
+// parseSearchNodeArgs splits the --sort and --limit options from the query words
+func parseSearchNodeArgs(args []string) (query string, sortSpec string, limit int, err error) {
+	var words []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--sort", "--limit":
+			if i+1 >= len(args) {
+				return "", "", 0, fmt.Errorf("%s requires a value", args[i])
+			}
+			value := args[i+1]
+			i++
+			if args[i-1] == "--sort" {
+				if err := search.ValidateSortSpec(value); err != nil {
+					return "", "", 0, err
+				}
+				sortSpec = value
+			} else {
+				limit, err = strconv.Atoi(value)
+				if err != nil || limit < 0 {
+					return "", "", 0, fmt.Errorf("invalid limit: %s", value)
+				}
+			}
+		default:
+			words = append(words, args[i])
+		}
+	}
+	return strings.Join(words, " "), sortSpec, limit, nil
+}
+
+// createSearchNode adds a search node after the selected item. The args hold
+// the query words and optionally --sort and --limit.
+func (a *App) createSearchNode(args []string) {
+	query, sortSpec, limit, err := parseSearchNodeArgs(args)
+	if err != nil {
+		a.SetStatus("Invalid search: " + err.Error())
+		return
+	}
+	if query == "" {
+		a.SetStatus("Usage: :search <query> [--sort field[:desc]] [--limit n]")
+		return
+	}
 
 	// Create a new search node
 	searchNode := model.NewItem("[Search] " + query)
 	searchNode.Metadata.Attributes["type"] = "search"
 	searchNode.Metadata.Attributes["query"] = query
+	if sortSpec != "" {
+		searchNode.Metadata.Attributes["sort"] = sortSpec
+	}
+	if limit > 0 {
+		searchNode.Metadata.Attributes["limit"] = strconv.Itoa(limit)
+	}
 
+	a.saveUndoState()
 	a.tree.AddItemAfter(searchNode)
 	a.refreshSearchNodes()
 	a.SetStatus(fmt.Sprintf("Created search node for: %s (use l to expand)", query))
-	a.dirty = true
+	a.markDirty()
+}
+
+// handleSearchSaveCommand stores a named query, ":search save! " overwrites an existing name
+func (a *App) handleSearchSaveCommand(parts []string) {
+	if len(parts) < 4 {
+		a.SetStatus("Usage: :search save <name> <query>")
+		return
+	}
+
+	searches, err := config.LoadSavedSearches()
+	if err != nil {
+		a.SetStatus("Failed to load saved searches: " + err.Error())
+		return
+	}
+
+	name := parts[2]
+	query := strings.Join(parts[3:], " ")
+	if _, exists := searches.Get(name); exists && parts[1] != "save!" {
+		a.SetStatus(fmt.Sprintf("Saved search '%s' already exists (use :search save! to overwrite)", name))
+		return
+	}
+
+	searches.Set(name, query)
+	if err := searches.Save(); err != nil {
+		a.SetStatus("Failed to save search: " + err.Error())
+		return
+	}
+	a.SetStatus(fmt.Sprintf("Saved search '%s': %s", name, query))
+}
+
+// handleSearchRunCommand creates a search node from a saved query
+func (a *App) handleSearchRunCommand(parts []string) {
+	if len(parts) < 3 {
+		a.SetStatus("Usage: :search run <name>")
+		return
+	}
+
+	searches, err := config.LoadSavedSearches()
+	if err != nil {
+		a.SetStatus("Failed to load saved searches: " + err.Error())
+		return
+	}
+
+	query, ok := searches.Get(parts[2])
+	if !ok {
+		a.SetStatus(fmt.Sprintf("No saved search named '%s'", parts[2]))
+		return
+	}
+	a.createSearchNode(strings.Fields(query))
+}
+
+// handleSearchListCommand shows the saved searches in the messages view
+func (a *App) handleSearchListCommand() {
+	searches, err := config.LoadSavedSearches()
+	if err != nil {
+		a.SetStatus("Failed to load saved searches: " + err.Error())
+		return
+	}
+
+	names := searches.Names()
+	if len(names) == 0 {
+		a.SetStatus("No saved searches")
+		return
+	}
+
+	now := time.Now()
+	var messages []*ui.Message
+	for _, name := range names {
+		query, _ := searches.Get(name)
+		messages = append(messages, &ui.Message{
+			Text:      fmt.Sprintf("%s: %s", name, query),
+			Timestamp: now,
+		})
+	}
+
+	a.messagesViewActive = true
+	a.messagesViewMessages = messages
+	a.messagesViewItems = nil
+	a.messagesViewScroll = 0
+}
+
+// handleBacklinksCommand lists the items linking to the selected item in the messages view
+func (a *App) handleBacklinksCommand() {
+	selected := a.tree.GetSelected()
+	if selected == nil {
+		a.SetStatus("No item selected")
+		return
+	}
+
+	// Sync outline with tree so new and edited items are included
+	a.outline.Items = a.tree.GetItems()
+	a.outline.BuildIndex()
+
+	backlinks := a.outline.FindBacklinks(selected.ID)
+	if len(backlinks) == 0 {
+		a.SetStatus("No backlinks found")
+		return
+	}
+
+	now := time.Now()
+	var messages []*ui.Message
+	for _, item := range backlinks {
+		messages = append(messages, &ui.Message{
+			Text:      item.Text,
+			Timestamp: now,
+		})
+	}
+
+	a.messagesViewActive = true
+	a.messagesViewMessages = messages
+	a.messagesViewItems = backlinks
+	a.messagesViewScroll = 0
+}
+
+// updateLinkTexts refreshes the display text of links to target in all items.
+// Returns the number of items that were changed.
+func (a *App) updateLinkTexts(target *model.Item) int {
+	updated := 0
+	for _, item := range a.outline.GetAllItems() {
+		newText := links.UpdateLinkText(item.Text, target.ID, target.Text)
+		if newText == item.Text {
+			continue
+		}
+		item.Text = newText
+		if item.Metadata != nil {
+			item.Metadata.Modified = time.Now()
+		}
+		updated++
+	}
+	return updated
+}
+
+// jumpToMessagesViewItem closes the messages view and selects the highlighted item
+func (a *App) jumpToMessagesViewItem() {
+	if a.messagesViewScroll >= len(a.messagesViewItems) {
+		return
+	}
+	item := a.messagesViewItems[a.messagesViewScroll]
+
+	a.messagesViewActive = false
+	a.messagesViewScroll = 0
+	a.messagesViewTitle = ""
+
+	if a.jumpToItem(item) {
+		a.SetStatus(fmt.Sprintf("Jumped to: %s", item.Text))
+	}
 }
 
 // populateSearchNode updates a single search node with current matching results
 // Returns the count of matches found, or 0 if query is empty/invalid
 func (a *App) populateSearchNode(item *model.Item) int {
-	queryStr := item.GetSearchQuery()
-	if queryStr == "" {
-		return 0
-	}
+	return a.populateSearchNodes([]*model.Item{item})[0]
+}
 
-	// Parse and execute the query
-	filterExpr, err := search.ParseQuery(queryStr)
-	if err != nil {
-		return 0
+// populateSearchNodes updates the results of several search nodes with a
+// single pass over the outline. Returns the count of matches for each node.
+func (a *App) populateSearchNodes(nodes []*model.Item) []int {
+	counts := make([]int, len(nodes))
+	exprs := make([]search.FilterExpr, len(nodes))
+	matches := make([][]*model.Item, len(nodes))
+
+	for i, node := range nodes {
+		if queryStr := node.GetSearchQuery(); queryStr != "" {
+			// Invalid queries stay nil and get no results
+			exprs[i], _ = a.parseSearchNodeQuery(queryStr)
+		}
 	}
 
 	// Find matching items
-	var matchingIDs []string
-	for _, candidate := range a.outline.GetAllItems() {
-		// Don't include the search node itself
-		if candidate.ID == item.ID {
-			continue
+	for _, candidate := range a.searchNodeItems() {
+		for i, expr := range exprs {
+			// Don't include the search node itself
+			if expr == nil || candidate.ID == nodes[i].ID {
+				continue
+			}
+			if expr.Matches(candidate) {
+				matches[i] = append(matches[i], candidate)
+			}
+		}
+	}
+
+	for i, node := range nodes {
+		if exprs[i] != nil {
+			counts[i] = a.setSearchNodeResults(node, matches[i])
 		}
-		if filterExpr.Matches(candidate) {
-			matchingIDs = append(matchingIDs, candidate.ID)
+	}
+	return counts
+}
+
+// parseSearchNodeQuery parses a search node query, each query text is parsed
+// only once. Editing the query attribute changes the text, so the cache never
+// returns the expression of an old query.
+func (a *App) parseSearchNodeQuery(queryStr string) (search.FilterExpr, error) {
+	if expr, ok := a.searchQueryCache[queryStr]; ok {
+		return expr, nil
+	}
+
+	expr, err := search.ParseQuery(queryStr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Start over instead of keeping every query typed while editing
+	if a.searchQueryCache == nil || len(a.searchQueryCache) >= 256 {
+		a.searchQueryCache = make(map[string]search.FilterExpr)
+	}
+	a.searchQueryCache[queryStr] = expr
+	return expr, nil
+}
+
+// setSearchNodeResults sorts and limits matches like the search node asks and
+// sets them as its virtual children. Returns the count of results.
+func (a *App) setSearchNodeResults(item *model.Item, matches []*model.Item) int {
+	// Apply the optional sort and limit stored on the search node
+	if sortSpec := item.Metadata.Attributes["sort"]; sortSpec != "" {
+		if err := search.SortItems(matches, sortSpec); err != nil {
+			return 0
 		}
 	}
+	if limit, err := strconv.Atoi(item.Metadata.Attributes["limit"]); err == nil {
+		matches = search.LimitItems(matches, limit)
+	}
+
+	matchingIDs := make([]string, 0, len(matches))
+	for _, match := range matches {
+		matchingIDs = append(matchingIDs, match.ID)
+	}
 
 	return a.outline.PopulateSearchNode(item, matchingIDs)
 }
@@ -2704,12 +4288,14 @@ func (a *App) refreshSearchNodes() {
 	a.outline.Items = a.tree.GetItems()
 	a.outline.BuildIndex()
 
-	// Find all search nodes and refresh them
+	// Find all search nodes and refresh them together
+	var nodes []*model.Item
 	for _, item := range a.outline.GetAllItems() {
 		if item.IsSearchNode() && item.Expanded {
-			a.populateSearchNode(item)
+			nodes = append(nodes, item)
 		}
 	}
+	a.populateSearchNodes(nodes)
 
 	// Rebuild the tree view to show updated results
 	a.tree.RebuildView()
@@ -1,9 +1,15 @@
 package app
 
 import (
+	"fmt"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/pstuifzand/tui-outliner/internal/config"
 	"github.com/pstuifzand/tui-outliner/internal/model"
 	"github.com/pstuifzand/tui-outliner/internal/storage"
 	"github.com/pstuifzand/tui-outliner/internal/ui"
@@ -223,3 +229,760 @@ func TestChangeEmptyItemWithoutChildrenIsDeleted(t *testing.T) {
 		t.Errorf("Expected item text to be empty, got %q", item.Text)
 	}
 }
+
+func TestVisualYankAndPasteMultipleItems(t *testing.T) {
+	// A
+	//   A1
+	// B
+	// C
+	a := model.NewItem("A")
+	a.AddChild(model.NewItem("A1"))
+	a.Expanded = true
+	b := model.NewItem("B")
+	c := model.NewItem("C")
+
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{a, b, c}
+
+	app := &App{
+		outline: outline,
+		tree:    ui.NewTreeView(outline.Items),
+	}
+
+	// Select A, A1 and B in visual mode
+	app.tree.SelectItem(0)
+	app.visualAnchor = 0
+	app.mode = VisualMode
+	app.tree.SelectItem(2)
+	app.yankVisualSelection()
+
+	// A1 is part of A's subtree, so only A and B are stored
+	if len(app.clipboard) != 2 {
+		t.Fatalf("Expected 2 clipboard items, got %d", len(app.clipboard))
+	}
+	if app.clipboard[0] == a || app.clipboard[1] == b {
+		t.Error("Expected clipboard to hold copies, not the original items")
+	}
+
+	// Paste after C twice
+	app.tree.SelectItemByID(c.ID)
+	app.pasteClipboard(false)
+	app.tree.SelectItemByID(c.ID)
+	app.pasteClipboard(false)
+
+	items := app.tree.GetItems()
+	var texts []string
+	for _, item := range items {
+		texts = append(texts, item.Text)
+	}
+	expected := []string{"A", "B", "C", "A", "B", "A", "B"}
+	if len(texts) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, texts)
+	}
+	for i := range expected {
+		if texts[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, texts)
+		}
+	}
+	if len(items[3].Children) != 1 || items[3].Children[0].Text != "A1" {
+		t.Error("Expected pasted A to keep its subtree")
+	}
+	if items[3] == items[5] || items[3].Children[0] == items[5].Children[0] {
+		t.Error("Expected repeated pastes not to share nodes")
+	}
+	if app.statusMsg != "Pasted 2 items" {
+		t.Errorf("Expected status 'Pasted 2 items', got %q", app.statusMsg)
+	}
+
+	// Paste before the first item keeps the order
+	app.tree.SelectItemByID(a.ID)
+	app.pasteClipboard(true)
+	items = app.tree.GetItems()
+	if items[0].Text != "A" || items[1].Text != "B" || items[2] != a {
+		t.Errorf("Expected pasted A, B before original A, got %q, %q, %q", items[0].Text, items[1].Text, items[2].Text)
+	}
+}
+
+func TestSavedSearchCommands(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{model.NewItem("Task")}
+	app := &App{
+		outline: outline,
+		tree:    ui.NewTreeView(outline.Items),
+	}
+
+	app.handleSearchCommand([]string{"search", "save", "tasks", "Task", "-#done"})
+	app.handleSearchCommand([]string{"search", "save", "tasks", "other"})
+	if app.statusMsg != "Saved search 'tasks' already exists (use :search save! to overwrite)" {
+		t.Errorf("Expected overwrite to require confirmation, got %q", app.statusMsg)
+	}
+
+	app.handleSearchCommand([]string{"search", "run", "tasks"})
+	items := app.tree.GetItems()
+	if len(items) != 2 || items[1].Metadata.Attributes["query"] != "Task -#done" {
+		t.Fatalf("Expected search node with saved query, got %v", items)
+	}
+
+	app.handleSearchCommand([]string{"search", "save!", "tasks", "other"})
+	app.handleSearchCommand([]string{"search", "list"})
+	if !app.messagesViewActive || len(app.messagesViewMessages) != 1 || app.messagesViewMessages[0].Text != "tasks: other" {
+		t.Errorf("Expected list to show overwritten search, got %v", app.messagesViewMessages)
+	}
+
+	app.handleSearchCommand([]string{"search", "run", "missing"})
+	if app.statusMsg != "No saved search named 'missing'" {
+		t.Errorf("Expected missing search status, got %q", app.statusMsg)
+	}
+}
+
+func TestSearchNodeSortAndLimit(t *testing.T) {
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{
+		model.NewItem("b task"),
+		model.NewItem("a task"),
+		model.NewItem("c task"),
+	}
+	app := &App{
+		outline: outline,
+		tree:    ui.NewTreeView(outline.Items),
+	}
+
+	app.handleSearchCommand([]string{"search", "task", "--sort", "bogus"})
+	if !strings.HasPrefix(app.statusMsg, "Invalid search:") {
+		t.Fatalf("Expected invalid sort to be rejected, got %q", app.statusMsg)
+	}
+
+	app.handleSearchCommand([]string{"search", "task", "--sort", "text:desc", "--limit", "2"})
+	var searchNode *model.Item
+	for _, item := range app.tree.GetItems() {
+		if item.IsSearchNode() {
+			searchNode = item
+		}
+	}
+	if searchNode == nil {
+		t.Fatal("Expected a search node to be created")
+	}
+	if query := searchNode.GetSearchQuery(); query != "task" {
+		t.Errorf("Expected query 'task', got %q", query)
+	}
+
+	if count := app.populateSearchNode(searchNode); count != 2 {
+		t.Fatalf("Expected 2 results, got %d", count)
+	}
+	var texts []string
+	for _, id := range searchNode.VirtualChildRefs {
+		texts = append(texts, app.outline.FindItemByID(id).Text)
+	}
+	if len(texts) != 2 || texts[0] != "c task" || texts[1] != "b task" {
+		t.Errorf("Expected [c task b task], got %v", texts)
+	}
+}
+
+func TestBacklinksCommand(t *testing.T) {
+	target := model.NewItem("Target")
+	parent := model.NewItem("Parent")
+	source := model.NewItem("Points to [[" + target.ID + "]]")
+	parent.AddChild(source)
+
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{target, parent}
+	app := &App{
+		outline: outline,
+		tree:    ui.NewTreeView(outline.Items),
+	}
+
+	app.tree.SelectItemByID(target.ID)
+	app.handleBacklinksCommand()
+	if !app.messagesViewActive || len(app.messagesViewItems) != 1 || app.messagesViewItems[0] != source {
+		t.Fatalf("Expected backlinks view with source item, got %v", app.messagesViewMessages)
+	}
+
+	// Enter jumps to the collapsed source item
+	app.jumpToMessagesViewItem()
+	if app.messagesViewActive {
+		t.Error("Expected messages view to close after jumping")
+	}
+	if selected := app.tree.GetSelected(); selected != source {
+		t.Errorf("Expected source item to be selected, got %v", selected)
+	}
+}
+
+func TestUpdateLinkTextsAfterRename(t *testing.T) {
+	target := model.NewItem("Renamed")
+	withText := model.NewItem("See [[" + target.ID + "|Old name]]")
+	withoutText := model.NewItem("See [[" + target.ID + "]]")
+
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{target, withText, withoutText}
+	app := &App{
+		outline: outline,
+		tree:    ui.NewTreeView(outline.Items),
+	}
+
+	if updated := app.updateLinkTexts(target); updated != 1 {
+		t.Errorf("Expected 1 updated item, got %d", updated)
+	}
+	if withText.Text != "See [["+target.ID+"|Renamed]]" {
+		t.Errorf("Expected link display text to be updated, got %q", withText.Text)
+	}
+	if withoutText.Text != "See [["+target.ID+"]]" {
+		t.Errorf("Expected link without display text to be unchanged, got %q", withoutText.Text)
+	}
+}
+
+func TestExportSource(t *testing.T) {
+	project := model.NewItem("Project")
+	project.AddChild(model.NewItem("Step"))
+	other := model.NewItem("Other")
+
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{project, other}
+	cfg := &config.Config{}
+	app := &App{
+		outline: outline,
+		tree:    ui.NewTreeView(outline.Items),
+		cfg:     cfg,
+	}
+
+	if got := app.exportSource(false); got != outline {
+		t.Error("Expected the whole outline without options")
+	}
+
+	app.tree.SelectItemByID(other.ID)
+	if got := app.exportSource(true); got == nil || len(got.Items) != 1 || got.Items[0] != other {
+		t.Errorf("Expected --subtree to export the selected item, got %v", got)
+	}
+
+	// Hoisting only narrows the default export when exporthoist is set
+	app.tree.SelectItemByID(project.ID)
+	app.tree.Hoist()
+	if got := app.exportSource(false); got != outline {
+		t.Error("Expected the whole outline while hoisted without exporthoist")
+	}
+	cfg.Set("exporthoist", "true")
+	if got := app.exportSource(false); got == nil || len(got.Items) != 1 || got.Items[0] != project {
+		t.Errorf("Expected the hoisted item with exporthoist=true, got %v", got)
+	}
+}
+
+func TestAutoSaveInterval(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Set("", "")
+	app := &App{cfg: cfg}
+
+	if got := app.autoSaveInterval(); got != defaultAutoSaveInterval {
+		t.Errorf("Expected default interval %v, got %v", defaultAutoSaveInterval, got)
+	}
+
+	app.handleSetCommand([]string{"set", "autosave", "30"})
+	if got := app.autoSaveInterval(); got != 30*time.Second {
+		t.Errorf("Expected 30s interval, got %v", got)
+	}
+
+	app.handleSetCommand([]string{"set", "autosave", "0"})
+	if got := app.autoSaveInterval(); got != 0 {
+		t.Errorf("Expected autosave to be disabled, got %v", got)
+	}
+	if app.statusMsg != "Autosave disabled, use :w to save your changes" {
+		t.Errorf("Expected disabled status, got %q", app.statusMsg)
+	}
+
+	app.handleSetCommand([]string{"set", "autosave", "soon"})
+	if !strings.HasPrefix(app.statusMsg, "Invalid autosave value") {
+		t.Errorf("Expected invalid value status, got %q", app.statusMsg)
+	}
+	if got := app.autoSaveInterval(); got != defaultAutoSaveInterval {
+		t.Errorf("Expected invalid value to fall back to the default, got %v", got)
+	}
+}
+
+func TestGotoCommand(t *testing.T) {
+	project := model.NewItem("Project")
+	task := model.NewItem("Task")
+	project.AddChild(task)
+	other := model.NewItem("Other")
+	detail := model.NewItem("Detail")
+	other.AddChild(detail)
+
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{project, other}
+	outline.BuildIndex()
+	app := &App{
+		outline: outline,
+		tree:    ui.NewTreeView(outline.Items),
+	}
+
+	app.handleGotoCommand([]string{"goto", "missing"})
+	if app.statusMsg != "Item not found: missing" {
+		t.Errorf("Expected not found status, got %q", app.statusMsg)
+	}
+
+	// Collapsed parents are expanded to reach the target
+	app.handleGotoCommand([]string{"goto", task.ID})
+	if selected := app.tree.GetSelected(); selected == nil || selected.ID != task.ID {
+		t.Fatalf("Expected Task to be selected, got %v", selected)
+	}
+
+	// A target outside the hoisted subtree unhoists first
+	app.tree.SelectItemByID(project.ID)
+	if !app.tree.Hoist() {
+		t.Fatal("Hoist failed")
+	}
+	app.handleGotoCommand([]string{"goto", detail.ID})
+	if app.tree.IsHoisted() {
+		t.Error("Expected tree to be unhoisted")
+	}
+	if selected := app.tree.GetSelected(); selected == nil || selected.ID != detail.ID {
+		t.Errorf("Expected Detail to be selected, got %v", selected)
+	}
+}
+
+func TestSelectionCountsText(t *testing.T) {
+	parent := model.NewItem("Parent")
+	child := model.NewItem("Child")
+	parent.AddChild(child)
+	parent.AddChild(model.NewItem("Sibling"))
+	child.AddChild(model.NewItem("Grandchild"))
+
+	cfg := &config.Config{}
+	cfg.Set("", "")
+	app := &App{
+		tree: ui.NewTreeView([]*model.Item{parent}),
+		cfg:  cfg,
+	}
+
+	if got := app.selectionCountsText(); got != "2 children, 3 descendants (collapsed)" {
+		t.Errorf("Unexpected counts for collapsed item: %q", got)
+	}
+
+	app.tree.Expand(false)
+	app.tree.SelectItemByID(child.ID)
+	if got := app.selectionCountsText(); got != "1 child, 1 descendant (collapsed)" {
+		t.Errorf("Unexpected counts for child: %q", got)
+	}
+
+	cfg.Set("showcounts", "false")
+	if got := app.selectionCountsText(); got != "" {
+		t.Errorf("Expected no counts with showcounts=false, got %q", got)
+	}
+}
+
+func TestStatusFileAndTime(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Set("", "")
+	app := &App{
+		store: &storage.JSONStore{FilePath: "/home/user/notes/todo.json"},
+		cfg:   cfg,
+	}
+	now := time.Date(2024, 3, 5, 9, 7, 0, 0, time.Local)
+
+	if got := app.fileStatusText(); got != "todo.json" {
+		t.Errorf("Expected the base name of the file, got %q", got)
+	}
+	if got := app.timeStatusText(now); got != "09:07" {
+		t.Errorf("Expected the time of day, got %q", got)
+	}
+
+	app.readOnly = true
+	app.currentBackupPath = "/backups/20240301_142530_abcd1234.tuo"
+	if got := app.fileStatusText(); got != "backup 2024-03-01 14:25:30" {
+		t.Errorf("Expected the backup timestamp, got %q", got)
+	}
+
+	cfg.Set("showfilename", "false")
+	cfg.Set("showclock", "false")
+	if got := app.fileStatusText(); got != "" {
+		t.Errorf("Expected no file name with showfilename=false, got %q", got)
+	}
+	if got := app.timeStatusText(now); got != "" {
+		t.Errorf("Expected no time with showclock=false, got %q", got)
+	}
+}
+
+func TestFitStatusParts(t *testing.T) {
+	parts := func() []statusPart {
+		return []statusPart{{"1 child", 0}, {"", 1}, {"todo.json", 3}, {"09:07", 4}}
+	}
+
+	if got := fitStatusParts(parts(), 80); got != "1 child | todo.json | 09:07" {
+		t.Errorf("Expected all parts to fit, got %q", got)
+	}
+	if got := fitStatusParts(parts(), 20); got != "todo.json | 09:07" {
+		t.Errorf("Expected the counts to be left out first, got %q", got)
+	}
+	if got := fitStatusParts(parts(), 5); got != "09:07" {
+		t.Errorf("Expected only the time to fit, got %q", got)
+	}
+	if got := fitStatusParts(parts(), 2); got != "" {
+		t.Errorf("Expected nothing to fit, got %q", got)
+	}
+}
+
+func TestAttrCommandVisualSelection(t *testing.T) {
+	a := model.NewItem("A")
+	b := model.NewItem("B")
+	c := model.NewItem("C")
+	b.Metadata.Attributes["status"] = "todo"
+
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{a, b, c}
+	app := &App{
+		outline: outline,
+		tree:    ui.NewTreeView(outline.Items),
+	}
+
+	// Select A and B in visual mode
+	app.tree.SelectItem(0)
+	app.visualAnchor = 0
+	app.mode = VisualMode
+	app.tree.SelectItem(1)
+	app.handleAttrCommand([]string{"attr", "set", "owner", "ann"})
+
+	if app.statusMsg != "Set owner on 2 items" {
+		t.Errorf("Unexpected status: %q", app.statusMsg)
+	}
+	if a.Metadata.Attributes["owner"] != "ann" || b.Metadata.Attributes["owner"] != "ann" {
+		t.Error("Expected owner to be set on A and B")
+	}
+	if _, ok := c.Metadata.Attributes["owner"]; ok {
+		t.Error("Expected C to be left alone")
+	}
+	if app.mode != NormalMode {
+		t.Error("Expected visual mode to end")
+	}
+
+	// Deleting counts only the items that have the attribute
+	app.tree.SelectItem(0)
+	app.visualAnchor = 0
+	app.mode = VisualMode
+	app.tree.SelectItem(2)
+	app.handleAttrCommand([]string{"attr", "del", "status"})
+	if app.statusMsg != "Deleted status from 1 items" {
+		t.Errorf("Unexpected status: %q", app.statusMsg)
+	}
+	if _, ok := b.Metadata.Attributes["status"]; ok {
+		t.Error("Expected status to be deleted from B")
+	}
+
+	// Readonly files are not changed
+	app.readOnly = true
+	app.visualAnchor = 0
+	app.mode = VisualMode
+	app.handleAttrCommand([]string{"attr", "set", "owner", "bob"})
+	if app.statusMsg != "Cannot modify readonly file" || a.Metadata.Attributes["owner"] != "ann" {
+		t.Error("Expected readonly file not to be changed")
+	}
+}
+
+func TestTagCommandVisualSelection(t *testing.T) {
+	a := model.NewItem("A")
+	b := model.NewItem("B")
+	c := model.NewItem("C")
+	b.AddTag("work")
+
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{a, b, c}
+	app := &App{
+		outline: outline,
+		tree:    ui.NewTreeView(outline.Items),
+	}
+
+	// Select A and B in visual mode
+	app.tree.SelectItem(0)
+	app.visualAnchor = 0
+	app.mode = VisualMode
+	app.tree.SelectItem(1)
+	app.handleTagCommand([]string{"tag", "add", "work", "home"})
+
+	if app.statusMsg != "2 tags added to 2 items" {
+		t.Errorf("Unexpected status: %q", app.statusMsg)
+	}
+	if got := strings.Join(b.GetTags(), ","); got != "home,work" {
+		t.Errorf("Expected sorted unique tags on B, got %q", got)
+	}
+	if !a.HasTag("home") || c.HasTag("home") {
+		t.Error("Expected only A and B to get the tags")
+	}
+	if app.mode != NormalMode {
+		t.Error("Expected visual mode to end")
+	}
+
+	app.tree.SelectItem(0)
+	app.visualAnchor = 0
+	app.mode = VisualMode
+	app.tree.SelectItem(2)
+	app.handleTagCommand([]string{"tag", "remove", "work"})
+	if app.statusMsg != "Tag 'work' removed from 2 items" {
+		t.Errorf("Unexpected status: %q", app.statusMsg)
+	}
+	if a.HasTag("work") || b.HasTag("work") || !b.HasTag("home") {
+		t.Error("Expected only the work tag to be removed")
+	}
+}
+
+func TestEnterSplitsItemAtCursor(t *testing.T) {
+	item := model.NewItem("Buy milk and eggs")
+	item.AddChild(model.NewItem("Child"))
+	item.Expanded = true
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{item}
+
+	app := &App{
+		outline:                outline,
+		tree:                   ui.NewTreeView(outline.Items),
+		search:                 ui.NewSearch(outline.Items),
+		splash:                 ui.NewSplashScreen(),
+		command:                ui.NewCommandMode(),
+		attributeEditor:        ui.NewAttributeEditor(),
+		nodeSearchWidget:       ui.NewNodeSearchWidget("Search"),
+		linkAutocompleteWidget: ui.NewNodeSearchWidget("Search links"),
+		calendarWidget:         ui.NewCalendarWidget(),
+		backupSelectorWidget:   ui.NewBackupSelectorWidget(),
+		visualAnchor:           -1,
+	}
+	app.editor = ui.NewMultiLineEditor(item)
+	app.editor.Start()
+	app.editor.SetCursorFromScreenX(len("Buy milk"))
+	app.mode = InsertMode
+
+	app.handleRawEvent(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+
+	if item.Text != "Buy milk" {
+		t.Errorf("Expected the text before the cursor to stay, got %q", item.Text)
+	}
+	if len(item.Children) != 1 || item.Children[0].Text != "Child" {
+		t.Error("Expected the children to stay with the original item")
+	}
+	items := app.tree.GetItems()
+	if len(items) != 2 || items[1].Text != " and eggs" {
+		t.Fatalf("Expected a new sibling with the rest of the text, got %d items", len(items))
+	}
+	if app.mode != InsertMode || app.editor.GetItem() != items[1] || app.editor.GetCursorPos() != 0 {
+		t.Error("Expected insert mode at the start of the new item")
+	}
+	if app.statusMsg != "Split item" {
+		t.Errorf("Unexpected status: %q", app.statusMsg)
+	}
+}
+
+func TestReplaceSubtree(t *testing.T) {
+	a := model.NewItem("A")
+	b := model.NewItem("B")
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{a, b}
+	app := &App{
+		outline: outline,
+		tree:    ui.NewTreeView(outline.Items),
+		cfg:     &config.Config{},
+	}
+
+	// The root item is split into two items in the editor
+	first := model.NewItem("A renamed")
+	first.Metadata.Attributes["ID"] = a.ID
+	second := model.NewItem("A2")
+	app.replaceSubtree(a, []*model.Item{first, second})
+
+	items := app.tree.GetItems()
+	if len(items) != 3 || items[0] != a || items[1] != second || items[2] != b {
+		t.Fatalf("Expected A, A2 and B at the root, got %d items", len(items))
+	}
+	if a.Text != "A renamed" || app.tree.GetSelected() != a {
+		t.Errorf("Expected A to be renamed and selected, got %q", a.Text)
+	}
+	if app.statusMsg != "Subtree updated from external editor (2 items)" || !app.dirty {
+		t.Errorf("Unexpected status: %q", app.statusMsg)
+	}
+	if len(app.undoStack) != 1 {
+		t.Error("Expected the replacement to be undoable")
+	}
+}
+
+func TestSelectionProgressText(t *testing.T) {
+	parent := model.NewItem("Project")
+	parent.Metadata.Attributes["type"] = "todo"
+	for _, status := range []string{"done", "doing", "todo"} {
+		child := model.NewItem("Task")
+		child.Metadata.Attributes["type"] = "todo"
+		child.Metadata.Attributes["status"] = status
+		parent.AddChild(child)
+	}
+	parent.AddChild(model.NewItem("Note"))
+
+	cfg := &config.Config{}
+	cfg.Set("", "")
+	app := &App{
+		tree: ui.NewTreeView([]*model.Item{parent, model.NewItem("Other")}),
+		cfg:  cfg,
+	}
+
+	if got := app.selectionProgressText(); got != "1/3 (33%)" {
+		t.Errorf("Unexpected progress: %q", got)
+	}
+
+	cfg.Set("showprogresspct", "false")
+	if got := app.selectionProgressText(); got != "" {
+		t.Errorf("Expected no progress with showprogresspct=false, got %q", got)
+	}
+
+	cfg.Set("showprogresspct", "true")
+	app.tree.SelectNext()
+	if got := app.selectionProgressText(); got != "" {
+		t.Errorf("Expected no progress for an item without todo children, got %q", got)
+	}
+}
+
+func TestWordcountCommand(t *testing.T) {
+	parent := model.NewItem("Two words")
+	parent.AddChild(model.NewItem("three more words"))
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{parent, model.NewItem("last")}
+	app := &App{
+		outline: outline,
+		tree:    ui.NewTreeView(outline.Items),
+	}
+
+	app.handleWordcountCommand([]string{"wordcount"})
+	if app.statusMsg != "6 words, 29 characters, 3 nodes" {
+		t.Errorf("Unexpected whole outline count: %q", app.statusMsg)
+	}
+
+	app.handleWordcountCommand([]string{"wordcount", "--subtree"})
+	if app.statusMsg != "5 words, 25 characters, 2 nodes" {
+		t.Errorf("Unexpected subtree count: %q", app.statusMsg)
+	}
+}
+
+func TestMoveCommand(t *testing.T) {
+	project := model.NewItem("Project")
+	for _, text := range []string{"First", "Second"} {
+		project.AddChild(model.NewItem(text))
+	}
+	task := model.NewItem("Task")
+
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{project, task}
+	outline.BuildIndex()
+	app := &App{
+		outline: outline,
+		tree:    ui.NewTreeView(outline.Items),
+		cfg:     &config.Config{},
+	}
+	app.tree.SelectItemByID(task.ID)
+
+	app.handleMoveCommand([]string{"move", project.ID, "0"})
+	if !strings.HasPrefix(app.statusMsg, "Invalid position") {
+		t.Errorf("Expected invalid position status, got %q", app.statusMsg)
+	}
+
+	app.handleMoveCommand([]string{"move", project.ID, "2"})
+	if task.Parent != project || project.Children[1] != task {
+		t.Fatalf("Expected Task to be the 2nd child of Project")
+	}
+	if !app.dirty || len(app.undoStack) != 1 {
+		t.Errorf("Expected a dirty outline with one undo state")
+	}
+}
+
+func TestMergeCommand(t *testing.T) {
+	other := writeTestOutline(t, t.TempDir(), "laptop.json", "Buy milk", "Call dentist")
+
+	project := model.NewItem("Project")
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{project}
+	outline.BuildIndex()
+	app := &App{
+		outline: outline,
+		tree:    ui.NewTreeView(outline.Items),
+		cfg:     &config.Config{},
+	}
+
+	app.handleCommand("merge " + other)
+	items := app.tree.GetItems()
+	if len(items) != 2 || !strings.HasPrefix(items[1].Text, "Merged ") || len(items[1].Children) != 2 {
+		t.Fatalf("Expected the items under a new Merged item, got %d root items", len(items))
+	}
+	if selected := app.tree.GetSelected(); selected != items[1] {
+		t.Errorf("Expected the Merged item to be selected, got %v", selected)
+	}
+
+	app.handleCommand("merge " + other + " " + project.ID)
+	if len(project.Children) != 2 || project.Children[0].Text != "Buy milk" {
+		t.Fatalf("Expected the items under Project, got %d children", len(project.Children))
+	}
+	if project.Children[0].ID == items[1].Children[0].ID {
+		t.Error("Expected the second merge to get new IDs")
+	}
+	if !strings.Contains(app.statusMsg, "reassigned 2 duplicate IDs") || !app.dirty || len(app.undoStack) != 2 {
+		t.Errorf("Expected a dirty outline with two undo states, got %q", app.statusMsg)
+	}
+}
+
+func TestCountPrefix(t *testing.T) {
+	outline := model.NewOutline()
+	for i := range 12 {
+		outline.Items = append(outline.Items, model.NewItem(fmt.Sprintf("Item %d", i+1)))
+	}
+	outline.BuildIndex()
+	cfg := &config.Config{}
+	cfg.Set("", "")
+	app := &App{
+		outline: outline,
+		tree:    ui.NewTreeView(outline.Items),
+		cfg:     cfg,
+	}
+	app.keybindings = app.InitializeKeybindings()
+	app.pendingKeybindings = app.InitializePendingKeybindings()
+
+	// keys presses each rune of keys in normal mode
+	keys := func(keys string) {
+		for _, r := range keys {
+			app.handleKeypress(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+		}
+	}
+
+	keys("10j")
+	if app.tree.GetSelectedIndex() != 10 {
+		t.Errorf("Expected 10j to select item 11, got index %d", app.tree.GetSelectedIndex())
+	}
+	keys("3k")
+	if app.tree.GetSelectedIndex() != 7 {
+		t.Errorf("Expected 3k to select item 8, got index %d", app.tree.GetSelectedIndex())
+	}
+
+	// Escape drops the count
+	keys("5")
+	app.handleKeypress(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone))
+	keys("j")
+	if app.tree.GetSelectedIndex() != 8 {
+		t.Errorf("Expected Escape to drop the count, got index %d", app.tree.GetSelectedIndex())
+	}
+
+	keys("2G")
+	if app.tree.GetSelected().Text != "Item 2" {
+		t.Errorf("Expected 2G to select Item 2, got %q", app.tree.GetSelected().Text)
+	}
+
+	// 3d deletes three items into the clipboard, undone in one step
+	keys("3d")
+	if len(app.tree.GetItems()) != 9 || len(app.clipboard) != 3 || app.clipboard[2].Text != "Item 4" {
+		t.Fatalf("Expected 3 items deleted into the clipboard, got %d items left", len(app.tree.GetItems()))
+	}
+	keys("u")
+	if len(app.tree.GetItems()) != 12 {
+		t.Errorf("Expected one undo to restore the three items, got %d items", len(app.tree.GetItems()))
+	}
+
+	// The z1 fold sequence is not a count
+	app.tree.SelectItem(2)
+	keys(">")
+	app.tree.SelectItem(3)
+	keys("2>z1")
+	if item := app.tree.GetItems()[1]; len(item.Children) != 1 || len(item.Children[0].Children) != 1 {
+		t.Errorf("Expected 2> to indent Item 4 twice, got %v", itemTexts(item.Children))
+	}
+	if app.pendingCount != 0 {
+		t.Errorf("Expected no count left, got %d", app.pendingCount)
+	}
+}
@@ -3,8 +3,9 @@ package app
 import (
 	"encoding/json"
 	"fmt"
+	"log"
 	"math/rand"
-	"os"
+	"strings"
 	"time"
 
 	"github.com/pstuifzand/tui-outliner/internal/model"
@@ -59,7 +60,7 @@ func (a *App) handleDiffCommand(parts []string) {
 			}
 
 			// Load the selected backup
-			backupData, err := os.ReadFile(backup.FilePath)
+			backupData, err := storage.ReadOutlineFile(backup.FilePath)
 			if err != nil {
 				a.SetStatus(fmt.Sprintf("Failed to read backup: %v", err))
 				return
@@ -78,7 +79,7 @@ func (a *App) handleDiffCommand(parts []string) {
 
 			// Replace current outline with restored backup
 			a.outline = &restoredOutline
-			a.dirty = true
+			a.markDirty()
 
 			// Recreate the tree view with restored items
 			a.tree = ui.NewTreeView(a.outline.Items)
@@ -326,3 +327,70 @@ func generateSessionID() string {
 	}
 	return string(b)
 }
+
+// handleBackupsCommand handles the :backups command
+// Usage: :backups prune
+func (a *App) handleBackupsCommand(parts []string) {
+	if len(parts) < 2 || parts[1] != "prune" {
+		a.SetStatus("Usage: :backups prune")
+		return
+	}
+	if a.originalFilePath == "" {
+		a.SetStatus("No file to prune backups for")
+		return
+	}
+
+	policy := storage.DefaultRetentionPolicy()
+	if value := a.backupRetentionSetting(); value != "" && value != "off" && value != "false" {
+		var err error
+		if policy, err = storage.ParseRetentionPolicy(value); err != nil {
+			a.SetStatus(err.Error())
+			return
+		}
+	}
+
+	backupMgr, err := storage.NewBackupManager()
+	if err != nil {
+		a.SetStatus("Failed to access backups")
+		return
+	}
+
+	deleted, err := backupMgr.Prune(a.originalFilePath, policy)
+	if err != nil {
+		a.SetStatus(fmt.Sprintf("Failed to prune backups: %v", err))
+		return
+	}
+	a.SetStatus(fmt.Sprintf("Pruned %d backups", deleted))
+}
+
+// autoPruneBackups prunes the backups of the current file after a save when
+// 'backupretention' is set. Failures are logged, they never fail the save.
+func (a *App) autoPruneBackups() {
+	value := a.backupRetentionSetting()
+	if value == "" || value == "off" || value == "false" || a.originalFilePath == "" {
+		return
+	}
+
+	policy, err := storage.ParseRetentionPolicy(value)
+	if err != nil {
+		log.Printf("Warning: %v\n", err)
+		return
+	}
+
+	backupMgr, err := storage.NewBackupManager()
+	if err != nil {
+		log.Printf("Warning: Failed to access backups: %v\n", err)
+		return
+	}
+	if _, err := backupMgr.Prune(a.originalFilePath, policy); err != nil {
+		log.Printf("Warning: Failed to prune backups: %v\n", err)
+	}
+}
+
+// backupRetentionSetting returns the 'backupretention' setting
+func (a *App) backupRetentionSetting() string {
+	if a.cfg == nil {
+		return ""
+	}
+	return strings.TrimSpace(a.cfg.Get("backupretention"))
+}
@@ -0,0 +1,297 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+	"github.com/pstuifzand/tui-outliner/internal/storage"
+	tmpl "github.com/pstuifzand/tui-outliner/internal/template"
+	"github.com/pstuifzand/tui-outliner/internal/ui"
+)
+
+// buffer holds the state of an open file. The active buffer lives in the App
+// fields, so the rest of the App works on it unchanged; its state is copied
+// into the buffer when switching to another one.
+type buffer struct {
+	outline           *model.Outline
+	store             *storage.JSONStore
+	tree              *ui.TreeView
+	dirty             bool
+	readOnly          bool
+	hasFile           bool
+	originalFilePath  string
+	currentBackupPath string
+	autoSaveTime      time.Time
+	undoStack         []*undoState
+	redoStack         []*undoState
+	marks             map[rune]string
+	markRows          map[rune]int
+	clockItemID       string
+	clockItemText     string
+	clockStart        time.Time
+}
+
+// ensureBuffers makes sure the buffer list holds the active buffer
+func (a *App) ensureBuffers() {
+	if len(a.buffers) == 0 {
+		a.buffers = []*buffer{{}}
+		a.bufferIdx = 0
+	}
+}
+
+// storeActiveBuffer copies the state of the active buffer from the App fields
+func (a *App) storeActiveBuffer() {
+	a.ensureBuffers()
+	*a.buffers[a.bufferIdx] = buffer{
+		outline:           a.outline,
+		store:             a.store,
+		tree:              a.tree,
+		dirty:             a.dirty,
+		readOnly:          a.readOnly,
+		hasFile:           a.hasFile,
+		originalFilePath:  a.originalFilePath,
+		currentBackupPath: a.currentBackupPath,
+		autoSaveTime:      a.autoSaveTime,
+		undoStack:         a.undoStack,
+		redoStack:         a.redoStack,
+		marks:             a.marks,
+		markRows:          a.markRows,
+		clockItemID:       a.clockItemID,
+		clockItemText:     a.clockItemText,
+		clockStart:        a.clockStart,
+	}
+}
+
+// activateBuffer makes buffer idx the active buffer. The state of the current
+// buffer must have been stored before.
+func (a *App) activateBuffer(idx int) {
+	b := a.buffers[idx]
+	a.bufferIdx = idx
+	a.outline = b.outline
+	a.store = b.store
+	a.tree = b.tree
+	a.dirty = b.dirty
+	a.readOnly = b.readOnly
+	a.hasFile = b.hasFile
+	a.originalFilePath = b.originalFilePath
+	a.currentBackupPath = b.currentBackupPath
+	a.autoSaveTime = b.autoSaveTime
+	a.undoStack = b.undoStack
+	a.redoStack = b.redoStack
+	a.marks = b.marks
+	a.markRows = b.markRows
+	a.clockItemID = b.clockItemID
+	a.clockItemText = b.clockItemText
+	a.clockStart = b.clockStart
+
+	// Drop state that points into the previous outline. Changes of the other
+	// file are not reported, the next check starts from this one.
+	a.itemHashes = nil
+	a.editor = nil
+	a.mode = NormalMode
+	a.visualAnchor = -1
+	a.pendingKeySeq = 0
+	a.pendingCount = 0
+	a.searchMatches = nil
+	a.lastSendDestination = nil
+	a.messagesViewActive = false
+	a.messagesViewTitle = ""
+	if a.search != nil {
+		a.search.Stop()
+		a.search.SetAllItems(a.searchItems())
+	}
+	if a.splash != nil && a.hasFile {
+		a.splash.Hide()
+	}
+
+	// Widgets that hold on to the types and items of the outline
+	if a.attributeEditor != nil {
+		registry := tmpl.NewTypeRegistry()
+		if err := registry.LoadFromOutline(a.outline); err != nil {
+			registry = tmpl.NewTypeRegistry()
+		}
+		a.attributeEditor.SetTypeRegistry(registry)
+	}
+	if a.calendarWidget != nil {
+		a.calendarWidget.SetItems(a.outline.GetAllItems())
+	}
+}
+
+// openBuffer opens filename in a new buffer after the active one, or switches
+// to the buffer that already has it open. An empty buffer without a file is
+// replaced instead.
+func (a *App) openBuffer(filename string) error {
+	a.ensureBuffers()
+	a.saveViewState()
+	a.storeActiveBuffer()
+
+	absPath, err := filepath.Abs(filename)
+	if err != nil {
+		absPath = filename
+	}
+	for i, b := range a.buffers {
+		if b.store != nil && b.store.FilePath != "" && bufferPath(b) == absPath {
+			a.activateBuffer(i)
+			return nil
+		}
+	}
+
+	store := storage.NewJSONStore(filename)
+	if store.NeedsPassphrase() {
+		return fmt.Errorf("encrypted files can only be opened from the command line")
+	}
+	store.SetSessionID(a.sessionID)
+	if a.cfg != nil {
+		store.SetBackupCompression(a.cfg.Get("backupcompression") == "true")
+	}
+	outline, err := store.Load()
+	if err != nil {
+		return err
+	}
+	problems, repaired := checkOutline(outline, a.cfg)
+
+	b := &buffer{
+		outline:          outline,
+		store:            store,
+		tree:             ui.NewTreeView(outline.Items),
+		readOnly:         store.ReadOnly,
+		hasFile:          true,
+		originalFilePath: absPath,
+		autoSaveTime:     time.Now(),
+	}
+	if store.ReadOnly {
+		b.currentBackupPath = filename
+		b.originalFilePath = outline.OriginalFilename
+	}
+
+	idx := a.bufferIdx + 1
+	if !a.hasFile && !a.dirty {
+		// Replace the empty buffer
+		idx = a.bufferIdx
+		a.buffers[idx] = b
+	} else {
+		a.buffers = append(a.buffers[:idx], append([]*buffer{b}, a.buffers[idx:]...)...)
+	}
+	a.activateBuffer(idx)
+	a.loadMarks()
+	a.loadViewState()
+	a.restoreClock()
+	a.reportOutlineProblems(problems, repaired)
+	return nil
+}
+
+// bufferPath returns the absolute file path of a buffer
+func bufferPath(b *buffer) string {
+	absPath, err := filepath.Abs(b.store.FilePath)
+	if err != nil {
+		return b.store.FilePath
+	}
+	return absPath
+}
+
+// switchBuffer switches to buffer idx
+func (a *App) switchBuffer(idx int) {
+	a.saveViewState()
+	a.storeActiveBuffer()
+	a.activateBuffer(idx)
+	a.SetStatus(a.bufferStatus())
+}
+
+// cycleBuffer switches to the next (1) or previous (-1) buffer, wrapping around
+func (a *App) cycleBuffer(offset int) {
+	a.ensureBuffers()
+	if len(a.buffers) == 1 {
+		a.SetStatus("Only one buffer")
+		return
+	}
+	a.switchBuffer((a.bufferIdx + offset + len(a.buffers)) % len(a.buffers))
+}
+
+// closeBuffer closes the active buffer and switches to the next one. A buffer
+// with unsaved changes is only closed with force.
+func (a *App) closeBuffer(force bool) {
+	a.ensureBuffers()
+	if len(a.buffers) == 1 {
+		a.SetStatus("Cannot close the last buffer")
+		return
+	}
+	if a.dirty && !force {
+		a.SetStatus("Unsaved changes! Use :bd! to close without saving or :w to save")
+		return
+	}
+
+	name := a.bufferName(a.bufferIdx)
+	a.saveViewState()
+	a.buffers = append(a.buffers[:a.bufferIdx], a.buffers[a.bufferIdx+1:]...)
+	a.activateBuffer(min(a.bufferIdx, len(a.buffers)-1))
+	a.SetStatus(fmt.Sprintf("Closed %s, %s", name, a.bufferStatus()))
+}
+
+// bufferName returns the name of buffer idx as shown in the buffer list
+func (a *App) bufferName(idx int) string {
+	store := a.store
+	if idx != a.bufferIdx {
+		store = a.buffers[idx].store
+	}
+	if store == nil || store.FilePath == "" {
+		return "[No Name]"
+	}
+	return filepath.Base(store.FilePath)
+}
+
+// bufferStatus describes the active buffer, like "Buffer 2/3: notes.json"
+func (a *App) bufferStatus() string {
+	return fmt.Sprintf("Buffer %d/%d: %s", a.bufferIdx+1, len(a.buffers), a.bufferName(a.bufferIdx))
+}
+
+// dirtyBuffer returns the name of a buffer with unsaved changes, or an empty string
+func (a *App) dirtyBuffer() string {
+	if a.dirty {
+		return a.bufferName(a.bufferIdx)
+	}
+	for i, b := range a.buffers {
+		if i != a.bufferIdx && b.dirty {
+			return a.bufferName(i)
+		}
+	}
+	return ""
+}
+
+// handleBuffersCommand lists the buffers, marking the active one with % and
+// buffers with unsaved changes with +
+func (a *App) handleBuffersCommand() {
+	a.ensureBuffers()
+	var entries []string
+	for i, b := range a.buffers {
+		entry := fmt.Sprintf("%d %s", i+1, a.bufferName(i))
+		dirty := b.dirty
+		if i == a.bufferIdx {
+			entry = "%" + entry
+			dirty = a.dirty
+		}
+		if dirty {
+			entry += " +"
+		}
+		entries = append(entries, entry)
+	}
+	a.SetStatus("Buffers: " + strings.Join(entries, ", "))
+}
+
+// handleBufferCommand handles :b <number>
+func (a *App) handleBufferCommand(parts []string) {
+	a.ensureBuffers()
+	if len(parts) < 2 {
+		a.SetStatus(a.bufferStatus())
+		return
+	}
+	n, err := strconv.Atoi(parts[1])
+	if err != nil || n < 1 || n > len(a.buffers) {
+		a.SetStatus(fmt.Sprintf("No buffer %s (use 1-%d)", parts[1], len(a.buffers)))
+		return
+	}
+	a.switchBuffer(n - 1)
+}
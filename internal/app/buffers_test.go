@@ -0,0 +1,98 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pstuifzand/tui-outliner/internal/config"
+	"github.com/pstuifzand/tui-outliner/internal/model"
+	"github.com/pstuifzand/tui-outliner/internal/storage"
+	"github.com/pstuifzand/tui-outliner/internal/ui"
+)
+
+// writeTestOutline saves an outline with items named texts to dir/name
+func writeTestOutline(t *testing.T, dir, name string, texts ...string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	outline := model.NewOutline()
+	for _, text := range texts {
+		outline.Items = append(outline.Items, model.NewItem(text))
+	}
+	if err := storage.NewJSONStore(path).Save(outline); err != nil {
+		t.Fatalf("Failed to save %s: %v", name, err)
+	}
+	return path
+}
+
+func TestBuffers(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	first := writeTestOutline(t, dir, "first.json", "One", "Two")
+	second := writeTestOutline(t, dir, "second.json", "Alpha")
+
+	// Start without a file, like tuo without arguments
+	outline := model.NewOutline()
+	app := &App{
+		outline:      outline,
+		store:        storage.NewJSONStore(""),
+		tree:         ui.NewTreeView(outline.Items),
+		cfg:          &config.Config{},
+		visualAnchor: -1,
+	}
+
+	// The empty buffer is replaced by the first file
+	app.handleCommand("e " + first)
+	if len(app.buffers) != 1 || !app.hasFile || app.tree.GetSelected().Text != "One" {
+		t.Fatalf("Expected first.json in the only buffer, got %d buffers", len(app.buffers))
+	}
+	app.tree.SelectItem(1)
+	app.dirty = true
+
+	app.handleCommand("e " + second)
+	if len(app.buffers) != 2 || app.bufferIdx != 1 || app.dirty || app.tree.GetSelected().Text != "Alpha" {
+		t.Fatalf("Expected second.json in a new buffer, got buffer %d of %d", app.bufferIdx+1, len(app.buffers))
+	}
+
+	app.handleCommand("buffers")
+	if status := app.statusMsg; status != "Buffers: 1 first.json +, %2 second.json" {
+		t.Errorf("Unexpected buffer list %q", status)
+	}
+
+	// Switching back restores the selection and dirty flag
+	app.handleCommand("bnext")
+	if app.bufferIdx != 0 || !app.dirty || app.tree.GetSelected().Text != "Two" {
+		t.Fatalf("Expected the first buffer with its state, got buffer %d", app.bufferIdx+1)
+	}
+
+	// Opening an open file switches to its buffer
+	app.handleCommand("e " + second)
+	if len(app.buffers) != 2 || app.bufferIdx != 1 {
+		t.Errorf("Expected to switch to the open second.json, got buffer %d of %d", app.bufferIdx+1, len(app.buffers))
+	}
+
+	// Quitting is refused while a buffer has unsaved changes
+	app.handleCommand("q")
+	if app.quit || !strings.Contains(app.statusMsg, "first.json") {
+		t.Errorf("Expected quit to be refused, got %q", app.statusMsg)
+	}
+
+	app.handleCommand("bprev")
+	app.handleCommand("bd")
+	if len(app.buffers) != 2 {
+		t.Fatal("Expected a buffer with unsaved changes to stay open")
+	}
+	app.handleCommand("bd!")
+	if len(app.buffers) != 1 || app.store.FilePath != second || app.dirty {
+		t.Fatalf("Expected only second.json to stay open, got %q", app.store.FilePath)
+	}
+
+	app.handleCommand("bd")
+	if len(app.buffers) != 1 {
+		t.Error("Expected the last buffer to stay open")
+	}
+	app.handleCommand("q")
+	if !app.quit {
+		t.Errorf("Expected to quit, got %q", app.statusMsg)
+	}
+}
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"slices"
+	"time"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+	"github.com/pstuifzand/tui-outliner/internal/socket"
+)
+
+// handleSubscribeCommand starts tracking changes for a client that subscribed
+// to events. The server sends the events, the app only takes the item hashes
+// the first changed event is compared with.
+func (a *App) handleSubscribeCommand(msg socket.Message) {
+	if a.itemHashes == nil {
+		a.itemHashes = model.ItemHashes(a.tree.GetItems())
+	}
+	if msg.ResponseChan != nil {
+		msg.ResponseChan <- &socket.Response{Success: true, Message: "Subscribed"}
+	}
+}
+
+// markDirty marks the outline as modified. Every change to the tree goes
+// through it, so it also tells publishChanges to look for changed items.
+func (a *App) markDirty() {
+	a.dirty = true
+	a.changesPending = true
+}
+
+// publishChanges sends a changed event to the subscribed clients with the
+// items that changed since the last event. It compares item hashes, so one
+// markDirty after a number of changes reports all of them. The main loop calls
+// it on every tick, so a burst of changes, like typing, makes one event.
+// Without subscribers or without changes it does nothing.
+func (a *App) publishChanges() {
+	if a.socketServer == nil || !a.socketServer.HasSubscribers() {
+		a.itemHashes = nil
+		a.changesPending = false
+		return
+	}
+	if !a.changesPending && a.itemHashes != nil {
+		return
+	}
+	a.changesPending = false
+
+	hashes := model.ItemHashes(a.tree.GetItems())
+	if a.itemHashes == nil {
+		a.itemHashes = hashes
+		return
+	}
+	changed, removed := changedItems(a.itemHashes, hashes)
+	a.itemHashes = hashes
+	if len(changed) == 0 && len(removed) == 0 {
+		return
+	}
+	a.socketServer.Publish(socket.Event{
+		Event:   socket.EventChanged,
+		File:    a.eventFile(),
+		IDs:     changed,
+		Removed: removed,
+		Time:    time.Now(),
+	})
+}
+
+// publishSaved sends a saved event to the subscribed clients
+func (a *App) publishSaved() {
+	if a.socketServer == nil {
+		return
+	}
+	a.socketServer.Publish(socket.Event{Event: socket.EventSaved, File: a.eventFile(), Time: time.Now()})
+}
+
+// eventFile returns the file of the outline for events
+func (a *App) eventFile() string {
+	if a.store == nil {
+		return ""
+	}
+	return a.store.FilePath
+}
+
+// changedItems returns the sorted IDs of the items that were added or changed,
+// and of the items that were removed, between two sets of item hashes
+func changedItems(before, after map[string]uint64) (changed, removed []string) {
+	for id, hash := range after {
+		if previous, ok := before[id]; !ok || previous != hash {
+			changed = append(changed, id)
+		}
+	}
+	for id := range before {
+		if _, ok := after[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	slices.Sort(changed)
+	slices.Sort(removed)
+	return changed, removed
+}
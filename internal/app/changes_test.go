@@ -0,0 +1,92 @@
+package app
+
+import (
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+	"github.com/pstuifzand/tui-outliner/internal/socket"
+	"github.com/pstuifzand/tui-outliner/internal/ui"
+)
+
+func TestChangedItems(t *testing.T) {
+	before := map[string]uint64{"a": 1, "b": 2, "c": 3}
+	after := map[string]uint64{"a": 1, "b": 5, "d": 4}
+	changed, removed := changedItems(before, after)
+	if !slices.Equal(changed, []string{"b", "d"}) || !slices.Equal(removed, []string{"c"}) {
+		t.Errorf("Expected b and d changed and c removed, got %v and %v", changed, removed)
+	}
+}
+
+func TestPublishChanges(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	server, err := socket.NewServer(os.Getpid())
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer server.Stop()
+	server.Start()
+
+	parent := model.NewItem("Project")
+	child := model.NewItem("Task")
+	parent.AddChild(child)
+	other := model.NewItem("Other")
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{parent, other}
+	app := &App{outline: outline, tree: ui.NewTreeView(outline.Items), socketServer: server}
+
+	// Nothing is tracked without subscribers
+	app.changesPending = true
+	app.publishChanges()
+	if app.itemHashes != nil {
+		t.Fatal("Expected no item hashes without subscribers")
+	}
+
+	client, err := socket.NewClient(server.SocketPath())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	events := make(chan socket.Event, 4)
+	go client.Watch(func(event socket.Event) error {
+		events <- event
+		return nil
+	})
+	// Answer the subscribe message like the main loop
+	app.handleSocketMessage(<-server.Messages())
+	if app.itemHashes == nil {
+		t.Fatal("Expected a subscribe to start tracking changes")
+	}
+
+	// Without markDirty in between nothing is compared
+	other.Text = "Changed while idle"
+	app.publishChanges()
+
+	child.Text = "Task, changed"
+	parent.RemoveChild(child)
+	app.tree.SetItems(outline.Items)
+	app.markDirty()
+	app.publishChanges()
+
+	app.markDirty()
+	app.publishChanges()
+	app.publishSaved()
+
+	expect := func(want socket.Event) {
+		t.Helper()
+		select {
+		case event := <-events:
+			if event.Event != want.Event || !slices.Equal(event.IDs, want.IDs) || !slices.Equal(event.Removed, want.Removed) {
+				t.Errorf("Expected %+v, got %+v", want, event)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timeout waiting for a %s event", want.Event)
+		}
+	}
+	changed := []string{other.ID, parent.ID}
+	slices.Sort(changed)
+	expect(socket.Event{Event: socket.EventChanged, IDs: changed, Removed: []string{child.ID}})
+	// The second check found no changes, so the next event is the save
+	expect(socket.Event{Event: socket.EventSaved})
+}
@@ -0,0 +1,235 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	import_parser "github.com/pstuifzand/tui-outliner/internal/import"
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+// clipboardCommands are the clipboard tools tried in order when 'clipboardcmd' is not set
+var clipboardCommands = [][]string{
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+	{"pbcopy"},
+}
+
+// clipboardPasteCommands are the clipboard tools tried in order when
+// 'clipboardpastecmd' is not set
+var clipboardPasteCommands = [][]string{
+	{"wl-paste", "--no-newline"},
+	{"xclip", "-selection", "clipboard", "-o"},
+	{"xsel", "--clipboard", "--output"},
+	{"pbpaste"},
+}
+
+// handleYankCommand copies the selected item's text to the system clipboard
+// Usage: :yank [--subtree]
+func (a *App) handleYankCommand(parts []string) {
+	selected := a.tree.GetSelected()
+	if selected == nil {
+		a.SetStatus("No item selected")
+		return
+	}
+
+	subtree := false
+	for _, part := range parts[1:] {
+		if part != "--subtree" {
+			a.SetStatus("Usage: :yank [--subtree]")
+			return
+		}
+		subtree = true
+	}
+
+	text := selected.Text
+	if subtree {
+		var sb strings.Builder
+		writeIndentedText(&sb, selected, 0)
+		text = sb.String()
+	}
+
+	note, err := a.copyToClipboard(text)
+	if err != nil {
+		a.SetStatus(fmt.Sprintf("Failed to copy to clipboard: %v", err))
+		return
+	}
+	if subtree {
+		a.SetStatus(withToolNote("Copied subtree to clipboard", note))
+	} else {
+		a.SetStatus(withToolNote("Copied text to clipboard", note))
+	}
+}
+
+// handlePasteCommand adds the lines on the system clipboard as children of the
+// selected item. Indented lines become children of the line above them.
+// Usage: :paste
+func (a *App) handlePasteCommand(parts []string) {
+	if a.readOnly {
+		a.SetStatus("Cannot modify readonly file")
+		return
+	}
+	if len(parts) > 1 {
+		a.SetStatus("Usage: :paste")
+		return
+	}
+	selected := a.tree.GetSelected()
+	if selected == nil {
+		a.SetStatus("No item selected")
+		return
+	}
+
+	text, note, err := a.readFromClipboard()
+	if err != nil {
+		a.SetStatus(fmt.Sprintf("Failed to read clipboard: %v", err))
+		return
+	}
+
+	items, err := import_parser.ImportFile(text, import_parser.FormatIndentedText)
+	if err != nil {
+		a.SetStatus("Failed to import: " + err.Error())
+		return
+	}
+	if len(items) == 0 {
+		a.SetStatus("Clipboard is empty")
+		return
+	}
+
+	a.saveUndoState()
+	count := 0
+	for _, item := range items {
+		selected.AddChild(item)
+		count += 1 + item.CountDescendants()
+	}
+	selected.Expanded = true
+	a.tree.RebuildView()
+	a.markDirty()
+	a.SetStatus(withToolNote(fmt.Sprintf("Pasted %d items from clipboard", count), note))
+}
+
+// readFromClipboard returns the text on the clipboard and what the clipboard
+// tool wrote to its standard error
+func (a *App) readFromClipboard() (text, note string, err error) {
+	args, err := a.clipboardPasteCommand()
+	if err != nil {
+		return "", "", err
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = &stdout
+	note, err = runClipboardTool(cmd)
+	if err != nil {
+		return "", "", err
+	}
+	return stdout.String(), note, nil
+}
+
+// clipboardPasteCommand returns the 'clipboardpastecmd' setting split into
+// arguments, or the first clipboard tool found in PATH
+func (a *App) clipboardPasteCommand() ([]string, error) {
+	if a.cfg != nil {
+		if value := strings.Fields(a.cfg.Get("clipboardpastecmd")); len(value) > 0 {
+			return value, nil
+		}
+	}
+
+	for _, candidate := range clipboardPasteCommands {
+		// wl-paste only works inside a Wayland session
+		if candidate[0] == "wl-paste" && os.Getenv("WAYLAND_DISPLAY") == "" {
+			continue
+		}
+		if _, err := exec.LookPath(candidate[0]); err == nil {
+			return candidate, nil
+		}
+	}
+	return nil, fmt.Errorf("no clipboard tool found (install xclip, xsel, wl-paste or pbpaste, or :set clipboardpastecmd)")
+}
+
+// copyToClipboard pipes text into the clipboard command and returns what the
+// tool wrote to its standard error
+func (a *App) copyToClipboard(text string) (string, error) {
+	args, err := a.clipboardCommand()
+	if err != nil {
+		return "", err
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	return runClipboardTool(cmd)
+}
+
+// runClipboardTool runs cmd with its standard error captured, because output
+// on the terminal would mess up the screen. The captured output is added to
+// the error when the tool fails, and returned otherwise. Standard error goes
+// to a temporary file instead of a pipe: copy tools like xclip leave a child
+// running to serve the selection, and waiting for a pipe they inherited would
+// block until another program takes the clipboard.
+func runClipboardTool(cmd *exec.Cmd) (string, error) {
+	stderr, err := os.CreateTemp("", "tuo-clipboard-*")
+	if err == nil {
+		defer os.Remove(stderr.Name())
+		defer stderr.Close()
+		cmd.Stderr = stderr
+	}
+
+	err = cmd.Run()
+	var note string
+	if stderr != nil {
+		if output, readErr := os.ReadFile(stderr.Name()); readErr == nil {
+			note = strings.Join(strings.Fields(string(output)), " ")
+		}
+	}
+	if err != nil {
+		if note != "" {
+			return "", fmt.Errorf("%w: %s", err, note)
+		}
+		return "", err
+	}
+	return note, nil
+}
+
+// withToolNote adds the output of a clipboard tool to a status message
+func withToolNote(status, note string) string {
+	if note == "" {
+		return status
+	}
+	return status + " (" + note + ")"
+}
+
+// clipboardCommand returns the 'clipboardcmd' setting split into arguments,
+// or the first clipboard tool found in PATH
+func (a *App) clipboardCommand() ([]string, error) {
+	if a.cfg != nil {
+		if value := strings.Fields(a.cfg.Get("clipboardcmd")); len(value) > 0 {
+			return value, nil
+		}
+	}
+
+	for _, candidate := range clipboardCommands {
+		// wl-copy only works inside a Wayland session
+		if candidate[0] == "wl-copy" && os.Getenv("WAYLAND_DISPLAY") == "" {
+			continue
+		}
+		if _, err := exec.LookPath(candidate[0]); err == nil {
+			return candidate, nil
+		}
+	}
+	return nil, fmt.Errorf("no clipboard tool found (install xclip, xsel, wl-copy or pbcopy, or :set clipboardcmd)")
+}
+
+// writeIndentedText writes an item and its descendants, indenting each level by two spaces
+func writeIndentedText(sb *strings.Builder, item *model.Item, depth int) {
+	for _, line := range strings.Split(item.Text, "\n") {
+		sb.WriteString(strings.Repeat("  ", depth))
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+	for _, child := range item.Children {
+		writeIndentedText(sb, child, depth+1)
+	}
+}
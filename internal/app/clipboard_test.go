@@ -0,0 +1,153 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pstuifzand/tui-outliner/internal/config"
+	"github.com/pstuifzand/tui-outliner/internal/model"
+	"github.com/pstuifzand/tui-outliner/internal/ui"
+)
+
+func TestYankCommandUsesClipboardCmd(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "clipboard.txt")
+	script := filepath.Join(dir, "copy.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ncat > "+output+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	parent := model.NewItem("Parent")
+	child := model.NewItem("Child")
+	parent.AddChild(child)
+	child.AddChild(model.NewItem("Grandchild"))
+
+	cfg := &config.Config{}
+	cfg.Set("clipboardcmd", script)
+	app := &App{
+		tree: ui.NewTreeView([]*model.Item{parent}),
+		cfg:  cfg,
+	}
+
+	app.handleYankCommand([]string{"yank"})
+	if data, _ := os.ReadFile(output); string(data) != "Parent" {
+		t.Errorf("Expected item text on the clipboard, got %q (status %q)", data, app.statusMsg)
+	}
+
+	app.handleYankCommand([]string{"yank", "--subtree"})
+	expected := "Parent\n  Child\n    Grandchild\n"
+	if data, _ := os.ReadFile(output); string(data) != expected {
+		t.Errorf("Expected indented subtree %q, got %q", expected, data)
+	}
+
+	cfg.Set("clipboardcmd", filepath.Join(dir, "missing"))
+	app.handleYankCommand([]string{"yank"})
+	if app.statusMsg == "Copied text to clipboard" {
+		t.Error("Expected a failure status for a missing clipboard command")
+	}
+}
+
+func TestPasteCommandImportsIndentedText(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "paste.sh")
+	content := "#!/bin/sh\nprintf 'Groceries\\n  Milk\\n  Eggs\\n\\nChores\\n  Laundry\\n'\n"
+	if err := os.WriteFile(script, []byte(content), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	parent := model.NewItem("Inbox")
+	cfg := &config.Config{}
+	cfg.Set("clipboardpastecmd", script)
+	app := &App{
+		tree: ui.NewTreeView([]*model.Item{parent}),
+		cfg:  cfg,
+	}
+
+	app.handlePasteCommand([]string{"paste"})
+	if app.statusMsg != "Pasted 5 items from clipboard" {
+		t.Fatalf("Unexpected status: %q", app.statusMsg)
+	}
+	if len(parent.Children) != 2 || parent.Children[0].Text != "Groceries" || parent.Children[1].Text != "Chores" {
+		t.Fatalf("Expected two pasted children, got %d", len(parent.Children))
+	}
+	groceries := parent.Children[0]
+	if len(groceries.Children) != 2 || groceries.Children[1].Text != "Eggs" || groceries.Children[1].Parent != groceries {
+		t.Error("Expected indented lines to become children")
+	}
+	if !parent.Expanded || !app.dirty || len(app.undoStack) != 1 {
+		t.Error("Expected the parent to be expanded and the paste to be undoable")
+	}
+
+	app.readOnly = true
+	app.handlePasteCommand([]string{"paste"})
+	if app.statusMsg != "Cannot modify readonly file" || len(parent.Children) != 2 {
+		t.Error("Expected readonly files not to be changed")
+	}
+}
+
+func TestClipboardToolStderrInStatus(t *testing.T) {
+	dir := t.TempDir()
+	warn := filepath.Join(dir, "warn.sh")
+	if err := os.WriteFile(warn, []byte("#!/bin/sh\ncat > /dev/null\necho 'no owner yet' >&2\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	fail := filepath.Join(dir, "fail.sh")
+	if err := os.WriteFile(fail, []byte("#!/bin/sh\necho 'cannot open display' >&2\nexit 1\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{}
+	cfg.Set("clipboardcmd", warn)
+	app := &App{
+		tree: ui.NewTreeView([]*model.Item{model.NewItem("Text")}),
+		cfg:  cfg,
+	}
+
+	app.handleYankCommand([]string{"yank"})
+	if app.statusMsg != "Copied text to clipboard (no owner yet)" {
+		t.Errorf("Expected the tool output in the status, got %q", app.statusMsg)
+	}
+
+	cfg.Set("clipboardcmd", fail)
+	app.handleYankCommand([]string{"yank"})
+	if app.statusMsg != "Failed to copy to clipboard: exit status 1: cannot open display" {
+		t.Errorf("Expected the tool error in the status, got %q", app.statusMsg)
+	}
+}
+
+func TestYankDoesNotWaitForBackgroundChild(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "clipboard.txt")
+	script := filepath.Join(dir, "copy.sh")
+	// Like xclip, leave a child running that keeps standard error open
+	content := "#!/bin/sh\ncat > " + output + "\nsleep 5 > /dev/null &\n"
+	if err := os.WriteFile(script, []byte(content), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{}
+	cfg.Set("clipboardcmd", script)
+	app := &App{
+		tree: ui.NewTreeView([]*model.Item{model.NewItem("Text")}),
+		cfg:  cfg,
+	}
+
+	done := make(chan struct{})
+	go func() {
+		app.handleYankCommand([]string{"yank"})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected :yank to return while the child of the copy tool runs")
+	}
+	if app.statusMsg != "Copied text to clipboard" {
+		t.Errorf("Unexpected status: %q", app.statusMsg)
+	}
+	if data, _ := os.ReadFile(output); string(data) != "Text" {
+		t.Errorf("Expected the text on the clipboard, got %q", data)
+	}
+}
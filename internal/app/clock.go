@@ -0,0 +1,167 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+	"github.com/pstuifzand/tui-outliner/internal/ui"
+)
+
+// Attributes used by :clock. The start of the running session is kept in the
+// item, so a session survives restarting tuo.
+const (
+	clockStartAttr = "clock_start" // Start of the running session, RFC 3339
+	clockLogAttr   = "clock"       // Finished sessions, "start/end" separated by spaces
+	timeSpentAttr  = "time_spent"  // Total minutes of the finished sessions
+)
+
+// clockLogFormat is the time format of the sessions in the clock attribute
+const clockLogFormat = "2006-01-02T15:04"
+
+// handleClockCommand handles :clock [in|out]
+func (a *App) handleClockCommand(parts []string) {
+	subcommand := ""
+	if len(parts) > 1 {
+		subcommand = parts[1]
+	}
+
+	switch subcommand {
+	case "":
+		if a.clockItemID == "" {
+			a.SetStatus("Not clocked in")
+			return
+		}
+		a.SetStatus(fmt.Sprintf("Clocked in on %s for %s", a.clockItemText, formatClockDuration(time.Since(a.clockStart))))
+	case "in":
+		if a.readOnly {
+			a.SetStatus("Cannot modify readonly file")
+			return
+		}
+		selected := a.tree.GetSelected()
+		if selected == nil {
+			a.SetStatus("No item selected")
+			return
+		}
+		if selected.ID == a.clockItemID {
+			a.SetStatus("Already clocked in on " + selected.Text)
+			return
+		}
+
+		a.saveUndoState()
+		status := ""
+		if a.clockItemID != "" {
+			status = a.clockOut(time.Now()) + ", "
+		}
+		a.clockIn(selected, time.Now())
+		a.SetStatus(status + "Clocked in on " + selected.Text)
+	case "out":
+		if a.readOnly {
+			a.SetStatus("Cannot modify readonly file")
+			return
+		}
+		if a.clockItemID == "" {
+			a.SetStatus("Not clocked in")
+			return
+		}
+		a.saveUndoState()
+		a.SetStatus(a.clockOut(time.Now()))
+	default:
+		a.SetStatus("Usage: :clock [in|out]")
+	}
+}
+
+// clockIn starts a session on item at now
+func (a *App) clockIn(item *model.Item, now time.Time) {
+	if item.Metadata == nil {
+		item.Metadata = &model.Metadata{
+			Attributes: make(map[string]string),
+			Created:    now,
+		}
+	}
+	if item.Metadata.Attributes == nil {
+		item.Metadata.Attributes = make(map[string]string)
+	}
+	item.Metadata.Attributes[clockStartAttr] = now.Format(time.RFC3339)
+	item.Metadata.Modified = now
+
+	a.clockItemID = item.ID
+	a.clockItemText = item.Text
+	a.clockStart = now
+	a.markDirty()
+}
+
+// clockOut ends the running session at now. The session is added to the clock
+// log and its minutes to time_spent. Returns a status message.
+func (a *App) clockOut(now time.Time) string {
+	elapsed := now.Sub(a.clockStart)
+	message := fmt.Sprintf("Clocked out of %s after %s", a.clockItemText, formatClockDuration(elapsed))
+
+	// Look the item up again, undo may have replaced it
+	item := a.findTreeItem(a.clockItemID)
+	a.clockItemID = ""
+	a.clockItemText = ""
+	if item == nil {
+		return message + " (item was deleted)"
+	}
+	if item.Metadata == nil {
+		item.Metadata = &model.Metadata{Attributes: make(map[string]string), Created: now}
+	}
+	if item.Metadata.Attributes == nil {
+		item.Metadata.Attributes = make(map[string]string)
+	}
+
+	attrs := item.Metadata.Attributes
+	delete(attrs, clockStartAttr)
+	session := a.clockStart.Format(clockLogFormat) + "/" + now.Format(clockLogFormat)
+	if attrs[clockLogAttr] == "" {
+		attrs[clockLogAttr] = session
+	} else {
+		attrs[clockLogAttr] += " " + session
+	}
+	spent, _ := strconv.Atoi(attrs[timeSpentAttr])
+	attrs[timeSpentAttr] = strconv.Itoa(spent + int(math.Round(elapsed.Minutes())))
+	item.Metadata.Modified = now
+
+	a.markDirty()
+	return message
+}
+
+// restoreClock resumes the session of the first item with a clock_start
+// attribute, so a session continues after restarting
+func (a *App) restoreClock() {
+	for _, item := range a.outline.GetAllItems() {
+		if item.Metadata == nil {
+			continue
+		}
+		start, err := time.Parse(time.RFC3339, item.Metadata.Attributes[clockStartAttr])
+		if err != nil {
+			continue
+		}
+		a.clockItemID = item.ID
+		a.clockItemText = item.Text
+		a.clockStart = start
+		return
+	}
+}
+
+// clockStatusText describes the running session for the status line, or
+// returns an empty string when not clocked in
+func (a *App) clockStatusText(now time.Time) string {
+	if a.clockItemID == "" {
+		return ""
+	}
+	text := ui.TruncateToWidthWithEllipsis(a.clockItemText, 20)
+	return fmt.Sprintf("%s %s", formatClockDuration(now.Sub(a.clockStart)), text)
+}
+
+// formatClockDuration formats d as h:mm:ss
+func formatClockDuration(d time.Duration) string {
+	d = max(d, 0).Round(time.Second)
+	hours := int(d / time.Hour)
+	minutes := int(d%time.Hour) / int(time.Minute)
+	seconds := int(d%time.Minute) / int(time.Second)
+	return fmt.Sprintf("%d:%02d:%02d", hours, minutes, seconds)
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pstuifzand/tui-outliner/internal/config"
+	"github.com/pstuifzand/tui-outliner/internal/model"
+	"github.com/pstuifzand/tui-outliner/internal/ui"
+)
+
+func TestClockInOut(t *testing.T) {
+	writing := model.NewItem("Write report")
+	review := model.NewItem("Review")
+	writing.Metadata.Attributes["time_spent"] = "5"
+
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{writing, review}
+	app := &App{
+		outline: outline,
+		tree:    ui.NewTreeView(outline.Items),
+		cfg:     &config.Config{},
+	}
+
+	start := time.Date(2026, 10, 16, 9, 0, 0, 0, time.Local)
+	app.clockIn(writing, start)
+	if writing.Metadata.Attributes["clock_start"] == "" || !app.dirty {
+		t.Fatal("Expected clock_start to be recorded")
+	}
+	if text := app.clockStatusText(start.Add(90 * time.Second)); text != "0:01:30 Write report" {
+		t.Errorf("Unexpected status text %q", text)
+	}
+
+	app.clockOut(start.Add(25 * time.Minute))
+	app.clockIn(review, start.Add(25*time.Minute))
+
+	attrs := writing.Metadata.Attributes
+	if attrs["clock_start"] != "" || attrs["time_spent"] != "30" || attrs["clock"] != "2026-10-16T09:00/2026-10-16T09:25" {
+		t.Errorf("Unexpected attributes after clocking out: %v", attrs)
+	}
+	if app.clockItemID != review.ID {
+		t.Fatalf("Expected to be clocked in on Review")
+	}
+
+	// A session in the file is resumed, like after a restart
+	resumed := &App{outline: outline, tree: app.tree}
+	resumed.restoreClock()
+	if resumed.clockItemID != review.ID || !resumed.clockStart.Equal(start.Add(25*time.Minute)) {
+		t.Errorf("Expected the Review session to be resumed, got %q at %v", resumed.clockItemID, resumed.clockStart)
+	}
+
+	resumed.clockOut(start.Add(40 * time.Minute))
+	if review.Metadata.Attributes["time_spent"] != "15" || resumed.clockStatusText(start) != "" {
+		t.Errorf("Expected 15 minutes on Review, got %v", review.Metadata.Attributes)
+	}
+
+	// Clocking into another item clocks out of the first
+	resumed.tree.SelectItemByID(review.ID)
+	resumed.handleClockCommand([]string{"clock", "in"})
+	resumed.tree.SelectItemByID(writing.ID)
+	resumed.handleClockCommand([]string{"clock", "in"})
+	if resumed.clockItemID != writing.ID || review.Metadata.Attributes["clock_start"] != "" {
+		t.Errorf("Expected to be clocked in on Write report only, status %q", resumed.statusMsg)
+	}
+	resumed.handleClockCommand([]string{"clock", "out"})
+	if resumed.clockItemID != "" || writing.Metadata.Attributes["clock_start"] != "" {
+		t.Errorf("Expected to be clocked out, status %q", resumed.statusMsg)
+	}
+	if len(resumed.undoStack) != 3 {
+		t.Errorf("Expected an undo state for each clock command, got %d", len(resumed.undoStack))
+	}
+}
+
+func TestClockWithoutAttributes(t *testing.T) {
+	// Items saved without attributes are loaded with a nil attribute map
+	item := model.NewItem("Plain")
+	item.Metadata.Attributes = nil
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{item}
+	app := &App{outline: outline, tree: ui.NewTreeView(outline.Items)}
+
+	start := time.Date(2026, 10, 16, 9, 0, 0, 0, time.Local)
+	app.clockIn(item, start)
+	item.Metadata.Attributes = nil
+	app.clockOut(start.Add(10 * time.Minute))
+	if item.Metadata.Attributes["time_spent"] != "10" {
+		t.Errorf("Expected 10 minutes spent, got %v", item.Metadata.Attributes)
+	}
+}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+// confirmDelete runs del when it deletes at most the number of items of the
+// 'confirmdelete' setting, and asks for confirmation in the command line
+// otherwise. A setting of 0 or no setting never asks, and doesn't count the
+// items with countNodes.
+func (a *App) confirmDelete(countNodes func() int, del func(a *App)) {
+	threshold := 0
+	if a.cfg != nil {
+		threshold, _ = strconv.Atoi(a.cfg.Get("confirmdelete"))
+	}
+	if threshold <= 0 {
+		del(a)
+		return
+	}
+	nodes := countNodes()
+	if nodes <= threshold {
+		del(a)
+		return
+	}
+
+	a.confirmAction = del
+	a.command.StartConfirm(fmt.Sprintf("Delete %s?", pluralize(nodes, "item", "items")))
+}
+
+// confirmDeleteSelected deletes count items from the selected item on, like d
+// and 3d, after asking when they are more than the 'confirmdelete' setting
+func (a *App) confirmDeleteSelected(count int) {
+	a.confirmDelete(func() int { return a.deleteCountNodes(count) }, func(a *App) {
+		if count == 1 {
+			a.deleteSelected()
+			return
+		}
+		a.deleteSelectedCount(count)
+	})
+}
+
+// answerConfirm runs the action waiting for confirmation when yes is true
+func (a *App) answerConfirm(yes bool) {
+	action := a.confirmAction
+	a.confirmAction = nil
+	if action == nil {
+		return
+	}
+	if !yes {
+		a.SetStatus("Delete cancelled")
+		return
+	}
+	action(a)
+}
+
+// deleteCountNodes returns the number of items deleted by deleting count
+// items from the selected item on, including their descendants
+func (a *App) deleteCountNodes(count int) int {
+	idx := a.tree.GetSelectedIndex()
+	items := a.tree.GetItemsInRange(idx, len(a.tree.GetDisplayItems())-1)
+	roots := selectionRoots(items)
+	return countNodes(roots[:min(count, len(roots))])
+}
+
+// countNodes returns the number of items in the subtrees of items
+func countNodes(items []*model.Item) int {
+	n := 0
+	for _, item := range items {
+		n += 1 + item.CountDescendants()
+	}
+	return n
+}
@@ -0,0 +1,119 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/pstuifzand/tui-outliner/internal/config"
+	"github.com/pstuifzand/tui-outliner/internal/model"
+	"github.com/pstuifzand/tui-outliner/internal/ui"
+)
+
+func TestConfirmDelete(t *testing.T) {
+	parent := model.NewItem("Parent")
+	parent.AddChild(model.NewItem("Child 1"))
+	parent.AddChild(model.NewItem("Child 2"))
+	sibling := model.NewItem("Sibling")
+
+	cfg := &config.Config{}
+	cfg.Set("confirmdelete", "2")
+	app := &App{
+		tree:    ui.NewTreeView([]*model.Item{parent, sibling}),
+		command: ui.NewCommandMode(),
+		cfg:     cfg,
+	}
+
+	if got := app.deleteCountNodes(1); got != 3 {
+		t.Errorf("Expected 3 items in the selected subtree, got %d", got)
+	}
+	if got := app.deleteCountNodes(5); got != 4 {
+		t.Errorf("Expected 4 items in all subtrees, got %d", got)
+	}
+
+	answer := func(r rune) {
+		cmd, done := app.command.HandleKey(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+		if !done {
+			t.Fatal("Expected a key to answer the question")
+		}
+		app.answerConfirm(cmd == "y")
+	}
+
+	// A large subtree asks first, and 'n' keeps it
+	app.confirmDeleteSelected(1)
+	if !app.command.IsConfirming() || len(app.tree.GetItems()) != 2 {
+		t.Fatal("Expected a confirmation before deleting the subtree")
+	}
+	answer('n')
+	if app.command.IsActive() || len(app.tree.GetItems()) != 2 {
+		t.Fatalf("Expected the subtree to be kept, got %v", itemTexts(app.tree.GetItems()))
+	}
+
+	app.confirmDeleteSelected(1)
+	answer('y')
+	if items := app.tree.GetItems(); len(items) != 1 || items[0] != sibling {
+		t.Fatalf("Expected only the sibling to remain, got %v", itemTexts(items))
+	}
+
+	// Small deletes don't ask
+	app.confirmDeleteSelected(1)
+	if app.command.IsConfirming() || len(app.tree.GetItems()) != 0 {
+		t.Errorf("Expected the sibling to be deleted right away, got %v", itemTexts(app.tree.GetItems()))
+	}
+}
+
+func TestConfirmDeleteDisabled(t *testing.T) {
+	parent := model.NewItem("Parent")
+	parent.AddChild(model.NewItem("Child"))
+
+	cfg := &config.Config{}
+	cfg.Set("confirmdelete", "0")
+	app := &App{
+		tree:    ui.NewTreeView([]*model.Item{parent}),
+		command: ui.NewCommandMode(),
+		cfg:     cfg,
+	}
+
+	app.confirmDeleteSelected(1)
+	if app.command.IsConfirming() || len(app.tree.GetItems()) != 0 {
+		t.Error("Expected no confirmation with confirmdelete=0")
+	}
+}
+
+func TestConfirmDeleteDisabledDoesNotCount(t *testing.T) {
+	app := &App{cfg: &config.Config{}}
+	deleted := false
+	app.confirmDelete(func() int {
+		t.Error("Expected the items not to be counted without confirmdelete")
+		return 0
+	}, func(*App) { deleted = true })
+	if !deleted {
+		t.Error("Expected the delete to run")
+	}
+}
+
+func TestRepeatDeleteAsksFirst(t *testing.T) {
+	small := model.NewItem("Small")
+	parent := model.NewItem("Parent")
+	parent.AddChild(model.NewItem("Child 1"))
+	parent.AddChild(model.NewItem("Child 2"))
+
+	cfg := &config.Config{}
+	cfg.Set("confirmdelete", "2")
+	app := &App{
+		tree:    ui.NewTreeView([]*model.Item{small, parent}),
+		command: ui.NewCommandMode(),
+		cfg:     cfg,
+	}
+
+	app.confirmDeleteSelected(1)
+	if items := app.tree.GetItems(); len(items) != 1 || items[0] != parent {
+		t.Fatalf("Expected the small item to be deleted, got %v", itemTexts(items))
+	}
+
+	// Repeating the delete on the large subtree asks first
+	app.repeatLastAction()
+	if !app.command.IsConfirming() || len(app.tree.GetItems()) != 1 {
+		t.Fatal("Expected a confirmation before repeating the delete")
+	}
+}
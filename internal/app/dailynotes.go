@@ -0,0 +1,145 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+	"github.com/pstuifzand/tui-outliner/internal/search"
+)
+
+// handleDailyNoteCommand handles :dailynote [day]
+// The day is today by default, a day offset like +1 or -1, or a date like 2025-11-01.
+func (a *App) handleDailyNoteCommand(parts []string) {
+	arg := ""
+	if len(parts) > 1 {
+		arg = parts[1]
+	}
+
+	date, err := parseDailyNoteDate(arg, time.Now())
+	if err != nil {
+		a.SetStatus(err.Error())
+		return
+	}
+	a.openDailyNote(date)
+}
+
+// stepDailyNote opens the daily note days before or after the selected daily
+// note, or before or after today when no daily note is selected
+func (a *App) stepDailyNote(days int) {
+	date := time.Now()
+	for item := a.tree.GetSelected(); item != nil; item = item.Parent {
+		if item.Metadata == nil || item.Metadata.Attributes["type"] != "day" {
+			continue
+		}
+		if noteDate, err := time.ParseInLocation("2006-01-02", item.Metadata.Attributes["date"], time.Local); err == nil {
+			date = noteDate
+			break
+		}
+	}
+	a.openDailyNote(date.AddDate(0, 0, days))
+}
+
+// parseDailyNoteDate parses the day argument of :dailynote relative to now
+func parseDailyNoteDate(arg string, now time.Time) (time.Time, error) {
+	switch arg {
+	case "", "today":
+		return now, nil
+	case "yesterday":
+		return now.AddDate(0, 0, -1), nil
+	case "tomorrow":
+		return now.AddDate(0, 0, 1), nil
+	}
+
+	if strings.HasPrefix(arg, "+") || strings.HasPrefix(arg, "-") {
+		days, err := strconv.Atoi(arg)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid day offset: %s", arg)
+		}
+		return now.AddDate(0, 0, days), nil
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", arg, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date: %s (use YYYY-MM-DD, +N or -N)", arg)
+	}
+	return date, nil
+}
+
+// openDailyNote selects the daily note (type=day) for date. A missing note is
+// created under the daily notes container (type=dailynotes), in date order.
+func (a *App) openDailyNote(date time.Time) {
+	dateStr := date.Format("2006-01-02")             // ISO format for attribute storage
+	formattedDate := date.Format("Mon, Jan 2, 2006") // Short day name format for display
+
+	// Sync outline with tree so notes created in this session are found
+	a.outline.Items = a.tree.GetItems()
+	a.outline.BuildIndex()
+
+	note, err := search.GetFirstByQuery(a.outline, fmt.Sprintf("@type=day @date=%s", dateStr))
+	if err != nil {
+		a.SetStatus(fmt.Sprintf("Error while searching: %v", err))
+		return
+	}
+	if note != nil {
+		if a.jumpToItem(note) {
+			a.SetStatus("Navigated to daily note for " + formattedDate)
+		}
+		return
+	}
+
+	if a.readOnly {
+		a.SetStatus("Cannot modify readonly file")
+		return
+	}
+
+	a.saveUndoState()
+
+	container, err := search.GetFirstByQuery(a.outline, "@type=dailynotes")
+	if err != nil {
+		a.SetStatus(fmt.Sprintf("Error while searching: %v", err))
+		return
+	}
+	if container == nil {
+		// Create the daily notes container at root level
+		container = model.NewItem("Daily Notes")
+		container.Metadata.Attributes["type"] = "dailynotes"
+		a.outline.Items = append(a.outline.Items, container)
+		a.tree.SetItems(a.outline.Items)
+	}
+
+	note = model.NewItem(formattedDate)
+	note.Metadata.Attributes["type"] = "day"
+	note.Metadata.Attributes["date"] = dateStr
+	insertDailyNote(container, note)
+	container.Expanded = true
+
+	a.markDirty()
+	a.tree.RebuildView()
+	if a.jumpToItem(note) {
+		a.SetStatus("Created daily note for " + formattedDate)
+	}
+}
+
+// insertDailyNote adds note to container before the first daily note with a
+// later date, so the notes stay in date order. Other children keep their place.
+func insertDailyNote(container, note *model.Item) {
+	date := note.Metadata.Attributes["date"]
+	idx := len(container.Children)
+	for i, child := range container.Children {
+		if child.Metadata == nil || child.Metadata.Attributes["type"] != "day" {
+			continue
+		}
+		// ISO dates sort as strings
+		if childDate := child.Metadata.Attributes["date"]; childDate > date {
+			idx = i
+			break
+		}
+	}
+
+	note.Parent = container
+	container.Children = slices.Insert(container.Children, idx, note)
+}
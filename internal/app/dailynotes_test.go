@@ -0,0 +1,85 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pstuifzand/tui-outliner/internal/config"
+	"github.com/pstuifzand/tui-outliner/internal/model"
+	"github.com/pstuifzand/tui-outliner/internal/ui"
+)
+
+func TestParseDailyNoteDate(t *testing.T) {
+	now := time.Date(2025, 11, 1, 9, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{"", "2025-11-01"},
+		{"+1", "2025-11-02"},
+		{"-1", "2025-10-31"},
+		{"yesterday", "2025-10-31"},
+		{"2025-12-24", "2025-12-24"},
+	}
+	for _, tt := range tests {
+		got, err := parseDailyNoteDate(tt.arg, now)
+		if err != nil {
+			t.Errorf("parseDailyNoteDate(%q) failed: %v", tt.arg, err)
+			continue
+		}
+		if got.Format("2006-01-02") != tt.want {
+			t.Errorf("parseDailyNoteDate(%q) = %s, want %s", tt.arg, got.Format("2006-01-02"), tt.want)
+		}
+	}
+
+	for _, arg := range []string{"+x", "next week", "2025-13-01"} {
+		if _, err := parseDailyNoteDate(arg, now); err == nil {
+			t.Errorf("parseDailyNoteDate(%q) should fail", arg)
+		}
+	}
+}
+
+func TestDailyNoteCommand(t *testing.T) {
+	day := func(date string) *model.Item {
+		item := model.NewItem(date)
+		item.Metadata.Attributes["type"] = "day"
+		item.Metadata.Attributes["date"] = date
+		return item
+	}
+
+	container := model.NewItem("Daily Notes")
+	container.Metadata.Attributes["type"] = "dailynotes"
+	first := day("2025-11-01")
+	last := day("2025-11-05")
+	container.AddChild(first)
+	container.AddChild(last)
+
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{container}
+	app := &App{
+		outline: outline,
+		tree:    ui.NewTreeView(outline.Items),
+		cfg:     &config.Config{},
+	}
+
+	app.handleDailyNoteCommand([]string{"dailynote", "2025-11-03"})
+	if len(container.Children) != 3 || container.Children[1].Metadata.Attributes["date"] != "2025-11-03" {
+		t.Fatalf("Expected the new note between the existing notes, got %d children", len(container.Children))
+	}
+	created := container.Children[1]
+	if app.tree.GetSelected() != created || !app.dirty {
+		t.Errorf("Expected the new note to be selected and the outline dirty")
+	}
+
+	// Stepping from a note goes to the day before, creating nothing when it exists
+	app.tree.SelectItemByID(last.ID)
+	app.stepDailyNote(-1)
+	if len(container.Children) != 4 || app.tree.GetSelected().Metadata.Attributes["date"] != "2025-11-04" {
+		t.Fatalf("Expected a note for 2025-11-04 to be created and selected")
+	}
+	app.stepDailyNote(-1)
+	if app.tree.GetSelected() != created || len(container.Children) != 4 {
+		t.Errorf("Expected to navigate to the existing note for 2025-11-03")
+	}
+}
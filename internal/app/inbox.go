@@ -42,7 +42,7 @@ func (app *App) getOrCreateInboxNode() (*model.Item, bool) {
 
 	// Add to root level
 	app.outline.Items = append(app.outline.Items, inbox)
-	app.dirty = true
+	app.markDirty()
 
 	return inbox, true
 }
@@ -58,21 +58,12 @@ func (app *App) addToInbox(text string, attributes map[string]string) error {
 
 	inbox, created := app.getOrCreateInboxNode()
 
-	// Create new item
-	newItem := model.NewItem(text)
-
-	// Set attributes if provided
-	if len(attributes) > 0 {
-		if newItem.Metadata.Attributes == nil {
-			newItem.Metadata.Attributes = make(map[string]string)
-		}
-		maps.Copy(newItem.Metadata.Attributes, attributes)
-	}
-
+	newItem := newItemWithAttributes(text, attributes)
+	app.applyTypeDefaults(newItem)
 	inbox.AddChild(newItem)
 
 	// Mark as dirty to trigger save
-	app.dirty = true
+	app.markDirty()
 
 	// Reset auto-save timer to save soon
 	app.autoSaveTime = time.Now()
@@ -92,3 +83,47 @@ func (app *App) addToInbox(text string, attributes map[string]string) error {
 
 	return nil
 }
+
+// addToParent adds a new item under an existing parent, found by ID or by the
+// first item matching parentQuery. Unlike addToInbox it never creates the parent.
+func (app *App) addToParent(parentID, parentQuery, text string, attributes map[string]string) error {
+	// Sync outline with tree so the index covers every item
+	app.outline.Items = app.tree.GetItems()
+	app.outline.BuildIndex()
+
+	parent, err := search.FindParent(app.outline, parentID, parentQuery)
+	if err != nil {
+		return err
+	}
+
+	app.saveUndoState()
+	newItem := newItemWithAttributes(text, attributes)
+	app.applyTypeDefaults(newItem)
+	parent.AddChild(newItem)
+	parent.Expanded = true
+
+	// Mark as dirty and save soon, like items added to the inbox
+	app.markDirty()
+	app.autoSaveTime = time.Now()
+
+	app.tree.RebuildView()
+	app.SetStatus("Added under " + parent.Text)
+	app.render()
+
+	return nil
+}
+
+// newItemWithAttributes creates an item with a copy of the given attributes
+func newItemWithAttributes(text string, attributes map[string]string) *model.Item {
+	newItem := model.NewItem(text)
+
+	// Set attributes if provided
+	if len(attributes) > 0 {
+		if newItem.Metadata.Attributes == nil {
+			newItem.Metadata.Attributes = make(map[string]string)
+		}
+		maps.Copy(newItem.Metadata.Attributes, attributes)
+	}
+
+	return newItem
+}
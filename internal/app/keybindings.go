@@ -2,8 +2,7 @@ package app
 
 import (
 	"fmt"
-	"strings"
-	"time"
+	"slices"
 
 	"github.com/pstuifzand/tui-outliner/internal/model"
 	"github.com/pstuifzand/tui-outliner/internal/ui"
@@ -13,7 +12,14 @@ import (
 type KeyBinding struct {
 	Key         rune
 	Description string
-	Handler     func(*App)
+	// Action names the binding in the keys file and :map, like "indent"
+	Action  string
+	Handler func(*App)
+	// Repeat runs the handler count times with a count prefix, like 5j
+	Repeat bool
+	// CountHandler handles the key with a count prefix instead. Without
+	// either, the count is ignored.
+	CountHandler func(*App, int)
 }
 
 // GetKey returns the key of this keybinding
@@ -57,20 +63,25 @@ func (a *App) InitializeKeybindings() []KeyBinding {
 	return []KeyBinding{
 		{
 			Key:         'j',
+			Action:      "select-next",
 			Description: "Move down",
 			Handler: func(app *App) {
 				app.tree.SelectNext()
 			},
+			Repeat: true,
 		},
 		{
 			Key:         'k',
+			Action:      "select-prev",
 			Description: "Move up",
 			Handler: func(app *App) {
 				app.tree.SelectPrev()
 			},
+			Repeat: true,
 		},
 		{
 			Key:         'h',
+			Action:      "collapse",
 			Description: "Collapse item",
 			Handler: func(app *App) {
 				app.tree.Collapse()
@@ -78,6 +89,7 @@ func (a *App) InitializeKeybindings() []KeyBinding {
 		},
 		{
 			Key:         'l',
+			Action:      "expand",
 			Description: "Expand item",
 			Handler: func(app *App) {
 				// If this is a search node, populate it with results first
@@ -99,6 +111,7 @@ func (a *App) InitializeKeybindings() []KeyBinding {
 		},
 		{
 			Key:         'R',
+			Action:      "refresh-search",
 			Description: "Refresh search node results",
 			Handler: func(app *App) {
 				// Refresh search node results if current item is a search node
@@ -120,36 +133,53 @@ func (a *App) InitializeKeybindings() []KeyBinding {
 				}
 			},
 		},
+		{
+			Key:         'Y',
+			Action:      "yank-to-clipboard",
+			Description: "Copy item text to the system clipboard",
+			Handler: func(app *App) {
+				app.handleYankCommand([]string{"yank"})
+			},
+		},
 		{
 			Key:         'J',
+			Action:      "move-down",
 			Description: "Move node down",
 			Handler: func(app *App) {
 				if app.readOnly {
 					app.SetStatus("File is readonly")
 					return
 				}
+				state := app.captureUndoState()
 				if app.tree.MoveItemDown() {
+					app.pushUndoState(state)
 					app.SetStatus("Moved item down")
-					app.dirty = true
+					app.markDirty()
 				}
 			},
+			Repeat: true,
 		},
 		{
 			Key:         'K',
+			Action:      "move-up",
 			Description: "Move node up",
 			Handler: func(app *App) {
 				if app.readOnly {
 					app.SetStatus("File is readonly")
 					return
 				}
+				state := app.captureUndoState()
 				if app.tree.MoveItemUp() {
+					app.pushUndoState(state)
 					app.SetStatus("Moved item up")
-					app.dirty = true
+					app.markDirty()
 				}
 			},
+			Repeat: true,
 		},
 		{
 			Key:         'i',
+			Action:      "edit-start",
 			Description: "Edit item (cursor at start)",
 			Handler: func(app *App) {
 				if app.readOnly {
@@ -167,6 +197,7 @@ func (a *App) InitializeKeybindings() []KeyBinding {
 		},
 		{
 			Key:         'c',
+			Action:      "change",
 			Description: "Change (replace) item text",
 			Handler: func(app *App) {
 				if app.readOnly {
@@ -184,6 +215,7 @@ func (a *App) InitializeKeybindings() []KeyBinding {
 		},
 		{
 			Key:         'A',
+			Action:      "append",
 			Description: "Append (edit at end of text)",
 			Handler: func(app *App) {
 				if app.readOnly {
@@ -200,15 +232,17 @@ func (a *App) InitializeKeybindings() []KeyBinding {
 		},
 		{
 			Key:         'O',
+			Action:      "insert-before",
 			Description: "Insert new item before",
 			Handler: func(app *App) {
 				if app.readOnly {
 					app.SetStatus("File is readonly")
 					return
 				}
+				app.saveUndoState()
 				app.tree.AddItemBefore("")
 				app.SetStatus("Created new item before")
-				app.dirty = true
+				app.markDirty()
 				// Enter insert mode for the new item
 				selected := app.tree.GetSelected()
 				if selected != nil {
@@ -220,12 +254,14 @@ func (a *App) InitializeKeybindings() []KeyBinding {
 		},
 		{
 			Key:         'o',
+			Action:      "insert-after",
 			Description: "Insert new item (as first child if parent has children, else as sibling)",
 			Handler: func(app *App) {
 				if app.readOnly {
 					app.SetStatus("File is readonly")
 					return
 				}
+				app.saveUndoState()
 				selected := app.tree.GetSelected()
 				item := model.NewItem("")
 
@@ -238,7 +274,7 @@ func (a *App) InitializeKeybindings() []KeyBinding {
 					app.tree.AddItemAfter(item)
 					app.SetStatus("Created new item after")
 				}
-				app.dirty = true
+				app.markDirty()
 				// Enter insert mode for the new item
 				newSelected := app.tree.GetSelected()
 				if newSelected != nil {
@@ -250,102 +286,101 @@ func (a *App) InitializeKeybindings() []KeyBinding {
 		},
 		{
 			Key:         'd',
+			Action:      "delete",
 			Description: "Delete item",
 			Handler: func(app *App) {
-				if app.readOnly {
-					app.SetStatus("File is readonly")
-					return
-				}
-				selected := app.tree.GetSelected()
-				app.clipboard = selected
-				if app.tree.DeleteSelected() {
-					app.SetStatus("Deleted item")
-					app.dirty = true
-				}
+				app.confirmDeleteSelected(1)
+			},
+			CountHandler: func(app *App, count int) {
+				app.confirmDeleteSelected(count)
 			},
 		},
 		{
 			Key:         'y',
+			Action:      "yank",
 			Description: "Yank (copy) item",
 			Handler: func(app *App) {
 				selected := app.tree.GetSelected()
 				if selected != nil {
-					yanked := model.NewItemFrom(selected)
-					app.clipboard = yanked
+					yanked := model.CloneItemTree(selected)
+					app.clipboard = []*model.Item{yanked}
 					app.SetStatus("Yanked item")
 				}
 			},
 		},
 		{
 			Key:         'p',
+			Action:      "paste-below",
 			Description: "Paste item below",
 			Handler: func(app *App) {
 				if app.readOnly {
 					app.SetStatus("File is readonly")
 					return
 				}
-				if app.clipboard != nil {
-					newItem := model.NewItemFrom(app.clipboard)
-					pastedItem := app.tree.PasteAfter(newItem)
-					if pastedItem != nil {
-						app.SetStatus("Pasted item")
-						app.dirty = true
-						app.refreshSearchNodes()
-						app.tree.SelectItemByID(pastedItem.ID)
-					}
-				}
+				app.pasteClipboard(false)
 			},
+			Repeat: true,
 		},
 		{
 			Key:         'P',
+			Action:      "paste-above",
 			Description: "Paste item above",
 			Handler: func(app *App) {
 				if app.readOnly {
 					app.SetStatus("File is readonly")
 					return
 				}
-				if app.clipboard != nil {
-					newItem := model.NewItemFrom(app.clipboard)
-					pastedItem := app.tree.PasteBefore(newItem)
-					if pastedItem != nil {
-						app.SetStatus("Pasted item")
-						app.dirty = true
-						app.refreshSearchNodes()
-						app.tree.SelectItemByID(pastedItem.ID)
-					}
-				}
+				app.pasteClipboard(true)
+			},
+			Repeat: true,
+		},
+		{
+			Key:         'D',
+			Action:      "duplicate",
+			Description: "Duplicate item (with children)",
+			Handler: func(app *App) {
+				app.handleDuplicateCommand()
+			},
+		},
+		{
+			Key:         'u',
+			Action:      "undo",
+			Description: "Undo last change",
+			Handler: func(app *App) {
+				app.undo()
 			},
+			Repeat: true,
 		},
 		{
 			Key:         '>',
+			Action:      "indent",
 			Description: "Indent item",
 			Handler: func(app *App) {
-				if app.readOnly {
-					app.SetStatus("File is readonly")
-					return
-				}
-				if app.tree.Indent() {
-					app.SetStatus("Indented")
-					app.dirty = true
-				}
+				app.indentSelected()
 			},
+			Repeat: true,
 		},
 		{
 			Key:         '<',
+			Action:      "outdent",
 			Description: "Outdent item",
 			Handler: func(app *App) {
-				if app.readOnly {
-					app.SetStatus("File is readonly")
-					return
-				}
-				if app.tree.Outdent() {
-					app.SetStatus("Outdented")
-					app.dirty = true
-				}
+				app.outdentSelected()
+			},
+			Repeat: true,
+		},
+		{
+			Key:         '.',
+			Action:      "repeat",
+			Description: "Repeat last change (indent when there is none)",
+			Handler: func(app *App) {
+				app.repeatLastAction()
 			},
+			Repeat: true,
 		},
 		{
 			Key:         'x',
+			Action:      "rotate-status",
 			Description: "Rotate todo status",
 			Handler: func(app *App) {
 				if app.readOnly {
@@ -356,75 +391,27 @@ func (a *App) InitializeKeybindings() []KeyBinding {
 				if selected == nil {
 					return
 				}
+				app.saveUndoState()
 
-				// Get todostatuses config
-				statusesStr := app.cfg.Get("todostatuses")
-				if statusesStr == "" {
-					statusesStr = "todo,doing,done" // Default
-				}
-				statuses := strings.Split(statusesStr, ",")
-
-				// Initialize metadata if needed
-				if selected.Metadata == nil {
-					selected.Metadata = &model.Metadata{
-						Attributes: nil,
-						Created:    time.Now(),
-						Modified:   time.Now(),
-					}
-				}
-				if selected.Metadata.Attributes == nil {
-					selected.Metadata.Attributes = make(map[string]string)
-				}
-
-				// Initialize type if not already set
-				if _, hasType := selected.Metadata.Attributes["type"]; !hasType {
-					selected.Metadata.Attributes["type"] = "todo"
-				}
-
-				// Get current status
-				currentStatus := selected.Metadata.Attributes["status"]
+				newStatus := app.rotateTodoStatus(selected)
 
-				// Find current index
-				currentIdx := -1
-				for i, s := range statuses {
-					if s == currentStatus {
-						currentIdx = i
-						break
-					}
-				}
-
-				// Rotate to next status
-				nextIdx := (currentIdx + 1) % len(statuses)
-				newStatus := statuses[nextIdx]
-				selected.Metadata.Attributes["status"] = newStatus
-				selected.Metadata.Modified = time.Now()
-
-				// Update parent status if parent is a todo
-				ui.UpdateParentStatusIfTodo(selected, statuses)
-
-				app.dirty = true
+				app.markDirty()
 				app.SetStatus(fmt.Sprintf("Status: %s", newStatus))
 
 				// Refresh search nodes since status change may affect search results
 				app.refreshSearchNodes()
 			},
+			Repeat: true,
 		},
 		{
 			Key:         '/',
+			Action:      "search",
 			Description: "Search",
 			Handler: func(app *App) {
 				wasSearching := app.search.IsActive()
+				app.searchMatches = nil
 				app.search.Start()
-				// When hoisted, search only within the hoisted subtree
-				searchItems := app.outline.GetAllItems()
-				if app.tree.IsHoisted() {
-					hoistedItem := app.tree.GetHoistedItem()
-					if hoistedItem != nil {
-						// Get all items within the hoisted subtree
-						searchItems = ui.GetAllItemsRecursive(hoistedItem)
-					}
-				}
-				app.search.SetAllItems(searchItems)
+				app.search.SetAllItems(app.searchItems())
 				// Only auto-navigate to first match if we just started a new search
 				// (not if we're clearing and restarting an existing search)
 				if !wasSearching && app.search.GetMatchCount() > 0 {
@@ -446,6 +433,7 @@ func (a *App) InitializeKeybindings() []KeyBinding {
 		},
 		{
 			Key:         '?',
+			Action:      "help",
 			Description: "Toggle help",
 			Handler: func(app *App) {
 				app.help.Toggle()
@@ -453,64 +441,25 @@ func (a *App) InitializeKeybindings() []KeyBinding {
 		},
 		{
 			Key:         'n',
+			Action:      "search-next",
 			Description: "Next search match",
 			Handler: func(app *App) {
-				if !app.search.HasResults() {
-					app.SetStatus("No active search")
-					return
-				}
-				if !app.search.NextMatch() {
-					app.SetStatus("No search results")
-					return
-				}
-				// Navigate to the current match
-				currentMatch := app.search.GetCurrentMatch()
-				if currentMatch != nil {
-					app.tree.ExpandParents(currentMatch)
-					items := app.tree.GetDisplayItems()
-					for idx, dispItem := range items {
-						if dispItem.Item.ID == currentMatch.ID {
-							app.tree.SelectItem(idx)
-							break
-						}
-					}
-					matchNum := app.search.GetCurrentMatchNumber()
-					totalMatches := app.search.GetMatchCount()
-					app.SetStatus(fmt.Sprintf("Match %d of %d", matchNum, totalMatches))
-				}
+				app.jumpToSearchMatch(1)
 			},
+			Repeat: true,
 		},
 		{
 			Key:         'N',
+			Action:      "search-prev",
 			Description: "Previous search match",
 			Handler: func(app *App) {
-				if !app.search.HasResults() {
-					app.SetStatus("No active search")
-					return
-				}
-				if !app.search.PrevMatch() {
-					app.SetStatus("No search results")
-					return
-				}
-				// Navigate to the current match
-				currentMatch := app.search.GetCurrentMatch()
-				if currentMatch != nil {
-					app.tree.ExpandParents(currentMatch)
-					items := app.tree.GetDisplayItems()
-					for idx, dispItem := range items {
-						if dispItem.Item.ID == currentMatch.ID {
-							app.tree.SelectItem(idx)
-							break
-						}
-					}
-					matchNum := app.search.GetCurrentMatchNumber()
-					totalMatches := app.search.GetMatchCount()
-					app.SetStatus(fmt.Sprintf("Match %d of %d", matchNum, totalMatches))
-				}
+				app.jumpToSearchMatch(-1)
 			},
+			Repeat: true,
 		},
 		{
 			Key:         ':',
+			Action:      "command",
 			Description: "Command mode",
 			Handler: func(app *App) {
 				app.command.Start()
@@ -518,6 +467,7 @@ func (a *App) InitializeKeybindings() []KeyBinding {
 		},
 		{
 			Key:         '@',
+			Action:      "edit-attributes",
 			Description: "Edit attributes",
 			Handler: func(app *App) {
 				selected := app.tree.GetSelected()
@@ -531,6 +481,7 @@ func (a *App) InitializeKeybindings() []KeyBinding {
 		},
 		{
 			Key:         'V',
+			Action:      "visual",
 			Description: "Visual mode (line-wise selection)",
 			Handler: func(app *App) {
 				if app.mode == NormalMode {
@@ -542,13 +493,21 @@ func (a *App) InitializeKeybindings() []KeyBinding {
 		},
 		{
 			Key:         'G',
-			Description: "Go to last node",
+			Action:      "go-last",
+			Description: "Go to last node (with a count, go to that node)",
 			Handler: func(app *App) {
 				app.tree.SelectLast()
 			},
+			CountHandler: func(app *App, count int) {
+				app.tree.SelectLast()
+				if count-1 < app.tree.GetSelectedIndex() {
+					app.tree.SelectItem(count - 1)
+				}
+			},
 		},
 		{
 			Key:         '-',
+			Action:      "select-parent",
 			Description: "Select parent (or hoist to parent if at hoisted root)",
 			Handler: func(app *App) {
 				// First try normal parent selection
@@ -559,6 +518,7 @@ func (a *App) InitializeKeybindings() []KeyBinding {
 				// If that failed and we're at root of hoisted view, hoist to parent
 				if app.tree.IsAtRootOfHoistedView() {
 					if app.tree.HoistToParent() {
+						app.searchScopeChanged()
 						hoistedItem := app.tree.GetHoistedItem()
 						if hoistedItem != nil {
 							app.SetStatus(fmt.Sprintf("Hoisted to parent: %s", hoistedItem.Text))
@@ -575,11 +535,20 @@ func (a *App) InitializeKeybindings() []KeyBinding {
 		},
 		{
 			Key:         'e',
+			Action:      "external-edit",
 			Description: "Edit item in external editor",
 			Handler: func(app *App) {
 				app.handleExternalEdit()
 			},
 		},
+		{
+			Key:         'E',
+			Action:      "external-edit-tree",
+			Description: "Edit subtree in external editor",
+			Handler: func(app *App) {
+				app.handleExternalTreeEdit()
+			},
+		},
 	}
 }
 
@@ -592,6 +561,7 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 			Sequences: map[rune]KeyBinding{
 				'g': {
 					Key:         'g',
+					Action:      "go-first",
 					Description: "Go to first node",
 					Handler: func(app *App) {
 						app.tree.SelectFirst()
@@ -599,6 +569,7 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 				},
 				'o': {
 					Key:         'o',
+					Action:      "open-url",
 					Description: "Open URL from 'url' attribute (xdg-open)",
 					Handler: func(app *App) {
 						app.handleGoCommand()
@@ -606,6 +577,7 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 				},
 				'r': {
 					Key:         'r',
+					Action:      "go-original",
 					Description: "Go to referenced (original) item",
 					Handler: func(app *App) {
 						app.handleGoReferencedCommand()
@@ -613,6 +585,7 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 				},
 				'c': {
 					Key:         'c',
+					Action:      "calendar",
 					Description: "Go to calendar date picker",
 					Handler: func(app *App) {
 						app.calendarWidget.Show()
@@ -621,6 +594,7 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 				},
 				'p': {
 					Key:         'p',
+					Action:      "paste-child",
 					Description: "Paste as child of selected item",
 					Handler: func(app *App) {
 						app.handlePasteAsChildCommand()
@@ -628,6 +602,7 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 				},
 				'f': {
 					Key:         'f',
+					Action:      "follow-link",
 					Description: "Follow link under cursor",
 					Handler: func(app *App) {
 						app.handleFollowLinkCommand()
@@ -635,13 +610,39 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 				},
 				'd': {
 					Key:         'd',
+					Action:      "diff-backup",
 					Description: "Show diff with backup",
 					Handler: func(app *App) {
 						app.handleDiffCommand([]string{"diff"})
 					},
 				},
+				'i': {
+					Key:         'i',
+					Action:      "goto-id",
+					Description: "Go to item by ID (:goto)",
+					Handler: func(app *App) {
+						app.command.StartWithInput("goto ")
+					},
+				},
+				'J': {
+					Key:         'J',
+					Action:      "join",
+					Description: "Join with next sibling",
+					Handler: func(app *App) {
+						app.handleJoinCommand()
+					},
+				},
+				'P': {
+					Key:         'P',
+					Action:      "promote",
+					Description: "Promote above parent (swap with parent)",
+					Handler: func(app *App) {
+						app.handlePromoteCommand()
+					},
+				},
 				'b': {
 					Key:         'b',
+					Action:      "backlinks",
 					Description: "Show backlinks (items linking to this item)",
 					Handler: func(app *App) {
 						selected := app.tree.GetSelected()
@@ -683,9 +684,11 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 			Sequences: map[rune]KeyBinding{
 				'h': {
 					Key:         'h',
+					Action:      "hoist",
 					Description: "Hoist (focus on subtree)",
 					Handler: func(app *App) {
 						if app.tree.Hoist() {
+							app.searchScopeChanged()
 							app.SetStatus("Hoisted - showing only this subtree (zu to unhoist)")
 						} else {
 							app.SetStatus("Cannot hoist: item has no children")
@@ -694,9 +697,11 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 				},
 				'u': {
 					Key:         'u',
+					Action:      "unhoist",
 					Description: "Unhoist (return to full view)",
 					Handler: func(app *App) {
 						if app.tree.Unhoist() {
+							app.searchScopeChanged()
 							app.SetStatus("Unhoisted - showing full tree")
 						} else {
 							app.SetStatus("Not currently hoisted")
@@ -705,48 +710,131 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 				},
 				'C': {
 					Key:         'C',
+					Action:      "close-all",
 					Description: "Close all (collapse recursively)",
 					Handler: func(app *App) {
 						app.tree.CollapseRecursive()
 						app.SetStatus("Closed all")
-						app.dirty = true
+						app.markDirty()
 					},
 				},
 				'O': {
 					Key:         'O',
+					Action:      "open-all",
 					Description: "Open all (expand recursively)",
 					Handler: func(app *App) {
 						app.tree.ExpandRecursive()
 						app.SetStatus("Opened all")
-						app.dirty = true
+						app.markDirty()
+					},
+				},
+				'F': {
+					Key:         'F',
+					Action:      "close-subtree",
+					Description: "Close the subtree of the selected item",
+					Handler: func(app *App) {
+						if selected := app.tree.GetSelected(); selected != nil {
+							app.tree.CollapseSubtree(selected)
+							app.SetStatus("Closed subtree")
+							app.markDirty()
+						}
+					},
+				},
+				'E': {
+					Key:         'E',
+					Action:      "open-subtree",
+					Description: "Open the subtree of the selected item",
+					Handler: func(app *App) {
+						if selected := app.tree.GetSelected(); selected != nil {
+							app.tree.ExpandSubtree(selected, false)
+							app.SetStatus("Opened subtree")
+							app.markDirty()
+						}
 					},
 				},
 				'c': {
 					Key:         'c',
+					Action:      "close-children",
 					Description: "Close all children",
 					Handler: func(app *App) {
 						app.tree.CollapseAllChildren()
 						app.SetStatus("Closed all children")
-						app.dirty = true
+						app.markDirty()
 					},
 				},
 				's': {
 					Key:         's',
+					Action:      "close-siblings",
 					Description: "Close all siblings",
 					Handler: func(app *App) {
 						app.tree.CollapseSiblings()
 						app.SetStatus("Closed all siblings")
-						app.dirty = true
+						app.markDirty()
 					},
 				},
+				'z': {
+					Key:         'z',
+					Action:      "scroll-center",
+					Description: "Scroll selection to the center",
+					Handler: func(app *App) {
+						app.tree.CenterSelection(app.treeViewportHeight())
+					},
+				},
+				't': {
+					Key:         't',
+					Action:      "scroll-top",
+					Description: "Scroll selection to the top",
+					Handler: func(app *App) {
+						app.tree.ScrollSelectionToTop(app.treeViewportHeight())
+					},
+				},
+				'b': {
+					Key:         'b',
+					Action:      "scroll-bottom",
+					Description: "Scroll selection to the bottom",
+					Handler: func(app *App) {
+						app.tree.ScrollSelectionToBottom(app.treeViewportHeight())
+					},
+				},
+				'1': foldLevelKeybinding('1', 1),
+				'2': foldLevelKeybinding('2', 2),
+				'3': foldLevelKeybinding('3', 3),
+				'4': foldLevelKeybinding('4', 4),
+				'5': foldLevelKeybinding('5', 5),
+				'6': foldLevelKeybinding('6', 6),
+				'7': foldLevelKeybinding('7', 7),
+				'8': foldLevelKeybinding('8', 8),
+				'9': foldLevelKeybinding('9', 9),
 			},
 		},
+		{
+			Prefix:      'm',
+			Description: "Set mark (m + letter)",
+			Sequences: markKeybindings("Set mark %c on the selected item", func(app *App, mark rune) {
+				app.setMark(mark)
+			}),
+		},
+		{
+			Prefix:      '\'',
+			Description: "Jump to mark (' + letter)",
+			Sequences: markKeybindings("Jump to mark %c", func(app *App, mark rune) {
+				app.jumpToMark(mark, false)
+			}),
+		},
+		{
+			Prefix:      '`',
+			Description: "Jump to mark at its screen position (` + letter)",
+			Sequences: markKeybindings("Jump to mark %c at its screen position", func(app *App, mark rune) {
+				app.jumpToMark(mark, true)
+			}),
+		},
 		{
 			Prefix:      '[',
 			Description: "Previous... ([ + key)",
 			Sequences: map[rune]KeyBinding{
 				'[': {
 					Key:         '[',
+					Action:      "prev-sibling",
 					Description: "Go to previous sibling",
 					Handler: func(app *App) {
 						if !app.tree.SelectPrevSibling() {
@@ -754,8 +842,25 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 						}
 					},
 				},
+				'M': {
+					Key:         'M',
+					Action:      "move-first",
+					Description: "Move item to the top of its siblings",
+					Handler: func(app *App) {
+						app.moveWithinSiblings(true)
+					},
+				},
+				'K': {
+					Key:         'K',
+					Action:      "swap-prev",
+					Description: "Swap item with its previous sibling",
+					Handler: func(app *App) {
+						app.swapWithSibling(true)
+					},
+				},
 				'd': {
 					Key:         'd',
+					Action:      "prev-date",
 					Description: "Go to previous item with date",
 					Handler: func(app *App) {
 						if !app.tree.FindPrevDateItem() {
@@ -765,8 +870,17 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 						}
 					},
 				},
+				'D': {
+					Key:         'D',
+					Action:      "prev-daily-note",
+					Description: "Go to the previous day's daily note",
+					Handler: func(app *App) {
+						app.stepDailyNote(-1)
+					},
+				},
 				'w': {
 					Key:         'w',
+					Action:      "prev-week",
 					Description: "Go to previous item this week",
 					Handler: func(app *App) {
 						if !app.tree.FindPrevItemWithDateInterval("week") {
@@ -778,6 +892,7 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 				},
 				'm': {
 					Key:         'm',
+					Action:      "prev-month",
 					Description: "Go to previous item this month",
 					Handler: func(app *App) {
 						if !app.tree.FindPrevItemWithDateInterval("month") {
@@ -789,6 +904,7 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 				},
 				'y': {
 					Key:         'y',
+					Action:      "prev-year",
 					Description: "Go to previous item this year",
 					Handler: func(app *App) {
 						if !app.tree.FindPrevItemWithDateInterval("year") {
@@ -800,6 +916,7 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 				},
 				'b': {
 					Key:         'b',
+					Action:      "prev-backup",
 					Description: "Go to previous backup (same session)",
 					Handler: func(app *App) {
 						app.handlePreviousBackupSameSession()
@@ -807,6 +924,7 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 				},
 				'B': {
 					Key:         'B',
+					Action:      "prev-backup-any",
 					Description: "Go to previous backup (any session)",
 					Handler: func(app *App) {
 						app.handlePreviousBackupAnySession()
@@ -820,6 +938,7 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 			Sequences: map[rune]KeyBinding{
 				']': {
 					Key:         ']',
+					Action:      "next-sibling",
 					Description: "Go to next sibling",
 					Handler: func(app *App) {
 						if !app.tree.SelectNextSibling() {
@@ -827,8 +946,25 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 						}
 					},
 				},
+				'M': {
+					Key:         'M',
+					Action:      "move-last",
+					Description: "Move item to the bottom of its siblings",
+					Handler: func(app *App) {
+						app.moveWithinSiblings(false)
+					},
+				},
+				'J': {
+					Key:         'J',
+					Action:      "swap-next",
+					Description: "Swap item with its next sibling",
+					Handler: func(app *App) {
+						app.swapWithSibling(false)
+					},
+				},
 				'd': {
 					Key:         'd',
+					Action:      "next-date",
 					Description: "Go to next item with date",
 					Handler: func(app *App) {
 						if !app.tree.FindNextDateItem() {
@@ -838,8 +974,17 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 						}
 					},
 				},
+				'D': {
+					Key:         'D',
+					Action:      "next-daily-note",
+					Description: "Go to the next day's daily note",
+					Handler: func(app *App) {
+						app.stepDailyNote(1)
+					},
+				},
 				'w': {
 					Key:         'w',
+					Action:      "next-week",
 					Description: "Go to next item this week",
 					Handler: func(app *App) {
 						if !app.tree.FindNextItemWithDateInterval("week") {
@@ -851,6 +996,7 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 				},
 				'm': {
 					Key:         'm',
+					Action:      "next-month",
 					Description: "Go to next item this month",
 					Handler: func(app *App) {
 						if !app.tree.FindNextItemWithDateInterval("month") {
@@ -862,6 +1008,7 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 				},
 				'y': {
 					Key:         'y',
+					Action:      "next-year",
 					Description: "Go to next item this year",
 					Handler: func(app *App) {
 						if !app.tree.FindNextItemWithDateInterval("year") {
@@ -873,6 +1020,7 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 				},
 				'b': {
 					Key:         'b',
+					Action:      "next-backup",
 					Description: "Go to next backup (same session)",
 					Handler: func(app *App) {
 						app.handleNextBackupSameSession()
@@ -880,6 +1028,7 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 				},
 				'B': {
 					Key:         'B',
+					Action:      "next-backup-any",
 					Description: "Go to next backup (any session)",
 					Handler: func(app *App) {
 						app.handleNextBackupAnySession()
@@ -893,6 +1042,7 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 			Sequences: map[rune]KeyBinding{
 				'a': {
 					Key:         'a',
+					Action:      "attr-add",
 					Description: "Add attribute (prompt for key and value)",
 					Handler: func(app *App) {
 						if app.readOnly {
@@ -910,6 +1060,7 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 				},
 				'd': {
 					Key:         'd',
+					Action:      "attr-delete",
 					Description: "Delete attribute (prompt for key)",
 					Handler: func(app *App) {
 						app.SetStatus("Use :attr del <key> to delete attributes")
@@ -917,6 +1068,7 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 				},
 				'c': {
 					Key:         'c',
+					Action:      "attr-change",
 					Description: "Change/edit attribute value (prompt for key)",
 					Handler: func(app *App) {
 						app.SetStatus("Use :attr add <key> <value> to change attributes")
@@ -924,6 +1076,7 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 				},
 				'v': {
 					Key:         'v',
+					Action:      "attr-view",
 					Description: "View all attributes for this item",
 					Handler: func(app *App) {
 						selected := app.tree.GetSelected()
@@ -946,6 +1099,7 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 			Sequences: map[rune]KeyBinding{
 				's': {
 					Key:         's',
+					Action:      "send",
 					Description: "Send item to selected node (search)",
 					Handler: func(app *App) {
 						app.handleSendToNode()
@@ -953,6 +1107,7 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 				},
 				'.': {
 					Key:         '.',
+					Action:      "send-last",
 					Description: "Send item to last destination",
 					Handler: func(app *App) {
 						app.handleSendToLastNode()
@@ -960,6 +1115,7 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 				},
 				'c': {
 					Key:         'c',
+					Action:      "copy-from-search",
 					Description: "Copy item from search (search and copy to me)",
 					Handler: func(app *App) {
 						app.handleSearchAndCopy()
@@ -967,6 +1123,7 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 				},
 				't': {
 					Key:         't',
+					Action:      "copy-template",
 					Description: "Copy template from search (instantiate template)",
 					Handler: func(app *App) {
 						app.handleSearchTemplate()
@@ -977,6 +1134,45 @@ func (a *App) InitializePendingKeybindings() []PendingKeyBinding {
 	}
 }
 
+// foldLevelKeybinding creates a z-sequence binding that folds the tree to a level
+func foldLevelKeybinding(key rune, level int) KeyBinding {
+	return KeyBinding{
+		Key:         key,
+		Action:      fmt.Sprintf("fold-%d", level),
+		Description: fmt.Sprintf("Fold to level %d", level),
+		Handler: func(app *App) {
+			app.tree.CollapseToLevel(level)
+			app.SetStatus(fmt.Sprintf("Folded to level %d", level))
+			app.markDirty()
+		},
+	}
+}
+
+// maxCount limits the count prefix of normal mode keys
+const maxCount = 9999
+
+// repeatCount creates a count handler that runs handler count times. The
+// changes of the repeated handler are undone in one step.
+func repeatCount(handler func(*App)) func(*App, int) {
+	return func(app *App, count int) {
+		var first *undoState
+		for i := range count {
+			top := app.topUndoState()
+			handler(app)
+			if i == 0 && app.topUndoState() != top {
+				first = app.topUndoState()
+			}
+		}
+		if first == nil {
+			return
+		}
+		// Keep only the state from before the first change
+		if idx := slices.Index(app.undoStack, first); idx >= 0 {
+			app.undoStack = app.undoStack[:idx+1]
+		}
+	}
+}
+
 // InitializeVisualKeybindings sets up all the key bindings for visual mode
 func (a *App) InitializeVisualKeybindings() []KeyBinding {
 	return []KeyBinding{
@@ -1059,6 +1255,13 @@ func (a *App) InitializeVisualKeybindings() []KeyBinding {
 				app.tree.SelectLast()
 			},
 		},
+		{
+			Key:         ':',
+			Description: "Command mode (:attr applies to the selection)",
+			Handler: func(app *App) {
+				app.command.Start()
+			},
+		},
 	}
 }
 
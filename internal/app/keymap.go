@@ -0,0 +1,178 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pstuifzand/tui-outliner/internal/config"
+	"github.com/pstuifzand/tui-outliner/internal/ui"
+)
+
+// keyActions returns the default normal mode keybindings by action name,
+// including the key sequences of the pending keys. Actions stay available when
+// their keys are mapped to something else.
+func (a *App) keyActions() map[string]KeyBinding {
+	actions := make(map[string]KeyBinding)
+	for _, kb := range a.InitializeKeybindings() {
+		if kb.Action != "" {
+			actions[kb.Action] = kb
+		}
+	}
+	for _, pending := range a.InitializePendingKeybindings() {
+		for _, kb := range pending.Sequences {
+			if kb.Action != "" {
+				actions[kb.Action] = kb
+			}
+		}
+	}
+	return actions
+}
+
+// mapKey binds key to the named action in normal mode. key is a single key,
+// or a pending key prefix and a key like "gh". "<space>" stands for the space
+// bar. The key loses its previous binding, other keys of the action keep it.
+func (a *App) mapKey(key, action string) error {
+	binding, ok := a.keyActions()[action]
+	if !ok {
+		return fmt.Errorf("unknown action '%s'", action)
+	}
+
+	runes := []rune(strings.ReplaceAll(key, "<space>", " "))
+	switch len(runes) {
+	case 1:
+		r := runes[0]
+		if r >= '1' && r <= '9' {
+			return fmt.Errorf("'%c' is used for counts", r)
+		}
+		if a.IsPendingKeyPrefix(r) {
+			return fmt.Errorf("'%c' is a prefix key, map a sequence like '%c%c'", r, r, r)
+		}
+		binding.Key = r
+		a.keybindings = slices.DeleteFunc(a.keybindings, func(kb KeyBinding) bool {
+			return kb.Key == r
+		})
+		a.keybindings = append(a.keybindings, binding)
+	case 2:
+		pending := a.GetPendingKeyBindingByPrefix(runes[0])
+		if pending == nil {
+			return fmt.Errorf("'%c' is not a prefix key", runes[0])
+		}
+		binding.Key = runes[1]
+		pending.Sequences[runes[1]] = binding
+	default:
+		return fmt.Errorf("invalid key '%s', use one key or a prefix key and a key", key)
+	}
+
+	a.updateHelpKeybindings()
+	return nil
+}
+
+// keyName returns the name of a key as used by :map
+func keyName(r rune) string {
+	if r == ' ' {
+		return "<space>"
+	}
+	return string(r)
+}
+
+// applyKeyMappings binds the keys of the keys file in the config directory.
+// Lines and mappings that can't be applied are logged and reported in the
+// status line, the other mappings still apply.
+func (a *App) applyKeyMappings() {
+	mappings, warnings, err := config.LoadKeyMappings()
+	if err != nil {
+		log.Printf("Warning: Failed to load key mappings: %v\n", err)
+		a.SetStatus(fmt.Sprintf("Failed to load key mappings: %v", err))
+		return
+	}
+
+	for _, warning := range warnings {
+		log.Printf("Warning: keys %v\n", warning)
+		a.SetStatus(fmt.Sprintf("Key mapping skipped on %v", warning))
+	}
+
+	for _, mapping := range mappings {
+		if err := a.mapKey(mapping.Key, mapping.Action); err != nil {
+			log.Printf("Warning: keys line %d: %v\n", mapping.Line, err)
+			a.SetStatus(fmt.Sprintf("Key mapping on line %d: %v", mapping.Line, err))
+		}
+	}
+}
+
+// updateHelpKeybindings passes the current keybindings to the help screen
+func (a *App) updateHelpKeybindings() {
+	if a.help == nil {
+		return
+	}
+
+	var helpKeybindings []ui.KeyBindingInfo
+	for i := range a.keybindings {
+		helpKeybindings = append(helpKeybindings, &a.keybindings[i])
+	}
+	// Add pending keybindings to help
+	for i := range a.pendingKeybindings {
+		helpKeybindings = append(helpKeybindings, &a.pendingKeybindings[i])
+	}
+	a.help.SetKeybindings(helpKeybindings)
+}
+
+// handleMapCommand binds a key to an action, or lists the actions
+// Usage: :map [<key> <action>]
+func (a *App) handleMapCommand(parts []string) {
+	switch len(parts) {
+	case 1:
+		a.showKeyActions()
+	case 3:
+		if err := a.mapKey(parts[1], parts[2]); err != nil {
+			a.SetStatus(fmt.Sprintf("Cannot map %s: %v", parts[1], err))
+			return
+		}
+		a.SetStatus(fmt.Sprintf("Mapped %s to %s", parts[1], parts[2]))
+	default:
+		a.SetStatus("Usage: :map <key> <action>")
+	}
+}
+
+// showKeyActions lists the actions and their keys in the messages view
+func (a *App) showKeyActions() {
+	keys := make(map[string][]string)
+	for action := range a.keyActions() {
+		keys[action] = nil
+	}
+	for _, kb := range a.keybindings {
+		if kb.Action != "" {
+			keys[kb.Action] = append(keys[kb.Action], keyName(kb.Key))
+		}
+	}
+	for _, pending := range a.pendingKeybindings {
+		for key, kb := range pending.Sequences {
+			if kb.Action != "" {
+				keys[kb.Action] = append(keys[kb.Action], keyName(pending.Prefix)+keyName(key))
+			}
+		}
+	}
+
+	actions := make([]string, 0, len(keys))
+	for action := range keys {
+		actions = append(actions, action)
+	}
+	slices.Sort(actions)
+
+	now := time.Now()
+	var messages []*ui.Message
+	for _, action := range actions {
+		slices.Sort(keys[action])
+		messages = append(messages, &ui.Message{
+			Text:      fmt.Sprintf("%-20s %s", action, strings.Join(keys[action], " ")),
+			Timestamp: now,
+		})
+	}
+
+	a.messagesViewActive = true
+	a.messagesViewMessages = messages
+	a.messagesViewItems = nil
+	a.messagesViewScroll = 0
+}
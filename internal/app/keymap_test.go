@@ -0,0 +1,69 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/pstuifzand/tui-outliner/internal/model"
+	"github.com/pstuifzand/tui-outliner/internal/ui"
+)
+
+func TestMapCommand(t *testing.T) {
+	a := model.NewItem("A")
+	b := model.NewItem("B")
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{a, b}
+	app := &App{
+		outline: outline,
+		tree:    ui.NewTreeView(outline.Items),
+	}
+	app.keybindings = app.InitializeKeybindings()
+	app.pendingKeybindings = app.InitializePendingKeybindings()
+
+	press := func(keys string) {
+		for _, r := range keys {
+			app.handleKeypress(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+		}
+	}
+
+	// Swap j and k
+	app.handleMapCommand([]string{"map", "j", "select-prev"})
+	app.handleMapCommand([]string{"map", "k", "select-next"})
+	if app.statusMsg != "Mapped k to select-next" {
+		t.Errorf("Unexpected status: %q", app.statusMsg)
+	}
+	press("k")
+	if app.tree.GetSelected() != b {
+		t.Error("Expected k to select the next item")
+	}
+	press("j")
+	if app.tree.GetSelected() != a {
+		t.Error("Expected j to select the previous item")
+	}
+
+	// Sequences of a prefix key
+	app.handleMapCommand([]string{"map", "gj", "select-next"})
+	press("gj")
+	if app.tree.GetSelected() != b {
+		t.Error("Expected gj to select the next item")
+	}
+
+	for _, args := range [][]string{
+		{"map", "H", "no-such-action"},
+		{"map", "g", "hoist"},
+		{"map", "5", "hoist"},
+		{"map", "qq", "hoist"},
+	} {
+		app.statusMsg = ""
+		app.handleMapCommand(args)
+		if !strings.HasPrefix(app.statusMsg, "Cannot map") {
+			t.Errorf("Expected %v to fail, got %q", args, app.statusMsg)
+		}
+	}
+
+	app.handleMapCommand([]string{"map"})
+	if !app.messagesViewActive || len(app.messagesViewMessages) == 0 {
+		t.Error("Expected the actions to be listed")
+	}
+}
@@ -0,0 +1,178 @@
+package app
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+	"github.com/pstuifzand/tui-outliner/internal/ui"
+)
+
+// marksFilename returns the history file that stores the marks of the current file
+func (a *App) marksFilename() string {
+	sum := sha1.Sum([]byte(a.originalFilePath))
+	return "marks_" + hex.EncodeToString(sum[:8]) + ".toml"
+}
+
+// loadMarks restores the marks of the current file, dropping marks whose item no longer exists
+func (a *App) loadMarks() {
+	a.marks = make(map[rune]string)
+	a.markRows = make(map[rune]int)
+	if a.historyManager == nil || a.originalFilePath == "" {
+		return
+	}
+
+	entries, err := a.historyManager.Load(a.marksFilename())
+	if err != nil {
+		log.Printf("Warning: Failed to load marks: %v\n", err)
+		return
+	}
+
+	// Entries have the form "<mark> <item id> <screen row>"
+	for _, entry := range entries {
+		fields := strings.Fields(entry)
+		if len(fields) != 3 || len([]rune(fields[0])) != 1 {
+			continue
+		}
+		mark := []rune(fields[0])[0]
+		if !isMarkName(mark) || a.outline.FindItemByID(fields[1]) == nil {
+			continue
+		}
+		row, _ := strconv.Atoi(fields[2])
+		a.marks[mark] = fields[1]
+		a.markRows[mark] = row
+	}
+}
+
+// saveMarks persists the marks of the current file
+func (a *App) saveMarks() {
+	if a.historyManager == nil || a.originalFilePath == "" {
+		return
+	}
+
+	var entries []string
+	for _, mark := range a.markNames() {
+		entries = append(entries, fmt.Sprintf("%c %s %d", mark, a.marks[mark], a.markRows[mark]))
+	}
+	if err := a.historyManager.Save(a.marksFilename(), entries); err != nil {
+		log.Printf("Warning: Failed to save marks: %v\n", err)
+	}
+}
+
+// setMark stores the selected item and its screen row under mark
+func (a *App) setMark(mark rune) {
+	selected := a.tree.GetSelected()
+	if selected == nil {
+		a.SetStatus("No item selected")
+		return
+	}
+	if a.marks == nil {
+		a.marks = make(map[rune]string)
+		a.markRows = make(map[rune]int)
+	}
+
+	a.marks[mark] = selected.ID
+	a.markRows[mark] = max(a.tree.SelectedRow(), 0)
+	a.saveMarks()
+	a.SetStatus(fmt.Sprintf("Mark '%c' set", mark))
+}
+
+// jumpToMark selects the item of a mark. With exact, the item is also
+// scrolled back to the screen row it had when the mark was set.
+func (a *App) jumpToMark(mark rune, exact bool) {
+	id, ok := a.marks[mark]
+	if !ok {
+		a.SetStatus(fmt.Sprintf("Mark '%c' not set", mark))
+		return
+	}
+
+	// Sync outline with tree so moved items are found
+	a.outline.Items = a.tree.GetItems()
+	a.outline.BuildIndex()
+
+	target := a.outline.FindItemByID(id)
+	if target == nil {
+		delete(a.marks, mark)
+		delete(a.markRows, mark)
+		a.saveMarks()
+		a.SetStatus(fmt.Sprintf("Mark '%c' points to a deleted item", mark))
+		return
+	}
+
+	if !a.jumpToItem(target) {
+		return
+	}
+	if exact {
+		a.tree.ScrollSelectionToRow(a.treeViewportHeight(), a.markRows[mark])
+	}
+	a.SetStatus(fmt.Sprintf("Jumped to mark '%c': %s", mark, target.Text))
+}
+
+// handleMarksCommand lists the marks in the messages view
+func (a *App) handleMarksCommand() {
+	names := a.markNames()
+	if len(names) == 0 {
+		a.SetStatus("No marks set")
+		return
+	}
+
+	now := time.Now()
+	var messages []*ui.Message
+	var items []*model.Item
+	for _, mark := range names {
+		item := a.outline.FindItemByID(a.marks[mark])
+		if item == nil {
+			continue
+		}
+		messages = append(messages, &ui.Message{
+			Text:      fmt.Sprintf("%c  %s", mark, item.Text),
+			Timestamp: now,
+		})
+		items = append(items, item)
+	}
+	if len(messages) == 0 {
+		a.SetStatus("No marks set")
+		return
+	}
+
+	a.messagesViewActive = true
+	a.messagesViewMessages = messages
+	a.messagesViewItems = items
+	a.messagesViewScroll = 0
+}
+
+// markNames returns the names of the set marks in sorted order
+func (a *App) markNames() []rune {
+	names := make([]rune, 0, len(a.marks))
+	for mark := range a.marks {
+		names = append(names, mark)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// isMarkName reports whether r can be used as a mark name
+func isMarkName(r rune) bool {
+	return r >= 'a' && r <= 'z'
+}
+
+// markKeybindings creates a sequence binding for each mark name
+func markKeybindings(description string, handler func(app *App, mark rune)) map[rune]KeyBinding {
+	bindings := make(map[rune]KeyBinding)
+	for mark := 'a'; mark <= 'z'; mark++ {
+		bindings[mark] = KeyBinding{
+			Key:         mark,
+			Description: fmt.Sprintf(description, mark),
+			Handler: func(app *App) {
+				handler(app, mark)
+			},
+		}
+	}
+	return bindings
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/pstuifzand/tui-outliner/internal/history"
+	"github.com/pstuifzand/tui-outliner/internal/model"
+	"github.com/pstuifzand/tui-outliner/internal/ui"
+)
+
+// createMarksTestApp creates an app for the given items with persisted marks
+func createMarksTestApp(t *testing.T, items []*model.Item) *App {
+	manager, err := history.NewManager()
+	if err != nil {
+		t.Fatalf("Failed to create history manager: %v", err)
+	}
+
+	outline := model.NewOutline()
+	outline.Items = items
+	outline.BuildIndex()
+	app := &App{
+		outline:          outline,
+		tree:             ui.NewTreeView(outline.Items),
+		historyManager:   manager,
+		originalFilePath: "/tmp/marks-test.json",
+	}
+	app.loadMarks()
+	return app
+}
+
+func TestMarksSetJumpAndPersist(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	parent := model.NewItem("Parent")
+	child := model.NewItem("Child")
+	parent.AddChild(child)
+	other := model.NewItem("Other")
+	parent.Expanded = true
+
+	app := createMarksTestApp(t, []*model.Item{parent, other})
+
+	app.tree.SelectItemByID(child.ID)
+	app.setMark('a')
+	app.tree.SelectItemByID(other.ID)
+	app.setMark('b')
+
+	// Jumping expands collapsed parents
+	parent.Expanded = false
+	app.tree.RebuildView()
+	app.jumpToMark('a', false)
+	if selected := app.tree.GetSelected(); selected == nil || selected.ID != child.ID {
+		t.Fatalf("Expected jump to Child, got %v", selected)
+	}
+
+	app.jumpToMark('c', false)
+	if app.statusMsg != "Mark 'c' not set" {
+		t.Errorf("Expected unset mark status, got %q", app.statusMsg)
+	}
+
+	// Marks survive a restart, marks of deleted items are dropped
+	reloaded := createMarksTestApp(t, []*model.Item{parent})
+	if reloaded.marks['a'] != child.ID {
+		t.Errorf("Expected mark a to be restored, got %q", reloaded.marks['a'])
+	}
+	if _, ok := reloaded.marks['b']; ok {
+		t.Error("Expected mark b of the deleted item to be dropped")
+	}
+
+	reloaded.handleMarksCommand()
+	if !reloaded.messagesViewActive || len(reloaded.messagesViewItems) != 1 || reloaded.messagesViewItems[0] != child {
+		t.Errorf("Expected :marks to list mark a, got %v", reloaded.messagesViewMessages)
+	}
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pstuifzand/tui-outliner/internal/config"
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+// checkOutline validates a loaded outline, and repairs it when the autorepair
+// setting is true. It returns the problems and whether they were repaired.
+// Loading already drops empty entries and sets the parent pointers, so only
+// :fix outline on an edited tree can find those.
+func checkOutline(outline *model.Outline, cfg *config.Config) ([]model.Problem, bool) {
+	if cfg != nil && cfg.Get("autorepair") == "true" {
+		return outline.Repair(), true
+	}
+	return outline.Validate(), false
+}
+
+// reportOutlineProblems logs the problems of a loaded outline and reports them
+// in the status line. The problems are also added to the messages, so :messages
+// shows which items they are about. A repaired outline needs to be saved.
+func (a *App) reportOutlineProblems(problems []model.Problem, repaired bool) {
+	if len(problems) == 0 {
+		return
+	}
+
+	for _, problem := range problems {
+		log.Printf("Outline problem: %s\n", problem)
+		if a.messageLogger != nil {
+			a.messageLogger.AddMessage("Outline problem: " + problem.String())
+		}
+	}
+
+	if repaired {
+		if !a.readOnly {
+			a.markDirty()
+		}
+		a.SetStatus(fmt.Sprintf("Repaired %d problems in the outline, see :messages", len(problems)))
+		return
+	}
+	a.SetStatus(fmt.Sprintf("Found %d problems in the outline, use :fix outline to repair", len(problems)))
+}
+
+// fixOutline repairs the structure of the outline with undo
+func (a *App) fixOutline() {
+	// Sync outline with tree so new items are checked
+	a.outline.Items = a.tree.GetItems()
+
+	state := a.captureUndoState()
+	problems := a.outline.Repair()
+	if len(problems) == 0 {
+		a.SetStatus("No problems in the outline")
+		return
+	}
+	a.pushUndoState(state)
+	a.tree.RebuildView()
+	a.refreshSearchNodes()
+	for _, problem := range problems {
+		if a.messageLogger != nil {
+			a.messageLogger.AddMessage("Repaired: " + problem.String())
+		}
+	}
+	a.SetStatus(fmt.Sprintf("Repaired %d problems in the outline", len(problems)))
+	a.markDirty()
+}
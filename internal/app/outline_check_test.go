@@ -0,0 +1,75 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/pstuifzand/tui-outliner/internal/config"
+	"github.com/pstuifzand/tui-outliner/internal/model"
+	"github.com/pstuifzand/tui-outliner/internal/ui"
+)
+
+func TestCheckOutlineAutoRepair(t *testing.T) {
+	brokenOutline := func() *model.Outline {
+		item := model.NewItem("No metadata")
+		item.Metadata = nil
+		outline := model.NewOutline()
+		outline.Items = []*model.Item{item}
+		return outline
+	}
+
+	cfg := &config.Config{}
+	cfg.Set("", "")
+
+	outline := brokenOutline()
+	if problems, repaired := checkOutline(outline, cfg); len(problems) != 1 || repaired {
+		t.Fatalf("Expected 1 problem that is not repaired, got %v (repaired %v)", problems, repaired)
+	}
+	if outline.Items[0].Metadata != nil {
+		t.Error("Expected the outline to stay unchanged without autorepair")
+	}
+
+	cfg.Set("autorepair", "true")
+	outline = brokenOutline()
+	if problems, repaired := checkOutline(outline, cfg); len(problems) != 1 || !repaired {
+		t.Fatalf("Expected 1 repaired problem, got %v (repaired %v)", problems, repaired)
+	}
+	if outline.Items[0].Metadata == nil {
+		t.Error("Expected the metadata to be added with autorepair")
+	}
+
+	app := &App{cfg: cfg, messageLogger: ui.NewMessageLogger(10)}
+	app.reportOutlineProblems([]model.Problem{{Message: "empty entry in the root items"}}, true)
+	if !app.dirty {
+		t.Error("Expected a repaired outline to need saving")
+	}
+	if got := app.messageLogger.Count(); got != 2 {
+		t.Errorf("Expected the problem and the status in the messages, got %d messages", got)
+	}
+}
+
+func TestFixOutlineCommand(t *testing.T) {
+	parent := model.NewItem("Parent")
+	child := model.NewItem("Child")
+	parent.AddChild(child)
+	child.Parent = nil
+	child.Metadata = nil
+
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{parent}
+	cfg := &config.Config{}
+	cfg.Set("", "")
+	app := &App{outline: outline, tree: ui.NewTreeView(outline.Items), cfg: cfg}
+
+	app.handleFixCommand([]string{"fix", "outline"})
+	if app.statusMsg != "Repaired 2 problems in the outline" {
+		t.Errorf("Unexpected status %q", app.statusMsg)
+	}
+	if child.Parent != parent || child.Metadata.Attributes == nil || !app.dirty {
+		t.Error("Expected the child to be repaired and the outline to need saving")
+	}
+
+	app.handleFixCommand([]string{"fix", "outline"})
+	if app.statusMsg != "No problems in the outline" {
+		t.Errorf("Unexpected status %q", app.statusMsg)
+	}
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pstuifzand/tui-outliner/internal/storage"
+	"golang.org/x/term"
+)
+
+// PromptPassphrase asks for the passphrase of an encrypted file on the terminal
+// and sets it on store. Nothing is asked when store does not need one. Unless
+// the file on disk is already encrypted, like for a new file or a plain file
+// that will be saved encrypted, the passphrase is asked twice, so a typo cannot
+// lock the file.
+func PromptPassphrase(store *storage.JSONStore) error {
+	if !store.NeedsPassphrase() {
+		return nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return fmt.Errorf("%s is encrypted, a terminal is needed to enter the passphrase", store.FilePath)
+	}
+
+	passphrase, err := readPassphrase(fd, fmt.Sprintf("Passphrase for %s: ", filepath.Base(store.FilePath)))
+	if err != nil {
+		return err
+	}
+	if !store.FileIsEncrypted() {
+		repeated, err := readPassphrase(fd, "Repeat passphrase: ")
+		if err != nil {
+			return err
+		}
+		if repeated != passphrase {
+			return fmt.Errorf("passphrases do not match")
+		}
+	}
+
+	return store.SetPassphrase(passphrase)
+}
+
+// readPassphrase reads a line from the terminal without echoing it
+func readPassphrase(fd int, prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	data, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+// recordAction remembers a mutating normal mode action so '.' can repeat it
+// on the item that is selected at that time
+func (a *App) recordAction(action func(a *App)) {
+	a.lastAction = action
+}
+
+// repeatLastAction replays the last recorded action. Without one, '.' keeps
+// its old meaning as an alternate indent key.
+func (a *App) repeatLastAction() {
+	if a.lastAction == nil {
+		a.indentSelected()
+		return
+	}
+	a.lastAction(a)
+}
+
+// deleteSelected deletes the selected item and keeps it in the clipboard
+func (a *App) deleteSelected() {
+	if a.readOnly {
+		a.SetStatus("File is readonly")
+		return
+	}
+	state := a.captureUndoState()
+	selected := a.tree.GetSelected()
+	if a.tree.DeleteSelected() {
+		a.clipboard = []*model.Item{selected}
+		a.searchMatches = nil
+		a.pushUndoState(state)
+		// Repeating asks for confirmation like d does
+		a.recordAction(func(a *App) { a.confirmDeleteSelected(1) })
+		a.SetStatus("Deleted item")
+		a.markDirty()
+	}
+}
+
+// deleteSelectedCount deletes count items from the selected item on, like 3d,
+// and keeps them all in the clipboard
+func (a *App) deleteSelectedCount(count int) {
+	clipboard := a.clipboard
+	var deleted []*model.Item
+	repeatCount(func(a *App) {
+		a.clipboard = nil
+		a.deleteSelected()
+		deleted = append(deleted, a.clipboard...)
+	})(a, count)
+
+	if len(deleted) == 0 {
+		a.clipboard = clipboard
+		return
+	}
+	a.clipboard = deleted
+	// Repeating deletes as many items again, not just the last one
+	a.recordAction(func(a *App) { a.confirmDeleteSelected(count) })
+	a.SetStatus(fmt.Sprintf("Deleted %d items", len(deleted)))
+}
+
+// indentSelected indents the selected item
+func (a *App) indentSelected() {
+	if a.readOnly {
+		a.SetStatus("File is readonly")
+		return
+	}
+	state := a.captureUndoState()
+	if a.tree.Indent() {
+		a.pushUndoState(state)
+		a.recordAction((*App).indentSelected)
+		a.SetStatus("Indented")
+		a.markDirty()
+	}
+}
+
+// outdentSelected outdents the selected item
+func (a *App) outdentSelected() {
+	if a.readOnly {
+		a.SetStatus("File is readonly")
+		return
+	}
+	state := a.captureUndoState()
+	if a.tree.Outdent() {
+		a.pushUndoState(state)
+		a.recordAction((*App).outdentSelected)
+		a.SetStatus("Outdented")
+		a.markDirty()
+	}
+}
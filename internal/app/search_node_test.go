@@ -0,0 +1,179 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/pstuifzand/tui-outliner/internal/config"
+	"github.com/pstuifzand/tui-outliner/internal/model"
+	"github.com/pstuifzand/tui-outliner/internal/storage"
+	"github.com/pstuifzand/tui-outliner/internal/ui"
+)
+
+// newSearchNode creates an expanded search node for query
+func newSearchNode(query string) *model.Item {
+	node := model.NewItem("Search: " + query)
+	node.Metadata.Attributes["type"] = "search"
+	node.Metadata.Attributes["query"] = query
+	node.Expanded = true
+	return node
+}
+
+func TestRefreshSearchNodes(t *testing.T) {
+	bug := newSearchNode("bug")
+	note := newSearchNode("note")
+	invalid := newSearchNode("(")
+
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{
+		model.NewItem("bug in parser"),
+		model.NewItem("note about bug"),
+		model.NewItem("plain note"),
+		bug, note, invalid,
+	}
+	app := &App{
+		outline: outline,
+		tree:    ui.NewTreeView(outline.Items),
+		cfg:     &config.Config{},
+	}
+
+	app.refreshSearchNodes()
+	if len(bug.VirtualChildRefs) != 2 || len(note.VirtualChildRefs) != 2 || len(invalid.VirtualChildRefs) != 0 {
+		t.Fatalf("Expected 2, 2 and 0 results, got %v, %v and %v",
+			bug.VirtualChildRefs, note.VirtualChildRefs, invalid.VirtualChildRefs)
+	}
+
+	// The parsed query is reused until the query attribute changes
+	cached := app.searchQueryCache["bug"]
+	app.refreshSearchNodes()
+	if app.searchQueryCache["bug"] != cached {
+		t.Error("Expected the parsed query to be reused")
+	}
+
+	bug.Metadata.Attributes["query"] = "parser"
+	app.refreshSearchNodes()
+	if len(bug.VirtualChildRefs) != 1 || outline.FindItemByID(bug.VirtualChildRefs[0]).Text != "bug in parser" {
+		t.Errorf("Expected the changed query to be used, got %v", bug.VirtualChildRefs)
+	}
+}
+
+// BenchmarkRefreshSearchNodes measures refreshing search nodes after an edit.
+// Set TUO_BENCH_FILE to an outline made with cmd/generate-test-file to use it
+// instead of the generated 10000 items.
+func BenchmarkRefreshSearchNodes(b *testing.B) {
+	var outline *model.Outline
+	if path := os.Getenv("TUO_BENCH_FILE"); path != "" {
+		var err error
+		outline, err = storage.NewJSONStore(path).Load()
+		if err != nil {
+			b.Fatalf("Failed to load %s: %v", path, err)
+		}
+	} else {
+		outline = model.NewOutline()
+		categories := []string{"Task", "Note", "Idea", "Bug", "Feature"}
+		for i := range 10000 {
+			outline.Items = append(outline.Items, model.NewItem(fmt.Sprintf("%s #%d", categories[i%len(categories)], i)))
+		}
+	}
+
+	for _, query := range []string{"task", "bug", "idea #1", "feature -note", "#99"} {
+		outline.Items = append(outline.Items, newSearchNode(query))
+	}
+	app := &App{
+		outline: outline,
+		tree:    ui.NewTreeView(outline.Items),
+		cfg:     &config.Config{},
+	}
+
+	b.ResetTimer()
+	for range b.N {
+		app.refreshSearchNodes()
+	}
+}
+
+func TestSearchScopeHoist(t *testing.T) {
+	project := model.NewItem("Project")
+	project.Expanded = true
+	node := newSearchNode("bug")
+	project.Children = []*model.Item{model.NewItem("bug in project"), node}
+	for _, child := range project.Children {
+		child.Parent = project
+	}
+
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{project, model.NewItem("bug elsewhere")}
+	app := &App{
+		outline: outline,
+		tree:    ui.NewTreeView(outline.Items),
+		cfg:     &config.Config{},
+		search:  ui.NewSearch(outline.GetAllItems()),
+	}
+	app.handleCommand("set searchscope hoist")
+
+	// Without hoisting the whole outline is searched
+	if len(node.VirtualChildRefs) != 2 {
+		t.Fatalf("Expected 2 results before hoisting, got %v", node.VirtualChildRefs)
+	}
+
+	app.tree.SelectItem(0)
+	if !app.tree.Hoist() {
+		t.Fatal("Failed to hoist")
+	}
+	app.searchScopeChanged()
+	if len(node.VirtualChildRefs) != 1 || outline.FindItemByID(node.VirtualChildRefs[0]).Text != "bug in project" {
+		t.Errorf("Expected only the result in the hoisted subtree, got %v", node.VirtualChildRefs)
+	}
+
+	app.tree.Unhoist()
+	app.searchScopeChanged()
+	if len(node.VirtualChildRefs) != 2 {
+		t.Errorf("Expected 2 results after unhoisting, got %v", node.VirtualChildRefs)
+	}
+
+	app.handleCommand("set searchscope project")
+	if !strings.Contains(app.statusMsg, "Invalid searchscope") {
+		t.Errorf("Expected an invalid value to be rejected, got %q", app.statusMsg)
+	}
+}
+
+func TestJumpToSearchMatch(t *testing.T) {
+	parent := model.NewItem("Project")
+	parent.AddChild(model.NewItem("second bug"))
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{model.NewItem("first bug"), parent, model.NewItem("no match")}
+	outline.BuildIndex()
+	app := &App{
+		outline: outline,
+		tree:    ui.NewTreeView(outline.Items),
+		cfg:     &config.Config{},
+		search:  ui.NewSearch(outline.GetAllItems()),
+	}
+
+	// Search and close the search bar with Enter
+	app.search.Start()
+	app.search.SetQuery("bug")
+	app.search.HandleKey(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	app.keepSearchMatches()
+
+	app.jumpToSearchMatch(1)
+	if selected := app.tree.GetSelected(); selected.Text != "second bug" || app.statusMsg != "Match 2 of 2" {
+		t.Errorf("Expected the second match in the collapsed parent, got %q (%s)", selected.Text, app.statusMsg)
+	}
+	app.jumpToSearchMatch(1)
+	if selected := app.tree.GetSelected(); selected.Text != "first bug" || !strings.Contains(app.statusMsg, "hit bottom") {
+		t.Errorf("Expected to wrap to the first match, got %q (%s)", selected.Text, app.statusMsg)
+	}
+	app.jumpToSearchMatch(-1)
+	if selected := app.tree.GetSelected(); selected.Text != "second bug" || !strings.Contains(app.statusMsg, "hit top") {
+		t.Errorf("Expected to wrap to the last match, got %q (%s)", selected.Text, app.statusMsg)
+	}
+
+	app.deleteSelected()
+	app.jumpToSearchMatch(1)
+	if app.statusMsg != "No search matches" {
+		t.Errorf("Expected a delete to clear the matches, got %q", app.statusMsg)
+	}
+}
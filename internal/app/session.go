@@ -0,0 +1,91 @@
+package app
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"github.com/pstuifzand/tui-outliner/internal/history"
+)
+
+// lastSessionFilename is the history file that records the last opened file
+const lastSessionFilename = "last_session.toml"
+
+// lastSession is the file, selected item and hoisted item recorded on quit.
+// It is stored as history entries of the form "<key> <value>".
+type lastSession struct {
+	FilePath   string
+	SelectedID string
+	HoistID    string
+}
+
+// restoreLastEnabled reports whether the restorelast setting is on
+func (a *App) restoreLastEnabled() bool {
+	return a.cfg != nil && a.cfg.Get("restorelast") == "true"
+}
+
+// loadLastSession returns the recorded session. Returns false when nothing was
+// recorded or the recorded file no longer exists.
+func loadLastSession(hm *history.Manager) (lastSession, bool) {
+	var session lastSession
+	if hm == nil {
+		return session, false
+	}
+	entries, err := hm.Load(lastSessionFilename)
+	if err != nil {
+		log.Printf("Warning: Failed to load last session: %v\n", err)
+		return session, false
+	}
+
+	for _, entry := range entries {
+		key, value, _ := strings.Cut(entry, " ")
+		switch key {
+		case "file":
+			session.FilePath = value
+		case "selected":
+			session.SelectedID = value
+		case "hoist":
+			session.HoistID = value
+		}
+	}
+	if session.FilePath == "" {
+		return session, false
+	}
+	if _, err := os.Stat(session.FilePath); err != nil {
+		log.Printf("Last opened file %s is not available: %v\n", session.FilePath, err)
+		return session, false
+	}
+	return session, true
+}
+
+// saveLastSession records the active file with its selected and hoisted item,
+// when restorelast is on. Backups and unsaved outlines are not recorded.
+func (a *App) saveLastSession() {
+	if !a.restoreLastEnabled() || a.historyManager == nil || !a.hasFile || a.readOnly || a.originalFilePath == "" {
+		return
+	}
+
+	entries := []string{"file " + a.originalFilePath}
+	if selected := a.tree.GetSelected(); selected != nil {
+		entries = append(entries, "selected "+selected.ID)
+	}
+	if hoisted := a.tree.GetHoistedItem(); hoisted != nil {
+		entries = append(entries, "hoist "+hoisted.ID)
+	}
+	if err := a.historyManager.Save(lastSessionFilename, entries); err != nil {
+		log.Printf("Warning: Failed to save last session: %v\n", err)
+	}
+}
+
+// restoreSessionView hoists and selects the items of a restored session. Items
+// that no longer exist are skipped, leaving the default view.
+func (a *App) restoreSessionView(session lastSession) {
+	if hoisted := a.outline.FindItemByID(session.HoistID); hoisted != nil && len(hoisted.Children) > 0 {
+		if a.jumpToItem(hoisted) && a.tree.Hoist() {
+			a.searchScopeChanged()
+		}
+	}
+	if selected := a.outline.FindItemByID(session.SelectedID); selected != nil {
+		a.jumpToItem(selected)
+	}
+}
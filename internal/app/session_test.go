@@ -0,0 +1,85 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pstuifzand/tui-outliner/internal/config"
+	"github.com/pstuifzand/tui-outliner/internal/history"
+	"github.com/pstuifzand/tui-outliner/internal/model"
+	"github.com/pstuifzand/tui-outliner/internal/ui"
+)
+
+func TestLastSessionRestore(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	manager, err := history.NewManager()
+	if err != nil {
+		t.Fatalf("Failed to create history manager: %v", err)
+	}
+	filePath := filepath.Join(t.TempDir(), "notes.json")
+	if err := os.WriteFile(filePath, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	project := model.NewItem("Project")
+	task := model.NewItem("Task")
+	project.AddChild(task)
+	project.Expanded = true
+	other := model.NewItem("Other")
+
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{project, other}
+	outline.BuildIndex()
+	cfg := &config.Config{}
+	app := &App{
+		outline:          outline,
+		tree:             ui.NewTreeView(outline.Items),
+		cfg:              cfg,
+		historyManager:   manager,
+		hasFile:          true,
+		originalFilePath: filePath,
+	}
+
+	// Nothing is recorded unless restorelast is on
+	app.saveLastSession()
+	if _, ok := loadLastSession(manager); ok {
+		t.Fatal("Expected no session without restorelast")
+	}
+
+	cfg.Set("restorelast", "true")
+	app.tree.Hoist()
+	app.tree.SelectItemByID(task.ID)
+	app.saveLastSession()
+
+	session, ok := loadLastSession(manager)
+	if !ok || session.FilePath != filePath || session.SelectedID != task.ID || session.HoistID != project.ID {
+		t.Fatalf("Unexpected session %+v", session)
+	}
+
+	// Restore the view in a fresh tree, like after a restart
+	project.Expanded = false
+	restored := &App{outline: outline, tree: ui.NewTreeView(outline.Items)}
+	restored.restoreSessionView(session)
+	if hoisted := restored.tree.GetHoistedItem(); hoisted == nil || hoisted.ID != project.ID {
+		t.Errorf("Expected Project to be hoisted, got %v", hoisted)
+	}
+	if selected := restored.tree.GetSelected(); selected == nil || selected.ID != task.ID {
+		t.Errorf("Expected Task to be selected, got %v", selected)
+	}
+
+	// Items that were deleted since are skipped
+	changed := &App{outline: outline, tree: ui.NewTreeView(outline.Items)}
+	changed.restoreSessionView(lastSession{FilePath: filePath, SelectedID: "gone", HoistID: "gone"})
+	if changed.tree.IsHoisted() || changed.tree.GetSelected() == nil {
+		t.Error("Expected the default view for missing items")
+	}
+
+	// A file that no longer exists is not restored
+	if err := os.Remove(filePath); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loadLastSession(manager); ok {
+		t.Error("Expected no session for a removed file")
+	}
+}
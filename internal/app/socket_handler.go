@@ -1,8 +1,13 @@
 package app
 
 import (
+	"encoding/json"
+	"fmt"
 	"log"
+	"maps"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/pstuifzand/tui-outliner/internal/export"
 	"github.com/pstuifzand/tui-outliner/internal/model"
@@ -11,18 +16,40 @@ import (
 )
 
 // handleSocketMessage processes messages received from the Unix socket
+// Messages are handled on the main loop, like key events, so they don't race
+// with editing and rendering. The responses are encoded on the connection
+// goroutine though, so they must not share maps or slices with the outline.
 func (app *App) handleSocketMessage(msg socket.Message) {
 	log.Printf("Received socket message: command=%s, text=%s, target=%s", msg.Command, msg.Text, msg.Target)
 
 	switch msg.Command {
 	case socket.CommandAddNode:
 		app.handleAddNodeCommand(msg)
+	case socket.CommandAddNodes:
+		app.handleAddNodesCommand(msg)
 	case socket.CommandExportMarkdown:
 		app.handleExportMarkdownCommand(msg)
 	case socket.CommandSearch:
 		app.handleSocketSearchCommand(msg)
+	case socket.CommandGetOutline:
+		app.handleGetOutlineCommand(msg)
+	case socket.CommandToggleTodo:
+		app.handleToggleTodoCommand(msg)
+	case socket.CommandSubscribe:
+		app.handleSubscribeCommand(msg)
 	default:
 		log.Printf("Unknown socket command: %s", msg.Command)
+		if msg.ResponseChan != nil {
+			msg.ResponseChan <- &socket.Response{
+				Success: false,
+				Message: "Unknown command: " + msg.Command,
+			}
+		}
+	}
+
+	// Restart a running search so its results match the changed outline
+	if app.search != nil && app.search.IsActive() && (msg.Command == socket.CommandAddNode || msg.Command == socket.CommandAddNodes || msg.Command == socket.CommandToggleTodo) {
+		app.search.SetAllItems(app.searchItems())
 	}
 }
 
@@ -31,6 +58,26 @@ func (app *App) handleAddNodeCommand(msg socket.Message) {
 	// Validate text
 	if msg.Text == "" {
 		log.Printf("Add node command missing text")
+		if msg.ResponseChan != nil {
+			msg.ResponseChan <- &socket.Response{
+				Success: false,
+				Message: "Text required",
+			}
+		}
+		return
+	}
+
+	// Add under a specific parent when one was requested
+	if msg.HasParent() {
+		response := &socket.Response{Success: true, Message: "Node added"}
+		if err := app.addToParent(msg.ParentID, msg.ParentQuery, msg.Text, msg.Attributes); err != nil {
+			log.Printf("Failed to add item under parent: %v", err)
+			app.SetStatus("Error adding item: " + err.Error())
+			response = &socket.Response{Success: false, Message: err.Error()}
+		}
+		if msg.ResponseChan != nil {
+			msg.ResponseChan <- response
+		}
 		return
 	}
 
@@ -44,6 +91,12 @@ func (app *App) handleAddNodeCommand(msg socket.Message) {
 	if target != "inbox" {
 		log.Printf("Unsupported target: %s (only 'inbox' is supported)", target)
 		app.SetStatus("Error: Only 'inbox' target is supported")
+		if msg.ResponseChan != nil {
+			msg.ResponseChan <- &socket.Response{
+				Success: false,
+				Message: "Only 'inbox' target is supported",
+			}
+		}
 		return
 	}
 
@@ -57,19 +110,151 @@ func (app *App) handleAddNodeCommand(msg socket.Message) {
 	if err := app.addToInbox(msg.Text, msg.Attributes); err != nil {
 		log.Printf("Failed to add item to inbox: %v", err)
 		app.SetStatus("Error adding item to inbox")
+		if msg.ResponseChan != nil {
+			msg.ResponseChan <- &socket.Response{
+				Success: false,
+				Message: err.Error(),
+			}
+		}
 		return
 	}
+	if msg.ResponseChan != nil {
+		msg.ResponseChan <- &socket.Response{Success: true, Message: "Node added"}
+	}
 
 	log.Printf("Successfully added item to inbox: %s", msg.Text)
 	log.Printf("Tree now has %d root items", len(app.outline.Items))
 }
 
+// handleAddNodesCommand processes an add_nodes command
+func (app *App) handleAddNodesCommand(msg socket.Message) {
+	response := &socket.Response{Success: true}
+	count, err := app.addNodes(msg.ParentID, msg.ParentQuery, msg.Nodes)
+	if err != nil {
+		log.Printf("Failed to add nodes: %v", err)
+		app.SetStatus("Error adding items: " + err.Error())
+		response = &socket.Response{Success: false, Message: err.Error()}
+	} else {
+		response.Message = fmt.Sprintf("Added %d nodes", count)
+	}
+	if msg.ResponseChan != nil {
+		msg.ResponseChan <- response
+	}
+}
+
+// addNodes adds the nodes of an add_nodes message in order and returns the
+// number of added items, children included. The nodes go under their own
+// parent, the parent given by parentID or parentQuery, or the inbox. All
+// parents are resolved before the outline changes, so when one node can't be
+// added none of them are. The view is refreshed once.
+func (app *App) addNodes(parentID, parentQuery string, nodes []socket.NodeSpec) (int, error) {
+	if len(nodes) == 0 {
+		return 0, fmt.Errorf("nodes required")
+	}
+	if app.readOnly {
+		return 0, fmt.Errorf("file is readonly")
+	}
+	for i, node := range nodes {
+		if err := checkNodeText(node); err != nil {
+			return 0, fmt.Errorf("node %d: %w", i+1, err)
+		}
+	}
+
+	// Sync outline with tree so the index covers every item
+	app.outline.Items = app.tree.GetItems()
+	app.outline.BuildIndex()
+
+	// Resolve the parents first, nil stands for the inbox
+	var defaultParent *model.Item
+	if parentID != "" || parentQuery != "" {
+		parent, err := search.FindParent(app.outline, parentID, parentQuery)
+		if err != nil {
+			return 0, err
+		}
+		defaultParent = parent
+	}
+	parents := make([]*model.Item, len(nodes))
+	for i, node := range nodes {
+		parents[i] = defaultParent
+		if node.HasParent() {
+			parent, err := search.FindParent(app.outline, node.ParentID, node.ParentQuery)
+			if err != nil {
+				return 0, fmt.Errorf("node %d: %w", i+1, err)
+			}
+			parents[i] = parent
+		}
+	}
+
+	app.saveUndoState()
+	var inbox *model.Item
+	inboxCreated := false
+	count := 0
+	for i, node := range nodes {
+		parent := parents[i]
+		if parent == nil {
+			if inbox == nil {
+				inbox, inboxCreated = app.getOrCreateInboxNode()
+			}
+			parent = inbox
+		}
+		parent.AddChild(app.newNodeItem(node, &count))
+		parent.Expanded = true
+	}
+
+	// Mark as dirty and save soon, like single added items
+	app.markDirty()
+	app.autoSaveTime = time.Now()
+
+	if inboxCreated {
+		// The new inbox is a new root item
+		app.tree.SetItems(app.outline.Items)
+	} else {
+		app.tree.RebuildView()
+	}
+	// The main loop renders the new items on its next tick
+	app.SetStatus(fmt.Sprintf("Added %d items", count))
+
+	return count, nil
+}
+
+// checkNodeText reports an error when a node or one of its children has no text
+func checkNodeText(node socket.NodeSpec) error {
+	if strings.TrimSpace(node.Text) == "" {
+		return fmt.Errorf("text required")
+	}
+	for _, child := range node.Children {
+		if err := checkNodeText(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newNodeItem creates the item of a node with its children, and adds the
+// number of created items to count
+func (app *App) newNodeItem(node socket.NodeSpec, count *int) *model.Item {
+	item := newItemWithAttributes(node.Text, node.Attributes)
+	*count++
+	for _, child := range node.Children {
+		item.AddChild(app.newNodeItem(child, count))
+	}
+	// After the children, so a template doesn't add its items next to them
+	app.applyTypeDefaults(item)
+	return item
+}
+
 // handleExportMarkdownCommand processes an export_markdown command
 func (app *App) handleExportMarkdownCommand(msg socket.Message) {
 	// Validate export path
 	if msg.ExportPath == "" {
 		log.Printf("Export command missing export path")
 		app.SetStatus("Error: Export path required")
+		if msg.ResponseChan != nil {
+			msg.ResponseChan <- &socket.Response{
+				Success: false,
+				Message: "Export path required",
+			}
+		}
 		return
 	}
 
@@ -82,11 +267,20 @@ func (app *App) handleExportMarkdownCommand(msg socket.Message) {
 	if err := export.ExportToMarkdown(app.outline, msg.ExportPath); err != nil {
 		log.Printf("Failed to export: %v", err)
 		app.SetStatus("Error exporting to markdown: " + err.Error())
+		if msg.ResponseChan != nil {
+			msg.ResponseChan <- &socket.Response{
+				Success: false,
+				Message: err.Error(),
+			}
+		}
 		return
 	}
 
 	log.Printf("Successfully exported to: %s", msg.ExportPath)
 	app.SetStatus("Exported to " + msg.ExportPath)
+	if msg.ResponseChan != nil {
+		msg.ResponseChan <- &socket.Response{Success: true, Message: "Exported to " + msg.ExportPath}
+	}
 }
 
 // handleSocketSearchCommand processes a search command from socket
@@ -122,6 +316,20 @@ func (app *App) handleSocketSearchCommand(msg socket.Message) {
 	matches := search.GetMatchingItems(app.outline, filterExpr)
 	log.Printf("Found %d matches", len(matches))
 
+	// Sort before limiting, so the limit keeps the first results in sort order
+	if msg.Sort != "" {
+		if err := search.SortItems(matches, msg.Sort); err != nil {
+			if msg.ResponseChan != nil {
+				msg.ResponseChan <- &socket.Response{
+					Success: false,
+					Message: err.Error(),
+				}
+			}
+			return
+		}
+	}
+	matches = search.LimitItems(matches, msg.Limit)
+
 	// Determine fields to include (defaults based on backward compatibility)
 	fields := msg.Fields
 	if len(fields) == 0 {
@@ -129,6 +337,13 @@ func (app *App) handleSocketSearchCommand(msg socket.Message) {
 		fields = []string{"text", "path", "attributes"}
 	}
 
+	// Always include the complete set of fields so clients can format the results themselves
+	for _, field := range socket.CompleteResultFields {
+		if !slices.Contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+
 	// For markdown/list format, always include children
 	includeChildren := msg.Format == "markdown" || msg.Format == "list"
 
@@ -172,7 +387,7 @@ func buildChildrenArray(item *model.Item) []interface{} {
 
 		// Add attributes if present
 		if child.Metadata != nil && child.Metadata.Attributes != nil && len(child.Metadata.Attributes) > 0 {
-			childData["attributes"] = child.Metadata.Attributes
+			childData["attributes"] = maps.Clone(child.Metadata.Attributes)
 		}
 
 		// Recursively add children
@@ -196,7 +411,7 @@ func buildItemPath(item *model.Item) []interface{} {
 			"text": current.Text,
 		}
 		if current.Metadata != nil && current.Metadata.Attributes != nil {
-			node["attributes"] = current.Metadata.Attributes
+			node["attributes"] = maps.Clone(current.Metadata.Attributes)
 		}
 		path = append([]interface{}{node}, path...)
 		current = current.Parent
@@ -230,7 +445,7 @@ func buildSearchResult(item *model.Item, fields []string) socket.SearchResult {
 			if item.Metadata == nil || item.Metadata.Attributes == nil {
 				result["attributes"] = make(map[string]string)
 			} else {
-				result["attributes"] = item.Metadata.Attributes
+				result["attributes"] = maps.Clone(item.Metadata.Attributes)
 			}
 		case "created":
 			if item.Metadata == nil {
@@ -248,7 +463,7 @@ func buildSearchResult(item *model.Item, fields []string) socket.SearchResult {
 			if item.Metadata == nil || item.Metadata.Tags == nil {
 				result["tags"] = []string{}
 			} else {
-				result["tags"] = item.Metadata.Tags
+				result["tags"] = slices.Clone(item.Metadata.Tags)
 			}
 		case "depth":
 			result["depth"] = getItemDepth(item)
@@ -276,3 +491,115 @@ func getItemDepth(item *model.Item) int {
 	}
 	return depth
 }
+
+// handleToggleTodoCommand processes a toggle_todo command, rotating the status
+// of the item with the given ID like the x key does
+func (app *App) handleToggleTodoCommand(msg socket.Message) {
+	respond := func(response *socket.Response) {
+		if msg.ResponseChan != nil {
+			msg.ResponseChan <- response
+		}
+	}
+
+	if msg.ID == "" {
+		log.Printf("Toggle todo command missing id")
+		respond(&socket.Response{Success: false, Message: "ID required"})
+		return
+	}
+	if app.readOnly {
+		respond(&socket.Response{Success: false, Message: "File is readonly"})
+		return
+	}
+
+	// Sync outline with tree so the index covers every item
+	app.outline.Items = app.tree.GetItems()
+	app.outline.BuildIndex()
+
+	item := app.outline.FindItemByID(msg.ID)
+	if item == nil {
+		log.Printf("Toggle todo: item not found: %s", msg.ID)
+		respond(&socket.Response{Success: false, Message: "item not found: " + msg.ID})
+		return
+	}
+
+	app.saveUndoState()
+	newStatus := app.rotateTodoStatus(item)
+
+	// Mark as dirty and save soon, like items added from the socket
+	app.markDirty()
+	app.autoSaveTime = time.Now()
+
+	app.refreshSearchNodes()
+	app.tree.RebuildView()
+	app.SetStatus(fmt.Sprintf("Status of %s: %s", item.Text, newStatus))
+	app.render()
+
+	log.Printf("Toggled todo %s to %s", msg.ID, newStatus)
+	respond(&socket.Response{Success: true, Message: newStatus})
+}
+
+// handleGetOutlineCommand processes a get_outline command, answering with the live
+// outline as JSON. With a query only the subtrees of the matching items are included.
+func (app *App) handleGetOutlineCommand(msg socket.Message) {
+	respond := func(response *socket.Response) {
+		if msg.ResponseChan != nil {
+			msg.ResponseChan <- response
+		}
+	}
+
+	// Serialize the live tree, outline.Items may be stale
+	app.outline.Items = app.tree.GetItems()
+	app.outline.BuildIndex()
+
+	outline := &model.Outline{
+		Items:            app.outline.Items,
+		OriginalFilename: app.outline.OriginalFilename,
+		TypeDefinitions:  app.outline.TypeDefinitions,
+		TypeDefaults:     app.outline.TypeDefaults,
+		TypeTemplates:    app.outline.TypeTemplates,
+	}
+
+	if msg.Query != "" {
+		filterExpr, err := search.ParseQuery(msg.Query)
+		if err != nil {
+			log.Printf("Failed to parse outline query: %v", err)
+			respond(&socket.Response{Success: false, Message: "Parse error: " + err.Error()})
+			return
+		}
+		outline.Items = topLevelItems(search.GetMatchingItems(app.outline, filterExpr))
+	}
+
+	data, err := json.Marshal(outline)
+	if err != nil {
+		log.Printf("Failed to marshal outline: %v", err)
+		respond(&socket.Response{Success: false, Message: "failed to marshal outline: " + err.Error()})
+		return
+	}
+
+	respond(&socket.Response{
+		Success: true,
+		Message: fmt.Sprintf("Outline with %d root items", len(outline.Items)),
+		Outline: data,
+	})
+}
+
+// topLevelItems drops the items that are descendants of other items in the list,
+// so each subtree is included once. items must be in outline order.
+func topLevelItems(items []*model.Item) []*model.Item {
+	kept := make(map[*model.Item]bool)
+	var result []*model.Item
+	for _, item := range items {
+		covered := false
+		for parent := item.Parent; parent != nil; parent = parent.Parent {
+			if kept[parent] {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			kept[item] = true
+			result = append(result, item)
+		}
+	}
+	return result
+}
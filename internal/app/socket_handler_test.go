@@ -0,0 +1,346 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/pstuifzand/tui-outliner/internal/config"
+	"github.com/pstuifzand/tui-outliner/internal/model"
+	"github.com/pstuifzand/tui-outliner/internal/socket"
+	"github.com/pstuifzand/tui-outliner/internal/ui"
+)
+
+func TestSocketSearchIncludesCompleteFields(t *testing.T) {
+	parent := model.NewItem("Project")
+	child := model.NewItem("Task")
+	child.Metadata.Attributes["status"] = "todo"
+	parent.AddChild(child)
+
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{parent}
+	app := &App{outline: outline}
+
+	responses := make(chan *socket.Response, 1)
+	app.handleSocketSearchCommand(socket.Message{
+		Command:      socket.CommandSearch,
+		Query:        "@status=todo",
+		Fields:       []string{"text", "attr:status"},
+		ResponseChan: responses,
+	})
+
+	response := <-responses
+	if !response.Success || len(response.Results) != 1 {
+		t.Fatalf("Expected 1 successful result, got %+v", response)
+	}
+
+	result := response.Results[0]
+	if result["attr:status"] != "todo" {
+		t.Errorf("Expected requested attr:status field, got %v", result["attr:status"])
+	}
+	for _, field := range socket.CompleteResultFields {
+		if _, ok := result[field]; !ok {
+			t.Errorf("Expected field %q in result", field)
+		}
+	}
+	if result["parent_id"] != parent.ID || result["depth"] != 1 {
+		t.Errorf("Expected parent_id %s and depth 1, got %v and %v", parent.ID, result["parent_id"], result["depth"])
+	}
+}
+
+func TestSocketToggleTodoUnknownID(t *testing.T) {
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{model.NewItem("Task")}
+	app := &App{outline: outline, tree: ui.NewTreeView(outline.Items)}
+
+	responses := make(chan *socket.Response, 1)
+	app.handleToggleTodoCommand(socket.Message{
+		Command:      socket.CommandToggleTodo,
+		ID:           "missing",
+		ResponseChan: responses,
+	})
+
+	response := <-responses
+	if response.Success {
+		t.Fatalf("Expected failure for unknown ID, got %+v", response)
+	}
+	if app.dirty {
+		t.Error("Expected app not to be dirty after a failed toggle")
+	}
+}
+
+func TestRotateTodoStatusUpdatesParent(t *testing.T) {
+	parent := model.NewItem("Project")
+	parent.Metadata.Attributes["type"] = "todo"
+	parent.Metadata.Attributes["status"] = "todo"
+	child := model.NewItem("Task")
+	child.Metadata.Attributes["status"] = "doing"
+	parent.AddChild(child)
+
+	app := &App{cfg: &config.Config{}}
+
+	if status := app.rotateTodoStatus(child); status != "done" {
+		t.Fatalf("Expected status done, got %s", status)
+	}
+	if child.Metadata.Attributes["type"] != "todo" {
+		t.Errorf("Expected type todo to be set, got %q", child.Metadata.Attributes["type"])
+	}
+	if parent.Metadata.Attributes["status"] != "done" {
+		t.Errorf("Expected parent status done, got %q", parent.Metadata.Attributes["status"])
+	}
+
+	// Rotation wraps around to the first status
+	if status := app.rotateTodoStatus(child); status != "todo" {
+		t.Errorf("Expected status to wrap to todo, got %s", status)
+	}
+}
+
+func TestSocketGetOutlineUsesLiveTree(t *testing.T) {
+	project := model.NewItem("Project")
+	project.Metadata.Attributes["type"] = "project"
+	task := model.NewItem("Task")
+	task.Metadata.Attributes["type"] = "project"
+	project.AddChild(task)
+	other := model.NewItem("Other")
+
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{project}
+	// The tree has an item that is not in the stale outline.Items yet
+	app := &App{outline: outline, tree: ui.NewTreeView([]*model.Item{project, other})}
+
+	responses := make(chan *socket.Response, 1)
+	app.handleGetOutlineCommand(socket.Message{Command: socket.CommandGetOutline, ResponseChan: responses})
+
+	response := <-responses
+	var got model.Outline
+	if err := json.Unmarshal(response.Outline, &got); err != nil {
+		t.Fatalf("Failed to decode outline: %v", err)
+	}
+	if len(got.Items) != 2 || got.Items[1].Text != "Other" {
+		t.Fatalf("Expected the live tree with 2 root items, got %+v", got.Items)
+	}
+
+	// With a query, nested matches are only included inside their matching ancestor
+	app.handleGetOutlineCommand(socket.Message{
+		Command:      socket.CommandGetOutline,
+		Query:        "@type=project",
+		ResponseChan: responses,
+	})
+	response = <-responses
+	got = model.Outline{}
+	if err := json.Unmarshal(response.Outline, &got); err != nil {
+		t.Fatalf("Failed to decode outline: %v", err)
+	}
+	if len(got.Items) != 1 || got.Items[0].ID != project.ID || len(got.Items[0].Children) != 1 {
+		t.Errorf("Expected only the Project subtree, got %+v", got.Items)
+	}
+}
+
+func TestSocketSearchResultsDontShareOutlineMaps(t *testing.T) {
+	parent := model.NewItem("Project")
+	parent.Metadata.Attributes["type"] = "project"
+	child := model.NewItem("Task")
+	child.Metadata.Attributes["status"] = "todo"
+	child.Metadata.Tags = []string{"work"}
+	parent.AddChild(child)
+
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{parent}
+	app := &App{outline: outline}
+
+	responses := make(chan *socket.Response, 1)
+	app.handleSocketSearchCommand(socket.Message{
+		Command:      socket.CommandSearch,
+		Query:        "@type=project",
+		Format:       "list",
+		ResponseChan: responses,
+	})
+	response := <-responses
+	if len(response.Results) != 1 {
+		t.Fatalf("Expected 1 result, got %+v", response)
+	}
+	before, _ := json.Marshal(response.Results)
+
+	// The outline changes while the connection goroutine encodes the response
+	parent.Metadata.Attributes["type"] = "done"
+	child.Metadata.Attributes["status"] = "done"
+	child.Metadata.Tags[0] = "home"
+
+	after, _ := json.Marshal(response.Results)
+	if string(before) != string(after) {
+		t.Errorf("Expected the results not to change with the outline, got\n%s\nthen\n%s", before, after)
+	}
+}
+
+func TestSocketSearchWhileEditing(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	server, err := socket.NewServer(os.Getpid())
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer server.Stop()
+	server.Start()
+
+	project := model.NewItem("Project")
+	task := model.NewItem("Task")
+	task.Metadata.Attributes["type"] = "todo"
+	task.Metadata.Attributes["status"] = "todo"
+	project.AddChild(task)
+	project.Expanded = true
+
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{project}
+	cfg := &config.Config{}
+	cfg.Set("", "")
+	app := &App{outline: outline, tree: ui.NewTreeView(outline.Items), cfg: cfg}
+
+	// Clients search for the task, and the results are encoded on the
+	// connection goroutines while the main loop below keeps editing it
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client, err := socket.NewClient(server.SocketPath())
+			if err != nil {
+				errs <- err
+				return
+			}
+			for range 25 {
+				response, err := client.SendSearch("@type=todo", []string{"attributes", "tags", "path"}, "list", "", 0)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if !response.Success {
+					errs <- fmt.Errorf("request failed: %s", response.Message)
+					return
+				}
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	for edits := 0; ; edits++ {
+		select {
+		case msg := <-server.Messages():
+			app.handleSocketMessage(msg)
+			continue
+		case <-done:
+		default:
+			key := fmt.Sprintf("edit%d", edits%8)
+			task.Metadata.Attributes[key] = task.Text
+			delete(task.Metadata.Attributes, fmt.Sprintf("edit%d", (edits+4)%8))
+			task.Metadata.Tags = append(task.Metadata.Tags[:0], key)
+			app.tree.RebuildView()
+			// Let the connection goroutines run on a single CPU too
+			runtime.Gosched()
+			continue
+		}
+		break
+	}
+
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestSocketAddNodes(t *testing.T) {
+	project := model.NewItem("Project")
+	project.Metadata.Attributes["type"] = "project"
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{project}
+	app := &App{outline: outline, tree: ui.NewTreeView(outline.Items)}
+
+	responses := make(chan *socket.Response, 1)
+	app.handleSocketMessage(socket.Message{
+		Command: socket.CommandAddNodes,
+		Nodes: []socket.NodeSpec{
+			{Text: "Buy milk", Attributes: map[string]string{"type": "todo"}},
+			{Text: "Plan trip", Children: []socket.NodeSpec{{Text: "Book hotel"}}},
+			{Text: "Next step", ParentQuery: "@type=project"},
+		},
+		ResponseChan: responses,
+	})
+
+	if response := <-responses; !response.Success || response.Message != "Added 4 nodes" {
+		t.Fatalf("Expected 4 added nodes, got %+v", response)
+	}
+	if len(outline.Items) != 2 || outline.Items[1].Metadata.Attributes["type"] != "inbox" {
+		t.Fatalf("Expected a new inbox after the project, got %d root items", len(outline.Items))
+	}
+	inbox := outline.Items[1]
+	if len(inbox.Children) != 2 || inbox.Children[0].Text != "Buy milk" || inbox.Children[1].Text != "Plan trip" {
+		t.Fatalf("Expected the nodes in order in the inbox, got %d children", len(inbox.Children))
+	}
+	if inbox.Children[0].Metadata.Attributes["type"] != "todo" {
+		t.Error("Expected the attributes of the node on its item")
+	}
+	if hotel := inbox.Children[1].Children; len(hotel) != 1 || hotel[0].Text != "Book hotel" || hotel[0].Parent != inbox.Children[1] {
+		t.Error("Expected the child node under its node")
+	}
+	if len(project.Children) != 1 || project.Children[0].Text != "Next step" {
+		t.Error("Expected the node with a parent query under the project")
+	}
+	if !app.dirty || len(app.undoStack) != 1 {
+		t.Errorf("Expected one undo state and a dirty outline, got %d undo states", len(app.undoStack))
+	}
+}
+
+func TestSocketAddNodesIsAllOrNothing(t *testing.T) {
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{model.NewItem("Project")}
+	app := &App{outline: outline, tree: ui.NewTreeView(outline.Items)}
+
+	for _, nodes := range [][]socket.NodeSpec{
+		{{Text: "First"}, {Text: "Second", ParentID: "missing"}},
+		{{Text: "First"}, {Text: "Second", Children: []socket.NodeSpec{{Text: " "}}}},
+		nil,
+	} {
+		responses := make(chan *socket.Response, 1)
+		app.handleSocketMessage(socket.Message{Command: socket.CommandAddNodes, Nodes: nodes, ResponseChan: responses})
+		if response := <-responses; response.Success {
+			t.Errorf("Expected failure, got %+v", response)
+		}
+	}
+
+	if len(outline.Items) != 1 || len(outline.Items[0].Children) != 0 {
+		t.Errorf("Expected the outline to stay unchanged, got %d root items", len(outline.Items))
+	}
+	if app.dirty || len(app.undoStack) != 0 {
+		t.Error("Expected no changes after failed batches")
+	}
+}
+
+func TestSocketCommandsRespondWhenWaitedFor(t *testing.T) {
+	outline := model.NewOutline()
+	app := &App{outline: outline, tree: ui.NewTreeView(outline.Items)}
+
+	// JSON-RPC requests always wait for a response, also for these commands
+	for _, msg := range []socket.Message{
+		{Command: socket.CommandExportMarkdown},
+		{Command: socket.CommandAddNode, Text: "Task", Target: "projects"},
+		{Command: "rename_everything"},
+	} {
+		responses := make(chan *socket.Response, 1)
+		msg.ResponseChan = responses
+		app.handleSocketMessage(msg)
+		select {
+		case response := <-responses:
+			if response.Success {
+				t.Errorf("Expected %s to fail, got %+v", msg.Command, response)
+			}
+		default:
+			t.Errorf("Expected a response to %s", msg.Command)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/pstuifzand/tui-outliner/internal/model"
 	tmpl "github.com/pstuifzand/tui-outliner/internal/template"
 )
 
@@ -28,6 +29,8 @@ func init() {
 //	:typedef list           - Show all type definitions
 //	:typedef add <key> <spec> - Add type definition
 //	:typedef remove <key>   - Remove type definition
+//	:typedef default <type> <key> [value] - Set or remove a default attribute for a node type
+//	:typedef template <type> [id|none]    - Set or remove the template item for a node type
 func (a *App) handleTypedefCommand(parts []string) {
 	debugLog.Printf("handleTypedefCommand called with parts: %v", parts)
 
@@ -39,7 +42,7 @@ func (a *App) handleTypedefCommand(parts []string) {
 
 	if len(parts) < 2 {
 		debugLog.Printf("Not enough arguments, expected at least 2, got %d", len(parts))
-		a.SetStatus(":typedef list|add|remove ...")
+		a.SetStatus(":typedef list|add|remove|default|template ...")
 		return
 	}
 
@@ -66,8 +69,13 @@ func (a *App) handleTypedefCommand(parts []string) {
 			a.SetStatus(":typedef add <key> <spec> (e.g., :typedef add status enum|todo|done)")
 			return
 		}
-		key := parts[2]
+		// Allow the "status: enum[todo,done]" form
+		key := strings.TrimSuffix(parts[2], ":")
 		spec := strings.Join(parts[3:], "|")
+		if strings.HasPrefix(parts[3], "enum[") {
+			// Spaces after the commas of enum[a, b] split the spec over several parts
+			spec = strings.Join(parts[3:], " ")
+		}
 		debugLog.Printf("Executing add command: key=%s, spec=%s", key, spec)
 		a.handleTypedefAdd(registry, key, spec)
 
@@ -81,6 +89,24 @@ func (a *App) handleTypedefCommand(parts []string) {
 		debugLog.Printf("Executing remove command: key=%s", key)
 		a.handleTypedefRemove(registry, key)
 
+	case "default":
+		if len(parts) < 4 {
+			a.SetStatus(":typedef default <type> <key> [value] (e.g., :typedef default meeting attendees none)")
+			return
+		}
+		a.handleTypedefDefault(registry, parts[2], parts[3], strings.Join(parts[4:], " "))
+
+	case "template":
+		if len(parts) < 3 {
+			a.SetStatus(":typedef template <type> [id|none]")
+			return
+		}
+		itemID := ""
+		if len(parts) > 3 {
+			itemID = parts[3]
+		}
+		a.handleTypedefTemplate(registry, parts[2], itemID)
+
 	default:
 		debugLog.Printf("Unknown subcommand: %s", subcommand)
 		a.SetStatus(fmt.Sprintf("Unknown typedef subcommand: %s", subcommand))
@@ -166,7 +192,12 @@ func (a *App) handleTypedefAdd(registry *tmpl.TypeRegistry, key string, spec str
 		}
 	}
 
-	a.dirty = true
+	// Offer the new type in the attribute editor
+	if a.attributeEditor != nil {
+		a.attributeEditor.SetTypeRegistry(registry)
+	}
+
+	a.markDirty()
 	debugLog.Printf("App marked as dirty, status set")
 	a.SetStatus(fmt.Sprintf("Added type: %s", key))
 }
@@ -191,10 +222,121 @@ func (a *App) handleTypedefRemove(registry *tmpl.TypeRegistry, key string) {
 		return
 	}
 
-	a.dirty = true
+	if a.attributeEditor != nil {
+		a.attributeEditor.SetTypeRegistry(registry)
+	}
+
+	a.markDirty()
 	a.SetStatus(fmt.Sprintf("Removed type: %s", key))
 }
 
+// handleTypedefDefault sets the default value of an attribute for a node type,
+// an empty value removes the default
+func (a *App) handleTypedefDefault(registry *tmpl.TypeRegistry, typeName, key, value string) {
+	if value == "" {
+		if _, exists := registry.GetDefaults(typeName)[key]; !exists {
+			a.SetStatus(fmt.Sprintf("No default for '%s' on type %s", key, typeName))
+			return
+		}
+		registry.RemoveDefault(typeName, key)
+	} else {
+		if a.validateAttributeValue(key, value) == false {
+			// validateAttributeValue sets the status message on error
+			return
+		}
+		registry.SetDefault(typeName, key, value)
+	}
+
+	if err := registry.SaveToOutline(a.outline); err != nil {
+		a.SetStatus(fmt.Sprintf("Failed to save type definitions: %s", err.Error()))
+		return
+	}
+
+	a.markDirty()
+	if value == "" {
+		a.SetStatus(fmt.Sprintf("Removed default '%s' from type %s", key, typeName))
+	} else {
+		a.SetStatus(fmt.Sprintf("Type %s: default '%s' set to '%s'", typeName, key, value))
+	}
+}
+
+// handleTypedefTemplate sets the template item for a node type. Without an ID
+// the selected item is used, "none" removes the template.
+func (a *App) handleTypedefTemplate(registry *tmpl.TypeRegistry, typeName, itemID string) {
+	if itemID == "none" {
+		if registry.GetTemplate(typeName) == "" {
+			a.SetStatus(fmt.Sprintf("Type %s has no template", typeName))
+			return
+		}
+		registry.SetTemplate(typeName, "")
+	} else {
+		if itemID == "" {
+			selected := a.tree.GetSelected()
+			if selected == nil {
+				a.SetStatus("No item selected")
+				return
+			}
+			itemID = selected.ID
+		} else if a.findTreeItem(itemID) == nil {
+			a.SetStatus(fmt.Sprintf("Item not found: %s", itemID))
+			return
+		}
+		registry.SetTemplate(typeName, itemID)
+	}
+
+	if err := registry.SaveToOutline(a.outline); err != nil {
+		a.SetStatus(fmt.Sprintf("Failed to save type definitions: %s", err.Error()))
+		return
+	}
+
+	a.markDirty()
+	if itemID == "none" {
+		a.SetStatus(fmt.Sprintf("Removed template from type %s", typeName))
+	} else {
+		a.SetStatus(fmt.Sprintf("Type %s: template set to %s", typeName, itemID))
+	}
+}
+
+// applyTypeDefaults fills in the default attributes of the item's node type
+// without overwriting attributes that are already set, and copies the children
+// of the type's template into the item when it has no children yet.
+// Returns the number of attributes set and children added.
+func (a *App) applyTypeDefaults(item *model.Item) (int, int) {
+	registry := tmpl.NewTypeRegistry()
+	if err := registry.LoadFromOutline(a.outline); err != nil {
+		debugLog.Printf("Error loading types: %v", err)
+		return 0, 0
+	}
+
+	set := registry.ApplyDefaults(item)
+
+	templateID := registry.GetTemplate(item.Metadata.Attributes["type"])
+	if templateID == "" || len(item.Children) > 0 {
+		return len(set), 0
+	}
+	template := a.findTreeItem(templateID)
+	if template == nil || template == item || isDescendantOf(template, item) {
+		return len(set), 0
+	}
+
+	for _, child := range template.Children {
+		copied := model.CloneItemTree(child)
+		item.AddChild(copied)
+		a.processTemplateItem(copied)
+	}
+	if len(template.Children) > 0 {
+		item.Expanded = true
+	}
+	return len(set), len(template.Children)
+}
+
+// findTreeItem finds an item by ID in the items of the tree view
+func (a *App) findTreeItem(id string) *model.Item {
+	outline := &model.Outline{Items: a.tree.GetItems()}
+	outline.BuildIndex()
+	return outline.FindItemByID(id)
+}
+
 // validateAttributeValue validates an attribute value against type definitions
 // Returns true if valid (or no type definition exists), false if invalid
 // Sets status message on error
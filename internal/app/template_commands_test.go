@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/pstuifzand/tui-outliner/internal/config"
 	"github.com/pstuifzand/tui-outliner/internal/model"
 	"github.com/pstuifzand/tui-outliner/internal/storage"
 	"github.com/pstuifzand/tui-outliner/internal/ui"
@@ -235,3 +236,51 @@ func createMockScreen() *ui.Screen {
 	screen, _ := ui.NewScreen()
 	return screen
 }
+
+// TestTypedefDefaultsApplied tests that setting the type attribute fills in defaults and template children
+func TestTypedefDefaultsApplied(t *testing.T) {
+	app := createTestApp()
+	app.cfg = &config.Config{}
+
+	template := model.NewItem("Meeting template")
+	template.AddChild(model.NewItem("Agenda"))
+	template.AddChild(model.NewItem("Action items"))
+	meeting := app.outline.Items[0]
+	app.outline.Items = append(app.outline.Items, template)
+	app.tree = ui.NewTreeView(app.outline.Items)
+
+	app.handleTypedefCommand([]string{"typedef", "default", "meeting", "location", "office"})
+	app.handleTypedefCommand([]string{"typedef", "default", "meeting", "attendees", "team"})
+	app.handleTypedefCommand([]string{"typedef", "template", "meeting", template.ID})
+	if !strings.Contains(app.statusMsg, "template set to") {
+		t.Fatalf("Expected template to be set, got: %s", app.statusMsg)
+	}
+
+	meeting.Metadata.Attributes["location"] = "online"
+	app.tree.SelectItemByID(meeting.ID)
+	app.handleAttrCommand([]string{"attr", "set", "type", "meeting"})
+
+	if got := meeting.Metadata.Attributes["attendees"]; got != "team" {
+		t.Errorf("Expected default attendees 'team', got %q", got)
+	}
+	if got := meeting.Metadata.Attributes["location"]; got != "online" {
+		t.Errorf("Default should not overwrite location, got %q", got)
+	}
+	if len(meeting.Children) != 2 || meeting.Children[0].Text != "Agenda" {
+		t.Fatalf("Expected the template children to be copied, got %d children", len(meeting.Children))
+	}
+	if meeting.Children[0].ID == template.Children[0].ID {
+		t.Errorf("Copied children should get new IDs")
+	}
+
+	// Setting the type again doesn't add the template children twice
+	app.handleAttrCommand([]string{"attr", "set", "type", "meeting"})
+	if len(meeting.Children) != 2 {
+		t.Errorf("Expected 2 children after setting the type again, got %d", len(meeting.Children))
+	}
+
+	app.handleTypedefCommand([]string{"typedef", "default", "meeting", "attendees"})
+	if _, exists := app.outline.TypeDefaults["meeting"]["attendees"]; exists {
+		t.Errorf("Expected the attendees default to be removed")
+	}
+}
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pstuifzand/tui-outliner/internal/theme"
+)
+
+// handleThemeCommand handles :theme [name]. Without a name it shows the
+// current theme, with a name it switches to that theme and saves it as the
+// theme in the config file.
+func (a *App) handleThemeCommand(parts []string) {
+	if len(parts) < 2 {
+		a.SetStatus(fmt.Sprintf("Theme: %s (built-in themes: %s)", a.screen.Theme.Name, strings.Join(theme.BuiltinNames(), ", ")))
+		return
+	}
+
+	name := parts[1]
+	t, err := theme.LoadThemeByName(name)
+	if err != nil {
+		a.SetStatus(err.Error())
+		return
+	}
+	a.screen.SetTheme(t)
+
+	a.cfg.Theme = name
+	if err := a.cfg.Save(); err != nil {
+		a.SetStatus(fmt.Sprintf("Switched to theme %s, but failed to save it: %v", name, err))
+		return
+	}
+	a.SetStatus("Switched to theme " + name)
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+	"github.com/pstuifzand/tui-outliner/internal/ui"
+)
+
+// todoStatuses returns the configured todo statuses in rotation order
+func (app *App) todoStatuses() []string {
+	statusesStr := app.cfg.Get("todostatuses")
+	if statusesStr == "" {
+		statusesStr = "todo,doing,done" // Default
+	}
+	return strings.Split(statusesStr, ",")
+}
+
+// rotateTodoStatus moves item to the next todo status and updates the status of
+// todo parents. Items without a type become todos. Returns the new status.
+func (app *App) rotateTodoStatus(item *model.Item) string {
+	statuses := app.todoStatuses()
+
+	// Initialize metadata if needed
+	if item.Metadata == nil {
+		item.Metadata = &model.Metadata{
+			Attributes: nil,
+			Created:    time.Now(),
+			Modified:   time.Now(),
+		}
+	}
+	if item.Metadata.Attributes == nil {
+		item.Metadata.Attributes = make(map[string]string)
+	}
+
+	// Initialize type if not already set
+	if _, hasType := item.Metadata.Attributes["type"]; !hasType {
+		item.Metadata.Attributes["type"] = "todo"
+	}
+
+	// Find current index
+	currentStatus := item.Metadata.Attributes["status"]
+	currentIdx := -1
+	for i, s := range statuses {
+		if s == currentStatus {
+			currentIdx = i
+			break
+		}
+	}
+
+	// Rotate to next status
+	newStatus := statuses[(currentIdx+1)%len(statuses)]
+	item.Metadata.Attributes["status"] = newStatus
+	item.Metadata.Modified = time.Now()
+
+	// Update parent status if parent is a todo
+	ui.UpdateParentStatusIfTodo(item, statuses)
+
+	return newStatus
+}
@@ -0,0 +1,208 @@
+package app
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+// defaultUndoLevels is the undo stack depth used when 'undolevels' is not set
+const defaultUndoLevels = 100
+
+// undoState is a snapshot of the outline taken before a change
+type undoState struct {
+	items       []*model.Item // Deep copy of the root items (IDs are kept)
+	selectedID  string        // ID of the item that was selected
+	selectedIdx int           // Selection index, used when the item no longer exists
+	hoistedID   string        // ID of the hoisted item, empty when not hoisted
+}
+
+// undoLevels returns the maximum number of undo states to keep
+func (a *App) undoLevels() int {
+	if a.cfg == nil {
+		return defaultUndoLevels
+	}
+	value := a.cfg.Get("undolevels")
+	if value == "" {
+		return defaultUndoLevels
+	}
+	levels, err := strconv.Atoi(value)
+	if err != nil || levels < 0 {
+		return defaultUndoLevels
+	}
+	return levels
+}
+
+// saveUndoState records the current outline on the undo stack.
+// Call this before every change to the outline structure or content.
+func (a *App) saveUndoState() {
+	a.pushUndoState(a.captureUndoState())
+}
+
+// topUndoState returns the most recent undo state, or nil when there is none
+func (a *App) topUndoState() *undoState {
+	if len(a.undoStack) == 0 {
+		return nil
+	}
+	return a.undoStack[len(a.undoStack)-1]
+}
+
+// pushUndoState records a previously captured state on the undo stack.
+// Used when an operation can fail and should only be recorded on success.
+func (a *App) pushUndoState(state *undoState) {
+	levels := a.undoLevels()
+	if levels == 0 {
+		return
+	}
+
+	a.undoStack = append(a.undoStack, state)
+
+	// Limit undo stack size
+	if len(a.undoStack) > levels {
+		a.undoStack = a.undoStack[len(a.undoStack)-levels:]
+	}
+
+	// Clear redo stack when a new edit is made
+	a.redoStack = nil
+}
+
+// trimUndoStack drops the oldest undo states when 'undolevels' was lowered
+func (a *App) trimUndoStack() {
+	levels := a.undoLevels()
+	if len(a.undoStack) > levels {
+		a.undoStack = a.undoStack[len(a.undoStack)-levels:]
+	}
+}
+
+// undo reverts the outline to the state before the last change
+func (a *App) undo() {
+	if a.readOnly {
+		a.SetStatus("File is readonly")
+		return
+	}
+	if len(a.undoStack) == 0 {
+		a.SetStatus("Already at oldest change")
+		return
+	}
+
+	// Save current state to redo stack
+	a.redoStack = append(a.redoStack, a.captureUndoState())
+
+	// Pop from undo stack
+	lastIdx := len(a.undoStack) - 1
+	previousState := a.undoStack[lastIdx]
+	a.undoStack = a.undoStack[:lastIdx]
+
+	a.restoreUndoState(previousState)
+	a.SetStatus(fmt.Sprintf("Undo (%d more)", len(a.undoStack)))
+}
+
+// redo reapplies the last change that was undone
+func (a *App) redo() {
+	if a.readOnly {
+		a.SetStatus("File is readonly")
+		return
+	}
+	if len(a.redoStack) == 0 {
+		a.SetStatus("Already at newest change")
+		return
+	}
+
+	// Save current state to undo stack
+	a.undoStack = append(a.undoStack, a.captureUndoState())
+
+	// Pop from redo stack
+	lastIdx := len(a.redoStack) - 1
+	nextState := a.redoStack[lastIdx]
+	a.redoStack = a.redoStack[:lastIdx]
+
+	a.restoreUndoState(nextState)
+	a.SetStatus(fmt.Sprintf("Redo (%d more)", len(a.redoStack)))
+}
+
+// captureUndoState takes a snapshot of the outline and the current selection
+func (a *App) captureUndoState() *undoState {
+	state := &undoState{
+		selectedIdx: a.tree.GetSelectedIndex(),
+	}
+	for _, item := range a.tree.GetItems() {
+		state.items = append(state.items, snapshotItem(item, nil))
+	}
+	if selected := a.tree.GetSelected(); selected != nil {
+		state.selectedID = selected.ID
+	}
+	if hoisted := a.tree.GetHoistedItem(); hoisted != nil {
+		state.hoistedID = hoisted.ID
+	}
+	return state
+}
+
+// restoreUndoState replaces the outline with a snapshot and restores the selection
+func (a *App) restoreUndoState(state *undoState) {
+	a.tree.Unhoist()
+
+	a.outline.Items = state.items
+	a.outline.BuildIndex()
+	a.outline.ResolveVirtualChildren()
+	a.tree.SetItems(a.outline.Items)
+
+	// Hoist again if the snapshot was taken while hoisted
+	if state.hoistedID != "" {
+		if hoisted := a.outline.FindItemByID(state.hoistedID); hoisted != nil {
+			a.tree.ExpandParents(hoisted)
+			a.tree.SelectItemByID(hoisted.ID)
+			a.tree.Hoist()
+		}
+	}
+
+	// The search works on the replaced tree, which may be hoisted differently
+	a.searchScopeChanged()
+	if a.searchScope() != "hoist" {
+		a.refreshSearchNodes()
+	}
+
+	// The last send destination points into the replaced tree
+	if a.lastSendDestination != nil {
+		a.lastSendDestination = a.outline.FindItemByID(a.lastSendDestination.ID)
+	}
+
+	// Restore the selection, falling back to the old position
+	if selected := a.outline.FindItemByID(state.selectedID); selected != nil {
+		a.tree.ExpandParents(selected)
+		a.tree.SelectItemByID(selected.ID)
+	} else if state.selectedIdx >= 0 && state.selectedIdx < len(a.tree.GetDisplayItems()) {
+		a.tree.SelectItem(state.selectedIdx)
+	}
+
+	a.markDirty()
+}
+
+// snapshotItem deep-copies an item and its children, keeping the IDs
+func snapshotItem(item *model.Item, parent *model.Item) *model.Item {
+	copied := &model.Item{
+		ID:                       item.ID,
+		Text:                     item.Text,
+		VirtualChildRefs:         slices.Clone(item.VirtualChildRefs),
+		Parent:                   parent,
+		Expanded:                 item.Expanded,
+		CollapsedVirtualChildren: maps.Clone(item.CollapsedVirtualChildren),
+	}
+	if copied.CollapsedVirtualChildren == nil {
+		copied.CollapsedVirtualChildren = make(map[string]bool)
+	}
+	if item.Metadata != nil {
+		copied.Metadata = &model.Metadata{
+			Tags:       slices.Clone(item.Metadata.Tags),
+			Attributes: maps.Clone(item.Metadata.Attributes),
+			Created:    item.Metadata.Created,
+			Modified:   item.Metadata.Modified,
+		}
+	}
+	for _, child := range item.Children {
+		copied.Children = append(copied.Children, snapshotItem(child, copied))
+	}
+	return copied
+}
@@ -0,0 +1,220 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/pstuifzand/tui-outliner/internal/config"
+	"github.com/pstuifzand/tui-outliner/internal/model"
+	"github.com/pstuifzand/tui-outliner/internal/ui"
+)
+
+// createUndoTestApp creates an app with three root items for undo tests
+func createUndoTestApp() *App {
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{
+		model.NewItem("First"),
+		model.NewItem("Second"),
+		model.NewItem("Third"),
+	}
+	outline.BuildIndex()
+
+	cfg := &config.Config{}
+	cfg.Set("", "")
+
+	return &App{
+		outline: outline,
+		tree:    ui.NewTreeView(outline.Items),
+		cfg:     cfg,
+	}
+}
+
+func TestUndoRedoDelete(t *testing.T) {
+	app := createUndoTestApp()
+	app.tree.SelectItem(1)
+
+	state := app.captureUndoState()
+	if !app.tree.DeleteSelected() {
+		t.Fatal("DeleteSelected failed")
+	}
+	app.pushUndoState(state)
+
+	if len(app.tree.GetItems()) != 2 {
+		t.Fatalf("Expected 2 items after delete, got %d", len(app.tree.GetItems()))
+	}
+
+	app.undo()
+
+	items := app.tree.GetItems()
+	if len(items) != 3 || items[1].Text != "Second" {
+		t.Fatalf("Undo did not restore deleted item: %v", itemTexts(items))
+	}
+	if selected := app.tree.GetSelected(); selected == nil || selected.Text != "Second" {
+		t.Errorf("Expected selection to be restored to 'Second', got %v", selected)
+	}
+
+	app.redo()
+
+	items = app.tree.GetItems()
+	if len(items) != 2 || items[1].Text != "Third" {
+		t.Errorf("Redo did not delete the item again: %v", itemTexts(items))
+	}
+}
+
+func TestUndoRestoresIndent(t *testing.T) {
+	app := createUndoTestApp()
+	app.tree.SelectItem(1)
+
+	app.saveUndoState()
+	if !app.tree.Indent() {
+		t.Fatal("Indent failed")
+	}
+
+	app.undo()
+
+	items := app.tree.GetItems()
+	if len(items) != 3 {
+		t.Fatalf("Expected 3 root items after undo, got %d", len(items))
+	}
+	if len(items[0].Children) != 0 {
+		t.Errorf("Expected first item to have no children after undo")
+	}
+	for _, item := range items {
+		if item.Parent != nil {
+			t.Errorf("Root item %q should not have a parent", item.Text)
+		}
+	}
+}
+
+func TestNewEditClearsRedoStack(t *testing.T) {
+	app := createUndoTestApp()
+
+	app.saveUndoState()
+	app.tree.GetItems()[0].Text = "Changed"
+	app.undo()
+
+	if len(app.redoStack) != 1 {
+		t.Fatalf("Expected 1 redo state, got %d", len(app.redoStack))
+	}
+
+	app.saveUndoState()
+	if len(app.redoStack) != 0 {
+		t.Errorf("Expected redo stack to be cleared after a new edit, got %d", len(app.redoStack))
+	}
+}
+
+func TestUndoLevels(t *testing.T) {
+	app := createUndoTestApp()
+	app.cfg.Set("undolevels", "2")
+
+	for range 5 {
+		app.saveUndoState()
+	}
+	if len(app.undoStack) != 2 {
+		t.Errorf("Expected undo stack capped at 2, got %d", len(app.undoStack))
+	}
+
+	app.cfg.Set("undolevels", "0")
+	app.undoStack = nil
+	app.saveUndoState()
+	if len(app.undoStack) != 0 {
+		t.Errorf("Expected undo to be disabled with undolevels=0, got %d", len(app.undoStack))
+	}
+}
+
+func itemTexts(items []*model.Item) []string {
+	var texts []string
+	for _, item := range items {
+		texts = append(texts, item.Text)
+	}
+	return texts
+}
+
+func TestRepeatLastAction(t *testing.T) {
+	app := createUndoTestApp()
+
+	// Without a recorded action '.' indents
+	app.tree.SelectItem(1)
+	app.repeatLastAction()
+	if items := app.tree.GetItems(); len(items) != 2 || len(items[0].Children) != 1 {
+		t.Fatalf("Expected '.' to indent without a previous action, got %v", itemTexts(items))
+	}
+	app.undo()
+
+	app.tree.SelectItem(0)
+	app.deleteSelected()
+	app.repeatLastAction()
+	if items := app.tree.GetItems(); len(items) != 1 || items[0].Text != "Third" {
+		t.Fatalf("Expected '.' to repeat the delete, got %v", itemTexts(items))
+	}
+
+	app.handleAttrCommand([]string{"attr", "set", "status", "done"})
+	app.undo()
+	app.repeatLastAction()
+	if selected := app.tree.GetSelected(); selected == nil || selected.Metadata.Attributes["status"] != "done" {
+		t.Errorf("Expected '.' to repeat the attribute set")
+	}
+	if !app.dirty {
+		t.Error("Expected repeat to mark the outline as modified")
+	}
+}
+
+func TestRepeatCountedDelete(t *testing.T) {
+	outline := model.NewOutline()
+	for _, text := range []string{"One", "Two", "Three", "Four", "Five", "Six", "Seven"} {
+		outline.Items = append(outline.Items, model.NewItem(text))
+	}
+	app := &App{
+		outline: outline,
+		tree:    ui.NewTreeView(outline.Items),
+		cfg:     &config.Config{},
+	}
+
+	// 3d followed by '.' deletes three items twice
+	app.confirmDeleteSelected(3)
+	app.repeatLastAction()
+	if items := app.tree.GetItems(); len(items) != 1 || items[0].Text != "Seven" {
+		t.Fatalf("Expected '.' to delete three items again, got %v", itemTexts(items))
+	}
+	if texts := itemTexts(app.clipboard); len(texts) != 3 || texts[0] != "Four" {
+		t.Errorf("Expected the last three deleted items in the clipboard, got %v", texts)
+	}
+
+	// Each delete is undone at once
+	app.undo()
+	if items := app.tree.GetItems(); len(items) != 4 {
+		t.Errorf("Expected undo to restore the three items of the repeat, got %v", itemTexts(items))
+	}
+}
+
+func TestUndoUpdatesSearchScope(t *testing.T) {
+	project := model.NewItem("Project")
+	project.AddChild(model.NewItem("bug in project"))
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{project, model.NewItem("bug elsewhere")}
+	app := &App{
+		outline: outline,
+		tree:    ui.NewTreeView(outline.Items),
+		cfg:     &config.Config{},
+		search:  ui.NewSearch(outline.GetAllItems()),
+	}
+
+	app.tree.SelectItem(0)
+	if !app.tree.Hoist() {
+		t.Fatal("Failed to hoist")
+	}
+	state := app.captureUndoState()
+	app.tree.Unhoist()
+
+	app.search.Start()
+	app.search.SetQuery("bug")
+	app.searchScopeChanged()
+	if got := len(app.search.Matches()); got != 2 {
+		t.Fatalf("Expected 2 matches in the full tree, got %d", got)
+	}
+
+	// Undo hoists again, so the search is limited to the subtree again
+	app.restoreUndoState(state)
+	if matches := app.search.Matches(); len(matches) != 1 || matches[0].Text != "bug in project" {
+		t.Errorf("Expected only the match in the hoisted subtree, got %d matches", len(matches))
+	}
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"log"
+	"strings"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+// viewStateFilename returns the history file that stores the expanded items
+// and selection of the current file
+func (a *App) viewStateFilename() string {
+	sum := sha1.Sum([]byte(a.originalFilePath))
+	return "view_" + hex.EncodeToString(sum[:8]) + ".toml"
+}
+
+// loadViewState expands the items that were expanded when the current file
+// was last closed and selects the item that was selected. Items that no longer
+// exist are skipped. The view state is kept outside the file, so it is not
+// shared with other machines that use the same file.
+func (a *App) loadViewState() {
+	if a.historyManager == nil || a.originalFilePath == "" {
+		return
+	}
+
+	entries, err := a.historyManager.Load(a.viewStateFilename())
+	if err != nil {
+		log.Printf("Warning: Failed to load view state: %v\n", err)
+		return
+	}
+
+	// Entries have the form "expanded <item id>" or "selected <item id>"
+	selectedID := ""
+	for _, entry := range entries {
+		key, id, _ := strings.Cut(entry, " ")
+		switch key {
+		case "expanded":
+			if item := a.outline.FindItemByID(id); item != nil && len(item.Children) > 0 {
+				item.Expanded = true
+			}
+		case "selected":
+			selectedID = id
+		}
+	}
+	a.tree.RebuildView()
+	if selectedID != "" {
+		a.tree.SelectItemByID(selectedID)
+	}
+}
+
+// saveViewState persists the expanded items and the selection of the current
+// file. Backups opened read-only don't change the view state of the file.
+func (a *App) saveViewState() {
+	if a.historyManager == nil || a.originalFilePath == "" || !a.hasFile || a.readOnly {
+		return
+	}
+
+	var entries []string
+	var collect func(items []*model.Item)
+	collect = func(items []*model.Item) {
+		for _, item := range items {
+			if item.Expanded && len(item.Children) > 0 {
+				entries = append(entries, "expanded "+item.ID)
+				collect(item.Children)
+			}
+		}
+	}
+	collect(a.tree.GetItems())
+	if selected := a.tree.GetSelected(); selected != nil {
+		entries = append(entries, "selected "+selected.ID)
+	}
+	if err := a.historyManager.Save(a.viewStateFilename(), entries); err != nil {
+		log.Printf("Warning: Failed to save view state: %v\n", err)
+	}
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/pstuifzand/tui-outliner/internal/history"
+	"github.com/pstuifzand/tui-outliner/internal/model"
+	"github.com/pstuifzand/tui-outliner/internal/ui"
+)
+
+func TestViewStatePersist(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	manager, err := history.NewManager()
+	if err != nil {
+		t.Fatalf("Failed to create history manager: %v", err)
+	}
+
+	// newApp opens a fresh copy of the outline, like reopening the file
+	newApp := func() (*App, []*model.Item) {
+		parent := model.NewItem("Parent")
+		parent.ID = "parent"
+		child := model.NewItem("Child")
+		child.ID = "child"
+		grandchild := model.NewItem("Grandchild")
+		grandchild.ID = "grandchild"
+		child.AddChild(grandchild)
+		parent.AddChild(child)
+		other := model.NewItem("Other")
+		other.ID = "other"
+
+		outline := model.NewOutline()
+		outline.Items = []*model.Item{parent, other}
+		outline.BuildIndex()
+		return &App{
+			outline:          outline,
+			tree:             ui.NewTreeView(outline.Items),
+			historyManager:   manager,
+			hasFile:          true,
+			originalFilePath: "/tmp/view-test.json",
+		}, []*model.Item{parent, child, grandchild}
+	}
+
+	app, items := newApp()
+	items[0].Expanded = true
+	app.tree.RebuildView()
+	app.tree.SelectItemByID("child")
+	app.saveViewState()
+
+	reopened, items := newApp()
+	reopened.loadViewState()
+	if !items[0].Expanded || items[1].Expanded {
+		t.Errorf("Expected only Parent to be expanded, got %v and %v", items[0].Expanded, items[1].Expanded)
+	}
+	if selected := reopened.tree.GetSelected(); selected == nil || selected.ID != "child" {
+		t.Errorf("Expected Child to be selected, got %v", selected)
+	}
+
+	// A backup opened read-only keeps the view state of the file
+	reopened.readOnly = true
+	reopened.tree.SelectItemByID("other")
+	reopened.saveViewState()
+	again, _ := newApp()
+	again.loadViewState()
+	if selected := again.tree.GetSelected(); selected == nil || selected.ID != "child" {
+		t.Errorf("Expected Child to stay selected, got %v", selected)
+	}
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// KeyMapping binds a key to a named action, like "H hoist"
+type KeyMapping struct {
+	Key    string
+	Action string
+	Line   int // Line in the keys file, for warnings
+}
+
+// LoadKeyMappings loads the key mappings from the keys file in the config
+// directory. The warnings are about lines that were skipped.
+func LoadKeyMappings() (mappings []KeyMapping, warnings []error, err error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return LoadKeyMappingsFromFile(filepath.Join(configDir, "keys"))
+}
+
+// LoadKeyMappingsFromFile loads key mappings from a specific file.
+// A missing file results in no mappings.
+func LoadKeyMappingsFromFile(filePath string) (mappings []KeyMapping, warnings []error, err error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to read key mappings: %w", err)
+	}
+
+	mappings, warnings = ParseKeyMappings(string(data))
+	return mappings, warnings, nil
+}
+
+// ParseKeyMappings parses lines of a key and an action name separated by
+// whitespace. Empty lines and lines starting with # are skipped. Malformed
+// lines are skipped too, with a warning for each.
+func ParseKeyMappings(content string) (mappings []KeyMapping, warnings []error) {
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			warnings = append(warnings, fmt.Errorf("line %d: expected a key and an action, got %q", i+1, line))
+			continue
+		}
+		mappings = append(mappings, KeyMapping{Key: fields[0], Action: fields[1], Line: i + 1})
+	}
+	return mappings, warnings
+}
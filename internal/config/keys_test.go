@@ -0,0 +1,38 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadKeyMappings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys")
+
+	mappings, warnings, err := LoadKeyMappingsFromFile(path)
+	if err != nil || mappings != nil || warnings != nil {
+		t.Fatalf("Expected no mappings for a missing file, got %v, %v", mappings, err)
+	}
+
+	content := "# Hoist with H\nH hoist\n\n  gh   hoist  \n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mappings, warnings, err = LoadKeyMappingsFromFile(path)
+	if err != nil || warnings != nil {
+		t.Fatalf("Load failed: %v %v", err, warnings)
+	}
+	expected := []KeyMapping{{Key: "H", Action: "hoist", Line: 2}, {Key: "gh", Action: "hoist", Line: 4}}
+	if len(mappings) != len(expected) || mappings[0] != expected[0] || mappings[1] != expected[1] {
+		t.Errorf("Expected %v, got %v", expected, mappings)
+	}
+
+	// A malformed line is skipped, the other lines still map
+	mappings, warnings = ParseKeyMappings("H\nL hoist\nx y z\n")
+	if len(warnings) != 2 || warnings[0].Error() != `line 1: expected a key and an action, got "H"` {
+		t.Errorf("Expected warnings for lines 1 and 3, got %v", warnings)
+	}
+	if len(mappings) != 1 || mappings[0] != (KeyMapping{Key: "L", Action: "hoist", Line: 2}) {
+		t.Errorf("Expected only the mapping of line 2, got %v", mappings)
+	}
+}
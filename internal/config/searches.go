@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// SavedSearches holds named search queries persisted in the config directory
+type SavedSearches struct {
+	Searches map[string]string `toml:"searches"`
+
+	// Path of the file the searches are loaded from and saved to
+	path string
+}
+
+// LoadSavedSearches loads the saved searches from the standard location
+func LoadSavedSearches() (*SavedSearches, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadSavedSearchesFromFile(filepath.Join(configDir, "searches.toml"))
+}
+
+// LoadSavedSearchesFromFile loads saved searches from a specific file.
+// A missing file results in an empty set of searches.
+func LoadSavedSearchesFromFile(filePath string) (*SavedSearches, error) {
+	searches := &SavedSearches{
+		Searches: make(map[string]string),
+		path:     filePath,
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return searches, nil
+		}
+		return nil, fmt.Errorf("failed to read saved searches: %w", err)
+	}
+
+	if err := toml.Unmarshal(data, searches); err != nil {
+		return nil, fmt.Errorf("failed to parse saved searches: %w", err)
+	}
+
+	if searches.Searches == nil {
+		searches.Searches = make(map[string]string)
+	}
+
+	return searches, nil
+}
+
+// Get returns the query saved under name
+func (s *SavedSearches) Get(name string) (string, bool) {
+	query, ok := s.Searches[name]
+	return query, ok
+}
+
+// Set stores a query under name, replacing any existing query
+func (s *SavedSearches) Set(name, query string) {
+	s.Searches[name] = query
+}
+
+// Names returns the names of all saved searches in sorted order
+func (s *SavedSearches) Names() []string {
+	names := make([]string, 0, len(s.Searches))
+	for name := range s.Searches {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Save writes the saved searches back to their file
+func (s *SavedSearches) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+
+	data, err := toml.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.path, data, 0644)
+}
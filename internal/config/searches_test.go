@@ -0,0 +1,39 @@
+package config
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestSavedSearchesRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "searches.toml")
+
+	searches, err := LoadSavedSearchesFromFile(path)
+	if err != nil {
+		t.Fatalf("Expected missing file to load, got %v", err)
+	}
+	if len(searches.Names()) != 0 {
+		t.Fatalf("Expected no saved searches, got %v", searches.Names())
+	}
+
+	searches.Set("todo", "@status=todo")
+	searches.Set("inbox", "#inbox -#done")
+	if err := searches.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadSavedSearchesFromFile(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if names := loaded.Names(); !slices.Equal(names, []string{"inbox", "todo"}) {
+		t.Errorf("Expected sorted names [inbox todo], got %v", names)
+	}
+	if query, ok := loaded.Get("inbox"); !ok || query != "#inbox -#done" {
+		t.Errorf("Expected query '#inbox -#done', got %q (found=%v)", query, ok)
+	}
+	if _, ok := loaded.Get("missing"); ok {
+		t.Errorf("Expected missing search not to be found")
+	}
+}
@@ -0,0 +1,233 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pstuifzand/tui-outliner/internal/links"
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+// HTMLOptions controls the optional parts of the HTML export.
+// The zero value uses the default todo statuses.
+type HTMLOptions struct {
+	TodoStatuses []string // Todo status order, the last one counts as done (default: todo,doing,done)
+}
+
+// htmlStyle keeps the exported page readable without external files
+const htmlStyle = `body { font-family: sans-serif; max-width: 50em; margin: 2em auto; line-height: 1.5; }
+ul.outline, ul.outline ul { list-style: disc; padding-left: 1.5em; }
+ul.outline details > summary { cursor: pointer; }
+ul.outline li.header > details > summary, ul.outline li.header > span { font-weight: bold; }
+ul.outline li[data-status="done"] > span, ul.outline li[data-status="done"] > details > summary { text-decoration: line-through; }
+`
+
+// ExportToHTML exports an outline to a self-contained HTML file.
+// Items become nested lists, items with children are collapsible.
+func ExportToHTML(outline *model.Outline, filePath string) error {
+	return ExportToHTMLWithOptions(outline, filePath, HTMLOptions{})
+}
+
+// ExportToHTMLWithOptions exports an outline to a self-contained HTML file using opts.
+func ExportToHTMLWithOptions(outline *model.Outline, filePath string, opts HTMLOptions) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create html file: %w", err)
+	}
+	defer f.Close()
+
+	if err := ExportToHTMLWriterWithOptions(outline, f, opts); err != nil {
+		return fmt.Errorf("failed to write html file: %w", err)
+	}
+
+	return nil
+}
+
+// ExportToHTMLWriter exports an outline to HTML and writes to the given writer.
+func ExportToHTMLWriter(outline *model.Outline, w io.Writer) error {
+	return ExportToHTMLWriterWithOptions(outline, w, HTMLOptions{})
+}
+
+// ExportToHTMLWriterWithOptions exports an outline to HTML using opts and writes to the given writer.
+//
+// Every item is an <li> with its ID as anchor and its attributes as data-* attributes.
+// Items with children use <details>/<summary>, open when the item is expanded.
+// Todo items get a checkbox, and [[id]] links to exported items become in-page links.
+func ExportToHTMLWriterWithOptions(outline *model.Outline, w io.Writer, opts HTMLOptions) error {
+	bw := bufio.NewWriter(w)
+
+	title := outline.OriginalFilename
+	if title == "" {
+		title = "tuo outline"
+	}
+
+	if len(opts.TodoStatuses) == 0 {
+		opts.TodoStatuses = []string{"todo", "doing", "done"}
+	}
+
+	// Index the exported items, links to other items can't become anchors
+	exported := make(map[string]*model.Item)
+	for _, item := range outline.Items {
+		indexHTMLItems(item, exported)
+	}
+
+	bw.WriteString("<!DOCTYPE html>\n")
+	bw.WriteString("<html>\n")
+	bw.WriteString("<head>\n")
+	bw.WriteString("<meta charset=\"utf-8\">\n")
+	bw.WriteString("<title>" + html.EscapeString(title) + "</title>\n")
+	bw.WriteString("<style>\n" + htmlStyle + "</style>\n")
+	bw.WriteString("</head>\n")
+	bw.WriteString("<body>\n")
+	bw.WriteString("<ul class=\"outline\">\n")
+
+	for _, item := range outline.Items {
+		writeItemAsHTML(bw, item, 1, exported, &opts)
+	}
+
+	bw.WriteString("</ul>\n")
+	bw.WriteString("</body>\n")
+	bw.WriteString("</html>\n")
+
+	return bw.Flush()
+}
+
+// GenerateHTML generates HTML content from an outline as a string.
+func GenerateHTML(outline *model.Outline) string {
+	var sb strings.Builder
+	// Writing to a strings.Builder never fails
+	_ = ExportToHTMLWriter(outline, &sb)
+	return sb.String()
+}
+
+// indexHTMLItems adds item and its descendants to index by ID
+func indexHTMLItems(item *model.Item, index map[string]*model.Item) {
+	if item == nil {
+		return
+	}
+	index[item.ID] = item
+	for _, child := range item.Children {
+		indexHTMLItems(child, index)
+	}
+}
+
+// writeItemAsHTML recursively writes an item and its children as <li> elements.
+// Empty items are kept so the structure matches the outline.
+func writeItemAsHTML(w *bufio.Writer, item *model.Item, depth int, exported map[string]*model.Item, opts *HTMLOptions) {
+	if item == nil {
+		return
+	}
+
+	indent := strings.Repeat("  ", depth)
+	w.WriteString(indent)
+	w.WriteString("<li")
+	writeHTMLAttr(w, "id", item.ID)
+	if item.IsHeader() {
+		writeHTMLAttr(w, "class", "header")
+	}
+
+	if item.Metadata != nil && len(item.Metadata.Attributes) > 0 {
+		// Sort keys so the output is stable between exports
+		keys := make([]string, 0, len(item.Metadata.Attributes))
+		for key := range item.Metadata.Attributes {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if !isValidHTMLDataName(key) {
+				continue
+			}
+			writeHTMLAttr(w, "data-"+key, item.Metadata.Attributes[key])
+		}
+	}
+	w.WriteString(">")
+
+	content := htmlCheckbox(item, opts) + htmlItemText(item.Text, exported)
+
+	if len(item.Children) == 0 {
+		w.WriteString("<span>" + content + "</span></li>\n")
+		return
+	}
+
+	if item.Expanded {
+		w.WriteString("<details open>")
+	} else {
+		w.WriteString("<details>")
+	}
+	w.WriteString("<summary>" + content + "</summary>\n")
+	w.WriteString(indent + "  <ul>\n")
+	for _, child := range item.Children {
+		writeItemAsHTML(w, child, depth+2, exported, opts)
+	}
+	w.WriteString(indent + "  </ul>\n")
+	w.WriteString(indent + "</details></li>\n")
+}
+
+// htmlCheckbox returns a read-only checkbox for todo items, checked when the item is done
+func htmlCheckbox(item *model.Item, opts *HTMLOptions) string {
+	if item.Metadata == nil || item.Metadata.Attributes["type"] != "todo" {
+		return ""
+	}
+	if item.Metadata.Attributes["status"] == opts.TodoStatuses[len(opts.TodoStatuses)-1] {
+		return "<input type=\"checkbox\" disabled checked> "
+	}
+	return "<input type=\"checkbox\" disabled> "
+}
+
+// htmlItemText escapes text and rewrites [[id]] links to exported items as in-page
+// anchors. Links to items outside the export keep only their display text.
+func htmlItemText(text string, exported map[string]*model.Item) string {
+	var sb strings.Builder
+	last := 0
+	for _, link := range links.ParseLinks(text) {
+		sb.WriteString(html.EscapeString(text[last:link.StartPos]))
+		last = link.EndPos
+
+		target := exported[link.ID]
+		display := link.DisplayText
+		if display == "" && target != nil {
+			display = target.Text
+		}
+		if display == "" {
+			display = link.ID
+		}
+
+		if target == nil {
+			sb.WriteString(html.EscapeString(display))
+			continue
+		}
+		sb.WriteString("<a href=\"#" + html.EscapeString(link.ID) + "\">" + html.EscapeString(display) + "</a>")
+	}
+	sb.WriteString(html.EscapeString(text[last:]))
+	return sb.String()
+}
+
+// writeHTMLAttr writes a single escaped HTML attribute
+func writeHTMLAttr(w *bufio.Writer, name, value string) {
+	w.WriteString(" ")
+	w.WriteString(name)
+	w.WriteString("=\"")
+	w.WriteString(html.EscapeString(value))
+	w.WriteString("\"")
+}
+
+// isValidHTMLDataName checks if an attribute key can be used in a data-* attribute name
+func isValidHTMLDataName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
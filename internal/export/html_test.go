@@ -0,0 +1,79 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+func TestGenerateHTML(t *testing.T) {
+	outline := &model.Outline{
+		OriginalFilename: "notes <1>.json",
+		Items: []*model.Item{
+			{
+				ID:       "item_project",
+				Text:     "Project <b>bold</b> & co",
+				Expanded: true,
+				Metadata: &model.Metadata{Attributes: map[string]string{"type": "project", "Owner": "skipped"}},
+				Children: []*model.Item{
+					{
+						ID:       "item_done",
+						Text:     "Done task",
+						Metadata: &model.Metadata{Attributes: map[string]string{"type": "todo", "status": "done"}},
+					},
+					{
+						ID:       "item_open",
+						Text:     "See [[item_done]] and [[item_missing|elsewhere]]",
+						Metadata: &model.Metadata{Attributes: map[string]string{"type": "todo", "status": "todo"}},
+					},
+				},
+			},
+			{
+				ID:   "item_closed",
+				Text: "Collapsed",
+				Children: []*model.Item{
+					{ID: "item_hidden", Text: "Hidden \"quoted\""},
+				},
+			},
+		},
+	}
+
+	got := GenerateHTML(outline)
+
+	for _, want := range []string{
+		"<title>notes &lt;1&gt;.json</title>",
+		`<li id="item_project" data-type="project"><details open><summary>Project &lt;b&gt;bold&lt;/b&gt; &amp; co</summary>`,
+		`<li id="item_done" data-status="done" data-type="todo"><span><input type="checkbox" disabled checked> Done task</span></li>`,
+		`<span><input type="checkbox" disabled> See <a href="#item_done">Done task</a> and elsewhere</span>`,
+		`<li id="item_closed"><details><summary>Collapsed</summary>`,
+		`<span>Hidden &#34;quoted&#34;</span>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "data-Owner") || strings.Contains(got, "<b>") {
+		t.Errorf("Expected invalid data names and user markup to be left out, got:\n%s", got)
+	}
+}
+
+func TestGenerateHTMLCustomTodoStatuses(t *testing.T) {
+	outline := &model.Outline{
+		Items: []*model.Item{
+			{
+				ID:       "item_1",
+				Text:     "Shipped",
+				Metadata: &model.Metadata{Attributes: map[string]string{"type": "todo", "status": "shipped"}},
+			},
+		},
+	}
+
+	var sb strings.Builder
+	if err := ExportToHTMLWriterWithOptions(outline, &sb, HTMLOptions{TodoStatuses: []string{"open", "shipped"}}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if !strings.Contains(sb.String(), "disabled checked> Shipped") {
+		t.Errorf("Expected the last custom status to count as done, got:\n%s", sb.String())
+	}
+}
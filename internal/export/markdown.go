@@ -1,18 +1,37 @@
 package export
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/pstuifzand/tui-outliner/internal/model"
 )
 
+// MarkdownOptions controls the optional parts of the markdown export.
+// The zero value produces the plain export.
+type MarkdownOptions struct {
+	TodoCheckboxes bool     // Render type=todo items as "- [ ]" or "- [x]"
+	TodoStatuses   []string // Todo status order, the last one counts as done (default: todo,doing,done)
+	Attributes     []string // Attributes to append as an inline "(key: value)" suffix
+	Frontmatter    bool     // Emit YAML front matter from the first root item's attributes
+	IDs            bool     // Append a " {#id}" marker to each item, see import_parser.ImportOptions.KeepIDs
+}
+
 // ExportToMarkdown exports an outline to a markdown file with full markdown format.
 // Headers are exported as markdown headers (# ## ###), regular items as bullets.
 func ExportToMarkdown(outline *model.Outline, filePath string) error {
-	content := GenerateMarkdownWithHeaders(outline)
+	return ExportToMarkdownWithOptions(outline, filePath, MarkdownOptions{})
+}
+
+// ExportToMarkdownWithOptions exports an outline to a markdown file with headers,
+// adding the optional parts selected in opts.
+func ExportToMarkdownWithOptions(outline *model.Outline, filePath string, opts MarkdownOptions) error {
+	content := GenerateMarkdownWithOptions(outline, opts)
 
 	// Write to file
 	if err := os.WriteFile(filePath, []byte(content), 0o644); err != nil {
@@ -38,26 +57,139 @@ func ExportToMarkdownList(outline *model.Outline, filePath string) error {
 // ExportToMarkdownWriter exports an outline to markdown format and writes to the given writer.
 // Uses the full markdown format with headers.
 func ExportToMarkdownWriter(outline *model.Outline, w io.Writer) error {
-	content := GenerateMarkdownWithHeaders(outline)
-	_, err := w.Write([]byte(content))
+	return ExportToMarkdownWriterWithOptions(outline, w, MarkdownOptions{})
+}
+
+// ExportToMarkdownWriterWithOptions exports an outline to markdown with headers and
+// writes to the given writer, adding the optional parts selected in opts.
+func ExportToMarkdownWriterWithOptions(outline *model.Outline, w io.Writer, opts MarkdownOptions) error {
+	mw := NewMarkdownWriter(w, opts)
+	for _, item := range outline.Items {
+		if err := mw.WriteItem(item); err != nil {
+			return err
+		}
+	}
+	return mw.Flush()
+}
+
+// MarkdownWriter writes the root items of an outline as markdown one at a
+// time, so the outline doesn't have to be in memory as a whole. The output is
+// the same as GenerateMarkdownWithOptions.
+type MarkdownWriter struct {
+	w       *bufio.Writer
+	opts    MarkdownOptions
+	sb      strings.Builder
+	roots   int  // Number of root items written
+	started bool // Any content written, the leading newline is trimmed before that
+}
+
+// NewMarkdownWriter creates a MarkdownWriter that writes to w. Call Flush after
+// the last item.
+func NewMarkdownWriter(w io.Writer, opts MarkdownOptions) *MarkdownWriter {
+	return &MarkdownWriter{w: bufio.NewWriter(w), opts: opts}
+}
+
+// WriteItem writes a root item and its descendants
+func (mw *MarkdownWriter) WriteItem(item *model.Item) error {
+	if mw.roots == 0 && mw.opts.Frontmatter {
+		if _, err := mw.w.WriteString(generateFrontmatter(item)); err != nil {
+			return err
+		}
+	}
+	mw.roots++
+
+	mw.sb.Reset()
+	writeItemAsMarkdownWithHeaders(&mw.sb, item, 0, 1, &mw.opts)
+	content := mw.sb.String()
+	if !mw.started && content != "" {
+		// Trim leading newline if present (from first header)
+		content = strings.TrimPrefix(content, "\n")
+		mw.started = true
+	}
+	_, err := mw.w.WriteString(content)
 	return err
 }
 
+// Flush writes any buffered output to the underlying writer
+func (mw *MarkdownWriter) Flush() error {
+	return mw.w.Flush()
+}
+
+// ExportSubtreeToMarkdown exports a single item and its descendants to markdown
+// and writes to the given writer. The item is written as the top level of the document.
+func ExportSubtreeToMarkdown(item *model.Item, w io.Writer) error {
+	return ExportToMarkdownWriter(&model.Outline{Items: []*model.Item{item}}, w)
+}
+
 // GenerateMarkdownWithHeaders generates markdown content from an outline with headers as markdown headers.
 // Headers are exported as # ## ### etc., regular items as bullets.
 func GenerateMarkdownWithHeaders(outline *model.Outline) string {
+	return GenerateMarkdownWithOptions(outline, MarkdownOptions{})
+}
+
+// GenerateMarkdownWithOptions generates markdown content with headers, like
+// GenerateMarkdownWithHeaders, adding the optional parts selected in opts.
+func GenerateMarkdownWithOptions(outline *model.Outline, opts MarkdownOptions) string {
 	var sb strings.Builder
+	// Writing to a strings.Builder never fails
+	_ = ExportToMarkdownWriterWithOptions(outline, &sb, opts)
+	return sb.String()
+}
 
-	// TODO: When writing Hugo markdown, write the attributes from the root node
-	// as frontmatter yaml
+// generateFrontmatter renders the attributes of an item as YAML front matter.
+// Keys are sorted and values are always quoted so they stay plain strings.
+func generateFrontmatter(item *model.Item) string {
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	if item.Metadata != nil {
+		keys := make([]string, 0, len(item.Metadata.Attributes))
+		for key := range item.Metadata.Attributes {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			sb.WriteString(key)
+			sb.WriteString(": ")
+			sb.WriteString(strconv.Quote(item.Metadata.Attributes[key]))
+			sb.WriteString("\n")
+		}
+	}
+	sb.WriteString("---\n\n")
+	return sb.String()
+}
 
-	// Write all items as markdown with header support
-	for _, item := range outline.Items {
-		writeItemAsMarkdownWithHeaders(&sb, item, 0, 1)
+// markdownBullet returns the bullet for an item, a checkbox for todo items when enabled
+func markdownBullet(item *model.Item, opts *MarkdownOptions) string {
+	if !opts.TodoCheckboxes || item.Metadata == nil || item.Metadata.Attributes["type"] != "todo" {
+		return "- "
 	}
 
-	// Trim leading newline if present (from first header)
-	return strings.TrimPrefix(sb.String(), "\n")
+	statuses := opts.TodoStatuses
+	if len(statuses) == 0 {
+		statuses = []string{"todo", "doing", "done"}
+	}
+	if item.Metadata.Attributes["status"] == statuses[len(statuses)-1] {
+		return "- [x] "
+	}
+	return "- [ ] "
+}
+
+// markdownAttributeSuffix returns the " (key: value, ...)" suffix for the selected attributes
+func markdownAttributeSuffix(item *model.Item, opts *MarkdownOptions) string {
+	if len(opts.Attributes) == 0 || item.Metadata == nil {
+		return ""
+	}
+
+	var parts []string
+	for _, name := range opts.Attributes {
+		if value := item.Metadata.Attributes[name]; value != "" {
+			parts = append(parts, name+": "+value)
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
 }
 
 // GenerateMarkdownList generates markdown content from an outline as an unordered list.
@@ -86,7 +218,7 @@ func GenerateMarkdown(outline *model.Outline) string {
 // Headers are written as markdown headers (# ## ###), regular items as bullets.
 // depth determines the bullet indentation level (2 spaces per level).
 // headerLevel determines the header level (1 = #, 2 = ##, etc.).
-func writeItemAsMarkdownWithHeaders(sb *strings.Builder, item *model.Item, depth int, headerLevel int) {
+func writeItemAsMarkdownWithHeaders(sb *strings.Builder, item *model.Item, depth int, headerLevel int, opts *MarkdownOptions) {
 	if item == nil {
 		return
 	}
@@ -95,7 +227,7 @@ func writeItemAsMarkdownWithHeaders(sb *strings.Builder, item *model.Item, depth
 	if strings.TrimSpace(item.Text) == "" {
 		// Still process children even if this item is empty
 		for _, child := range item.Children {
-			writeItemAsMarkdownWithHeaders(sb, child, depth, headerLevel)
+			writeItemAsMarkdownWithHeaders(sb, child, depth, headerLevel, opts)
 		}
 		return
 	}
@@ -107,23 +239,31 @@ func writeItemAsMarkdownWithHeaders(sb *strings.Builder, item *model.Item, depth
 		sb.WriteString(strings.Repeat("#", headerLevel))
 		sb.WriteString(" ")
 		sb.WriteString(item.Text)
+		sb.WriteString(markdownAttributeSuffix(item, opts))
+		if opts.IDs {
+			sb.WriteString(idMarker(item))
+		}
 		sb.WriteString("\n\n")
 
 		// Write children with increased header level
 		for _, child := range item.Children {
-			writeItemAsMarkdownWithHeaders(sb, child, 0, headerLevel+1)
+			writeItemAsMarkdownWithHeaders(sb, child, 0, headerLevel+1, opts)
 		}
 	} else {
 		// Write as bullet with indentation (2 spaces per level)
 		indent := strings.Repeat("  ", depth)
 		sb.WriteString(indent)
-		sb.WriteString("- ")
+		sb.WriteString(markdownBullet(item, opts))
 		sb.WriteString(item.Text)
+		sb.WriteString(markdownAttributeSuffix(item, opts))
+		if opts.IDs {
+			sb.WriteString(idMarker(item))
+		}
 		sb.WriteString("\n")
 
 		// Write children with increased depth
 		for _, child := range item.Children {
-			writeItemAsMarkdownWithHeaders(sb, child, depth+1, headerLevel)
+			writeItemAsMarkdownWithHeaders(sb, child, depth+1, headerLevel, opts)
 		}
 	}
 }
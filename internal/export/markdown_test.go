@@ -1,6 +1,7 @@
 package export
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 	"testing"
@@ -114,3 +115,94 @@ func TestExportToMarkdownWithEmptyItems(t *testing.T) {
 		t.Errorf("Output mismatch.\nExpected:\n%s\n\nGot:\n%s", expectedContent, string(content))
 	}
 }
+
+func TestExportSubtreeToMarkdown(t *testing.T) {
+	project := model.NewItem("Project")
+	project.AddChild(model.NewItem("Step one"))
+	root := model.NewItem("Root")
+	root.AddChild(project)
+	root.AddChild(model.NewItem("Sibling"))
+
+	var buf bytes.Buffer
+	if err := ExportSubtreeToMarkdown(project, &buf); err != nil {
+		t.Fatalf("ExportSubtreeToMarkdown failed: %v", err)
+	}
+
+	expected := "- Project\n  - Step one\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestGenerateMarkdownWithOptions(t *testing.T) {
+	post := model.NewItem("Post")
+	post.Metadata.Attributes["title"] = "Hello: world"
+	done := model.NewItem("Write draft")
+	done.Metadata.Attributes["type"] = "todo"
+	done.Metadata.Attributes["status"] = "published"
+	done.Metadata.Attributes["priority"] = "high"
+	review := model.NewItem("Review")
+	review.Metadata.Attributes["type"] = "todo"
+	review.Metadata.Attributes["status"] = "done"
+	post.AddChild(done)
+	post.AddChild(review)
+	outline := &model.Outline{Items: []*model.Item{post}}
+
+	// The zero value keeps the plain export
+	if got := GenerateMarkdownWithOptions(outline, MarkdownOptions{}); got != GenerateMarkdownWithHeaders(outline) {
+		t.Errorf("Expected zero options to match the default export, got:\n%s", got)
+	}
+
+	got := GenerateMarkdownWithOptions(outline, MarkdownOptions{
+		TodoCheckboxes: true,
+		TodoStatuses:   []string{"todo", "done", "published"},
+		Attributes:     []string{"priority"},
+		Frontmatter:    true,
+	})
+	expected := `---
+title: "Hello: world"
+---
+
+- Post
+  - [x] Write draft (priority: high)
+  - [ ] Review
+`
+	if got != expected {
+		t.Errorf("Output mismatch.\nExpected:\n%s\n\nGot:\n%s", expected, got)
+	}
+}
+
+func TestMarkdownWriter(t *testing.T) {
+	empty := model.NewItem("")
+	empty.Metadata.Attributes["title"] = "Notes"
+	title := model.NewItem("Title")
+	title.Metadata.Attributes["type"] = "header"
+	title.AddChild(model.NewItem("Child"))
+	plain := model.NewItem("Plain")
+
+	var buf bytes.Buffer
+	mw := NewMarkdownWriter(&buf, MarkdownOptions{Frontmatter: true})
+	for _, item := range []*model.Item{empty, title, plain} {
+		if err := mw.WriteItem(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mw.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The front matter comes from the first root, the newline before the
+	// first header is trimmed even when the first root writes nothing
+	expected := `---
+title: "Notes"
+---
+
+# Title
+
+- Child
+- Plain
+`
+	if buf.String() != expected {
+		t.Errorf("Output mismatch.\nExpected:\n%s\n\nGot:\n%s", expected, buf.String())
+	}
+}
@@ -0,0 +1,140 @@
+package export
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+// ExportToOPML exports an outline to an OPML 2.0 file.
+// Every item becomes an <outline> element, attributes are kept as extra XML attributes.
+func ExportToOPML(outline *model.Outline, filePath string) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create opml file: %w", err)
+	}
+	defer f.Close()
+
+	if err := ExportToOPMLWriter(outline, f); err != nil {
+		return fmt.Errorf("failed to write opml file: %w", err)
+	}
+
+	return nil
+}
+
+// ExportToOPMLWriter exports an outline to OPML format and writes to the given writer.
+func ExportToOPMLWriter(outline *model.Outline, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	title := outline.OriginalFilename
+	if title == "" {
+		title = "tuo outline"
+	}
+
+	bw.WriteString(xml.Header)
+	bw.WriteString("<opml version=\"2.0\">\n")
+	bw.WriteString("  <head>\n")
+	bw.WriteString("    <title>")
+	xml.EscapeText(bw, []byte(title))
+	bw.WriteString("</title>\n")
+	bw.WriteString("  </head>\n")
+	bw.WriteString("  <body>\n")
+
+	for _, item := range outline.Items {
+		writeItemAsOPML(bw, item, 2)
+	}
+
+	bw.WriteString("  </body>\n")
+	bw.WriteString("</opml>\n")
+
+	return bw.Flush()
+}
+
+// GenerateOPML generates OPML content from an outline as a string.
+func GenerateOPML(outline *model.Outline) string {
+	var sb strings.Builder
+	// Writing to a strings.Builder never fails
+	_ = ExportToOPMLWriter(outline, &sb)
+	return sb.String()
+}
+
+// writeItemAsOPML recursively writes an item and its children as <outline> elements.
+// Empty items are kept (unlike the markdown export) so the structure round-trips.
+func writeItemAsOPML(w *bufio.Writer, item *model.Item, depth int) {
+	if item == nil {
+		return
+	}
+
+	indent := strings.Repeat("  ", depth)
+	w.WriteString(indent)
+	w.WriteString("<outline")
+	writeOPMLAttr(w, "text", item.Text)
+
+	// OmniOutliner uses _expanded to remember the collapsed state
+	if len(item.Children) > 0 {
+		if item.Expanded {
+			writeOPMLAttr(w, "_expanded", "true")
+		} else {
+			writeOPMLAttr(w, "_expanded", "false")
+		}
+	}
+
+	if item.Metadata != nil && len(item.Metadata.Attributes) > 0 {
+		// Sort keys so the output is stable between exports
+		keys := make([]string, 0, len(item.Metadata.Attributes))
+		for key := range item.Metadata.Attributes {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if key == "text" || key == "_expanded" || !isValidOPMLAttrName(key) {
+				continue
+			}
+			writeOPMLAttr(w, key, item.Metadata.Attributes[key])
+		}
+	}
+
+	if len(item.Children) == 0 {
+		w.WriteString("/>\n")
+		return
+	}
+
+	w.WriteString(">\n")
+	for _, child := range item.Children {
+		writeItemAsOPML(w, child, depth+1)
+	}
+	w.WriteString(indent)
+	w.WriteString("</outline>\n")
+}
+
+// writeOPMLAttr writes a single escaped XML attribute
+func writeOPMLAttr(w *bufio.Writer, name, value string) {
+	w.WriteString(" ")
+	w.WriteString(name)
+	w.WriteString("=\"")
+	xml.EscapeText(w, []byte(value))
+	w.WriteString("\"")
+}
+
+// isValidOPMLAttrName checks if an attribute key can be used as an XML attribute name
+func isValidOPMLAttrName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+		case i > 0 && (r >= '0' && r <= '9' || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,135 @@
+package export
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+// opmlNode mirrors an <outline> element for round-trip checks
+type opmlNode struct {
+	Text     string     `xml:"text,attr"`
+	Expanded string     `xml:"_expanded,attr"`
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Children []opmlNode `xml:"outline"`
+}
+
+type opmlDoc struct {
+	Title string     `xml:"head>title"`
+	Body  []opmlNode `xml:"body>outline"`
+}
+
+func TestExportToOPML(t *testing.T) {
+	outline := &model.Outline{
+		Items: []*model.Item{
+			{
+				ID:       "1",
+				Text:     "First Item",
+				Expanded: true,
+				Children: []*model.Item{
+					{
+						ID:       "1.1",
+						Text:     "", // Empty item must survive
+						Children: []*model.Item{},
+					},
+					{
+						ID:       "1.2",
+						Text:     "Nested <Item> & \"quotes\"",
+						Expanded: false,
+						Children: []*model.Item{
+							{
+								ID:       "1.2.1",
+								Text:     "Deep Item",
+								Children: []*model.Item{},
+							},
+						},
+					},
+				},
+			},
+			{
+				ID:   "2",
+				Text: "Second Item",
+				Metadata: &model.Metadata{
+					Attributes: map[string]string{
+						"status": "done",
+						"type":   "task",
+					},
+				},
+				Children: []*model.Item{},
+			},
+		},
+	}
+
+	tempDir := t.TempDir()
+	outputFile := filepath.Join(tempDir, "test_output.opml")
+
+	if err := ExportToOPML(outline, outputFile); err != nil {
+		t.Fatalf("ExportToOPML failed: %v", err)
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+
+	var doc opmlDoc
+	if err := xml.Unmarshal(content, &doc); err != nil {
+		t.Fatalf("Output is not valid XML: %v\n%s", err, content)
+	}
+
+	if len(doc.Body) != 2 {
+		t.Fatalf("Expected 2 top-level outlines, got %d", len(doc.Body))
+	}
+
+	first := doc.Body[0]
+	if first.Text != "First Item" || first.Expanded != "true" {
+		t.Errorf("Unexpected first outline: text=%q _expanded=%q", first.Text, first.Expanded)
+	}
+	if len(first.Children) != 2 {
+		t.Fatalf("Expected 2 children, got %d", len(first.Children))
+	}
+	if first.Children[0].Text != "" {
+		t.Errorf("Expected empty item to be kept, got %q", first.Children[0].Text)
+	}
+	nested := first.Children[1]
+	if nested.Text != "Nested <Item> & \"quotes\"" {
+		t.Errorf("Text not escaped correctly, got %q", nested.Text)
+	}
+	if nested.Expanded != "false" {
+		t.Errorf("Expected collapsed item to have _expanded=false, got %q", nested.Expanded)
+	}
+	if len(nested.Children) != 1 || nested.Children[0].Text != "Deep Item" {
+		t.Errorf("Deep item not exported correctly: %+v", nested.Children)
+	}
+
+	second := doc.Body[1]
+	if second.Expanded != "" {
+		t.Errorf("Leaf item should not have _expanded, got %q", second.Expanded)
+	}
+	attrs := make(map[string]string)
+	for _, attr := range second.Attrs {
+		attrs[attr.Name.Local] = attr.Value
+	}
+	if attrs["status"] != "done" || attrs["type"] != "task" {
+		t.Errorf("Attributes not exported, got %v", attrs)
+	}
+}
+
+func TestGenerateOPMLTitle(t *testing.T) {
+	outline := &model.Outline{
+		OriginalFilename: "notes.json",
+		Items:            []*model.Item{},
+	}
+
+	output := GenerateOPML(outline)
+	if !strings.HasPrefix(output, xml.Header) {
+		t.Errorf("Expected output to start with XML header, got:\n%s", output)
+	}
+	if !strings.Contains(output, "<title>notes.json</title>") {
+		t.Errorf("Expected title in head, got:\n%s", output)
+	}
+}
@@ -0,0 +1,218 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+// OrgOptions controls the optional parts of the Org export.
+// The zero value uses the default todo statuses.
+type OrgOptions struct {
+	TodoStatuses []string // Todo status order, the last one counts as done (default: todo,doing,done)
+}
+
+// ExportToOrg exports an outline to an Org file.
+// Items become headings, the status of todo items becomes a TODO keyword and
+// the other attributes are kept in a :PROPERTIES: drawer.
+func ExportToOrg(outline *model.Outline, filePath string) error {
+	return ExportToOrgWithOptions(outline, filePath, OrgOptions{})
+}
+
+// ExportToOrgWithOptions exports an outline to an Org file using opts.
+func ExportToOrgWithOptions(outline *model.Outline, filePath string, opts OrgOptions) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create org file: %w", err)
+	}
+	defer f.Close()
+
+	if err := ExportToOrgWriterWithOptions(outline, f, opts); err != nil {
+		return fmt.Errorf("failed to write org file: %w", err)
+	}
+
+	return nil
+}
+
+// ExportToOrgWriter exports an outline to Org format and writes to the given writer.
+func ExportToOrgWriter(outline *model.Outline, w io.Writer) error {
+	return ExportToOrgWriterWithOptions(outline, w, OrgOptions{})
+}
+
+// ExportToOrgWriterWithOptions exports an outline to Org format using opts and writes to the given writer.
+//
+// A #+TODO line declares the todo statuses as keywords, so org-mode cycles
+// through the same statuses as tuo. The status of type=todo items becomes the
+// heading keyword, tags become heading tags and the first line of the item text
+// is the heading, further lines are the body.
+func ExportToOrgWriterWithOptions(outline *model.Outline, w io.Writer, opts OrgOptions) error {
+	bw := bufio.NewWriter(w)
+
+	if len(opts.TodoStatuses) == 0 {
+		opts.TodoStatuses = []string{"todo", "doing", "done"}
+	}
+
+	if keywords := orgTodoKeywords(outline, opts.TodoStatuses); keywords != "" {
+		bw.WriteString("#+TODO: " + keywords + "\n\n")
+	}
+
+	for _, item := range outline.Items {
+		writeItemAsOrg(bw, item, 1)
+	}
+
+	return bw.Flush()
+}
+
+// GenerateOrg generates Org content from an outline as a string.
+func GenerateOrg(outline *model.Outline) string {
+	var sb strings.Builder
+	// Writing to a strings.Builder never fails
+	_ = ExportToOrgWriter(outline, &sb)
+	return sb.String()
+}
+
+// orgTodoKeywords returns the keywords for the #+TODO line, or "" when the
+// outline has no todo items. Statuses used in the outline that are not in
+// statuses are added as open keywords, the last status is the done keyword.
+func orgTodoKeywords(outline *model.Outline, statuses []string) string {
+	var used []string
+	var collect func(items []*model.Item)
+	collect = func(items []*model.Item) {
+		for _, item := range items {
+			if keyword := orgKeyword(item); keyword != "" && !slices.Contains(used, keyword) {
+				used = append(used, keyword)
+			}
+			collect(item.Children)
+		}
+	}
+	collect(outline.Items)
+	if len(used) == 0 {
+		return ""
+	}
+
+	var open []string
+	for _, status := range statuses[:len(statuses)-1] {
+		open = append(open, strings.ToUpper(status))
+	}
+	done := strings.ToUpper(statuses[len(statuses)-1])
+	for _, keyword := range used {
+		if keyword != done && !slices.Contains(open, keyword) {
+			open = append(open, keyword)
+		}
+	}
+
+	return strings.Join(open, " ") + " | " + done
+}
+
+// orgKeyword returns the heading keyword for the status of a todo item, or ""
+// when the status can't be written as a keyword and stays in the drawer
+func orgKeyword(item *model.Item) string {
+	if item.Metadata == nil || item.Metadata.Attributes["type"] != "todo" {
+		return ""
+	}
+	status := item.Metadata.Attributes["status"]
+	if status == "" || strings.ContainsAny(status, " \t") || strings.ToLower(status) != status {
+		return ""
+	}
+	return strings.ToUpper(status)
+}
+
+// writeItemAsOrg recursively writes an item and its children as Org headings
+func writeItemAsOrg(w *bufio.Writer, item *model.Item, depth int) {
+	if item == nil {
+		return
+	}
+
+	lines := strings.Split(item.Text, "\n")
+	keyword := orgKeyword(item)
+
+	w.WriteString(strings.Repeat("*", depth))
+	if keyword != "" {
+		w.WriteString(" " + keyword)
+	}
+	if lines[0] != "" {
+		w.WriteString(" " + lines[0])
+	}
+	if tags := orgTags(item); tags != "" {
+		w.WriteString(" " + tags)
+	}
+	w.WriteString("\n")
+
+	if item.Metadata != nil && len(item.Metadata.Attributes) > 0 {
+		// Sort keys so the output is stable between exports
+		keys := make([]string, 0, len(item.Metadata.Attributes))
+		for key := range item.Metadata.Attributes {
+			if key == "status" && keyword != "" {
+				continue
+			}
+			if !IsValidOrgProperty(key) {
+				continue
+			}
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		if len(keys) > 0 {
+			w.WriteString(":PROPERTIES:\n")
+			for _, key := range keys {
+				// Drawer values are single lines
+				value := strings.ReplaceAll(item.Metadata.Attributes[key], "\n", " ")
+				w.WriteString(":" + key + ": " + value + "\n")
+			}
+			w.WriteString(":END:\n")
+		}
+	}
+
+	for _, line := range lines[1:] {
+		// Keep body lines from being read as headings
+		if strings.HasPrefix(line, "*") {
+			line = " " + line
+		}
+		w.WriteString(line + "\n")
+	}
+
+	for _, child := range item.Children {
+		writeItemAsOrg(w, child, depth+1)
+	}
+}
+
+// orgTags returns the tags of an item as ":a:b:", skipping tags org can't represent
+func orgTags(item *model.Item) string {
+	var tags []string
+	for _, tag := range item.GetTags() {
+		if IsValidOrgTag(tag) {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) == 0 {
+		return ""
+	}
+	return ":" + strings.Join(tags, ":") + ":"
+}
+
+// IsValidOrgProperty checks if an attribute key can be written as an Org property
+func IsValidOrgProperty(key string) bool {
+	return key != "" && !strings.ContainsAny(key, " \t:")
+}
+
+// IsValidOrgTag checks if a tag only uses the characters org-mode allows in tags
+func IsValidOrgTag(tag string) bool {
+	if tag == "" {
+		return false
+	}
+	for _, r := range tag {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '_', r == '@', r == '#', r == '%':
+		default:
+			return false
+		}
+	}
+	return true
+}
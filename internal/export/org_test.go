@@ -0,0 +1,100 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	import_parser "github.com/pstuifzand/tui-outliner/internal/import"
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+func TestGenerateOrg(t *testing.T) {
+	outline := &model.Outline{
+		Items: []*model.Item{
+			{
+				ID:   "1",
+				Text: "Project",
+				Metadata: &model.Metadata{
+					Attributes: map[string]string{"type": "project", "owner": "me"},
+					Tags:       []string{"work", "not valid"},
+				},
+				Children: []*model.Item{
+					{
+						ID:       "1.1",
+						Text:     "Write report\nFirst draft\n* not a heading",
+						Metadata: &model.Metadata{Attributes: map[string]string{"type": "todo", "status": "doing"}},
+					},
+					{
+						ID:       "1.2",
+						Text:     "Ship it",
+						Metadata: &model.Metadata{Attributes: map[string]string{"type": "todo", "status": "done"}},
+					},
+				},
+			},
+			{ID: "2", Text: "Plain"},
+		},
+	}
+
+	want := `#+TODO: TODO DOING | DONE
+
+* Project :work:
+:PROPERTIES:
+:owner: me
+:type: project
+:END:
+** DOING Write report
+:PROPERTIES:
+:type: todo
+:END:
+First draft
+ * not a heading
+** DONE Ship it
+:PROPERTIES:
+:type: todo
+:END:
+* Plain
+`
+	if got := GenerateOrg(outline); got != want {
+		t.Errorf("Unexpected org output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestGenerateOrgWithoutTodos(t *testing.T) {
+	outline := &model.Outline{Items: []*model.Item{{ID: "1", Text: "Notes"}}}
+	if got := GenerateOrg(outline); strings.Contains(got, "#+TODO") {
+		t.Errorf("Expected no #+TODO line without todo items, got:\n%s", got)
+	}
+}
+
+func TestOrgRoundTrip(t *testing.T) {
+	todo := model.NewItem("Task with TODO in the text")
+	todo.Metadata.Attributes["type"] = "todo"
+	todo.Metadata.Attributes["status"] = "waiting"
+	todo.Metadata.Attributes["due"] = "2024-05-01"
+	todo.AddTag("urgent")
+	project := model.NewItem("Project")
+	project.Metadata.Attributes["type"] = "project"
+	project.AddChild(todo)
+
+	content := GenerateOrg(&model.Outline{Items: []*model.Item{project}})
+	items, err := import_parser.ImportFile(content, import_parser.FormatOrg)
+	if err != nil {
+		t.Fatalf("ImportFile failed: %v", err)
+	}
+
+	if len(items) != 1 || len(items[0].Children) != 1 {
+		t.Fatalf("Expected the project with one child, got:\n%s", content)
+	}
+	if items[0].Metadata.Attributes["type"] != "project" {
+		t.Errorf("Expected type=project, got %v", items[0].Metadata.Attributes)
+	}
+	got := items[0].Children[0]
+	if got.Text != todo.Text || !got.HasTag("urgent") {
+		t.Errorf("Expected text and tag to survive, got %q %v", got.Text, got.GetTags())
+	}
+	for key, value := range todo.Metadata.Attributes {
+		if got.Metadata.Attributes[key] != value {
+			t.Errorf("Expected attribute %s=%s, got %q", key, value, got.Metadata.Attributes[key])
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+// textIndent is the indentation of one level in the indented text export
+const textIndent = "  "
+
+// TextOptions selects the optional parts of the indented text export
+type TextOptions struct {
+	IDs bool // Append a " {#id}" marker to each item, see import_parser.ImportOptions.KeepIDs
+}
+
+// ExportToText exports an outline to a plain text file with each item on its
+// own line, indented by two spaces per level.
+func ExportToText(outline *model.Outline, filePath string) error {
+	return ExportToTextWithOptions(outline, filePath, TextOptions{})
+}
+
+// ExportToTextWithOptions exports an outline to a plain text file like
+// ExportToText, adding the optional parts selected in opts.
+func ExportToTextWithOptions(outline *model.Outline, filePath string, opts TextOptions) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create text file: %w", err)
+	}
+	defer f.Close()
+
+	if err := ExportToTextWriterWithOptions(outline, f, opts); err != nil {
+		return fmt.Errorf("failed to write text file: %w", err)
+	}
+
+	return nil
+}
+
+// ExportToTextWriter exports an outline as indented plain text and writes to the given writer.
+func ExportToTextWriter(outline *model.Outline, w io.Writer) error {
+	return ExportToTextWriterWithOptions(outline, w, TextOptions{})
+}
+
+// ExportToTextWriterWithOptions exports an outline as indented plain text and
+// writes to the given writer, adding the optional parts selected in opts.
+func ExportToTextWriterWithOptions(outline *model.Outline, w io.Writer, opts TextOptions) error {
+	bw := bufio.NewWriter(w)
+	for _, item := range outline.Items {
+		writeItemAsText(bw, item, 0, &opts)
+	}
+	return bw.Flush()
+}
+
+// ExportToIndentedText exports item and all its descendants as indented plain
+// text, regardless of whether they are collapsed. The item is written without
+// indentation.
+//
+// This is the inverse of the indented text importer: importing the output
+// gives the same texts at the same depths. Lines of multi-line items are
+// joined with spaces, and metadata is not exported.
+func ExportToIndentedText(item *model.Item, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	writeItemAsText(bw, item, 0, &TextOptions{})
+	return bw.Flush()
+}
+
+// GenerateText generates indented plain text from an outline as a string.
+func GenerateText(outline *model.Outline) string {
+	var sb strings.Builder
+	// Writing to a strings.Builder never fails
+	_ = ExportToTextWriter(outline, &sb)
+	return sb.String()
+}
+
+// writeItemAsText writes item at depth and its children one level deeper. An
+// item without text has no line, its children take its place at depth.
+func writeItemAsText(w *bufio.Writer, item *model.Item, depth int, opts *TextOptions) {
+	childDepth := depth
+	if text := textLine(item.Text); text != "" {
+		w.WriteString(strings.Repeat(textIndent, depth))
+		w.WriteString(text)
+		if opts.IDs {
+			w.WriteString(idMarker(item))
+		}
+		w.WriteString("\n")
+		childDepth++
+	}
+
+	for _, child := range item.Children {
+		writeItemAsText(w, child, childDepth, opts)
+	}
+}
+
+// idMarker returns the " {#id}" marker that keeps the ID of item in a text
+// format. The importers read it back when keeping IDs.
+func idMarker(item *model.Item) string {
+	return " {#" + item.ID + "}"
+}
+
+// textLine joins the lines of text with spaces and trims the surrounding
+// whitespace, which the importer would drop
+func textLine(text string) string {
+	var parts []string
+	for line := range strings.SplitSeq(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			parts = append(parts, line)
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,158 @@
+package export
+
+import (
+	"maps"
+	"strings"
+	"testing"
+
+	import_parser "github.com/pstuifzand/tui-outliner/internal/import"
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+func TestExportToIndentedText(t *testing.T) {
+	project := &model.Item{
+		ID:   "1",
+		Text: "Project",
+		Metadata: &model.Metadata{
+			Attributes: map[string]string{"type": "project"},
+		},
+		Children: []*model.Item{
+			{
+				ID:   "1.1",
+				Text: "Write report\n  first draft",
+				Children: []*model.Item{
+					{ID: "1.1.1", Text: "Outline  sections"},
+				},
+			},
+			{ID: "1.2", Text: "Ship it"},
+		},
+	}
+
+	var sb strings.Builder
+	if err := ExportToIndentedText(project, &sb); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	want := "Project\n  Write report first draft\n    Outline  sections\n  Ship it\n"
+	if sb.String() != want {
+		t.Errorf("Unexpected text export:\n%s\nwant:\n%s", sb.String(), want)
+	}
+}
+
+func TestTextRoundTrip(t *testing.T) {
+	outline := &model.Outline{
+		Items: []*model.Item{
+			{Text: "One", Children: []*model.Item{
+				{Text: "One.a", Children: []*model.Item{{Text: "One.a.i"}}},
+				{Text: "One.b"},
+			}},
+			{Text: "Two"},
+		},
+	}
+
+	content := GenerateText(outline)
+	items, err := import_parser.ImportFile(content, import_parser.FormatIndentedText)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	if got := GenerateText(&model.Outline{Items: items}); got != content {
+		t.Errorf("Round trip changed the outline:\n%s\nwant:\n%s", got, content)
+	}
+	if len(items) != 2 || len(items[0].Children) != 2 || items[0].Children[0].Children[0].Text != "One.a.i" {
+		t.Errorf("Unexpected imported structure")
+	}
+}
+
+func TestTextSkipsEmptyItems(t *testing.T) {
+	outline := &model.Outline{
+		Items: []*model.Item{
+			{Text: "One", Children: []*model.Item{
+				{Text: "  ", Children: []*model.Item{{Text: "Under empty"}}},
+				{Text: "One.b"},
+			}},
+			{Text: "", Children: []*model.Item{{Text: "Root child"}}},
+		},
+	}
+
+	// The children of an empty item take its place
+	want := "One\n  Under empty\n  One.b\nRoot child\n"
+	content := GenerateText(outline)
+	if content != want {
+		t.Fatalf("Unexpected text export:\n%s\nwant:\n%s", content, want)
+	}
+
+	items, err := import_parser.ImportFile(content, import_parser.FormatIndentedText)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if len(items) != 2 || len(items[0].Children) != 2 || items[0].Children[0].Text != "Under empty" {
+		t.Errorf("Expected the children of the empty item under One")
+	}
+}
+
+func TestExportIDsRoundTrip(t *testing.T) {
+	header := &model.Item{
+		ID:       "h1",
+		Text:     "Project",
+		Metadata: &model.Metadata{Attributes: map[string]string{"type": "header"}},
+		Children: []*model.Item{
+			{ID: "item_2", Text: "See [[item_3|the notes]]", Children: []*model.Item{
+				{ID: "item_3", Text: "Notes {#not-at-the-end} here"},
+			}},
+		},
+	}
+	outline := &model.Outline{Items: []*model.Item{header, {ID: "item_4", Text: "Done"}}}
+
+	var text strings.Builder
+	if err := ExportToTextWriterWithOptions(outline, &text, TextOptions{IDs: true}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	want := "Project {#h1}\n  See [[item_3|the notes]] {#item_2}\n    Notes {#not-at-the-end} here {#item_3}\nDone {#item_4}\n"
+	if text.String() != want {
+		t.Errorf("Unexpected text export:\n%s\nwant:\n%s", text.String(), want)
+	}
+
+	markdown := GenerateMarkdownWithOptions(outline, MarkdownOptions{IDs: true})
+	if want := "# Project {#h1}\n\n- See [[item_3|the notes]] {#item_2}\n  - Notes {#not-at-the-end} here {#item_3}\n- Done {#item_4}\n"; markdown != want {
+		t.Errorf("Unexpected markdown export:\n%s\nwant:\n%s", markdown, want)
+	}
+
+	for _, tt := range []struct {
+		format  import_parser.ImportFormat
+		content string
+	}{
+		{import_parser.FormatIndentedText, text.String()},
+		{import_parser.FormatMarkdown, markdown},
+	} {
+		items, err := import_parser.ImportFileWithOptions(tt.content, import_parser.ImportOptions{Format: tt.format, KeepIDs: true})
+		if err != nil {
+			t.Fatalf("Import %s failed: %v", tt.format, err)
+		}
+		// Markdown puts the list after a header under the header, so only
+		// compare the IDs and texts
+		got := (&model.Outline{Items: items}).GetAllItems()
+		texts := make(map[string]string)
+		for _, item := range got {
+			texts[item.ID] = item.Text
+		}
+		want := map[string]string{
+			"h1":     "Project",
+			"item_2": "See [[item_3|the notes]]",
+			"item_3": "Notes {#not-at-the-end} here",
+			"item_4": "Done",
+		}
+		if !maps.Equal(texts, want) {
+			t.Errorf("Import %s gave items %v, want %v", tt.format, texts, want)
+		}
+
+		// Without KeepIDs the markers are text, and the items get new IDs
+		items, err = import_parser.ImportFileWithOptions(tt.content, import_parser.ImportOptions{Format: tt.format})
+		if err != nil {
+			t.Fatalf("Import %s failed: %v", tt.format, err)
+		}
+		if items[0].ID == "h1" || !strings.HasSuffix(items[0].Text, " {#h1}") {
+			t.Errorf("Import %s without KeepIDs gave %s %q", tt.format, items[0].ID, items[0].Text)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package import_parser
+
+import (
+	"encoding/csv"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+// CSV hierarchy modes for CSVParser.Hierarchy
+const (
+	CSVHierarchyNone   = ""       // Every row is a root item
+	CSVHierarchyDepth  = "depth"  // A depth column gives the nesting level, 0 is the root level
+	CSVHierarchyParent = "parent" // A parent column names the parent row by its id column, or by its title
+)
+
+// CSVParser imports CSV files with a header row, like task lists exported from other tools.
+// The title column becomes the item text and the other columns become attributes.
+type CSVParser struct {
+	TitleColumn string // Column with the item text, default: title, text, name, or the first column
+	Hierarchy   string // One of the CSVHierarchy modes
+}
+
+func (p *CSVParser) Name() string {
+	return "CSV"
+}
+
+// Parse converts CSV content to outline items. Rows with an empty title are skipped.
+func (p *CSVParser) Parse(content string) ([]*model.Item, error) {
+	reader := csv.NewReader(strings.NewReader(content))
+	reader.FieldsPerRecord = -1 // Allow short rows, missing fields are empty
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := make([]string, len(records[0]))
+	for i, column := range records[0] {
+		header[i] = csvAttributeName(column)
+	}
+
+	titleIdx, err := p.titleColumn(header)
+	if err != nil {
+		return nil, err
+	}
+
+	hierarchyIdx := -1
+	switch p.Hierarchy {
+	case CSVHierarchyNone:
+	case CSVHierarchyDepth, CSVHierarchyParent:
+		hierarchyIdx = slices.Index(header, p.Hierarchy)
+		if hierarchyIdx < 0 {
+			return nil, fmt.Errorf("no %s column in CSV header", p.Hierarchy)
+		}
+	default:
+		return nil, fmt.Errorf("unknown CSV hierarchy: %s (use depth or parent)", p.Hierarchy)
+	}
+	idIdx := slices.Index(header, "id")
+
+	var rootItems []*model.Item
+	var stack []*model.Item               // Depth mode: the last item at each depth
+	byKey := make(map[string]*model.Item) // Parent mode: items by id, or by title without an id column
+
+	for line, record := range records[1:] {
+		title := strings.TrimSpace(csvField(record, titleIdx))
+		if title == "" {
+			continue
+		}
+
+		item := model.NewItem(title)
+		for i, name := range header {
+			value := strings.TrimSpace(csvField(record, i))
+			if i == titleIdx || i == hierarchyIdx || name == "" || value == "" {
+				continue
+			}
+			item.Metadata.Attributes[name] = value
+		}
+
+		var parent *model.Item
+		switch p.Hierarchy {
+		case CSVHierarchyDepth:
+			depth := 0
+			if value := strings.TrimSpace(csvField(record, hierarchyIdx)); value != "" {
+				depth, err = strconv.Atoi(value)
+				if err != nil || depth < 0 {
+					return nil, fmt.Errorf("line %d: invalid depth %q", line+2, value)
+				}
+			}
+			// A depth deeper than the previous row nests one level below it
+			depth = min(depth, len(stack))
+			stack = append(stack[:depth], item)
+			if depth > 0 {
+				parent = stack[depth-1]
+			}
+		case CSVHierarchyParent:
+			if key := strings.TrimSpace(csvField(record, hierarchyIdx)); key != "" {
+				parent = byKey[key]
+				if parent == nil {
+					return nil, fmt.Errorf("line %d: parent not found: %s", line+2, key)
+				}
+			}
+			key := title
+			if idIdx >= 0 {
+				key = strings.TrimSpace(csvField(record, idIdx))
+			}
+			if key != "" {
+				byKey[key] = item
+			}
+		}
+
+		if parent == nil {
+			rootItems = append(rootItems, item)
+		} else {
+			parent.AddChild(item)
+		}
+	}
+
+	return rootItems, nil
+}
+
+// titleColumn returns the index of the title column in header
+func (p *CSVParser) titleColumn(header []string) (int, error) {
+	if p.TitleColumn != "" {
+		idx := slices.Index(header, csvAttributeName(p.TitleColumn))
+		if idx < 0 {
+			return -1, fmt.Errorf("no %s column in CSV header", p.TitleColumn)
+		}
+		return idx, nil
+	}
+
+	for _, name := range []string{"title", "text", "name"} {
+		if idx := slices.Index(header, name); idx >= 0 {
+			return idx, nil
+		}
+	}
+	return 0, nil
+}
+
+// csvField returns field i of record, or "" for short rows
+func csvField(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+	return record[i]
+}
+
+// csvAttributeName turns a column header into an attribute name, "Due Date" becomes "due_date"
+func csvAttributeName(column string) string {
+	column = strings.TrimPrefix(column, "\ufeff") // Byte order mark written by spreadsheets
+	return strings.ToLower(strings.Join(strings.Fields(column), "_"))
+}
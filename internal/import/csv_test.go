@@ -0,0 +1,84 @@
+package import_parser
+
+import (
+	"testing"
+)
+
+func TestCSVParserFlat(t *testing.T) {
+	content := "Task,Due Date,Notes\n" +
+		"\"Buy milk, eggs\",2024-05-01,\"says \"\"fresh\"\"\"\n" +
+		",2024-05-02,no title\n" +
+		"Call dentist,,\n"
+
+	items, err := ImportFileWithOptions(content, ImportOptions{Format: DetectFormat("tasks.csv"), TitleColumn: "Task"})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 items (empty title skipped), got %d", len(items))
+	}
+	first := items[0]
+	if first.Text != "Buy milk, eggs" {
+		t.Errorf("Expected quoted field with comma, got %q", first.Text)
+	}
+	if first.Metadata.Attributes["due_date"] != "2024-05-01" || first.Metadata.Attributes["notes"] != `says "fresh"` {
+		t.Errorf("Unexpected attributes %v", first.Metadata.Attributes)
+	}
+	if _, ok := first.Metadata.Attributes["task"]; ok {
+		t.Error("The title column should not be stored as an attribute")
+	}
+	if len(items[1].Metadata.Attributes) != 0 {
+		t.Errorf("Expected empty values to be skipped, got %v", items[1].Metadata.Attributes)
+	}
+}
+
+func TestCSVParserDepth(t *testing.T) {
+	content := "title,depth,status\nProject,0,\nTask,1,todo\nSubtask,2,done\nOther,0,\n"
+
+	items, err := ImportFileWithOptions(content, ImportOptions{Format: FormatCSV, Hierarchy: CSVHierarchyDepth})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	if len(items) != 2 || items[1].Text != "Other" {
+		t.Fatalf("Expected Project and Other as root items, got %d", len(items))
+	}
+	task := items[0].Children[0]
+	if task.Text != "Task" || task.Parent != items[0] || task.Metadata.Attributes["status"] != "todo" {
+		t.Errorf("Expected Task under Project, got %q", task.Text)
+	}
+	if len(task.Children) != 1 || task.Children[0].Text != "Subtask" {
+		t.Errorf("Expected Subtask under Task")
+	}
+	if _, ok := task.Metadata.Attributes["depth"]; ok {
+		t.Error("The depth column should not be stored as an attribute")
+	}
+
+	if _, err := ImportFileWithOptions("title,depth\nBad,x\n", ImportOptions{Format: FormatCSV, Hierarchy: CSVHierarchyDepth}); err == nil {
+		t.Error("Expected an error for an invalid depth")
+	}
+}
+
+func TestCSVParserParent(t *testing.T) {
+	content := "id,name,parent\n1,Project,\n2,Task,1\n3,Subtask,2\n"
+
+	items, err := ImportFileWithOptions(content, ImportOptions{Format: FormatCSV, Hierarchy: CSVHierarchyParent})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	if len(items) != 1 || items[0].Text != "Project" {
+		t.Fatalf("Expected the name column as title and one root item, got %d", len(items))
+	}
+	if sub := items[0].Children[0].Children[0]; sub.Text != "Subtask" {
+		t.Errorf("Expected Subtask two levels down, got %q", sub.Text)
+	}
+
+	if _, err := ImportFileWithOptions("id,title,parent\n1,Task,9\n", ImportOptions{Format: FormatCSV, Hierarchy: CSVHierarchyParent}); err == nil {
+		t.Error("Expected an error for an unknown parent")
+	}
+	if _, err := ImportFileWithOptions("title\nTask\n", ImportOptions{Format: FormatCSV, Hierarchy: CSVHierarchyParent}); err == nil {
+		t.Error("Expected an error without a parent column")
+	}
+}
@@ -7,7 +7,9 @@ import (
 )
 
 // IndentedTextParser imports plain text files with indentation-based hierarchy
-type IndentedTextParser struct{}
+type IndentedTextParser struct {
+	KeepIDs bool // Take item IDs from " {#id}" markers, see ImportOptions
+}
 
 func (p *IndentedTextParser) Name() string {
 	return "Indented Text"
@@ -39,7 +41,7 @@ func (p *IndentedTextParser) Parse(content string) ([]*model.Item, error) {
 		}
 
 		// Create new item
-		item := model.NewItem(text)
+		item := newImportedItem(text, p.KeepIDs)
 
 		// Determine parent based on indentation
 		if indent == 0 {
@@ -7,7 +7,9 @@ import (
 )
 
 // MarkdownParser imports markdown files
-type MarkdownParser struct{}
+type MarkdownParser struct {
+	KeepIDs bool // Take item IDs from " {#id}" markers, see ImportOptions
+}
 
 func (p *MarkdownParser) Name() string {
 	return "Markdown"
@@ -34,7 +36,7 @@ func (p *MarkdownParser) Parse(content string) ([]*model.Item, error) {
 		if strings.HasPrefix(line, "#") {
 			level, text := parseHeader(line)
 			if level >= 0 {
-				item := model.NewItem(text)
+				item := newImportedItem(text, p.KeepIDs)
 
 				// Reset list context when we hit a header
 				inList = false
@@ -66,7 +68,7 @@ func (p *MarkdownParser) Parse(content string) ([]*model.Item, error) {
 
 		// Check for unordered list item
 		if listLevel, text := parseListItem(line); listLevel >= 0 {
-			item := model.NewItem(text)
+			item := newImportedItem(text, p.KeepIDs)
 
 			if !inList {
 				// First list item - add as child of current context (header or root)
@@ -135,7 +137,7 @@ func (p *MarkdownParser) Parse(content string) ([]*model.Item, error) {
 			inList = false
 			listStack = nil
 
-			item := model.NewItem(text)
+			item := newImportedItem(text, p.KeepIDs)
 			if len(stack) > 0 {
 				parent := stack[len(stack)-1]
 				parent.Children = append(parent.Children, item)
@@ -0,0 +1,68 @@
+package import_parser
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+// OPMLParser imports OPML files, the common interchange format between outliners
+type OPMLParser struct{}
+
+func (p *OPMLParser) Name() string {
+	return "OPML"
+}
+
+// opmlDocument is the minimal OPML structure needed for importing
+type opmlDocument struct {
+	XMLName xml.Name      `xml:"opml"`
+	Body    []opmlOutline `xml:"body>outline"`
+}
+
+// opmlOutline is a single <outline> element with all of its attributes
+type opmlOutline struct {
+	Attrs    []xml.Attr    `xml:",any,attr"`
+	Children []opmlOutline `xml:"outline"`
+}
+
+// Parse converts OPML content to outline items
+func (p *OPMLParser) Parse(content string) ([]*model.Item, error) {
+	var doc opmlDocument
+	if err := xml.Unmarshal([]byte(content), &doc); err != nil {
+		return nil, fmt.Errorf("invalid OPML: %w", err)
+	}
+
+	var rootItems []*model.Item
+	for _, outline := range doc.Body {
+		rootItems = append(rootItems, convertOPMLOutline(outline, nil))
+	}
+
+	return rootItems, nil
+}
+
+// convertOPMLOutline recursively converts an <outline> element to an item.
+// The text attribute becomes the item text, _expanded the collapsed state,
+// and every other attribute is stored in the item metadata.
+func convertOPMLOutline(outline opmlOutline, parent *model.Item) *model.Item {
+	item := model.NewItem("")
+	item.Parent = parent
+
+	for _, attr := range outline.Attrs {
+		switch attr.Name.Local {
+		case "text":
+			item.Text = attr.Value
+		case "_expanded":
+			item.Expanded = strings.EqualFold(attr.Value, "true")
+		default:
+			item.Metadata.Attributes[attr.Name.Local] = attr.Value
+		}
+	}
+
+	for _, child := range outline.Children {
+		item.Children = append(item.Children, convertOPMLOutline(child, item))
+	}
+
+	return item
+}
@@ -0,0 +1,78 @@
+package import_parser
+
+import (
+	"testing"
+)
+
+func TestOPMLParser(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head><title>Test</title></head>
+  <body>
+    <outline text="Fish &amp; Chips" _expanded="true" status="done">
+      <outline text="&lt;tag&gt;"/>
+      <outline text=""/>
+    </outline>
+    <outline text="Second"/>
+  </body>
+</opml>`
+
+	items, err := ImportFile(content, FormatOPML)
+	if err != nil {
+		t.Fatalf("ImportFile failed: %v", err)
+	}
+
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 root items, got %d", len(items))
+	}
+
+	first := items[0]
+	if first.Text != "Fish & Chips" {
+		t.Errorf("Expected decoded text 'Fish & Chips', got %q", first.Text)
+	}
+	if !first.Expanded {
+		t.Errorf("Expected first item to be expanded")
+	}
+	if first.Metadata.Attributes["status"] != "done" {
+		t.Errorf("Expected status attribute 'done', got %q", first.Metadata.Attributes["status"])
+	}
+	if _, ok := first.Metadata.Attributes["text"]; ok {
+		t.Errorf("text should not be stored as an attribute")
+	}
+	if len(first.Children) != 2 {
+		t.Fatalf("Expected 2 children, got %d", len(first.Children))
+	}
+	if first.Children[0].Text != "<tag>" {
+		t.Errorf("Expected decoded text '<tag>', got %q", first.Children[0].Text)
+	}
+	if first.Children[0].Parent != first {
+		t.Errorf("Expected child parent to be set")
+	}
+	if first.Children[1].Text != "" {
+		t.Errorf("Expected empty item, got %q", first.Children[1].Text)
+	}
+}
+
+func TestOPMLParserEmptyBody(t *testing.T) {
+	content := `<opml version="2.0"><head/><body></body></opml>`
+
+	items, err := ImportFile(content, FormatOPML)
+	if err != nil {
+		t.Fatalf("Expected no error for empty body, got %v", err)
+	}
+	if len(items) != 0 {
+		t.Errorf("Expected 0 items, got %d", len(items))
+	}
+}
+
+func TestDetectFormatOPML(t *testing.T) {
+	if got := DetectFormat("notes.opml"); got != FormatOPML {
+		t.Errorf("Expected FormatOPML, got %s", got)
+	}
+	if got := DetectFormat("NOTES.OPML"); got != FormatOPML {
+		t.Errorf("Expected FormatOPML for uppercase extension, got %s", got)
+	}
+	if got := DetectFormat("notes.md"); got != FormatMarkdown {
+		t.Errorf("Expected FormatMarkdown, got %s", got)
+	}
+}
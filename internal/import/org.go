@@ -0,0 +1,169 @@
+package import_parser
+
+import (
+	"bufio"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+// OrgParser imports Org files, the outline format of Emacs org-mode
+type OrgParser struct{}
+
+func (p *OrgParser) Name() string {
+	return "Org"
+}
+
+var (
+	orgHeadingPattern  = regexp.MustCompile(`^(\*+)(?:\s+(.*))?$`)
+	orgTagsPattern     = regexp.MustCompile(`(?:^|\s+)(:[A-Za-z0-9_@#%:]+:)\s*$`)
+	orgPropertyPattern = regexp.MustCompile(`^\s*:([^:\s]+):\s?(.*)$`)
+)
+
+// Parse converts Org content to outline items.
+//
+// Heading depth becomes nesting, a todo keyword at the start of the heading
+// becomes the status attribute of a type=todo item, and :PROPERTIES: drawers
+// become attributes. Lines below a heading are added to its text.
+func (p *OrgParser) Parse(content string) ([]*model.Item, error) {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	keywords := []string{"TODO", "DONE"}
+	var rootItems []*model.Item
+	var stack []*model.Item // Open headings, from the root to the current heading
+	var depths []int        // Depth of each heading on the stack
+	var current *model.Item
+	inDrawer := false
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// Todo keyword declarations, like "#+TODO: TODO DOING | DONE"
+		if declared, ok := parseOrgTodoLine(line); ok {
+			keywords = declared
+			continue
+		}
+
+		if match := orgHeadingPattern.FindStringSubmatch(line); match != nil {
+			depth := len(match[1])
+			current = newOrgItem(match[2], keywords)
+			inDrawer = false
+
+			// Pop back to the nearest shallower heading, skipped levels attach to it
+			for len(depths) > 0 && depths[len(depths)-1] >= depth {
+				stack = stack[:len(stack)-1]
+				depths = depths[:len(depths)-1]
+			}
+			if len(stack) == 0 {
+				rootItems = append(rootItems, current)
+			} else {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, current)
+				current.Parent = parent
+			}
+			stack = append(stack, current)
+			depths = append(depths, depth)
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+
+		// Text before the first heading becomes root items
+		if current == nil {
+			if trimmed != "" && !strings.HasPrefix(trimmed, "#+") {
+				rootItems = append(rootItems, model.NewItem(trimmed))
+			}
+			continue
+		}
+
+		if strings.EqualFold(trimmed, ":PROPERTIES:") {
+			inDrawer = true
+			continue
+		}
+		if inDrawer {
+			if strings.EqualFold(trimmed, ":END:") {
+				inDrawer = false
+				continue
+			}
+			if match := orgPropertyPattern.FindStringSubmatch(line); match != nil {
+				current.Metadata.Attributes[match[1]] = strings.TrimSpace(match[2])
+			}
+			continue
+		}
+
+		// Body text, the export indents lines that would look like headings
+		if strings.HasPrefix(line, " *") {
+			line = line[1:]
+		}
+		current.Text += "\n" + line
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// Drop the trailing blank lines between an item and the next heading
+	for _, item := range rootItems {
+		trimOrgText(item)
+	}
+
+	return rootItems, nil
+}
+
+// parseOrgTodoLine parses a #+TODO, #+SEQ_TODO or #+TYP_TODO line into its keywords
+func parseOrgTodoLine(line string) ([]string, bool) {
+	upper := strings.ToUpper(line)
+	for _, prefix := range []string{"#+TODO:", "#+SEQ_TODO:", "#+TYP_TODO:"} {
+		if !strings.HasPrefix(upper, prefix) {
+			continue
+		}
+		var keywords []string
+		for _, field := range strings.Fields(line[len(prefix):]) {
+			if field == "|" {
+				continue
+			}
+			// Drop fast-access keys and logging options, like "WAIT(w@/!)"
+			if idx := strings.Index(field, "("); idx > 0 {
+				field = field[:idx]
+			}
+			keywords = append(keywords, field)
+		}
+		return keywords, true
+	}
+	return nil, false
+}
+
+// newOrgItem creates an item from the heading text after the stars
+func newOrgItem(heading string, keywords []string) *model.Item {
+	item := model.NewItem("")
+
+	if match := orgTagsPattern.FindStringSubmatchIndex(heading); match != nil {
+		for tag := range strings.SplitSeq(heading[match[2]:match[3]], ":") {
+			if tag != "" {
+				item.AddTag(tag)
+			}
+		}
+		heading = heading[:match[0]]
+	}
+
+	first, rest, _ := strings.Cut(heading, " ")
+	if slices.Contains(keywords, first) {
+		item.Metadata.Attributes["type"] = "todo"
+		item.Metadata.Attributes["status"] = strings.ToLower(first)
+		heading = rest
+	}
+
+	item.Text = strings.TrimSpace(heading)
+	return item
+}
+
+// trimOrgText removes trailing blank lines from the text of item and its descendants
+func trimOrgText(item *model.Item) {
+	item.Text = strings.TrimRight(item.Text, " \t\n")
+	for _, child := range item.Children {
+		trimOrgText(child)
+	}
+}
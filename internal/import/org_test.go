@@ -0,0 +1,69 @@
+package import_parser
+
+import (
+	"testing"
+)
+
+func TestOrgParser(t *testing.T) {
+	content := `#+TITLE: Notes
+#+TODO: TODO DOING(d) | DONE
+Loose line
+
+* Project :work:home:
+:PROPERTIES:
+:owner: me
+:END:
+** DOING Write report
+First draft
+ * not a heading
+
+*** Details
+* DONE Ship it
+*** TODO Deep
+* Meeting at 10:30:
+`
+
+	items, err := ImportFile(content, DetectFormat("notes.org"))
+	if err != nil {
+		t.Fatalf("ImportFile failed: %v", err)
+	}
+
+	if len(items) != 4 {
+		t.Fatalf("Expected 4 root items, got %d", len(items))
+	}
+	if items[0].Text != "Loose line" {
+		t.Errorf("Expected text before the first heading as a root item, got %q", items[0].Text)
+	}
+
+	project := items[1]
+	if project.Text != "Project" || project.Metadata.Attributes["owner"] != "me" {
+		t.Errorf("Expected Project with owner=me, got %q %v", project.Text, project.Metadata.Attributes)
+	}
+	if !project.HasTag("work") || !project.HasTag("home") {
+		t.Errorf("Expected tags work and home, got %v", project.GetTags())
+	}
+
+	report := project.Children[0]
+	if report.Text != "Write report\nFirst draft\n* not a heading" {
+		t.Errorf("Unexpected text %q", report.Text)
+	}
+	if report.Metadata.Attributes["type"] != "todo" || report.Metadata.Attributes["status"] != "doing" {
+		t.Errorf("Expected a todo with status doing, got %v", report.Metadata.Attributes)
+	}
+	if len(report.Children) != 1 || report.Children[0].Parent != report {
+		t.Errorf("Expected Details to be nested under the report")
+	}
+
+	ship := items[2]
+	if ship.Metadata.Attributes["status"] != "done" || ship.Text != "Ship it" {
+		t.Errorf("Expected done item 'Ship it', got %q %v", ship.Text, ship.Metadata.Attributes)
+	}
+	deep := ship.Children[0]
+	if deep.Text != "Deep" || deep.Metadata.Attributes["status"] != "todo" {
+		t.Errorf("Expected the todo Deep under Ship it despite the skipped level, got %q %v", deep.Text, deep.Metadata.Attributes)
+	}
+
+	if items[3].Text != "Meeting at 10:30:" {
+		t.Errorf("Expected colons inside the text to stay, got %q", items[3].Text)
+	}
+}
@@ -2,6 +2,9 @@ package import_parser
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
+
 	"github.com/pstuifzand/tui-outliner/internal/model"
 )
 
@@ -11,6 +14,9 @@ type ImportFormat string
 const (
 	FormatMarkdown     ImportFormat = "markdown"
 	FormatIndentedText ImportFormat = "indented"
+	FormatOPML         ImportFormat = "opml"
+	FormatOrg          ImportFormat = "org"
+	FormatCSV          ImportFormat = "csv"
 	FormatAuto         ImportFormat = "auto" // Auto-detect from extension
 )
 
@@ -18,6 +24,14 @@ const (
 type ImportOptions struct {
 	Format     ImportFormat
 	InsertMode string // "append", "prepend", "replace"
+
+	// CSV only
+	TitleColumn string // Column with the item text, see CSVParser
+	Hierarchy   string // How rows are nested, one of the CSVHierarchy modes
+
+	// Markdown and indented text only: take the item IDs from the " {#id}"
+	// markers that the exports write with their IDs option
+	KeepIDs bool
 }
 
 // Parser interface for different import formats
@@ -28,13 +42,25 @@ type Parser interface {
 
 // ImportFile imports a file and returns the root items
 func ImportFile(content string, format ImportFormat) ([]*model.Item, error) {
+	return ImportFileWithOptions(content, ImportOptions{Format: format})
+}
+
+// ImportFileWithOptions imports a file in opts.Format and returns the root items,
+// using the format specific options in opts
+func ImportFileWithOptions(content string, opts ImportOptions) ([]*model.Item, error) {
 	var parser Parser
 
-	switch format {
+	switch format := opts.Format; format {
 	case FormatMarkdown:
-		parser = &MarkdownParser{}
+		parser = &MarkdownParser{KeepIDs: opts.KeepIDs}
 	case FormatIndentedText:
-		parser = &IndentedTextParser{}
+		parser = &IndentedTextParser{KeepIDs: opts.KeepIDs}
+	case FormatOPML:
+		parser = &OPMLParser{}
+	case FormatOrg:
+		parser = &OrgParser{}
+	case FormatCSV:
+		parser = &CSVParser{TitleColumn: opts.TitleColumn, Hierarchy: opts.Hierarchy}
 	default:
 		return nil, fmt.Errorf("unsupported import format: %s", format)
 	}
@@ -47,9 +73,34 @@ func ImportFile(content string, format ImportFormat) ([]*model.Item, error) {
 	return items, nil
 }
 
+// idMarkerPattern matches a " {#id}" marker at the end of a line
+var idMarkerPattern = regexp.MustCompile(`\s*\{#([^\s{}]+)\}$`)
+
+// newImportedItem creates an item with text. With keepID, a " {#id}" marker
+// at the end of text is taken off and gives the item its ID.
+func newImportedItem(text string, keepID bool) *model.Item {
+	if keepID {
+		if m := idMarkerPattern.FindStringSubmatchIndex(text); m != nil {
+			item := model.NewItem(text[:m[0]])
+			item.ID = text[m[2]:m[3]]
+			return item
+		}
+	}
+	return model.NewItem(text)
+}
+
 // DetectFormat attempts to detect the file format from extension
 func DetectFormat(filename string) ImportFormat {
 	// Simple extension-based detection
+	if strings.HasSuffix(strings.ToLower(filename), ".opml") {
+		return FormatOPML
+	}
+	if strings.HasSuffix(strings.ToLower(filename), ".org") {
+		return FormatOrg
+	}
+	if strings.HasSuffix(strings.ToLower(filename), ".csv") {
+		return FormatCSV
+	}
 	if len(filename) > 3 {
 		ext := filename[len(filename)-3:]
 		if ext == ".md" {
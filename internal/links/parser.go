@@ -75,3 +75,48 @@ func IsValidID(id string) bool {
 	// IDs should start with "item_" followed by timestamp and random text
 	return strings.HasPrefix(id, "item_") && len(id) > 30
 }
+
+// UpdateLinkText rewrites the display text of every link to id in text to newTitle.
+// Links without display text are left alone, as they already resolve to the item text.
+// Titles that cannot be stored in a link (empty, or containing ']', '|' or a newline)
+// leave the text unchanged.
+func UpdateLinkText(text, id, newTitle string) string {
+	newTitle = strings.TrimSpace(newTitle)
+	if newTitle == "" || strings.ContainsAny(newTitle, "]|\n") {
+		return text
+	}
+
+	links := ParseLinks(text)
+
+	// Replace from the end so earlier positions stay valid
+	for i := len(links) - 1; i >= 0; i-- {
+		link := links[i]
+		if link.ID != id || link.DisplayText == "" || link.DisplayText == newTitle {
+			continue
+		}
+		text = text[:link.StartPos] + "[[" + link.ID + "|" + newTitle + "]]" + text[link.EndPos:]
+	}
+
+	return text
+}
+
+// ReplaceLinkID rewrites every link to oldID in text to link to newID, keeping
+// the display text
+func ReplaceLinkID(text, oldID, newID string) string {
+	links := ParseLinks(text)
+
+	// Replace from the end so earlier positions stay valid
+	for i := len(links) - 1; i >= 0; i-- {
+		link := links[i]
+		if link.ID != oldID {
+			continue
+		}
+		replacement := "[[" + newID + "]]"
+		if link.DisplayText != "" {
+			replacement = "[[" + newID + "|" + link.DisplayText + "]]"
+		}
+		text = text[:link.StartPos] + replacement + text[link.EndPos:]
+	}
+
+	return text
+}
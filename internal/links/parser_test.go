@@ -0,0 +1,59 @@
+package links
+
+import "testing"
+
+func TestUpdateLinkText(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		newTitle string
+		expected string
+	}{
+		{
+			name:     "updates display text",
+			text:     "See [[item_a|Old title]] for details",
+			newTitle: "New title",
+			expected: "See [[item_a|New title]] for details",
+		},
+		{
+			name:     "updates every matching link",
+			text:     "[[item_a|one]] and [[item_b|other]] and [[item_a|two]]",
+			newTitle: "New",
+			expected: "[[item_a|New]] and [[item_b|other]] and [[item_a|New]]",
+		},
+		{
+			name:     "leaves links without display text alone",
+			text:     "See [[item_a]]",
+			newTitle: "New title",
+			expected: "See [[item_a]]",
+		},
+		{
+			name:     "ignores other ids",
+			text:     "See [[item_b|Old]]",
+			newTitle: "New title",
+			expected: "See [[item_b|Old]]",
+		},
+		{
+			name:     "skips titles that would break the link",
+			text:     "See [[item_a|Old]]",
+			newTitle: "Array[0]",
+			expected: "See [[item_a|Old]]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UpdateLinkText(tt.text, "item_a", tt.newTitle); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestReplaceLinkID(t *testing.T) {
+	text := "[[item_a|one]] and [[item_b]] and [[item_a]]"
+	expected := "[[item_c|one]] and [[item_b]] and [[item_c]]"
+	if got := ReplaceLinkID(text, "item_a", "item_c"); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
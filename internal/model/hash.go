@@ -0,0 +1,124 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"hash/fnv"
+	"maps"
+	"slices"
+	"strconv"
+)
+
+// ContentHash returns a SHA-256 hex digest of the content of the outline: the
+// items with their text, tags, attributes, virtual children and children, and
+// the type definitions, defaults and templates. Map keys are sorted, so the
+// hash does not depend on their order. Timestamps and view state are not part
+// of the content.
+func (o *Outline) ContentHash() string {
+	h := sha256.New()
+
+	var buf []byte
+	buf = appendHashMap(buf, "types", o.TypeDefinitions)
+	buf = appendHashField(buf, "defaults", len(o.TypeDefaults))
+	for _, typeName := range slices.Sorted(maps.Keys(o.TypeDefaults)) {
+		buf = appendHashMap(buf, typeName, o.TypeDefaults[typeName])
+	}
+	buf = appendHashMap(buf, "templates", o.TypeTemplates)
+	h.Write(buf)
+
+	writeHashItems(h, buf[:0], o.Items)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeHashItems writes items and their descendants to h, using buf to
+// encode each item
+func writeHashItems(h hash.Hash, buf []byte, items []*Item) []byte {
+	buf = appendHashField(buf[:0], "items", len(items))
+	h.Write(buf)
+	for _, item := range items {
+		buf = appendHashString(buf[:0], item.ID)
+		buf = appendHashItem(buf, item)
+		h.Write(buf)
+		buf = writeHashItems(h, buf, item.Children)
+	}
+	return buf
+}
+
+// appendHashItem appends the text, tags, attributes and virtual children of an item
+func appendHashItem(buf []byte, item *Item) []byte {
+	buf = appendHashString(buf, item.Text)
+	var tags []string
+	var attributes map[string]string
+	if item.Metadata != nil {
+		tags = item.Metadata.Tags
+		attributes = item.Metadata.Attributes
+	}
+	buf = appendHashField(buf, "tags", len(tags))
+	for _, tag := range tags {
+		buf = appendHashString(buf, tag)
+	}
+	buf = appendHashMap(buf, "attributes", attributes)
+	buf = appendHashField(buf, "virtual", len(item.VirtualChildRefs))
+	for _, ref := range item.VirtualChildRefs {
+		buf = appendHashString(buf, ref)
+	}
+	return buf
+}
+
+// ItemHashes returns a hash of each of the items and their descendants by ID.
+// An item hash covers the content that ContentHash covers, the ID of the
+// parent and the IDs of the children in order, but not the content of the
+// children. Comparing the hashes of two moments tells which items changed.
+func ItemHashes(items []*Item) map[string]uint64 {
+	hashes := make(map[string]uint64)
+	h := fnv.New64a()
+	var buf []byte
+	var walk func(items []*Item, parentID string)
+	walk = func(items []*Item, parentID string) {
+		for _, item := range items {
+			buf = appendHashString(buf[:0], parentID)
+			buf = appendHashItem(buf, item)
+			buf = appendHashField(buf, "children", len(item.Children))
+			for _, child := range item.Children {
+				buf = appendHashString(buf, child.ID)
+			}
+			h.Reset()
+			h.Write(buf)
+			hashes[item.ID] = h.Sum64()
+			walk(item.Children, item.ID)
+		}
+	}
+	walk(items, "")
+	return hashes
+}
+
+// appendHashMap appends m with its keys in sorted order
+func appendHashMap(buf []byte, name string, m map[string]string) []byte {
+	buf = appendHashField(buf, name, len(m))
+	if len(m) == 0 {
+		return buf
+	}
+	for _, key := range slices.Sorted(maps.Keys(m)) {
+		buf = appendHashString(buf, key)
+		buf = appendHashString(buf, m[key])
+	}
+	return buf
+}
+
+// appendHashField appends the name and count of a list
+func appendHashField(buf []byte, name string, count int) []byte {
+	buf = append(buf, name...)
+	buf = append(buf, ':')
+	buf = strconv.AppendInt(buf, int64(count), 10)
+	return append(buf, ';')
+}
+
+// appendHashString appends s, prefixed with its length so the boundaries
+// between strings are part of the hash
+func appendHashString(buf []byte, s string) []byte {
+	buf = strconv.AppendInt(buf, int64(len(s)), 10)
+	buf = append(buf, ':')
+	buf = append(buf, s...)
+	return append(buf, ';')
+}
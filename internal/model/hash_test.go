@@ -0,0 +1,91 @@
+package model
+
+import "testing"
+
+// newHashTestOutline creates an outline with attributes added in the given key order
+func newHashTestOutline(keys []string) *Outline {
+	item := &Item{ID: "item_1", Text: "Task", Metadata: &Metadata{Attributes: make(map[string]string)}}
+	for _, key := range keys {
+		item.Metadata.Attributes[key] = key + "-value"
+	}
+	child := &Item{ID: "item_2", Text: "Child"}
+	item.AddChild(child)
+
+	outline := NewOutline()
+	outline.Items = []*Item{item}
+	return outline
+}
+
+func TestContentHash(t *testing.T) {
+	keys := []string{"status", "priority", "deadline", "owner", "type", "url"}
+	first := newHashTestOutline(keys)
+	hash := first.ContentHash()
+	if len(hash) != 64 {
+		t.Fatalf("Expected a SHA-256 hex digest, got %q", hash)
+	}
+
+	// The same content with attributes added in another order
+	reversed := make([]string, len(keys))
+	for i, key := range keys {
+		reversed[len(keys)-1-i] = key
+	}
+	for range 10 {
+		if got := newHashTestOutline(reversed).ContentHash(); got != hash {
+			t.Fatalf("Expected the same hash for the same content, got %s and %s", hash, got)
+		}
+	}
+
+	// Timestamps and view state are not content
+	first.Items[0].Expanded = true
+	first.Items[0].Metadata.Modified = first.Items[0].Metadata.Modified.AddDate(0, 0, 1)
+	if got := first.ContentHash(); got != hash {
+		t.Error("Expected timestamps and view state not to change the hash")
+	}
+
+	changes := map[string]func(o *Outline){
+		"text":      func(o *Outline) { o.Items[0].Children[0].Text = "Changed" },
+		"attribute": func(o *Outline) { o.Items[0].Metadata.Attributes["status"] = "done" },
+		"tag":       func(o *Outline) { o.Items[0].AddTag("work") },
+		"order": func(o *Outline) {
+			o.Items[0].RemoveChild(o.Items[0].Children[0])
+			o.Items = append(o.Items, &Item{ID: "item_2", Text: "Child"})
+		},
+		"boundary": func(o *Outline) {
+			o.Items[0].Text = "Task" + o.Items[0].Children[0].ID
+			o.Items[0].Children[0].ID = ""
+		},
+	}
+	for name, change := range changes {
+		outline := newHashTestOutline(keys)
+		change(outline)
+		if outline.ContentHash() == hash {
+			t.Errorf("Expected a %s change to change the hash", name)
+		}
+	}
+}
+
+func TestItemHashes(t *testing.T) {
+	outline := newHashTestOutline([]string{"status"})
+	outline.Items = append(outline.Items, &Item{ID: "item_3", Text: "Other"})
+	before := ItemHashes(outline.Items)
+	if len(before) != 3 {
+		t.Fatalf("Expected a hash for each item, got %d", len(before))
+	}
+
+	// Moving the child changes the child and both parents, text only the item itself
+	task, child, other := outline.Items[0], outline.Items[0].Children[0], outline.Items[1]
+	task.RemoveChild(child)
+	other.AddChild(child)
+	other.Text = "Other, changed"
+	after := ItemHashes(outline.Items)
+	for _, id := range []string{"item_1", "item_2", "item_3"} {
+		if before[id] == after[id] {
+			t.Errorf("Expected the hash of %s to change", id)
+		}
+	}
+
+	child.Text = "Changed"
+	if changed := ItemHashes(outline.Items); changed["item_1"] != after["item_1"] || changed["item_3"] != after["item_3"] || changed["item_2"] == after["item_2"] {
+		t.Error("Expected only the hash of the changed child to change")
+	}
+}
@@ -5,7 +5,11 @@ import (
 	"crypto/rand"
 	"maps"
 	"slices"
+	"strings"
 	"time"
+	"unicode/utf8"
+
+	"github.com/pstuifzand/tui-outliner/internal/links"
 )
 
 // Item represents a single node in the outline tree
@@ -34,10 +38,12 @@ type Metadata struct {
 
 // Outline represents the entire outline document
 type Outline struct {
-	Items            []*Item           `json:"items"`
-	OriginalFilename string            `json:"original_filename,omitempty"`
-	TypeDefinitions  map[string]string `json:"type_definitions,omitempty"` // Global type definitions (key -> type spec)
-	itemIndex        map[string]*Item  `json:"-"`                          // Fast O(1) ID lookup cache
+	Items            []*Item                      `json:"items"`
+	OriginalFilename string                       `json:"original_filename,omitempty"`
+	TypeDefinitions  map[string]string            `json:"type_definitions,omitempty"` // Global type definitions (key -> type spec)
+	TypeDefaults     map[string]map[string]string `json:"type_defaults,omitempty"`    // Default attributes per node type (type -> key -> value)
+	TypeTemplates    map[string]string            `json:"type_templates,omitempty"`   // Template item per node type (type -> item ID)
+	itemIndex        map[string]*Item             `json:"-"`                          // Fast O(1) ID lookup cache
 }
 
 // NewItem creates a new outline item with a generated ID
@@ -82,6 +88,25 @@ func NewItemFromWithout(item *Item, attrKey string) *Item {
 	return yanked
 }
 
+// CloneItemTree creates a deep copy of an item and all of its descendants.
+// Every copied node gets a new ID, so the copy can live next to the original
+// in the same outline without colliding in the item index.
+func CloneItemTree(src *Item) *Item {
+	clone := NewItem(src.Text)
+	clone.Expanded = src.Expanded
+	clone.VirtualChildRefs = slices.Clone(src.VirtualChildRefs)
+	if src.Metadata != nil {
+		clone.Metadata.Tags = slices.Clone(src.Metadata.Tags)
+		if src.Metadata.Attributes != nil {
+			clone.Metadata.Attributes = maps.Clone(src.Metadata.Attributes)
+		}
+	}
+	for _, child := range src.Children {
+		clone.AddChild(CloneItemTree(child))
+	}
+	return clone
+}
+
 // NewOutline creates a new outline with the given title
 func NewOutline() *Outline {
 	return &Outline{
@@ -112,6 +137,39 @@ func (i *Item) RemoveChild(child *Item) {
 	}
 }
 
+// CountDescendants returns the number of items below this item, virtual children excluded
+func (i *Item) CountDescendants() int {
+	count := len(i.Children)
+	for _, child := range i.Children {
+		count += child.CountDescendants()
+	}
+	return count
+}
+
+// TextCounts holds the number of nodes, words and characters in outline text
+type TextCounts struct {
+	Nodes      int
+	Words      int
+	Characters int
+}
+
+// CountText counts the nodes, words and characters of the items and their
+// descendants. Words are separated by whitespace, characters are runes.
+func CountText(items []*Item) TextCounts {
+	var counts TextCounts
+	for _, item := range items {
+		counts.Nodes++
+		counts.Words += len(strings.Fields(item.Text))
+		counts.Characters += utf8.RuneCountInString(item.Text)
+
+		childCounts := CountText(item.Children)
+		counts.Nodes += childCounts.Nodes
+		counts.Words += childCounts.Words
+		counts.Characters += childCounts.Characters
+	}
+	return counts
+}
+
 // GetAllItems returns all items in the outline (depth-first)
 func (o *Outline) GetAllItems() []*Item {
 	var items []*Item
@@ -156,6 +214,21 @@ func (o *Outline) BuildIndex() {
 	}
 }
 
+// FindBacklinks returns the items whose text links to the item with the given ID.
+// Each source item is returned once, even when it links to the target several times.
+func (o *Outline) FindBacklinks(id string) []*Item {
+	var backlinks []*Item
+	for _, item := range o.GetAllItems() {
+		for _, link := range links.ParseLinks(item.Text) {
+			if link.ID == id {
+				backlinks = append(backlinks, item)
+				break
+			}
+		}
+	}
+	return backlinks
+}
+
 // ResolveVirtualChildren resolves all virtual child references to actual item pointers
 // Detects and prevents circular references
 func (o *Outline) ResolveVirtualChildren() {
@@ -284,7 +357,8 @@ func (o *Outline) PopulateSearchNode(item *Item, matchingIDs []string) int {
 	return len(matchingIDs)
 }
 
-// AddTag adds a tag to the item's metadata (creates metadata if needed)
+// AddTag adds a tag to the item's metadata (creates metadata if needed). Tags
+// are kept sorted and without duplicates, so diffs of the file are stable.
 func (i *Item) AddTag(tag string) {
 	if i.Metadata == nil {
 		i.Metadata = &Metadata{
@@ -296,6 +370,8 @@ func (i *Item) AddTag(tag string) {
 	// Check if tag already exists
 	if !i.HasTag(tag) {
 		i.Metadata.Tags = append(i.Metadata.Tags, tag)
+		slices.Sort(i.Metadata.Tags)
+		i.Metadata.Tags = slices.Compact(i.Metadata.Tags)
 		i.Metadata.Modified = time.Now()
 	}
 }
@@ -343,6 +419,114 @@ func (i *Item) ClearTags() {
 	}
 }
 
+// DeduplicateIDs gives a new ID to every item that has the ID of an earlier
+// item, as happens when files are merged by hand. Links and virtual children
+// that refer to a duplicated ID are pointed at the nearest item with that ID,
+// the one sharing the deepest ancestor with the referring item, so links
+// within a copied subtree follow the copy. Returns the number of reassigned IDs.
+func (o *Outline) DeduplicateIDs() int {
+	allItems := o.GetAllItems()
+	occurrences := make(map[string][]*Item)
+	var duplicated []string
+	for _, item := range allItems {
+		if len(occurrences[item.ID]) == 1 {
+			duplicated = append(duplicated, item.ID)
+		}
+		occurrences[item.ID] = append(occurrences[item.ID], item)
+	}
+	if len(duplicated) == 0 {
+		return 0
+	}
+
+	count := 0
+	for _, id := range duplicated {
+		for _, item := range occurrences[id][1:] {
+			newID := generateID()
+			for occurrences[newID] != nil {
+				newID = generateID()
+			}
+			occurrences[newID] = []*Item{item}
+			item.ID = newID
+			count++
+		}
+	}
+
+	// nearestID returns the current ID of the item with the old ID id nearest to item
+	nearestID := func(item *Item, id string) string {
+		nearest, nearestDepth := occurrences[id][0], commonAncestorDepth(item, occurrences[id][0])
+		for _, candidate := range occurrences[id][1:] {
+			if depth := commonAncestorDepth(item, candidate); depth > nearestDepth {
+				nearest, nearestDepth = candidate, depth
+			}
+		}
+		return nearest.ID
+	}
+
+	isDuplicated := make(map[string]bool, len(duplicated))
+	for _, id := range duplicated {
+		isDuplicated[id] = true
+	}
+	for _, item := range allItems {
+		for _, link := range links.ParseLinks(item.Text) {
+			if isDuplicated[link.ID] {
+				if newID := nearestID(item, link.ID); newID != link.ID {
+					item.Text = links.ReplaceLinkID(item.Text, link.ID, newID)
+				}
+			}
+		}
+		for idx, ref := range item.VirtualChildRefs {
+			if isDuplicated[ref] {
+				item.VirtualChildRefs[idx] = nearestID(item, ref)
+			}
+		}
+	}
+
+	o.BuildIndex()
+	return count
+}
+
+// Merge adds the root items of other, with their attributes and children, as
+// the last children of parent, which must be an item of o. Merged items with
+// the ID of an item in o get a new ID, links between merged items follow
+// them. Returns the number of reassigned IDs.
+func (o *Outline) Merge(other *Outline, parent *Item) int {
+	// Collect the merged items under a container after the existing items, so
+	// the existing items keep their IDs and links within the merged items
+	// find the merged items first
+	container := NewItem("")
+	for _, item := range other.Items {
+		container.AddChild(item)
+	}
+	combined := &Outline{Items: append(slices.Clone(o.Items), container)}
+	count := combined.DeduplicateIDs()
+
+	for _, item := range container.Children {
+		parent.AddChild(item)
+	}
+	o.BuildIndex()
+	o.ResolveVirtualChildren()
+	return count
+}
+
+// commonAncestorDepth returns the depth of the deepest item that is a or b or
+// an ancestor of both, or -1 when they are in different root items
+func commonAncestorDepth(a, b *Item) int {
+	ancestors := make(map[*Item]bool)
+	for current := a; current != nil; current = current.Parent {
+		ancestors[current] = true
+	}
+	for current := b; current != nil; current = current.Parent {
+		if ancestors[current] {
+			depth := 0
+			for parent := current.Parent; parent != nil; parent = parent.Parent {
+				depth++
+			}
+			return depth
+		}
+	}
+	return -1
+}
+
 func generateID() string {
 	return "item_" + time.Now().Format("20060102150405") + "_" + rand.Text()
 }
@@ -0,0 +1,223 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCloneItemTree(t *testing.T) {
+	root := NewItem("Root")
+	root.Expanded = true
+	root.Metadata.Tags = []string{"work"}
+	root.Metadata.Attributes["status"] = "todo"
+	child := NewItem("Child")
+	grandchild := NewItem("Grandchild")
+	child.AddChild(grandchild)
+	root.AddChild(child)
+
+	clone := CloneItemTree(root)
+
+	if clone.ID == root.ID || clone.Children[0].ID == child.ID || clone.Children[0].Children[0].ID == grandchild.ID {
+		t.Error("Expected every cloned node to get a new ID")
+	}
+	if clone.Text != "Root" || clone.Children[0].Text != "Child" || clone.Children[0].Children[0].Text != "Grandchild" {
+		t.Error("Cloned text does not match the original")
+	}
+	if !clone.Expanded {
+		t.Error("Expected expanded state to be copied")
+	}
+
+	// Parent pointers must point into the clone, not the original
+	if clone.Parent != nil {
+		t.Error("Expected clone root to have no parent")
+	}
+	if clone.Children[0].Parent != clone || clone.Children[0].Children[0].Parent != clone.Children[0] {
+		t.Error("Expected parent pointers to be wired to the cloned nodes")
+	}
+
+	// Metadata must be deep-copied
+	clone.Metadata.Attributes["status"] = "done"
+	clone.Metadata.Tags[0] = "home"
+	if root.Metadata.Attributes["status"] != "todo" {
+		t.Error("Changing the clone attributes modified the original")
+	}
+	if root.Metadata.Tags[0] != "work" {
+		t.Error("Changing the clone tags modified the original")
+	}
+
+	// Clone and original can share an index without collisions
+	outline := NewOutline()
+	outline.Items = []*Item{root, clone}
+	outline.BuildIndex()
+	if outline.FindItemByID(clone.Children[0].ID) != clone.Children[0] {
+		t.Error("Expected cloned child to be found in the index")
+	}
+	if outline.FindItemByID(child.ID) != child {
+		t.Error("Expected original child to be found in the index")
+	}
+}
+
+func TestCloneItemTreeNilMetadata(t *testing.T) {
+	item := &Item{ID: "1", Text: "No metadata"}
+
+	clone := CloneItemTree(item)
+	if clone.Metadata == nil || clone.Metadata.Attributes == nil {
+		t.Error("Expected clone to have initialized metadata")
+	}
+}
+
+func TestAddTagSortedAndUnique(t *testing.T) {
+	item := NewItem("Task")
+	for _, tag := range []string{"work", "home", "work", "urgent"} {
+		item.AddTag(tag)
+	}
+	if got := strings.Join(item.GetTags(), ","); got != "home,urgent,work" {
+		t.Errorf("Expected sorted unique tags, got %q", got)
+	}
+}
+
+func TestFindBacklinks(t *testing.T) {
+	target := NewItem("Target")
+	twice := NewItem("See [[" + target.ID + "]] and [[" + target.ID + "|again]]")
+	nested := NewItem("Nested [[" + target.ID + "|target]]")
+	parent := NewItem("Parent")
+	parent.AddChild(nested)
+	other := NewItem("No links here [[item_other]]")
+
+	outline := NewOutline()
+	outline.Items = []*Item{target, twice, parent, other}
+	outline.BuildIndex()
+
+	backlinks := outline.FindBacklinks(target.ID)
+	if len(backlinks) != 2 {
+		t.Fatalf("Expected 2 backlinks, got %d", len(backlinks))
+	}
+	if backlinks[0] != twice || backlinks[1] != nested {
+		t.Errorf("Expected backlinks in outline order, got %q and %q", backlinks[0].Text, backlinks[1].Text)
+	}
+
+	if got := outline.FindBacklinks(other.ID); len(got) != 0 {
+		t.Errorf("Expected no backlinks, got %d", len(got))
+	}
+}
+
+func TestDeduplicateIDs(t *testing.T) {
+	// newTestItem creates an item with a fixed ID
+	newTestItem := func(id, text string) *Item {
+		item := NewItem(text)
+		item.ID = id
+		return item
+	}
+
+	// The project was merged in twice, the copy links to its own task
+	project := newTestItem("item_project", "Project")
+	task := newTestItem("item_task", "Task")
+	link := newTestItem("item_link", "See [[item_task]]")
+	project.AddChild(task)
+	project.AddChild(link)
+
+	projectCopy := newTestItem("item_project", "Project")
+	taskCopy := newTestItem("item_task", "Task copy")
+	linkCopy := newTestItem("item_link2", "See [[item_task|the copy]]")
+	linkCopy.VirtualChildRefs = []string{"item_task"}
+	projectCopy.AddChild(taskCopy)
+	projectCopy.AddChild(linkCopy)
+
+	other := newTestItem("item_other", "Elsewhere [[item_task]]")
+
+	outline := NewOutline()
+	outline.Items = []*Item{project, projectCopy, other}
+
+	if count := outline.DeduplicateIDs(); count != 2 {
+		t.Fatalf("Expected 2 reassigned IDs, got %d", count)
+	}
+	if project.ID != "item_project" || task.ID != "item_task" {
+		t.Errorf("Expected the first items to keep their IDs, got %s and %s", project.ID, task.ID)
+	}
+	if projectCopy.ID == "item_project" || taskCopy.ID == "item_task" || projectCopy.ID == taskCopy.ID {
+		t.Errorf("Expected new unique IDs for the copies, got %s and %s", projectCopy.ID, taskCopy.ID)
+	}
+
+	if want := "See [[" + taskCopy.ID + "|the copy]]"; linkCopy.Text != want {
+		t.Errorf("Expected the link in the copy to follow the copy, got %q", linkCopy.Text)
+	}
+	if len(linkCopy.VirtualChildRefs) != 1 || linkCopy.VirtualChildRefs[0] != taskCopy.ID {
+		t.Errorf("Expected the virtual child to follow the copy, got %v", linkCopy.VirtualChildRefs)
+	}
+	if link.Text != "See [[item_task]]" || other.Text != "Elsewhere [[item_task]]" {
+		t.Errorf("Expected other links to keep the first item, got %q and %q", link.Text, other.Text)
+	}
+	if outline.FindItemByID("item_task") != task || outline.FindItemByID(taskCopy.ID) != taskCopy {
+		t.Error("Expected the index to find both tasks")
+	}
+
+	if count := outline.DeduplicateIDs(); count != 0 {
+		t.Errorf("Expected no duplicates left, got %d", count)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	inbox := NewItem("Inbox")
+	existing := NewItem("Existing")
+	existing.ID = "item_shared"
+	inbox.AddChild(existing)
+	base := NewOutline()
+	base.Items = []*Item{inbox}
+
+	task := NewItem("Task on laptop")
+	task.ID = "item_shared"
+	task.Metadata.Attributes["status"] = "todo"
+	note := NewItem("Note")
+	note.AddChild(NewItem("Details"))
+	link := NewItem("See [[item_shared]]")
+	other := NewOutline()
+	other.Items = []*Item{task, note, link}
+
+	merged := NewItem("Merged")
+	base.Items = append(base.Items, merged)
+	if count := base.Merge(other, merged); count != 1 {
+		t.Fatalf("Expected 1 reassigned ID, got %d", count)
+	}
+
+	if existing.ID != "item_shared" || task.ID == "item_shared" {
+		t.Errorf("Expected the existing item to keep its ID, got %s and %s", existing.ID, task.ID)
+	}
+	if len(merged.Children) != 3 || merged.Children[0] != task || task.Parent != merged {
+		t.Fatalf("Expected the merged items under the merged item, got %v", merged.Children)
+	}
+	if task.Metadata.Attributes["status"] != "todo" || len(note.Children) != 1 {
+		t.Error("Expected attributes and children to come along")
+	}
+	if link.Text != "See [["+task.ID+"]]" {
+		t.Errorf("Expected the link to follow the merged task, got %q", link.Text)
+	}
+	if base.FindItemByID(task.ID) != task || base.FindItemByID("item_shared") != existing {
+		t.Error("Expected the index to find the merged and existing items")
+	}
+}
+
+func TestCountDescendants(t *testing.T) {
+	root := NewItem("Root")
+	child := NewItem("Child")
+	root.AddChild(child)
+	root.AddChild(NewItem("Sibling"))
+	child.AddChild(NewItem("Grandchild"))
+
+	if got := root.CountDescendants(); got != 3 {
+		t.Errorf("Expected 3 descendants, got %d", got)
+	}
+	if got := child.CountDescendants(); got != 1 {
+		t.Errorf("Expected 1 descendant, got %d", got)
+	}
+}
+
+func TestCountText(t *testing.T) {
+	root := NewItem("Chapter one")
+	root.AddChild(NewItem("It was a  dark night"))
+	root.AddChild(NewItem("Café"))
+
+	counts := CountText([]*Item{root})
+	if counts.Nodes != 3 || counts.Words != 8 || counts.Characters != 35 {
+		t.Errorf("Expected 3 nodes, 8 words, 35 characters, got %+v", counts)
+	}
+}
@@ -0,0 +1,112 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Problem is something wrong in the structure of an outline, like an item
+// without ID. Hand-edited or partially written files can have them.
+type Problem struct {
+	Item    *Item  // The item with the problem, nil for an empty entry
+	Message string // What is wrong, like "has no metadata"
+}
+
+// String returns the problem with the ID and text of the item
+func (p Problem) String() string {
+	if p.Item == nil {
+		return p.Message
+	}
+	text := []rune(p.Item.Text)
+	if len(text) > 30 {
+		text = append(text[:29], '…')
+	}
+	return fmt.Sprintf("%s %q: %s", p.Item.ID, string(text), p.Message)
+}
+
+// Validate returns the problems of the outline in depth-first order: empty
+// entries in the items, items without ID or metadata, parent pointers that
+// don't match the nesting, and IDs used by more than one item. Repair fixes
+// them. Attributes may be nil, items saved without attributes load that way.
+func (o *Outline) Validate() []Problem {
+	var problems []Problem
+	seen := make(map[string]bool)
+
+	var walk func(items []*Item, parent *Item)
+	walk = func(items []*Item, parent *Item) {
+		for _, item := range items {
+			if item == nil {
+				message := "empty entry in the root items"
+				if parent != nil {
+					message = fmt.Sprintf("empty entry in the children of %s", parent.ID)
+				}
+				problems = append(problems, Problem{Message: message})
+				continue
+			}
+
+			if item.ID == "" {
+				problems = append(problems, Problem{Item: item, Message: "has no ID"})
+			} else if seen[item.ID] {
+				problems = append(problems, Problem{Item: item, Message: "has the ID of an earlier item"})
+			}
+			seen[item.ID] = true
+
+			if item.Metadata == nil {
+				problems = append(problems, Problem{Item: item, Message: "has no metadata"})
+			}
+
+			if item.Parent != parent {
+				problems = append(problems, Problem{Item: item, Message: "has the wrong parent"})
+			}
+
+			walk(item.Children, item)
+		}
+	}
+	walk(o.Items, nil)
+
+	return problems
+}
+
+// Repair fixes the problems that Validate finds and returns them. Empty
+// entries are removed, items without ID get a new one, missing metadata is
+// added, parent pointers are set from the nesting, and items
+// with a duplicate ID get a new one like DeduplicateIDs. Nil attribute maps
+// are replaced by empty ones without being reported. The index and the
+// virtual children are rebuilt when anything was fixed.
+func (o *Outline) Repair() []Problem {
+	problems := o.Validate()
+
+	now := time.Now()
+	var repair func(items []*Item, parent *Item) []*Item
+	repair = func(items []*Item, parent *Item) []*Item {
+		kept := items[:0]
+		for _, item := range items {
+			if item == nil {
+				continue
+			}
+			if item.ID == "" {
+				item.ID = generateID()
+			}
+			if item.Metadata == nil {
+				item.Metadata = &Metadata{Created: now, Modified: now}
+			}
+			if item.Metadata.Attributes == nil {
+				item.Metadata.Attributes = make(map[string]string)
+			}
+			item.Parent = parent
+			item.Children = repair(item.Children, item)
+			kept = append(kept, item)
+		}
+		clear(items[len(kept):])
+		return kept
+	}
+	o.Items = repair(o.Items, nil)
+	if len(problems) == 0 {
+		return nil
+	}
+
+	o.DeduplicateIDs()
+	o.BuildIndex()
+	o.ResolveVirtualChildren()
+	return problems
+}
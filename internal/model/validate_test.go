@@ -0,0 +1,122 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+// brokenOutline returns an outline with one of each problem Validate finds,
+// like a hand-edited file would have after loading
+func brokenOutline() (*Outline, map[string]*Item) {
+	items := make(map[string]*Item)
+	for _, text := range []string{"Root", "Child", "No ID", "No metadata", "No attributes", "Copy"} {
+		item := NewItem(text)
+		items[text] = item
+	}
+	items["Root"].AddChild(items["Child"])
+	items["Root"].AddChild(items["No ID"])
+	items["Child"].AddChild(items["No metadata"])
+
+	items["No ID"].ID = ""
+	items["No metadata"].Metadata = nil
+	// Not a problem, items saved without attributes load this way
+	items["No attributes"].Metadata.Attributes = nil
+	items["Copy"].ID = items["Child"].ID
+	// The child lost its parent, and a null ended up between the children
+	items["Child"].Parent = nil
+	items["Root"].Children = append(items["Root"].Children, nil)
+
+	outline := NewOutline()
+	outline.Items = []*Item{items["Root"], nil, items["No attributes"], items["Copy"]}
+	return outline, items
+}
+
+func TestValidate(t *testing.T) {
+	outline, items := brokenOutline()
+
+	var got []string
+	for _, problem := range outline.Validate() {
+		got = append(got, problem.Message)
+	}
+	want := []string{
+		"has the wrong parent",
+		"has no metadata",
+		"has no ID",
+		"empty entry in the children of " + items["Root"].ID,
+		"empty entry in the root items",
+		"has the ID of an earlier item",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected problems\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+
+	if problems := NewOutline().Validate(); len(problems) != 0 {
+		t.Errorf("Expected no problems in an empty outline, got %v", problems)
+	}
+}
+
+func TestRepair(t *testing.T) {
+	outline, items := brokenOutline()
+	childID := items["Child"].ID
+
+	if problems := outline.Repair(); len(problems) != 6 {
+		t.Fatalf("Expected 6 repaired problems, got %d: %v", len(problems), problems)
+	}
+	if problems := outline.Validate(); len(problems) != 0 {
+		t.Fatalf("Expected no problems after repair, got %v", problems)
+	}
+	if problems := outline.Repair(); problems != nil {
+		t.Errorf("Expected nothing to repair the second time, got %v", problems)
+	}
+
+	if len(outline.Items) != 3 || len(items["Root"].Children) != 2 {
+		t.Errorf("Expected the empty entries to be removed, got %d root items and %d children", len(outline.Items), len(items["Root"].Children))
+	}
+	if items["Child"].Parent != items["Root"] {
+		t.Error("Expected the parent of the child to be restored")
+	}
+	if items["No ID"].ID == "" {
+		t.Error("Expected the item without ID to get one")
+	}
+	if items["No metadata"].Metadata == nil || items["No metadata"].Metadata.Attributes == nil {
+		t.Error("Expected the item without metadata to get it")
+	}
+	if items["No attributes"].Metadata.Attributes == nil {
+		t.Error("Expected the item without attributes to get an attribute map")
+	}
+	if items["Child"].ID != childID || items["Copy"].ID == childID {
+		t.Errorf("Expected the copy to get a new ID, got %s and %s", items["Child"].ID, items["Copy"].ID)
+	}
+	for text, item := range items {
+		if outline.FindItemByID(item.ID) != item {
+			t.Errorf("Expected the index to find %q", text)
+		}
+	}
+}
+
+func TestRepairNormalizesAttributes(t *testing.T) {
+	outline := NewOutline()
+	item := NewItem("Saved without attributes")
+	item.Metadata.Attributes = nil
+	outline.Items = append(outline.Items, item)
+
+	if problems := outline.Repair(); problems != nil {
+		t.Errorf("Expected a nil attribute map not to be a problem, got %v", problems)
+	}
+	if item.Metadata.Attributes == nil {
+		t.Error("Expected Repair to create the attribute map")
+	}
+}
+
+func TestProblemString(t *testing.T) {
+	item := NewItem("A long item text that doesn't fit in a problem message")
+	item.ID = "item_1"
+	got := Problem{Item: item, Message: "has no metadata"}.String()
+	if want := `item_1 "A long item text that doesn't…": has no metadata`; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	if got := (Problem{Message: "empty entry in the root items"}).String(); got != "empty entry in the root items" {
+		t.Errorf("Expected only the message without item, got %q", got)
+	}
+}
@@ -84,6 +84,9 @@ func evaluateWithReason(item *model.Item, expr FilterExpr) string {
 		}
 		if val, exists := item.Metadata.Attributes[e.key]; exists {
 			if e.op == "" {
+				if val == "" {
+					return fmt.Sprintf("Attribute %q is empty", e.key)
+				}
 				return fmt.Sprintf("Has attribute %q = %q", e.key, val)
 			}
 			if e.Matches(item) {
@@ -3,9 +3,12 @@ package search
 import (
 	"fmt"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/lithammer/fuzzysearch/fuzzy"
 	"github.com/pstuifzand/tui-outliner/internal/model"
@@ -15,6 +18,11 @@ import (
 // normalizeForMatching removes diacritical marks from text for accent-insensitive matching
 // This normalizes the text to NFD form and removes combining marks (accents)
 func normalizeForMatching(s string) string {
+	// ASCII text has no accents, skip the decomposition
+	if isASCII(s) {
+		return s
+	}
+
 	// NFD decomposes characters into base character + combining marks
 	nfd := norm.NFD.String(s)
 	var result strings.Builder
@@ -27,6 +35,16 @@ func normalizeForMatching(s string) string {
 	return result.String()
 }
 
+// isASCII checks if s only contains ASCII characters
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
 // FilterExpr represents a filter expression that can match items
 type FilterExpr interface {
 	Matches(item *model.Item) bool
@@ -150,6 +168,66 @@ func (e *FuzzyExpr) GetMatchPositions(text string) []int {
 	return positions
 }
 
+// Score rates how well text matches the fuzzy term, higher is better. Matched
+// characters that follow each other, start words or come early in the text
+// score more, gaps between them score less. Returns -1 when text doesn't match.
+func (e *FuzzyExpr) Score(text string) int {
+	term := []rune(normalizeForMatching(strings.ToLower(e.term)))
+	runes := []rune(normalizeForMatching(strings.ToLower(text)))
+	if len(term) == 0 {
+		return 0
+	}
+
+	// Try every position of the first character, the leftmost match isn't
+	// always the closest
+	best := -1
+	for start, r := range runes {
+		if r != term[0] {
+			continue
+		}
+		score, pos, matched := 0, start, 0
+		prev := -1
+		for pos < len(runes) && matched < len(term) {
+			if runes[pos] == term[matched] {
+				if prev >= 0 && pos == prev+1 {
+					score += 8 // consecutive match
+				}
+				if pos == 0 || !unicode.IsLetter(runes[pos-1]) && !unicode.IsDigit(runes[pos-1]) {
+					score += 4 // start of a word
+				}
+				prev = pos
+				matched++
+			}
+			pos++
+		}
+		if matched < len(term) {
+			break // later starts can't match either
+		}
+		score += max(0, 10-start)              // early match
+		score -= prev - start + 1 - len(term) // gaps between matched characters
+		best = max(best, score)
+	}
+	return best
+}
+
+// RankByFuzzyScore sorts items by how well their text matches expr, best
+// first, when expr is a single fuzzy term. Items with the same score keep their
+// order. Returns false, leaving items alone, for other expressions.
+func RankByFuzzyScore(items []*model.Item, expr FilterExpr) bool {
+	fuzzyExpr, ok := expr.(*FuzzyExpr)
+	if !ok {
+		return false
+	}
+	scores := make(map[*model.Item]int, len(items))
+	for _, item := range items {
+		scores[item] = fuzzyExpr.Score(item.Text)
+	}
+	slices.SortStableFunc(items, func(a, b *model.Item) int {
+		return scores[b] - scores[a]
+	})
+	return true
+}
+
 // AlwaysMatchExpr matches all items (for empty queries)
 type AlwaysMatchExpr struct{}
 
@@ -261,8 +339,8 @@ func (e *AttributeFilter) Matches(item *model.Item) bool {
 	attrVal, exists := item.Metadata.Attributes[e.key]
 
 	if e.op == "" {
-		// Just checking for existence
-		return exists
+		// Just checking for existence, an empty value counts as not set
+		return exists && attrVal != ""
 	}
 
 	if !exists {
@@ -271,14 +349,38 @@ func (e *AttributeFilter) Matches(item *model.Item) bool {
 
 	switch e.op {
 	case "=":
-		return attrVal == e.value
+		return matchAttrValue(e.value, attrVal)
 	case "!=":
-		return attrVal != e.value
+		return !matchAttrValue(e.value, attrVal)
 	default:
 		return false
 	}
 }
 
+// matchAttrValue compares an attribute value with the value of a filter. A
+// value with * is a wildcard pattern where * matches any text, like "do*" or
+// "*urgent*". Other values must match exactly.
+func matchAttrValue(pattern, value string) bool {
+	if !strings.Contains(pattern, "*") {
+		return value == pattern
+	}
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+	value = value[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		idx := strings.Index(value, part)
+		if idx == -1 {
+			return false
+		}
+		value = value[idx+len(part):]
+	}
+	return len(value) >= len(last) && strings.HasSuffix(value, last)
+}
+
 func (e *AttributeFilter) String() string {
 	if e.op == "" {
 		return fmt.Sprintf("attr(%s)", e.key)
@@ -286,11 +388,83 @@ func (e *AttributeFilter) String() string {
 	return fmt.Sprintf("attr(%s%s%s)", e.key, e.op, e.value)
 }
 
+// AnyAttrExpr matches items where the value of any attribute contains the term
+// (case-insensitive, accent-insensitive). Attribute keys are not searched.
+type AnyAttrExpr struct {
+	term string
+}
+
+func NewAnyAttrExpr(term string) *AnyAttrExpr {
+	return &AnyAttrExpr{term: normalizeForMatching(strings.ToLower(term))}
+}
+
+func (e *AnyAttrExpr) Matches(item *model.Item) bool {
+	if item.Metadata == nil {
+		return false
+	}
+	for _, value := range item.Metadata.Attributes {
+		if strings.Contains(normalizeForMatching(strings.ToLower(value)), e.term) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *AnyAttrExpr) String() string {
+	return fmt.Sprintf("attr(*:%q)", e.term)
+}
+
+// AttributeNumberFilter matches items where an attribute is compared with >, >=, < or <=.
+// Numeric values are compared arithmetically; when either side is not a number the
+// values are compared as strings.
+type AttributeNumberFilter struct {
+	key   string
+	op    ComparisonOp
+	value string
+}
+
+func NewAttrNumberFilter(key string, op ComparisonOp, value string) *AttributeNumberFilter {
+	return &AttributeNumberFilter{key: key, op: op, value: value}
+}
+
+func (e *AttributeNumberFilter) Matches(item *model.Item) bool {
+	if item.Metadata == nil || item.Metadata.Attributes == nil {
+		return false
+	}
+
+	attrVal, exists := item.Metadata.Attributes[e.key]
+	if !exists {
+		return false
+	}
+
+	attrNum, attrErr := strconv.ParseFloat(strings.TrimSpace(attrVal), 64)
+	compareNum, compareErr := strconv.ParseFloat(e.value, 64)
+	if attrErr != nil || compareErr != nil {
+		// Fall back to string comparison
+		return compareOrdered(strings.Compare(attrVal, e.value), e.op)
+	}
+
+	switch {
+	case attrNum < compareNum:
+		return compareOrdered(-1, e.op)
+	case attrNum > compareNum:
+		return compareOrdered(1, e.op)
+	default:
+		return compareOrdered(0, e.op)
+	}
+}
+
+func (e *AttributeNumberFilter) String() string {
+	return fmt.Sprintf("attr(%s%s%s)", e.key, e.op, e.value)
+}
+
 // AttributeDateFilter matches items where an attribute contains a date value that matches a date comparison
 type AttributeDateFilter struct {
 	key   string
 	op    ComparisonOp
 	value string
+	from  string // Start of an OpRange, empty when open-ended
+	to    string // End of an OpRange, empty when open-ended
 }
 
 func NewAttrDateFilter(key string, op ComparisonOp, value string) (*AttributeDateFilter, error) {
@@ -301,6 +475,21 @@ func NewAttrDateFilter(key string, op ComparisonOp, value string) (*AttributeDat
 	return &AttributeDateFilter{key: key, op: op, value: value}, nil
 }
 
+// NewAttrDateRangeFilter creates a filter matching attribute dates from from
+// to to, both inclusive and compared by day. Either end may be empty for an
+// open-ended range.
+func NewAttrDateRangeFilter(key, from, to string) (*AttributeDateFilter, error) {
+	if from == "" && to == "" {
+		return nil, fmt.Errorf("date range for attribute filter needs a start or an end")
+	}
+	for _, value := range []string{from, to} {
+		if value != "" && !isValidDateValue(value) {
+			return nil, fmt.Errorf("invalid date value for attribute filter: %s", value)
+		}
+	}
+	return &AttributeDateFilter{key: key, op: OpRange, from: from, to: to}, nil
+}
+
 func (e *AttributeDateFilter) Matches(item *model.Item) bool {
 	if item.Metadata == nil || item.Metadata.Attributes == nil {
 		return false
@@ -318,6 +507,14 @@ func (e *AttributeDateFilter) Matches(item *model.Item) bool {
 		return false
 	}
 
+	if e.op == OpRange {
+		day := attrDate.Format("2006-01-02")
+		if e.from != "" && day < parseDate(e.from).Format("2006-01-02") {
+			return false
+		}
+		return e.to == "" || day <= parseDate(e.to).Format("2006-01-02")
+	}
+
 	// Parse the comparison date
 	compareDate := parseDate(e.value)
 	if compareDate.IsZero() {
@@ -325,29 +522,17 @@ func (e *AttributeDateFilter) Matches(item *model.Item) bool {
 	}
 
 	// Perform the comparison
-	switch e.op {
-	case OpGreater:
-		return attrDate.After(compareDate)
-	case OpGreaterEqual:
-		return attrDate.After(compareDate) || attrDate.Equal(compareDate)
-	case OpLess:
-		return attrDate.Before(compareDate)
-	case OpLessEqual:
-		return attrDate.Before(compareDate) || attrDate.Equal(compareDate)
-	case OpEqual:
-		return attrDate.Format("2006-01-02") == compareDate.Format("2006-01-02")
-	case OpNotEqual:
-		return attrDate.Format("2006-01-02") != compareDate.Format("2006-01-02")
-	default:
-		return false
-	}
+	return compareTimes(attrDate, e.op, compareDate)
 }
 
 func (e *AttributeDateFilter) String() string {
+	if e.op == OpRange {
+		return fmt.Sprintf("attr(%s:%s%s%s)", e.key, e.from, e.op, e.to)
+	}
 	return fmt.Sprintf("attr(%s%s%s)", e.key, e.op, e.value)
 }
 
-// TagFilter matches items that have a specific tag
+// TagFilter matches items that have a specific tag (case-insensitive)
 type TagFilter struct {
 	tag string
 }
@@ -363,7 +548,7 @@ func (e *TagFilter) Matches(item *model.Item) bool {
 
 	// Check if the tag exists in the item's tags
 	for _, t := range item.Metadata.Tags {
-		if t == e.tag {
+		if strings.EqualFold(t, e.tag) {
 			return true
 		}
 	}
@@ -376,9 +561,10 @@ func (e *TagFilter) String() string {
 
 // DateFilter matches items based on creation or modification date
 type DateFilter struct {
-	filterType FilterType
-	op         ComparisonOp
-	value      string
+	filterType  FilterType
+	op          ComparisonOp
+	value       string
+	dayGranular bool // Compare whole days in the local timezone (created:/modified:)
 }
 
 func NewDateFilter(filterType FilterType, op ComparisonOp, value string) (*DateFilter, error) {
@@ -389,6 +575,17 @@ func NewDateFilter(filterType FilterType, op ComparisonOp, value string) (*DateF
 	return &DateFilter{filterType: filterType, op: op, value: value}, nil
 }
 
+// NewDayDateFilter creates a date filter that compares calendar days in the local timezone.
+// Hour-based values (e.g. -2h) are still compared exactly.
+func NewDayDateFilter(filterType FilterType, op ComparisonOp, value string) (*DateFilter, error) {
+	filter, err := NewDateFilter(filterType, op, value)
+	if err != nil {
+		return nil, err
+	}
+	filter.dayGranular = true
+	return filter, nil
+}
+
 func (e *DateFilter) Matches(item *model.Item) bool {
 	var targetTime time.Time
 	if item.Metadata == nil {
@@ -411,22 +608,12 @@ func (e *DateFilter) Matches(item *model.Item) bool {
 		return false
 	}
 
-	switch e.op {
-	case OpGreater:
-		return targetTime.After(compareTime)
-	case OpGreaterEqual:
-		return targetTime.After(compareTime) || targetTime.Equal(compareTime)
-	case OpLess:
-		return targetTime.Before(compareTime)
-	case OpLessEqual:
-		return targetTime.Before(compareTime) || targetTime.Equal(compareTime)
-	case OpEqual:
-		return targetTime.Format("2006-01-02") == compareTime.Format("2006-01-02")
-	case OpNotEqual:
-		return targetTime.Format("2006-01-02") != compareTime.Format("2006-01-02")
-	default:
-		return false
+	if e.dayGranular && !strings.HasSuffix(e.value, "h") {
+		targetTime = localDay(targetTime.In(time.Local))
+		compareTime = localDay(compareTime)
 	}
+
+	return compareTimes(targetTime, e.op, compareTime)
 }
 
 func (e *DateFilter) String() string {
@@ -775,6 +962,39 @@ func compare(a int, op ComparisonOp, b int) bool {
 	}
 }
 
+// compareOrdered checks the result of a three-way comparison (-1, 0, 1) against the operator
+func compareOrdered(result int, op ComparisonOp) bool {
+	return compare(result, op, 0)
+}
+
+// compareTimes performs a comparison between two times based on the operator
+// Equality compares the calendar date only
+func compareTimes(a time.Time, op ComparisonOp, b time.Time) bool {
+	switch op {
+	case OpGreater:
+		return a.After(b)
+	case OpGreaterEqual:
+		return a.After(b) || a.Equal(b)
+	case OpLess:
+		return a.Before(b)
+	case OpLessEqual:
+		return a.Before(b) || a.Equal(b)
+	case OpEqual:
+		return a.Format("2006-01-02") == b.Format("2006-01-02")
+	case OpNotEqual:
+		return a.Format("2006-01-02") != b.Format("2006-01-02")
+	default:
+		return false
+	}
+}
+
+// localDay returns midnight in the local timezone for the calendar date of t
+// The date is taken in t's own location, so absolute dates (parsed as UTC) keep their day
+func localDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+}
+
 // isValidDateValue checks if a date value is in a valid format
 func isValidDateValue(value string) bool {
 	// Empty string is not a valid date
@@ -808,6 +1028,16 @@ func isValidDateValue(value string) bool {
 	return len(value) == 10 && value[4] == '-' && value[7] == '-'
 }
 
+// ParseDate parses a date value like the date filters do, either absolute
+// (YYYY-MM-DD) or relative (-7d, +1w, 3d). Returns the zero time for values
+// that aren't dates.
+func ParseDate(value string) time.Time {
+	if !isValidDateValue(value) {
+		return time.Time{}
+	}
+	return parseDate(value)
+}
+
 // parseDate parses a date value (relative or absolute) into a time.Time
 func parseDate(value string) time.Time {
 	now := time.Now()
@@ -900,10 +1130,34 @@ func GetFirstByQuery(outline *model.Outline, query string) (*model.Item, error)
 	return GetFirstMatchingItem(outline, filterExpr), nil
 }
 
+// FindParent returns the item with parentID, or the first item matching query
+// when parentID is empty. Used to find where new items are added. The index of
+// the outline must be up to date.
+func FindParent(outline *model.Outline, parentID, query string) (*model.Item, error) {
+	if parentID != "" {
+		parent := outline.FindItemByID(parentID)
+		if parent == nil {
+			return nil, fmt.Errorf("parent item not found: %s", parentID)
+		}
+		return parent, nil
+	}
+
+	parent, err := GetFirstByQuery(outline, query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parent query: %w", err)
+	}
+	if parent == nil {
+		return nil, fmt.Errorf("no item matches parent query: %s", query)
+	}
+	return parent, nil
+}
+
 func GetAlllByQuery(outline *model.Outline, query string) ([]*model.Item, error) {
 	filterExpr, err := ParseQuery(query)
 	if err != nil {
 		return nil, err
 	}
-	return GetMatchingItems(outline, filterExpr), nil
+	matches := GetMatchingItems(outline, filterExpr)
+	RankByFuzzyScore(matches, filterExpr)
+	return matches, nil
 }
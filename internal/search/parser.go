@@ -49,6 +49,7 @@ const (
 	OpGreaterEqual ComparisonOp = ">="
 	OpLess         ComparisonOp = "<"
 	OpLessEqual    ComparisonOp = "<="
+	OpRange        ComparisonOp = ".." // Inclusive date range, like 2025-01-01..2025-03-31
 )
 
 // Tokenizer converts a search query string into tokens
@@ -231,6 +232,14 @@ func (t *Tokenizer) readFilterCriteria() string {
 		if ch == ' ' || ch == '\t' || ch == '|' || ch == ')' {
 			break
 		}
+		// A quote at the start of a value runs to the closing quote, so the
+		// value can hold spaces
+		if (ch == '"' || ch == '\'') && (t.pos == start || strings.ContainsRune("=<>:", rune(t.input[t.pos-1]))) {
+			if end := strings.IndexByte(t.input[t.pos+1:], ch); end != -1 {
+				t.pos += end + 2
+				continue
+			}
+		}
 		t.pos++
 	}
 
@@ -252,15 +261,20 @@ func (t *Tokenizer) readText() Token {
 func (t *Tokenizer) readAttrFilter() Token {
 	t.pos++ // Skip @
 
-	// Read attribute key
+	// Read attribute key, or * for any attribute
 	keyStart := t.pos
-	for t.pos < len(t.input) && (isAlphaNumeric(t.input[t.pos]) || t.input[t.pos] == '_') {
+	if t.pos < len(t.input) && t.input[t.pos] == '*' {
 		t.pos++
+	} else {
+		for t.pos < len(t.input) && (isAlphaNumeric(t.input[t.pos]) || t.input[t.pos] == '_') {
+			t.pos++
+		}
 	}
 	key := t.input[keyStart:t.pos]
 
 	// Check if there's a comparison operator (no colon required for attributes)
-	if t.pos < len(t.input) && (t.input[t.pos] == '>' || t.input[t.pos] == '<' || t.input[t.pos] == '!' || t.input[t.pos] == '=') {
+	// or a date range or search term after a colon
+	if t.pos < len(t.input) && (t.input[t.pos] == '>' || t.input[t.pos] == '<' || t.input[t.pos] == '!' || t.input[t.pos] == '=' || t.input[t.pos] == ':') {
 		criteria := t.readFilterCriteria()
 		value := "@" + key + criteria
 		return Token{Type: TokenFilter, Value: value}
@@ -613,6 +627,10 @@ func parseFilterValue(value string) (FilterExpr, error) {
 		expr, err = parseDateFilter(FilterTypeCreated, criteria)
 	case "m":
 		expr, err = parseDateFilter(FilterTypeModified, criteria)
+	case "created":
+		expr, err = parseDayDateFilter(FilterTypeCreated, criteria)
+	case "modified":
+		expr, err = parseDayDateFilter(FilterTypeModified, criteria)
 	case "children":
 		expr, err = parseChildrenFilter(criteria)
 	case "parent", "p":
@@ -676,16 +694,36 @@ func parseDepthFilter(criteria string) (FilterExpr, error) {
 }
 
 func parseAttrFilter(criteria string) (FilterExpr, error) {
-	// Check for comparison operators (from longest to shortest to avoid partial matches)
-	ops := []string{"!=", ">=", "<=", ">", "<", "="}
-	var key, op, value string
+	// Check for a search in all attribute values: *:term
+	if term, ok := strings.CutPrefix(criteria, "*:"); ok {
+		if term = unquote(term); term == "" {
+			return nil, fmt.Errorf("missing search term for attribute values (use @*:term)")
+		}
+		return NewAnyAttrExpr(term), nil
+	}
 
-	for _, o := range ops {
-		if idx := strings.Index(criteria, o); idx != -1 {
+	// Check for a date range: key:FROM..TO
+	if key, dateRange, ok := strings.Cut(criteria, ":"); ok && !strings.ContainsAny(key, "!=<>") {
+		from, to, ok := strings.Cut(dateRange, string(OpRange))
+		if !ok {
+			return nil, fmt.Errorf("invalid date range for attribute filter: %s (use FROM..TO)", dateRange)
+		}
+		return NewAttrDateRangeFilter(key, from, to)
+	}
+
+	// Find the first comparison operator, so the value may contain operator
+	// characters too
+	var key, op, value string
+	if idx := strings.IndexAny(criteria, "!=<>"); idx != -1 {
+		op = criteria[idx : idx+1]
+		if idx+1 < len(criteria) && criteria[idx+1] == '=' && op != "=" {
+			op += "="
+		}
+		if op == "!" {
+			op = ""
+		} else {
 			key = criteria[:idx]
-			op = o
-			value = criteria[idx+len(o):]
-			break
+			value = unquote(criteria[idx+len(op):])
 		}
 	}
 
@@ -700,10 +738,24 @@ func parseAttrFilter(criteria string) (FilterExpr, error) {
 		return NewAttrDateFilter(key, compOp, value)
 	}
 
+	// Ordering operators compare numbers (or strings when not numeric)
+	switch ComparisonOp(op) {
+	case OpGreater, OpGreaterEqual, OpLess, OpLessEqual:
+		return NewAttrNumberFilter(key, ComparisonOp(op), value), nil
+	}
+
 	// Otherwise use regular string comparison
 	return NewAttrFilter(key, op, value), nil
 }
 
+// unquote removes matching single or double quotes around value
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
 func parseDateFilter(filterType FilterType, criteria string) (FilterExpr, error) {
 	op, val, err := parseComparison(criteria)
 	if err != nil {
@@ -712,6 +764,14 @@ func parseDateFilter(filterType FilterType, criteria string) (FilterExpr, error)
 	return NewDateFilter(filterType, op, val)
 }
 
+func parseDayDateFilter(filterType FilterType, criteria string) (FilterExpr, error) {
+	op, val, err := parseComparison(criteria)
+	if err != nil {
+		return nil, err
+	}
+	return NewDayDateFilter(filterType, op, val)
+}
+
 func parseChildrenFilter(criteria string) (FilterExpr, error) {
 	op, val, err := parseComparison(criteria)
 	if err != nil {
@@ -2798,3 +2798,614 @@ func TestAccentInsensitiveSearch(t *testing.T) {
 		})
 	}
 }
+
+// TestDayGranularDateFilters tests the created: and modified: filters that compare whole days
+func TestDayGranularDateFilters(t *testing.T) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	// Early on the day exactly one week ago, which an exact -7d comparison would exclude
+	weekAgoMorning := today.AddDate(0, 0, -7).Add(1 * time.Minute)
+
+	tests := []struct {
+		name         string
+		query        string
+		createdTime  time.Time
+		modifiedTime time.Time
+		matches      bool
+	}{
+		{
+			name:         "modified:>-7d matches item modified yesterday",
+			query:        "modified:>-7d",
+			createdTime:  today.AddDate(0, 0, -30),
+			modifiedTime: today.AddDate(0, 0, -1),
+			matches:      true,
+		},
+		{
+			name:         "modified:>-7d doesn't match item modified two weeks ago",
+			query:        "modified:>-7d",
+			createdTime:  today.AddDate(0, 0, -30),
+			modifiedTime: today.AddDate(0, 0, -14),
+			matches:      false,
+		},
+		{
+			name:         "modified:>=-7d matches the whole day a week ago",
+			query:        "modified:>=-7d",
+			createdTime:  today.AddDate(0, 0, -30),
+			modifiedTime: weekAgoMorning,
+			matches:      true,
+		},
+		{
+			name:         "created:DATE matches any time on that local day",
+			query:        "created:" + today.Format("2006-01-02"),
+			createdTime:  today.Add(23 * time.Hour),
+			modifiedTime: now,
+			matches:      true,
+		},
+		{
+			name:         "created:>DATE doesn't match the same day",
+			query:        "created:>" + today.Format("2006-01-02"),
+			createdTime:  today.Add(23 * time.Hour),
+			modifiedTime: now,
+			matches:      false,
+		},
+		{
+			name:         "created:<=DATE matches the same day",
+			query:        "created:<=" + today.Format("2006-01-02"),
+			createdTime:  today.Add(23 * time.Hour),
+			modifiedTime: now,
+			matches:      true,
+		},
+		{
+			name:         "zero timestamp never matches",
+			query:        "created:<=" + today.Format("2006-01-02"),
+			createdTime:  time.Time{},
+			modifiedTime: now,
+			matches:      false,
+		},
+		{
+			name:         "zero timestamp never matches not-equal",
+			query:        "modified:!=" + today.Format("2006-01-02"),
+			createdTime:  now,
+			modifiedTime: time.Time{},
+			matches:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+
+			item := &model.Item{
+				ID:   "test-item",
+				Text: "test",
+				Metadata: &model.Metadata{
+					Created:  tt.createdTime,
+					Modified: tt.modifiedTime,
+				},
+			}
+
+			matches := expr.Matches(item)
+			if matches != tt.matches {
+				t.Errorf("expected %v, got %v", tt.matches, matches)
+			}
+		})
+	}
+}
+
+func TestTagFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		itemText string
+		tags     []string
+		matches  bool
+	}{
+		{
+			name:     "#work matches item with tag",
+			query:    "#work",
+			itemText: "Write report",
+			tags:     []string{"work", "q4"},
+			matches:  true,
+		},
+		{
+			name:     "#work doesn't match item without tag",
+			query:    "#work",
+			itemText: "Write report",
+			tags:     []string{"home"},
+			matches:  false,
+		},
+		{
+			name:     "#work doesn't match item without tags",
+			query:    "#work",
+			itemText: "Write report",
+			tags:     nil,
+			matches:  false,
+		},
+		{
+			name:     "#work is case-insensitive",
+			query:    "#Work",
+			itemText: "Write report",
+			tags:     []string{"WORK"},
+			matches:  true,
+		},
+		{
+			name:     "#work looks at tags, not text",
+			query:    "#work",
+			itemText: "work on #work",
+			tags:     nil,
+			matches:  false,
+		},
+		{
+			name:     "-#work excludes item with tag",
+			query:    "-#work",
+			itemText: "Write report",
+			tags:     []string{"work"},
+			matches:  false,
+		},
+		{
+			name:     "-#work matches item without tag",
+			query:    "-#work",
+			itemText: "Write report",
+			tags:     []string{"home"},
+			matches:  true,
+		},
+		{
+			name:     "#work #urgent matches item with both tags",
+			query:    "#work #urgent",
+			itemText: "Write report",
+			tags:     []string{"urgent", "work"},
+			matches:  true,
+		},
+		{
+			name:     "#work #urgent doesn't match item with one tag",
+			query:    "#work #urgent",
+			itemText: "Write report",
+			tags:     []string{"work"},
+			matches:  false,
+		},
+		{
+			name:     "#work -#urgent matches item with only work",
+			query:    "#work -#urgent",
+			itemText: "Write report",
+			tags:     []string{"work"},
+			matches:  true,
+		},
+		{
+			name:     "#work | #home matches either tag",
+			query:    "#work | #home",
+			itemText: "Write report",
+			tags:     []string{"home"},
+			matches:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+
+			item := &model.Item{
+				ID:   "test-item",
+				Text: tt.itemText,
+				Metadata: &model.Metadata{
+					Tags:     tt.tags,
+					Created:  time.Now(),
+					Modified: time.Now(),
+				},
+			}
+			matches := expr.Matches(item)
+
+			if matches != tt.matches {
+				t.Errorf("expected %v, got %v", tt.matches, matches)
+			}
+		})
+	}
+}
+
+func TestAttributeNumberFilter(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		key       string
+		attrValue string
+		hasAttr   bool
+		matches   bool
+	}{
+		// Integer values
+		{name: "@priority>2 with 3", query: "@priority>2", key: "priority", attrValue: "3", hasAttr: true, matches: true},
+		{name: "@priority>2 with 2", query: "@priority>2", key: "priority", attrValue: "2", hasAttr: true, matches: false},
+		{name: "@priority>=2 with 2", query: "@priority>=2", key: "priority", attrValue: "2", hasAttr: true, matches: true},
+		{name: "@priority<2 with 1", query: "@priority<2", key: "priority", attrValue: "1", hasAttr: true, matches: true},
+		{name: "@priority<=2 with 3", query: "@priority<=2", key: "priority", attrValue: "3", hasAttr: true, matches: false},
+		{name: "@priority>2 compares numerically, not as strings", query: "@priority>2", key: "priority", attrValue: "10", hasAttr: true, matches: true},
+		{name: "@priority>-1 with negative bound", query: "@priority>-1", key: "priority", attrValue: "0", hasAttr: true, matches: true},
+		// Decimal values
+		{name: "@estimate<=8 with 7.5", query: "@estimate<=8", key: "estimate", attrValue: "7.5", hasAttr: true, matches: true},
+		{name: "@estimate<=8 with 8.0", query: "@estimate<=8", key: "estimate", attrValue: "8.0", hasAttr: true, matches: true},
+		{name: "@estimate>1.5 with 1.25", query: "@estimate>1.5", key: "estimate", attrValue: "1.25", hasAttr: true, matches: false},
+		{name: "@estimate>1.5 with 2", query: "@estimate>1.5", key: "estimate", attrValue: "2", hasAttr: true, matches: true},
+		// Missing and non-numeric values
+		{name: "@priority>2 without attribute", query: "@priority>2", key: "priority", hasAttr: false, matches: false},
+		{name: "@priority>2 with non-numeric value falls back to string", query: "@priority>2", key: "priority", attrValue: "high", hasAttr: true, matches: true},
+		{name: "@name<m with string values", query: "@name<m", key: "name", attrValue: "alice", hasAttr: true, matches: true},
+		{name: "@name<m with later string", query: "@name<m", key: "name", attrValue: "zoe", hasAttr: true, matches: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+
+			item := &model.Item{
+				ID:   "test-item",
+				Text: "test",
+				Metadata: &model.Metadata{
+					Attributes: map[string]string{},
+					Created:    time.Now(),
+					Modified:   time.Now(),
+				},
+			}
+			if tt.hasAttr {
+				item.Metadata.Attributes[tt.key] = tt.attrValue
+			}
+
+			matches := expr.Matches(item)
+			if matches != tt.matches {
+				t.Errorf("expected %v, got %v", tt.matches, matches)
+			}
+		})
+	}
+}
+
+func TestAttributeNumberFilterParsing(t *testing.T) {
+	expr, err := ParseQuery("@priority>=2")
+	assert.NoError(t, err)
+	filter, ok := expr.(*AttributeNumberFilter)
+	if !ok {
+		t.Fatalf("expected *AttributeNumberFilter, got %T", expr)
+	}
+	assert.Equal(t, "priority", filter.key)
+	assert.Equal(t, OpGreaterEqual, filter.op)
+	assert.Equal(t, "2", filter.value)
+
+	// Equality keeps using the string attribute filter
+	expr, err = ParseQuery("@priority=2")
+	assert.NoError(t, err)
+	if _, ok := expr.(*AttributeFilter); !ok {
+		t.Errorf("expected *AttributeFilter for equality, got %T", expr)
+	}
+}
+
+func TestMissingAttributeFilter(t *testing.T) {
+	for _, tt := range []struct {
+		query string
+		want  string
+	}{
+		{"-@status", "(not attr(status))"},
+		{"@type=todo -@status", "(and attr(type=todo) (not attr(status)))"},
+		{"-@status | @url", "(or (not attr(status)) attr(url))"},
+	} {
+		expr, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Fatalf("ParseQuery(%q) failed: %v", tt.query, err)
+		}
+		if got := expr.String(); got != tt.want {
+			t.Errorf("ParseQuery(%q) = %s, want %s", tt.query, got, tt.want)
+		}
+	}
+
+	newTodo := func(attrs map[string]string) *model.Item {
+		item := model.NewItem("Task")
+		item.Metadata.Attributes["type"] = "todo"
+		for k, v := range attrs {
+			item.Metadata.Attributes[k] = v
+		}
+		return item
+	}
+	noMetadata := model.NewItem("Note")
+	noMetadata.Metadata = nil
+
+	expr, err := ParseQuery("@type=todo -@status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name string
+		item *model.Item
+		want bool
+	}{
+		{"todo without status", newTodo(nil), true},
+		{"todo with empty status", newTodo(map[string]string{"status": ""}), true},
+		{"todo with status", newTodo(map[string]string{"status": "todo"}), false},
+		{"item without type", model.NewItem("Note"), false},
+		{"item without metadata", noMetadata, false},
+	} {
+		if got := expr.Matches(tt.item); got != tt.want {
+			t.Errorf("%s: Matches = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	missing, err := ParseQuery("-@status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !missing.Matches(noMetadata) {
+		t.Error("Expected an item without metadata to match -@status")
+	}
+	exists, err := ParseQuery("@status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists.Matches(newTodo(map[string]string{"status": ""})) {
+		t.Error("Expected an empty status not to match @status")
+	}
+}
+
+func TestAttributeDateRangeFilter(t *testing.T) {
+	now := time.Now()
+	day := func(offset int) string {
+		return now.AddDate(0, 0, offset).Format("2006-01-02")
+	}
+
+	tests := []struct {
+		query   string
+		dateVal string
+		matches bool
+	}{
+		// Both ends are included
+		{"@date:2025-01-01..2025-03-31", "2025-01-01", true},
+		{"@date:2025-01-01..2025-03-31", "2025-03-31", true},
+		{"@date:2025-01-01..2025-03-31", "2025-02-15", true},
+		{"@date:2025-01-01..2025-03-31", "2024-12-31", false},
+		{"@date:2025-01-01..2025-03-31", "2025-04-01", false},
+		// Open-ended ranges
+		{"@date:..2025-03-31", "2025-03-31", true},
+		{"@date:..2025-03-31", "1999-01-01", true},
+		{"@date:..2025-03-31", "2025-04-01", false},
+		{"@date:2025-01-01..", "2025-01-01", true},
+		{"@date:2025-01-01..", "2024-12-31", false},
+		// Relative ends
+		{"@date:-7d..+7d", day(-7), true},
+		{"@date:-7d..+7d", day(0), true},
+		{"@date:-7d..+7d", day(7), true},
+		{"@date:-7d..+7d", day(-8), false},
+		{"@date:-7d..+7d", day(8), false},
+		// Values that aren't dates never match
+		{"@date:2025-01-01..", "soon", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query+" "+tt.dateVal, func(t *testing.T) {
+			expr, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if _, ok := expr.(*AttributeDateFilter); !ok {
+				t.Fatalf("expected *AttributeDateFilter, got %T", expr)
+			}
+
+			item := model.NewItem("test")
+			item.Metadata.Attributes["date"] = tt.dateVal
+			if matches := expr.Matches(item); matches != tt.matches {
+				t.Errorf("query %s with date %s: expected %v, got %v", tt.query, tt.dateVal, tt.matches, matches)
+			}
+		})
+	}
+
+	expr, err := ParseQuery("@deadline:-7d..+7d #work")
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if got := expr.String(); got != "(and attr(deadline:-7d..+7d) tag(work))" {
+		t.Errorf("Unexpected expression %s", got)
+	}
+
+	for _, query := range []string{"@date:..", "@date:2025-01-01", "@date:soon..2025-03-31", "@date:2025-01-01..later"} {
+		if _, err := ParseQuery(query); err == nil {
+			t.Errorf("Expected %q to fail to parse", query)
+		}
+	}
+}
+
+func TestQuotedAttributeValues(t *testing.T) {
+	newItem := func(key, value string) *model.Item {
+		item := model.NewItem("test")
+		item.Metadata.Attributes[key] = value
+		return item
+	}
+
+	tests := []struct {
+		query   string
+		want    string
+		item    *model.Item
+		matches bool
+	}{
+		{`@project="Big Launch"`, "attr(project=Big Launch)", newItem("project", "Big Launch"), true},
+		{`@project='Big Launch'`, "attr(project=Big Launch)", newItem("project", "Big Launch"), true},
+		{`@project="Big Launch"`, "attr(project=Big Launch)", newItem("project", "Big"), false},
+		{`@project!="Big Launch"`, "attr(project!=Big Launch)", newItem("project", "Small Launch"), true},
+		{`@formula="a = b"`, "attr(formula=a = b)", newItem("formula", "a = b"), true},
+		{`@formula=a=b`, "attr(formula=a=b)", newItem("formula", "a=b"), true},
+		{`@range="x >= y"`, "attr(range=x >= y)", newItem("range", "x >= y"), true},
+		{`@quote="it's here"`, "attr(quote=it's here)", newItem("quote", "it's here"), true},
+		{`@name=O'Brien`, "attr(name=O'Brien)", newItem("name", "O'Brien"), true},
+		{`@project="Big Launch" #work`, "(and attr(project=Big Launch) tag(work))", nil, false},
+		{`-@project='Big Launch'`, "(not attr(project=Big Launch))", newItem("project", "Other"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			expr, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if got := expr.String(); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+			if tt.item != nil && expr.Matches(tt.item) != tt.matches {
+				t.Errorf("expected match %v for %v", tt.matches, tt.item.Metadata.Attributes)
+			}
+		})
+	}
+}
+
+func TestAttributeWildcardFilter(t *testing.T) {
+	tests := []struct {
+		query   string
+		value   string
+		matches bool
+	}{
+		// Prefix
+		{"@status=do*", "doing", true},
+		{"@status=do*", "done", true},
+		{"@status=do*", "do", true},
+		{"@status=do*", "todo", false},
+		// Suffix
+		{"@file=*.go", "main.go", true},
+		{"@file=*.go", "main.go.bak", false},
+		// Contains
+		{"@tags=*urgent*", "very urgent stuff", true},
+		{"@tags=*urgent*", "urgent", true},
+		{"@tags=*urgent*", "Urgent", false},
+		// Several wildcards
+		{"@path=a*b*c", "aXbYc", true},
+		{"@path=a*b*c", "abc", true},
+		{"@path=a*b*c", "aXcYb", false},
+		{"@path=ab*ba", "aba", false},
+		{"@any=*", "", true},
+		// Negation
+		{"@status!=do*", "todo", true},
+		{"@status!=do*", "done", false},
+		// Plain values stay exact
+		{"@status=do", "doing", false},
+		{"@status=do", "do", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query+" "+tt.value, func(t *testing.T) {
+			expr, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			item := model.NewItem("test")
+			for _, key := range []string{"status", "file", "tags", "path", "any"} {
+				item.Metadata.Attributes[key] = tt.value
+			}
+			if matches := expr.Matches(item); matches != tt.matches {
+				t.Errorf("query %s with value %q: expected %v, got %v", tt.query, tt.value, tt.matches, matches)
+			}
+		})
+	}
+}
+
+func TestAnyAttributeValueFilter(t *testing.T) {
+	item := model.NewItem("Read later")
+	item.Metadata.Attributes["url"] = "https://Example.com/docs"
+	item.Metadata.Attributes["note"] = "Call back on Monday"
+	plain := model.NewItem("example.com in the text")
+
+	tests := []struct {
+		query   string
+		want    string
+		matches bool
+	}{
+		{"@*:example.com", `attr(*:"example.com")`, true},
+		{"@*:EXAMPLE", `attr(*:"example")`, true},
+		{`@*:"call back"`, `attr(*:"call back")`, true},
+		{"@*:'on monday'", `attr(*:"on monday")`, true},
+		// Keys are not searched
+		{"@*:url", `attr(*:"url")`, false},
+		{"@*:note", `attr(*:"note")`, false},
+		{"@*:missing", `attr(*:"missing")`, false},
+		{"-@*:missing", `(not attr(*:"missing"))`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			expr, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if got := expr.String(); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+			if matches := expr.Matches(item); matches != tt.matches {
+				t.Errorf("expected match %v, got %v", tt.matches, matches)
+			}
+		})
+	}
+
+	// Text search does not look at attribute values, and @*: not at the text
+	if expr, _ := ParseQuery("example.com"); expr.Matches(item) {
+		t.Error("Expected text search to ignore attribute values")
+	}
+	if expr, _ := ParseQuery("@*:example.com"); expr.Matches(plain) {
+		t.Error("Expected @*: to ignore the item text")
+	}
+	if _, err := ParseQuery("@*:"); err == nil {
+		t.Error("Expected @*: without a term to fail")
+	}
+}
+
+func TestFuzzyScore(t *testing.T) {
+	expr := NewFuzzyExpr("task")
+	tests := []struct {
+		closer    string
+		scattered string
+	}{
+		{"task", "t a s k"},
+		{"my task list", "the amazing sky kite"},
+		{"Task", "a long list with task"}, // early match
+		{"tame the task", "tame the tusk and kite"},
+		{"t... task", "t... tXaXsXk"}, // a later start can be closer
+		{"bad task", "badtask"},       // word starts score more
+	}
+	for _, tt := range tests {
+		closer, scattered := expr.Score(tt.closer), expr.Score(tt.scattered)
+		if closer <= scattered {
+			t.Errorf("Expected %q (%d) to score higher than %q (%d)", tt.closer, closer, tt.scattered, scattered)
+		}
+	}
+
+	if score := expr.Score("project"); score != -1 {
+		t.Errorf("Expected -1 for a non-match, got %d", score)
+	}
+	if score := NewFuzzyExpr("tâsk").Score("TASK"); score < 0 {
+		t.Errorf("Expected an accent-insensitive match, got %d", score)
+	}
+}
+
+func TestGetAllByQueryRanksFuzzyMatches(t *testing.T) {
+	outline := model.NewOutline()
+	for _, text := range []string{"the amazing sky kite", "task", "t a s k", "my task"} {
+		outline.Items = append(outline.Items, model.NewItem(text))
+	}
+
+	matches, err := GetAlllByQuery(outline, "~task")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, item := range matches {
+		got = append(got, item.Text)
+	}
+	if want := "[task my task t a s k the amazing sky kite]"; fmt.Sprint(got) != want {
+		t.Errorf("Expected %s, got %v", want, got)
+	}
+
+	// Other queries keep the outline order
+	matches, err = GetAlllByQuery(outline, "task")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 || matches[0].Text != "task" || matches[1].Text != "my task" {
+		t.Errorf("Unexpected text matches %v", matches)
+	}
+}
@@ -94,3 +94,25 @@ func TestRefExprParsing(t *testing.T) {
 		})
 	}
 }
+
+func TestFindParent(t *testing.T) {
+	project := model.NewItem("Project")
+	project.Metadata.Attributes["type"] = "project"
+
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{model.NewItem("Other"), project}
+	outline.BuildIndex()
+
+	if parent, err := FindParent(outline, project.ID, ""); err != nil || parent != project {
+		t.Errorf("Expected to find parent by ID, got %v (err=%v)", parent, err)
+	}
+	if parent, err := FindParent(outline, "", "@type=project"); err != nil || parent != project {
+		t.Errorf("Expected to find parent by query, got %v (err=%v)", parent, err)
+	}
+	if _, err := FindParent(outline, "item_missing", ""); err == nil {
+		t.Error("Expected an error for an unknown parent ID")
+	}
+	if _, err := FindParent(outline, "", "@type=area"); err == nil {
+		t.Error("Expected an error when no item matches the parent query")
+	}
+}
@@ -0,0 +1,93 @@
+package search
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+// SortItems sorts search results in place by a sort spec of the form
+// "<field>[:desc]", where field is one of id, text, created, modified or depth.
+// The sort is stable, so items that compare equal keep the outline order.
+func SortItems(items []*model.Item, spec string) error {
+	field, descending, err := parseSortSpec(spec)
+	if err != nil {
+		return err
+	}
+
+	var compareItems func(a, b *model.Item) int
+	switch field {
+	case "id":
+		compareItems = func(a, b *model.Item) int { return strings.Compare(a.ID, b.ID) }
+	case "text":
+		compareItems = func(a, b *model.Item) int {
+			return strings.Compare(strings.ToLower(a.Text), strings.ToLower(b.Text))
+		}
+	case "created":
+		compareItems = func(a, b *model.Item) int {
+			return itemTimestamp(a, false).Compare(itemTimestamp(b, false))
+		}
+	case "modified":
+		compareItems = func(a, b *model.Item) int {
+			return itemTimestamp(a, true).Compare(itemTimestamp(b, true))
+		}
+	case "depth":
+		compareItems = func(a, b *model.Item) int {
+			return cmp.Compare(calculateDepth(a), calculateDepth(b))
+		}
+	}
+
+	slices.SortStableFunc(items, func(a, b *model.Item) int {
+		if descending {
+			return compareItems(b, a)
+		}
+		return compareItems(a, b)
+	})
+	return nil
+}
+
+// ValidateSortSpec checks that a sort spec can be used with SortItems
+func ValidateSortSpec(spec string) error {
+	_, _, err := parseSortSpec(spec)
+	return err
+}
+
+// LimitItems returns at most limit items. A limit of 0 or less means no limit.
+func LimitItems(items []*model.Item, limit int) []*model.Item {
+	if limit <= 0 || len(items) <= limit {
+		return items
+	}
+	return items[:limit]
+}
+
+// itemTimestamp returns the created or modified time of an item, zero without metadata
+func itemTimestamp(item *model.Item, modified bool) time.Time {
+	if item.Metadata == nil {
+		return time.Time{}
+	}
+	if modified {
+		return item.Metadata.Modified
+	}
+	return item.Metadata.Created
+}
+
+// parseSortSpec splits "<field>[:asc|:desc]" into the field and the direction
+func parseSortSpec(spec string) (string, bool, error) {
+	field, direction, _ := strings.Cut(spec, ":")
+	switch direction {
+	case "", "asc":
+	case "desc":
+	default:
+		return "", false, fmt.Errorf("invalid sort direction: %s (use asc or desc)", direction)
+	}
+
+	switch field {
+	case "id", "text", "created", "modified", "depth":
+		return field, direction == "desc", nil
+	}
+	return "", false, fmt.Errorf("invalid sort field: %s (use id, text, created, modified or depth)", field)
+}
@@ -0,0 +1,59 @@
+package search
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+func TestSortItemsAndLimit(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var items []*model.Item
+	for i, text := range []string{"b task", "a task", "c task"} {
+		item := model.NewItem(text)
+		item.Metadata.Modified = base.Add(time.Duration(i) * time.Hour)
+		items = append(items, item)
+	}
+
+	if err := SortItems(items, "modified:desc"); err != nil {
+		t.Fatalf("SortItems failed: %v", err)
+	}
+	limited := LimitItems(items, 2)
+	if texts := itemTexts(limited); !slices.Equal(texts, []string{"c task", "a task"}) {
+		t.Errorf("Expected newest two items, got %v", texts)
+	}
+
+	if err := SortItems(items, "text"); err != nil {
+		t.Fatalf("SortItems failed: %v", err)
+	}
+	if texts := itemTexts(items); !slices.Equal(texts, []string{"a task", "b task", "c task"}) {
+		t.Errorf("Expected items sorted by text, got %v", texts)
+	}
+
+	if got := LimitItems(items, 0); len(got) != 3 {
+		t.Errorf("Expected limit 0 to keep all items, got %d", len(got))
+	}
+}
+
+func TestValidateSortSpec(t *testing.T) {
+	for _, spec := range []string{"id", "created:asc", "depth:desc"} {
+		if err := ValidateSortSpec(spec); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", spec, err)
+		}
+	}
+	for _, spec := range []string{"", "priority", "text:up"} {
+		if err := ValidateSortSpec(spec); err == nil {
+			t.Errorf("Expected %q to be invalid", spec)
+		}
+	}
+}
+
+func itemTexts(items []*model.Item) []string {
+	var texts []string
+	for _, item := range items {
+		texts = append(texts, item.Text)
+	}
+	return texts
+}
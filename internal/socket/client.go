@@ -3,6 +3,7 @@ package socket
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/fs"
 	"net"
 	"os"
@@ -140,6 +141,36 @@ func (c *Client) SendAddNode(text, target string, attributes map[string]string)
 	return c.Send(msg)
 }
 
+// SendAddNodeToParent sends an add_node command that adds the item under a parent,
+// found by ID or by the first item matching parentQuery. The server reports an error
+// when the parent cannot be found.
+func (c *Client) SendAddNodeToParent(text, parentID, parentQuery string, attributes map[string]string) (*Response, error) {
+	msg := Message{
+		Command:     CommandAddNode,
+		Text:        text,
+		ParentID:    parentID,
+		ParentQuery: parentQuery,
+		Attributes:  attributes,
+	}
+
+	return c.Send(msg)
+}
+
+// SendAddNodes sends an add_nodes command that adds all nodes in one request.
+// Nodes without a parent of their own go under the item found by parentID or
+// parentQuery, or the inbox when both are empty. The server adds all nodes or,
+// when one of them can't be added, none of them.
+func (c *Client) SendAddNodes(nodes []NodeSpec, parentID, parentQuery string) (*Response, error) {
+	msg := Message{
+		Command:     CommandAddNodes,
+		ParentID:    parentID,
+		ParentQuery: parentQuery,
+		Nodes:       nodes,
+	}
+
+	return c.Send(msg)
+}
+
 // SendExportMarkdown is a convenience method to send an export_markdown command
 func (c *Client) SendExportMarkdown(exportPath string) (*Response, error) {
 	msg := Message{
@@ -150,13 +181,79 @@ func (c *Client) SendExportMarkdown(exportPath string) (*Response, error) {
 	return c.Send(msg)
 }
 
-// SendSearch is a convenience method to send a search command
-func (c *Client) SendSearch(query string, fields []string, format string) (*Response, error) {
+// SendSearch is a convenience method to send a search command.
+// sort and limit are optional, an empty sort keeps the outline order and a limit of 0 returns all results.
+func (c *Client) SendSearch(query string, fields []string, format string, sort string, limit int) (*Response, error) {
 	msg := Message{
 		Command: CommandSearch,
 		Query:   query,
 		Fields:  fields,
 		Format:  format,
+		Sort:    sort,
+		Limit:   limit,
+	}
+
+	return c.Send(msg)
+}
+
+// SendToggleTodo is a convenience method to send a toggle_todo command, which moves
+// the item with the given ID to its next todo status. The response message holds the new status.
+func (c *Client) SendToggleTodo(id string) (*Response, error) {
+	msg := Message{
+		Command: CommandToggleTodo,
+		ID:      id,
+	}
+
+	return c.Send(msg)
+}
+
+// Watch subscribes to the events of the running instance and calls fn for
+// each of them. It returns when the connection closes, when the instance
+// quits, or with the first error of fn.
+func (c *Client) Watch(fn func(event Event) error) error {
+	conn, err := net.Dial("unix", c.socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to connect to socket: %w", err)
+	}
+	defer conn.Close()
+
+	encoder := json.NewEncoder(conn)
+	decoder := json.NewDecoder(conn)
+
+	// Only the response has a timeout, events can take any time
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if err := encoder.Encode(Message{Command: CommandSubscribe}); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	var response Response
+	if err := decoder.Decode(&response); err != nil {
+		return fmt.Errorf("failed to receive response: %w", err)
+	}
+	if !response.Success {
+		return fmt.Errorf("server error: %s", response.Message)
+	}
+	conn.SetDeadline(time.Time{})
+
+	for {
+		var event Event
+		if err := decoder.Decode(&event); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("failed to receive event: %w", err)
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+}
+
+// SendGetOutline is a convenience method to send a get_outline command. With an empty
+// query the response holds the whole outline, otherwise only the subtrees of the matching items.
+func (c *Client) SendGetOutline(query string) (*Response, error) {
+	msg := Message{
+		Command: CommandGetOutline,
+		Query:   query,
 	}
 
 	return c.Send(msg)
@@ -0,0 +1,267 @@
+package socket
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"slices"
+	"sync"
+	"time"
+)
+
+// JSON-RPC 2.0 error codes
+const (
+	RPCParseError     = -32700 // The request is not valid JSON
+	RPCInvalidRequest = -32600 // The request is not a JSON-RPC 2.0 request
+	RPCMethodNotFound = -32601 // The method is not one of RPCMethods
+	RPCInvalidParams  = -32602 // The params are not an object with Message fields
+	RPCCommandFailed  = -32000 // The app could not run the command, the message tells why
+)
+
+// RPCMethods are the methods of the JSON-RPC protocol. They have the names
+// and take the fields of the legacy commands.
+var RPCMethods = []string{CommandAddNode, CommandAddNodes, CommandSearch, CommandGetOutline, CommandToggleTodo, CommandExportMarkdown, CommandSubscribe}
+
+// RPCEventMethod is the method of the notifications the server sends to a
+// subscribed JSON-RPC connection, with an Event as params
+const RPCEventMethod = "event"
+
+// RPCRequest is a JSON-RPC 2.0 request. Params is an object with the fields of
+// a Message, like {"text": "Buy milk"} for add_node. A request without ID is a
+// notification and gets no response.
+type RPCRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+// RPCResponse is a JSON-RPC 2.0 response with either a result or an error.
+// The result has the fields of a Response.
+type RPCResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  *Response       `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// RPCError is the error of a JSON-RPC response
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+// isRPC reports whether the first value on a connection is a JSON-RPC request
+// or batch. Legacy messages have no jsonrpc field.
+func isRPC(raw json.RawMessage) bool {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) > 0 && raw[0] == '[' {
+		return true
+	}
+	var probe struct {
+		JSONRPC *string `json:"jsonrpc"`
+	}
+	return json.Unmarshal(raw, &probe) == nil && probe.JSONRPC != nil
+}
+
+// rpcConn is a JSON-RPC connection. Events of a subscription are written
+// between the responses, so writes take the lock.
+type rpcConn struct {
+	server  *Server
+	mu      sync.Mutex
+	encoder *json.Encoder
+	events  chan Event // Set after a subscribe request
+}
+
+// write encodes a response or notification on the connection
+func (c *rpcConn) write(v any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.encoder.Encode(v)
+}
+
+// forwardEvents writes the events of the subscription as notifications until
+// done is closed or the server stops
+func (c *rpcConn) forwardEvents(done <-chan struct{}) {
+	for {
+		select {
+		case event := <-c.events:
+			notification := struct {
+				JSONRPC string `json:"jsonrpc"`
+				Method  string `json:"method"`
+				Params  Event  `json:"params"`
+			}{"2.0", RPCEventMethod, event}
+			if err := c.write(notification); err != nil {
+				return
+			}
+		case <-done:
+			return
+		case <-c.server.stopChan:
+			return
+		}
+	}
+}
+
+// serveRPC answers JSON-RPC requests on a connection until the client closes
+// it, starting with the already decoded first request. Requests are separated
+// by whitespace, usually a newline, and handled one at a time in order. After
+// a subscribe request, events are sent as notifications until then.
+func (s *Server) serveRPC(first json.RawMessage, decoder *json.Decoder, encoder *json.Encoder) {
+	c := &rpcConn{server: s, encoder: encoder}
+	done := make(chan struct{})
+	defer func() {
+		close(done)
+		if c.events != nil {
+			s.unsubscribe(c.events)
+		}
+	}()
+
+	forwarding := false
+	raw := first
+	for {
+		if reply := c.handleRPC(raw); reply != nil {
+			if err := c.write(reply); err != nil {
+				return
+			}
+		}
+		// Start sending events after the response to subscribe
+		if c.events != nil && !forwarding {
+			forwarding = true
+			go c.forwardEvents(done)
+		}
+
+		raw = nil
+		if err := decoder.Decode(&raw); err != nil {
+			// The stream can't be read after invalid JSON, report it and stop
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) || err == io.ErrUnexpectedEOF {
+				c.write(rpcError(nil, RPCParseError, err.Error()))
+			} else if err != io.EOF && !errors.Is(err, net.ErrClosed) {
+				log.Printf("Error decoding JSON-RPC request: %v", err)
+			}
+			return
+		}
+	}
+}
+
+// handleRPC handles a request or a batch of requests, and returns the
+// response, the responses of the batch, or nil when there is nothing to answer
+func (c *rpcConn) handleRPC(raw json.RawMessage) any {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || raw[0] != '[' {
+		if response := c.handleRPCRequest(raw); response != nil {
+			return response
+		}
+		return nil
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(raw, &batch); err != nil {
+		return rpcError(nil, RPCParseError, err.Error())
+	}
+	if len(batch) == 0 {
+		return rpcError(nil, RPCInvalidRequest, "empty batch")
+	}
+	var responses []*RPCResponse
+	for _, request := range batch {
+		if response := c.handleRPCRequest(request); response != nil {
+			responses = append(responses, response)
+		}
+	}
+	if len(responses) == 0 {
+		return nil
+	}
+	return responses
+}
+
+// handleRPCRequest passes a request to the app as a Message and converts the
+// response. Returns nil for notifications.
+func (c *rpcConn) handleRPCRequest(raw json.RawMessage) *RPCResponse {
+	var request RPCRequest
+	if err := json.Unmarshal(raw, &request); err != nil {
+		return rpcError(nil, RPCInvalidRequest, err.Error())
+	}
+	if request.JSONRPC != "2.0" || request.Method == "" {
+		return rpcError(request.ID, RPCInvalidRequest, `expected "jsonrpc": "2.0" and a method`)
+	}
+	if !slices.Contains(RPCMethods, request.Method) {
+		return rpcError(request.ID, RPCMethodNotFound, "unknown method: "+request.Method)
+	}
+
+	var msg Message
+	if params := bytes.TrimSpace(request.Params); len(params) > 0 && !bytes.Equal(params, []byte("null")) {
+		if params[0] != '{' {
+			return rpcError(request.ID, RPCInvalidParams, "params must be an object")
+		}
+		if err := json.Unmarshal(params, &msg); err != nil {
+			return rpcError(request.ID, RPCInvalidParams, err.Error())
+		}
+	}
+	msg.Command = request.Method
+	// Every method answers after the app ran it, so errors reach the client
+	msg.ResponseChan = make(chan *Response, 1)
+
+	var response *Response
+	if msg.Command == CommandSubscribe {
+		if c.events != nil {
+			response = &Response{Success: false, Message: "Already subscribed"}
+		} else {
+			c.events, response = c.server.subscribeApp()
+		}
+	} else {
+		response = c.server.dispatch(msg)
+	}
+	if request.ID == nil {
+		return nil
+	}
+	if !response.Success {
+		return rpcError(request.ID, RPCCommandFailed, response.Message)
+	}
+	return &RPCResponse{JSONRPC: "2.0", Result: response, ID: request.ID}
+}
+
+// rpcError returns an error response for the request with the given ID
+func rpcError(id json.RawMessage, code int, message string) *RPCResponse {
+	return &RPCResponse{JSONRPC: "2.0", Error: &RPCError{Code: code, Message: message}, ID: id}
+}
+
+// Call sends a JSON-RPC request for method, with params encoded as the params
+// object, and returns the result. When the server answers with an error, the
+// error is an *RPCError.
+func (c *Client) Call(method string, params any) (*Response, error) {
+	conn, err := net.Dial("unix", c.socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to socket: %w", err)
+	}
+	defer conn.Close()
+
+	// Set a timeout for the operation
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	request := struct {
+		JSONRPC string `json:"jsonrpc"`
+		Method  string `json:"method"`
+		Params  any    `json:"params,omitempty"`
+		ID      int    `json:"id"`
+	}{"2.0", method, params, 1}
+	if err := json.NewEncoder(conn).Encode(request); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var response RPCResponse
+	if err := json.NewDecoder(conn).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to receive response: %w", err)
+	}
+	if response.Error != nil {
+		return nil, response.Error
+	}
+	return response.Result, nil
+}
@@ -0,0 +1,140 @@
+package socket
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startRPCServer starts a server with a fake app that answers search with one
+// result per query word and fails toggle_todo for unknown items
+func startRPCServer(t *testing.T) *Server {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	server, err := NewServer(os.Getpid())
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	t.Cleanup(server.Stop)
+	server.Start()
+
+	go func() {
+		for msg := range server.Messages() {
+			if msg.ResponseChan == nil {
+				// A legacy async command, JSON-RPC requests always wait
+				continue
+			}
+			switch msg.Command {
+			case CommandSearch:
+				var results []SearchResult
+				for _, word := range strings.Fields(msg.Query) {
+					results = append(results, SearchResult{"text": word})
+				}
+				msg.ResponseChan <- &Response{Success: true, Message: "Found", Results: results}
+			case CommandToggleTodo:
+				msg.ResponseChan <- &Response{Success: false, Message: "item not found: " + msg.ID}
+			default:
+				msg.ResponseChan <- &Response{Success: true, Message: msg.Command + " " + msg.Text}
+			}
+		}
+	}()
+	return server
+}
+
+func TestRPCCall(t *testing.T) {
+	server := startRPCServer(t)
+	client, err := NewClient(server.SocketPath())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	result, err := client.Call(CommandSearch, map[string]any{"query": "milk eggs"})
+	if err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	if len(result.Results) != 2 || result.Results[1]["text"] != "eggs" {
+		t.Errorf("Expected the search results in the result, got %+v", result)
+	}
+
+	_, err = client.Call(CommandToggleTodo, Message{ID: "item_missing"})
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != RPCCommandFailed || rpcErr.Message != "item not found: item_missing" {
+		t.Errorf("Expected the failure of the app as error, got %v", err)
+	}
+
+	// The legacy protocol keeps working next to JSON-RPC
+	response, err := client.SendAddNode("Buy milk", "inbox", nil)
+	if err != nil || !response.Success {
+		t.Errorf("Expected the legacy add_node to work, got %+v, %v", response, err)
+	}
+}
+
+func TestRPCConnection(t *testing.T) {
+	server := startRPCServer(t)
+	conn, err := net.Dial("unix", server.SocketPath())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	requests := []string{
+		`{"jsonrpc": "2.0", "method": "add_node", "params": {"text": "Buy milk"}, "id": 1}`,
+		`{"jsonrpc": "2.0", "method": "add_node", "params": {"text": "Notification"}}`,
+		`{"jsonrpc": "2.0", "method": "delete_everything", "id": "a"}`,
+		`{"jsonrpc": "2.0", "method": "search", "params": ["milk"], "id": 2}`,
+		`{"jsonrpc": "1.0", "method": "search", "id": 3}`,
+		`[{"jsonrpc": "2.0", "method": "search", "params": {"query": "a b c"}, "id": 4}, {"jsonrpc": "2.0", "method": "add_node"}, 5]`,
+		`{"jsonrpc": `,
+	}
+	if _, err := conn.Write([]byte(strings.Join(requests, "\n"))); err != nil {
+		t.Fatalf("Failed to send requests: %v", err)
+	}
+	conn.(*net.UnixConn).CloseWrite()
+
+	var replies []string
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		replies = append(replies, scanner.Text())
+	}
+
+	decode := func(reply string) RPCResponse {
+		var response RPCResponse
+		if err := json.Unmarshal([]byte(reply), &response); err != nil {
+			t.Fatalf("Invalid response %q: %v", reply, err)
+		}
+		return response
+	}
+	expectError := func(reply string, id string, code int) {
+		t.Helper()
+		response := decode(reply)
+		if response.Error == nil || response.Error.Code != code || string(response.ID) != id {
+			t.Errorf("Expected error %d for id %s, got %s", code, id, reply)
+		}
+	}
+
+	// The notification gets no reply, the others are answered in order
+	if len(replies) != 6 {
+		t.Fatalf("Expected 6 replies, got %d:\n%s", len(replies), strings.Join(replies, "\n"))
+	}
+	if response := decode(replies[0]); response.Result == nil || response.Result.Message != "add_node Buy milk" || string(response.ID) != "1" {
+		t.Errorf("Unexpected reply to add_node: %s", replies[0])
+	}
+	expectError(replies[1], `"a"`, RPCMethodNotFound)
+	expectError(replies[2], "2", RPCInvalidParams)
+	expectError(replies[3], "3", RPCInvalidRequest)
+
+	var batch []RPCResponse
+	if err := json.Unmarshal([]byte(replies[4]), &batch); err != nil {
+		t.Fatalf("Expected a batch reply, got %s", replies[4])
+	}
+	if len(batch) != 2 || batch[0].Result == nil || len(batch[0].Result.Results) != 3 || batch[1].Error == nil || batch[1].Error.Code != RPCInvalidRequest {
+		t.Errorf("Expected a search result and an invalid request in the batch, got %s", replies[4])
+	}
+
+	expectError(replies[5], "null", RPCParseError)
+}
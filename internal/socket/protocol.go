@@ -1,34 +1,90 @@
 package socket
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // Message represents a command sent to the running tuo instance
 type Message struct {
-	Command    string            `json:"command"`
-	Text       string            `json:"text,omitempty"`
-	Target     string            `json:"target,omitempty"`     // Default: "inbox"
-	Attributes map[string]string `json:"attributes,omitempty"` // Attributes to set on the new item
-	ExportPath string            `json:"export_path,omitempty"` // Path for export commands
-	Query      string            `json:"query,omitempty"`      // Search query
-	Fields     []string          `json:"fields,omitempty"`     // Fields to include in search results
-	Format     string            `json:"format,omitempty"`     // Output format for search results
+	Command     string            `json:"command"`
+	Text        string            `json:"text,omitempty"`
+	Target      string            `json:"target,omitempty"`       // Default: "inbox"
+	ParentID    string            `json:"parent_id,omitempty"`    // Add the new item under the item with this ID
+	ParentQuery string            `json:"parent_query,omitempty"` // Add the new item under the first item matching this query
+	Attributes  map[string]string `json:"attributes,omitempty"`   // Attributes to set on the new item
+	ExportPath  string            `json:"export_path,omitempty"`  // Path for export commands
+	Query       string            `json:"query,omitempty"`        // Search query
+	Fields      []string          `json:"fields,omitempty"`       // Fields to include in search results
+	Format      string            `json:"format,omitempty"`       // Output format for search results
+	Sort        string            `json:"sort,omitempty"`         // Sort spec for search results, e.g. "modified:desc"
+	Limit       int               `json:"limit,omitempty"`        // Maximum number of search results, applied after sorting
+	ID          string            `json:"id,omitempty"`           // Item ID for commands that act on one item
+	Nodes       []NodeSpec        `json:"nodes,omitempty"`        // Nodes for add_nodes, in order
 
 	// Internal field for synchronous responses (not sent over the wire)
 	ResponseChan chan *Response `json:"-"`
 }
 
+// HasParent reports whether an add_node message targets a specific parent instead of the inbox
+func (m Message) HasParent() bool {
+	return m.ParentID != "" || m.ParentQuery != ""
+}
+
+// NodeSpec is a node of an add_nodes message. Without a parent of its own the
+// node is added under the parent of the message, or the inbox.
+type NodeSpec struct {
+	Text        string            `json:"text"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+	ParentID    string            `json:"parent_id,omitempty"`    // Add the node under the item with this ID
+	ParentQuery string            `json:"parent_query,omitempty"` // Add the node under the first item matching this query
+	Children    []NodeSpec        `json:"children,omitempty"`     // Nodes added under this node, their parents are ignored
+}
+
+// HasParent reports whether the node targets a specific parent
+func (n NodeSpec) HasParent() bool {
+	return n.ParentID != "" || n.ParentQuery != ""
+}
+
 // SearchResult represents a single search result item with flexible fields
-// The actual fields included depend on the Fields parameter in the search request
+// The requested fields are included, together with the CompleteResultFields
 type SearchResult map[string]interface{}
 
+// CompleteResultFields are included in every search result, so clients can rebuild
+// the matched items and format them with any fields they like
+var CompleteResultFields = []string{"id", "text", "attributes", "created", "modified", "tags", "depth", "parent_id", "path"}
+
 // Response represents the response from the server
 type Response struct {
-	Success bool           `json:"success"`
-	Message string         `json:"message"`
-	Results []SearchResult `json:"results,omitempty"` // For search commands
+	Success bool            `json:"success"`
+	Message string          `json:"message"`
+	Results []SearchResult  `json:"results,omitempty"` // For search commands
+	Outline json.RawMessage `json:"outline,omitempty"` // For get_outline, the outline in the file format
+}
+
+// Event is pushed to subscribed clients when the outline of the running
+// instance changes or is saved
+type Event struct {
+	Event   string    `json:"event"`             // EventChanged or EventSaved
+	File    string    `json:"file,omitempty"`    // The file of the outline
+	IDs     []string  `json:"ids,omitempty"`     // Changed and added items, for EventChanged
+	Removed []string  `json:"removed,omitempty"` // Deleted items, for EventChanged
+	Time    time.Time `json:"time"`
 }
 
+// Event types
+const (
+	EventChanged = "changed"
+	EventSaved   = "saved"
+)
+
 // Command types
 const (
 	CommandAddNode        = "add_node"
+	CommandAddNodes       = "add_nodes"
 	CommandExportMarkdown = "export_markdown"
 	CommandSearch         = "search"
+	CommandToggleTodo     = "toggle_todo"
+	CommandGetOutline     = "get_outline"
+	CommandSubscribe      = "subscribe"
 )
@@ -8,6 +8,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 )
 
@@ -17,6 +18,9 @@ type Server struct {
 	listener   net.Listener
 	msgChan    chan Message
 	stopChan   chan struct{}
+
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{} // Event channels of subscribed connections
 }
 
 // NewServer creates a new Unix socket server
@@ -51,10 +55,11 @@ func NewServer(pid int) (*Server, error) {
 	log.Printf("Socket server listening on: %s", socketPath)
 
 	return &Server{
-		socketPath: socketPath,
-		listener:   listener,
-		msgChan:    make(chan Message, 10), // Buffer up to 10 messages
-		stopChan:   make(chan struct{}),
+		socketPath:  socketPath,
+		listener:    listener,
+		msgChan:     make(chan Message, 10), // Buffer up to 10 messages
+		stopChan:    make(chan struct{}),
+		subscribers: make(map[chan Event]struct{}),
 	}, nil
 }
 
@@ -86,15 +91,27 @@ func (s *Server) acceptLoop() {
 	}
 }
 
-// handleConnection processes a single client connection
+// handleConnection processes a single client connection. A connection that
+// starts with a JSON-RPC 2.0 request is served as JSON-RPC, see serveRPC.
+// Otherwise it carries one legacy Message and its Response.
 func (s *Server) handleConnection(conn net.Conn) {
 	defer conn.Close()
 
 	decoder := json.NewDecoder(conn)
 	encoder := json.NewEncoder(conn)
 
+	var raw json.RawMessage
+	err := decoder.Decode(&raw)
+	if err == nil && isRPC(raw) {
+		s.serveRPC(raw, decoder, encoder)
+		return
+	}
+
 	var msg Message
-	if err := decoder.Decode(&msg); err != nil {
+	if err == nil {
+		err = json.Unmarshal(raw, &msg)
+	}
+	if err != nil {
 		if err != io.EOF {
 			log.Printf("Error decoding message: %v", err)
 		}
@@ -116,40 +133,49 @@ func (s *Server) handleConnection(conn net.Conn) {
 		return
 	}
 
-	// For synchronous commands (like search), create a response channel
-	if msg.Command == CommandSearch {
+	// A subscribed connection stays open for events
+	if msg.Command == CommandSubscribe {
+		s.serveSubscription(conn, encoder)
+		return
+	}
+
+	// For synchronous commands (like search, fetching the outline, toggling a
+	// todo, adding under a parent that may not exist, or adding a batch that
+	// may fail as a whole), create a response channel
+	if msg.Command == CommandSearch || msg.Command == CommandGetOutline || msg.Command == CommandToggleTodo || msg.Command == CommandAddNodes || (msg.Command == CommandAddNode && msg.HasParent()) {
 		msg.ResponseChan = make(chan *Response, 1)
 	}
 
-	// Send message to channel for processing
+	encoder.Encode(s.dispatch(msg))
+}
+
+// dispatch passes msg to the app and returns its response. Without a response
+// channel the message is acknowledged as soon as the app received it.
+func (s *Server) dispatch(msg Message) *Response {
 	select {
 	case s.msgChan <- msg:
 		// For synchronous commands, wait for response
 		if msg.ResponseChan != nil {
 			select {
 			case response := <-msg.ResponseChan:
-				encoder.Encode(response)
+				return response
 			case <-time.After(10 * time.Second):
-				response := Response{
+				return &Response{
 					Success: false,
 					Message: "Command timed out",
 				}
-				encoder.Encode(response)
-			}
-		} else {
-			// For async commands, acknowledge immediately
-			response := Response{
-				Success: true,
-				Message: "Command queued",
 			}
-			encoder.Encode(response)
+		}
+		// For async commands, acknowledge immediately
+		return &Response{
+			Success: true,
+			Message: "Command queued",
 		}
 	case <-s.stopChan:
-		response := Response{
+		return &Response{
 			Success: false,
 			Message: "Server is shutting down",
 		}
-		encoder.Encode(response)
 	}
 }
 
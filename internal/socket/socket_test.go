@@ -201,3 +201,133 @@ func TestSendAddNodeWithAttributes(t *testing.T) {
 		t.Fatal("Timeout waiting for message")
 	}
 }
+
+func TestSendAddNodeToParentWaitsForResponse(t *testing.T) {
+	// Create a server
+	pid := os.Getpid()
+	server, err := NewServer(pid)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer server.Stop()
+
+	server.Start()
+
+	// Wait a bit for server to be ready
+	time.Sleep(100 * time.Millisecond)
+
+	// Reply like the app does when the parent cannot be found
+	go func() {
+		msg := <-server.Messages()
+		if msg.ParentQuery != "@type=project" {
+			t.Errorf("Expected parent query '@type=project', got '%s'", msg.ParentQuery)
+		}
+		if msg.ResponseChan == nil {
+			t.Error("Expected add_node with a parent to be synchronous")
+			return
+		}
+		msg.ResponseChan <- &Response{Success: false, Message: "no item matches parent query"}
+	}()
+
+	client, err := NewClient(server.SocketPath())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	response, err := client.SendAddNodeToParent("Next step", "", "@type=project", nil)
+	if err != nil {
+		t.Fatalf("Failed to send add_node: %v", err)
+	}
+	if response.Success {
+		t.Error("Expected the app's failure response to reach the client")
+	}
+	if response.Message != "no item matches parent query" {
+		t.Errorf("Unexpected response message: %s", response.Message)
+	}
+}
+
+func TestSendToggleTodoWaitsForResponse(t *testing.T) {
+	// Create a server
+	pid := os.Getpid()
+	server, err := NewServer(pid)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer server.Stop()
+
+	server.Start()
+
+	// Wait a bit for server to be ready
+	time.Sleep(100 * time.Millisecond)
+
+	// Reply with the new status like the app does
+	go func() {
+		msg := <-server.Messages()
+		if msg.Command != CommandToggleTodo || msg.ID != "item_1" {
+			t.Errorf("Expected toggle_todo for item_1, got %s for '%s'", msg.Command, msg.ID)
+		}
+		if msg.ResponseChan == nil {
+			t.Error("Expected toggle_todo to be synchronous")
+			return
+		}
+		msg.ResponseChan <- &Response{Success: true, Message: "doing"}
+	}()
+
+	client, err := NewClient(server.SocketPath())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	response, err := client.SendToggleTodo("item_1")
+	if err != nil {
+		t.Fatalf("Failed to send toggle_todo: %v", err)
+	}
+	if !response.Success || response.Message != "doing" {
+		t.Errorf("Expected the new status in the response, got %+v", response)
+	}
+}
+
+func TestSendAddNodesWaitsForResponse(t *testing.T) {
+	// Create a server
+	pid := os.Getpid()
+	server, err := NewServer(pid)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer server.Stop()
+
+	server.Start()
+
+	// Wait a bit for server to be ready
+	time.Sleep(100 * time.Millisecond)
+
+	// Reply like the app does after adding the batch
+	go func() {
+		msg := <-server.Messages()
+		if msg.Command != CommandAddNodes || len(msg.Nodes) != 2 || msg.Nodes[1].Children[0].Text != "Book hotel" {
+			t.Errorf("Expected add_nodes with the nodes, got %s with %+v", msg.Command, msg.Nodes)
+		}
+		if msg.ResponseChan == nil {
+			t.Error("Expected add_nodes to be synchronous")
+			return
+		}
+		msg.ResponseChan <- &Response{Success: true, Message: "Added 3 nodes"}
+	}()
+
+	client, err := NewClient(server.SocketPath())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	nodes := []NodeSpec{
+		{Text: "Buy milk"},
+		{Text: "Plan trip", Children: []NodeSpec{{Text: "Book hotel"}}},
+	}
+	response, err := client.SendAddNodes(nodes, "", "")
+	if err != nil {
+		t.Fatalf("Failed to send add_nodes: %v", err)
+	}
+	if !response.Success || response.Message != "Added 3 nodes" {
+		t.Errorf("Expected the app's response to reach the client, got %+v", response)
+	}
+}
@@ -0,0 +1,93 @@
+package socket
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net"
+)
+
+// eventBuffer is the number of events a slow subscriber can fall behind before
+// events for it are dropped
+const eventBuffer = 64
+
+// subscribe registers a channel that receives the published events
+func (s *Server) subscribe() chan Event {
+	events := make(chan Event, eventBuffer)
+	s.mu.Lock()
+	s.subscribers[events] = struct{}{}
+	s.mu.Unlock()
+	return events
+}
+
+// unsubscribe stops sending events to a channel of subscribe
+func (s *Server) unsubscribe(events chan Event) {
+	s.mu.Lock()
+	delete(s.subscribers, events)
+	s.mu.Unlock()
+}
+
+// HasSubscribers reports whether a client is subscribed to events, so the app
+// only tracks changes when someone listens
+func (s *Server) HasSubscribers() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subscribers) > 0
+}
+
+// Publish sends an event to every subscribed client. It doesn't block: a
+// client that doesn't keep up misses the event.
+func (s *Server) Publish(event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for events := range s.subscribers {
+		select {
+		case events <- event:
+		default:
+			log.Printf("Dropped %s event for a slow subscriber", event.Event)
+		}
+	}
+}
+
+// subscribeApp subscribes to events and lets the app know, so it starts
+// tracking changes. The subscription starts before the app answers, so no
+// change after the response is missed. Returns nil when the app refused.
+func (s *Server) subscribeApp() (chan Event, *Response) {
+	events := s.subscribe()
+	response := s.dispatch(Message{Command: CommandSubscribe, ResponseChan: make(chan *Response, 1)})
+	if !response.Success {
+		s.unsubscribe(events)
+		return nil, response
+	}
+	return events, response
+}
+
+// serveSubscription answers a legacy subscribe message and then writes each
+// event as a JSON line, until the client disconnects or the server stops
+func (s *Server) serveSubscription(conn net.Conn, encoder *json.Encoder) {
+	events, response := s.subscribeApp()
+	if err := encoder.Encode(response); err != nil || events == nil {
+		return
+	}
+	defer s.unsubscribe(events)
+
+	// The client sends nothing after subscribing, reading ends when it disconnects
+	closed := make(chan struct{})
+	go func() {
+		io.Copy(io.Discard, conn)
+		close(closed)
+	}()
+
+	for {
+		select {
+		case event := <-events:
+			if err := encoder.Encode(event); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		case <-s.stopChan:
+			return
+		}
+	}
+}
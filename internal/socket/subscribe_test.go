@@ -0,0 +1,106 @@
+package socket
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// waitForSubscribers waits until the server has subscribers or not
+func waitForSubscribers(t *testing.T, server *Server, want bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for server.HasSubscribers() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected HasSubscribers to become %v", want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	server := startRPCServer(t)
+	client, err := NewClient(server.SocketPath())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	errDone := errors.New("done")
+	received := make(chan Event, 2)
+	watchErr := make(chan error, 1)
+	go func() {
+		watchErr <- client.Watch(func(event Event) error {
+			received <- event
+			if event.Event == EventSaved {
+				return errDone
+			}
+			return nil
+		})
+	}()
+
+	waitForSubscribers(t, server, true)
+	server.Publish(Event{Event: EventChanged, IDs: []string{"item_a"}, Removed: []string{"item_b"}})
+	server.Publish(Event{Event: EventSaved, File: "notes.json"})
+
+	if err := <-watchErr; err != errDone {
+		t.Fatalf("Expected Watch to return the error of fn, got %v", err)
+	}
+	if event := <-received; event.Event != EventChanged || event.IDs[0] != "item_a" || event.Removed[0] != "item_b" {
+		t.Errorf("Unexpected first event %+v", event)
+	}
+	if event := <-received; event.Event != EventSaved || event.File != "notes.json" {
+		t.Errorf("Unexpected second event %+v", event)
+	}
+
+	// The client disconnected, so the server stops sending it events
+	waitForSubscribers(t, server, false)
+}
+
+func TestRPCSubscribe(t *testing.T) {
+	server := startRPCServer(t)
+	conn, err := net.Dial("unix", server.SocketPath())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	scanner := bufio.NewScanner(conn)
+
+	conn.Write([]byte(`{"jsonrpc": "2.0", "method": "subscribe", "id": 1}` + "\n"))
+	if !scanner.Scan() {
+		t.Fatalf("Expected a response to subscribe: %v", scanner.Err())
+	}
+	var response RPCResponse
+	if err := json.Unmarshal(scanner.Bytes(), &response); err != nil || response.Result == nil || !response.Result.Success {
+		t.Fatalf("Expected subscribe to succeed, got %s", scanner.Text())
+	}
+
+	server.Publish(Event{Event: EventChanged, IDs: []string{"item_a"}})
+	if !scanner.Scan() {
+		t.Fatalf("Expected an event notification: %v", scanner.Err())
+	}
+	var notification struct {
+		Method string
+		Params Event
+		ID     *int
+	}
+	if err := json.Unmarshal(scanner.Bytes(), &notification); err != nil || notification.Method != RPCEventMethod || notification.ID != nil || notification.Params.IDs[0] != "item_a" {
+		t.Errorf("Expected a changed event notification, got %s", scanner.Text())
+	}
+
+	// Requests still work on the subscribed connection
+	conn.Write([]byte(`{"jsonrpc": "2.0", "method": "subscribe", "id": 2}` + "\n"))
+	if !scanner.Scan() || !json.Valid(scanner.Bytes()) {
+		t.Fatalf("Expected a response to the second subscribe: %v", scanner.Err())
+	}
+	response = RPCResponse{}
+	json.Unmarshal(scanner.Bytes(), &response)
+	if response.Error == nil || string(response.ID) != "2" {
+		t.Errorf("Expected an error for subscribing twice, got %s", scanner.Text())
+	}
+
+	conn.Close()
+	waitForSubscribers(t, server, false)
+}
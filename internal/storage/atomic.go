@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// writeFileAtomic writes data to a temporary file next to filePath and renames
+// it into place, so a crash or a full disk never leaves a truncated file behind.
+// The permissions of an existing file are preserved. When filePath is a
+// symlink the file it points to is replaced, so the link stays in place.
+func writeFileAtomic(filePath string, data []byte, perm os.FileMode) error {
+	if info, err := os.Stat(filePath); err == nil {
+		perm = info.Mode().Perm()
+		if target, err := filepath.EvalSymlinks(filePath); err == nil {
+			filePath = target
+		}
+	}
+
+	dir := filepath.Dir(filePath)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(filePath)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	// Remove the temporary file unless it was renamed into place
+	renamed := false
+	defer func() {
+		if !renamed {
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+
+	if err := renameOver(tmpPath, filePath); err != nil {
+		return err
+	}
+	renamed = true
+
+	syncDir(dir)
+	return nil
+}
+
+// renameOver renames src to dst, replacing dst. On Windows the rename fails
+// while another process has dst open, so it is retried after removing dst.
+func renameOver(src, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil || runtime.GOOS != "windows" {
+		return err
+	}
+
+	if removeErr := os.Remove(dst); removeErr != nil && !os.IsNotExist(removeErr) {
+		return fmt.Errorf("failed to replace %s: %w", dst, err)
+	}
+	return os.Rename(src, dst)
+}
+
+// syncDir flushes the directory entry of a rename to disk. Directories cannot
+// be synced on Windows, and failures are ignored as the data itself is synced.
+func syncDir(dir string) {
+	if runtime.GOOS == "windows" {
+		return
+	}
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+func TestSaveToFileIsAtomic(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "notes.json")
+	if err := os.WriteFile(filePath, []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	outline := model.NewOutline()
+	outline.Items = append(outline.Items, model.NewItem("Saved item"))
+
+	store := &JSONStore{FilePath: filePath}
+	if err := store.Save(outline); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected permissions 0600 to be preserved, got %o", info.Mode().Perm())
+	}
+
+	loaded, err := store.Load()
+	if err != nil || len(loaded.Items) != 1 || loaded.Items[0].Text != "Saved item" {
+		t.Fatalf("Expected saved outline to load, got %v", err)
+	}
+
+	// No temporary files are left next to the outline
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		t.Errorf("Expected only the outline file, got %v", names)
+	}
+}
+
+func TestSaveThroughSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "synced", "notes.json")
+	if err := os.Mkdir(filepath.Dir(target), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "notes.json")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+
+	outline := model.NewOutline()
+	outline.Items = append(outline.Items, model.NewItem("Saved item"))
+	if err := (&JSONStore{FilePath: link}).Save(outline); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("Expected the symlink to stay in place, got %v", err)
+	}
+	loaded, err := (&JSONStore{FilePath: target}).Load()
+	if err != nil || len(loaded.Items) != 1 || loaded.Items[0].Text != "Saved item" {
+		t.Fatalf("Expected the outline to be written to the target, got %v", err)
+	}
+	if info, _ := os.Stat(target); info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected permissions 0600 to be preserved, got %o", info.Mode().Perm())
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("Expected no temporary files next to the link, got %d entries", len(entries))
+	}
+}
+
+func TestSaveToNewFileUsesDefaultPermissions(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "new.json")
+
+	store := &JSONStore{FilePath: filePath}
+	if err := store.Save(model.NewOutline()); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o644 {
+		t.Errorf("Expected permissions 0644 for a new file, got %o", info.Mode().Perm())
+	}
+}
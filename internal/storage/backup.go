@@ -1,12 +1,18 @@
 package storage
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pstuifzand/tui-outliner/internal/model"
@@ -15,8 +21,28 @@ import (
 // BackupManager handles backup creation for outline files
 type BackupManager struct {
 	backupDir string
+	compress  bool           // Write gzip compressed backups
+	key       *encryptionKey // Encrypt backups with this key, set for encrypted files
 }
 
+// backupExt and compressedBackupExt are the extensions of backup files
+const (
+	backupExt           = ".tuo"
+	compressedBackupExt = ".tuo.gz"
+)
+
+// gzipMagic are the first bytes of gzip compressed data
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// originalFileLine starts the line with the original file name in backups
+// written by CreateBackup. JSON strings can't contain a newline, so at this
+// indentation it is always the field of the outline itself.
+var originalFileLine = []byte("\n  \"original_filename\": ")
+
+// backupTailSize is how much of the end of a backup is searched for the
+// original file name
+const backupTailSize = 64 * 1024
+
 // NewBackupManager creates a new backup manager
 func NewBackupManager() (*BackupManager, error) {
 	// Ensure backup directory exists
@@ -30,6 +56,50 @@ func NewBackupManager() (*BackupManager, error) {
 	}, nil
 }
 
+// SetCompression enables or disables gzip compression of new backups.
+// Existing backups are read in either form.
+func (bm *BackupManager) SetCompression(enabled bool) {
+	bm.compress = enabled
+}
+
+// ReadOutlineFile reads an outline file, decrypting and decompressing it when
+// needed. Both are detected by the magic bytes, so plain, compressed and
+// encrypted backups can be read without knowing how they were written.
+// Encrypted files are decrypted with the passphrases set in this process.
+func ReadOutlineFile(filePath string) ([]byte, error) {
+	return readOutlineFile(filePath, knownPassphrases())
+}
+
+// readOutlineFile reads an outline file like ReadOutlineFile, decrypting it
+// with one of passphrases
+func readOutlineFile(filePath string, passphrases []string) ([]byte, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if IsEncryptedData(data) {
+		data, err = decryptWithPassphrases(data, passphrases)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt %s: %w", filePath, err)
+		}
+	}
+	if !bytes.HasPrefix(data, gzipMagic) {
+		return data, nil
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s: %w", filePath, err)
+	}
+	defer reader.Close()
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(reader); err != nil {
+		return nil, fmt.Errorf("failed to decompress %s: %w", filePath, err)
+	}
+	return buf.Bytes(), nil
+}
+
 // CreateBackup creates a timestamped backup of the outline before saving
 // It stores both the outline data and the original filename
 func (bm *BackupManager) CreateBackup(outline *model.Outline, originalPath string, sessionID string) error {
@@ -55,6 +125,25 @@ func (bm *BackupManager) CreateBackup(outline *model.Outline, originalPath strin
 		return fmt.Errorf("failed to marshal backup JSON: %w", err)
 	}
 
+	if bm.compress {
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		if _, err := writer.Write(data); err != nil {
+			return fmt.Errorf("failed to compress backup: %w", err)
+		}
+		if err := writer.Close(); err != nil {
+			return fmt.Errorf("failed to compress backup: %w", err)
+		}
+		data = buf.Bytes()
+	}
+
+	if bm.key != nil {
+		data, err = bm.key.encrypt(data)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt backup: %w", err)
+		}
+	}
+
 	// Write backup file
 	if err := os.WriteFile(backupPath, data, 0o644); err != nil {
 		return fmt.Errorf("failed to write backup file: %w", err)
@@ -64,9 +153,13 @@ func (bm *BackupManager) CreateBackup(outline *model.Outline, originalPath strin
 }
 
 // generateBackupFilename creates a filename in the format: YYYYMMDD_HHMMSS_<sessionID>.tuo
+// Compressed backups end in .tuo.gz
 func (bm *BackupManager) generateBackupFilename(sessionID string) string {
 	timestamp := time.Now().Format("20060102_150405")
-	return fmt.Sprintf("%s_%s.tuo", timestamp, sessionID)
+	if bm.compress {
+		return timestamp + "_" + sessionID + compressedBackupExt
+	}
+	return timestamp + "_" + sessionID + backupExt
 }
 
 // getBackupDir returns the path to the backup directory
@@ -99,8 +192,6 @@ func (bm *BackupManager) FindBackupsForFile(originalFilePath string) ([]BackupMe
 		return nil, fmt.Errorf("failed to read backup directory: %w", err)
 	}
 
-	var backups []BackupMetadata
-
 	// Normalize the search path to absolute for consistent comparison
 	var searchPath string
 	if originalFilePath != "" {
@@ -113,15 +204,41 @@ func (bm *BackupManager) FindBackupsForFile(originalFilePath string) ([]BackupMe
 		}
 	}
 
+	var names []string
 	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".tuo") {
+		if entry.IsDir() || !(strings.HasSuffix(entry.Name(), backupExt) || strings.HasSuffix(entry.Name(), compressedBackupExt)) {
 			continue
 		}
+		names = append(names, entry.Name())
+	}
 
-		// Parse metadata from file
-		metadata, err := parseBackupFilename(entry.Name(), filepath.Join(bm.backupDir, entry.Name()))
-		if err != nil {
-			continue // Skip files that can't be parsed
+	// Parse metadata from the files with a worker per CPU
+	parsed := make([]*BackupMetadata, len(names))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), len(names)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				metadata, err := parseBackupFilename(names[i], filepath.Join(bm.backupDir, names[i]))
+				if err != nil {
+					continue // Skip files that can't be parsed
+				}
+				parsed[i] = &metadata
+			}
+		}()
+	}
+	for i := range names {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	var backups []BackupMetadata
+	for _, metadata := range parsed {
+		if metadata == nil {
+			continue
 		}
 
 		// If originalFilePath specified, filter by it
@@ -133,7 +250,7 @@ func (bm *BackupManager) FindBackupsForFile(originalFilePath string) ([]BackupMe
 			}
 		}
 
-		backups = append(backups, metadata)
+		backups = append(backups, *metadata)
 	}
 
 	// Sort chronologically by timestamp
@@ -149,39 +266,260 @@ func parseBackupFilename(filename string, fullPath string) (BackupMetadata, erro
 		return BackupMetadata{}, fmt.Errorf("filename too short")
 	}
 
-	// Extract timestamp: YYYYMMDD_HHMMSS (15 characters)
-	timestampStr := filename[:15]
-
 	// Extract session ID: 8 characters after the second underscore
 	sessionID := filename[16 : 16+8]
 
-	// Parse timestamp
-	timestamp, err := time.Parse("20060102_150405", timestampStr)
+	timestamp, err := ParseBackupTimestamp(filename)
 	if err != nil {
-		return BackupMetadata{}, fmt.Errorf("invalid timestamp format: %w", err)
-	}
-
-	// Read the backup file to get original filename
-	var originalFile string
-	data, err := os.ReadFile(fullPath)
-	if err == nil {
-		var outline model.Outline
-		if err := json.Unmarshal(data, &outline); err == nil {
-			originalFile = outline.OriginalFilename
-		}
+		return BackupMetadata{}, err
 	}
 
 	return BackupMetadata{
 		FilePath:     fullPath,
 		Timestamp:    timestamp,
 		SessionID:    sessionID,
-		OriginalFile: originalFile,
+		OriginalFile: readBackupOriginalFile(fullPath),
 	}, nil
 }
 
+// readBackupOriginalFile returns the original file name stored in a backup.
+// Only the end of uncompressed, unencrypted backups is read, where
+// CreateBackup writes the name after the items. Other backups are read as a
+// whole, without decoding the items.
+func readBackupOriginalFile(fullPath string) string {
+	if originalFile, ok := readBackupOriginalFileFromTail(fullPath); ok {
+		return originalFile
+	}
+
+	data, err := ReadOutlineFile(fullPath)
+	if err != nil {
+		return ""
+	}
+	var header struct {
+		OriginalFilename string `json:"original_filename"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return ""
+	}
+	return header.OriginalFilename
+}
+
+// readBackupOriginalFileFromTail looks for the original file name in the last
+// backupTailSize bytes of a plain backup. Returns false when it isn't there.
+func readBackupOriginalFileFromTail(fullPath string) (string, bool) {
+	f, err := os.Open(fullPath)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", false
+	}
+	head := make([]byte, max(len(encryptedMagic), len(gzipMagic)))
+	n, _ := f.ReadAt(head, 0)
+	if IsEncryptedData(head[:n]) || bytes.HasPrefix(head[:n], gzipMagic) {
+		return "", false
+	}
+
+	offset := max(info.Size()-backupTailSize, 0)
+	tail := make([]byte, info.Size()-offset)
+	if _, err := f.ReadAt(tail, offset); err != nil && err != io.EOF {
+		return "", false
+	}
+
+	start := bytes.LastIndex(tail, originalFileLine)
+	if start < 0 {
+		return "", false
+	}
+	value := tail[start+len(originalFileLine):]
+	end := bytes.IndexByte(value, '\n')
+	if end < 0 {
+		return "", false
+	}
+	var originalFile string
+	if err := json.Unmarshal(bytes.TrimSuffix(value[:end], []byte(",")), &originalFile); err != nil {
+		return "", false
+	}
+	return originalFile, true
+}
+
+// ParseBackupTimestamp returns the time a backup was made from the
+// YYYYMMDD_HHMMSS prefix of its file name
+func ParseBackupTimestamp(filePath string) (time.Time, error) {
+	filename := filepath.Base(filePath)
+	if len(filename) < 15 {
+		return time.Time{}, fmt.Errorf("filename too short")
+	}
+	timestamp, err := time.Parse("20060102_150405", filename[:15])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp format: %w", err)
+	}
+	return timestamp, nil
+}
+
 // sortBackupsByTimestamp sorts backups chronologically (oldest first)
 func sortBackupsByTimestamp(backups []BackupMetadata) {
 	slices.SortFunc(backups, func(a, b BackupMetadata) int {
 		return a.Timestamp.Compare(b.Timestamp)
 	})
 }
+
+// RetentionPolicy describes which backups of a file are kept when pruning
+type RetentionPolicy struct {
+	PerSession int // Newest backups to keep of each session within the daily window
+	Days       int // Keep the newest backup of each day for this many days
+	Weeks      int // After that, keep the newest backup of each week for this many weeks (0 keeps them all)
+}
+
+// DefaultRetentionPolicy keeps 5 backups per recent session, one per day for
+// a week and one per week after that
+func DefaultRetentionPolicy() RetentionPolicy {
+	return RetentionPolicy{PerSession: 5, Days: 7, Weeks: 0}
+}
+
+// ParseRetentionPolicy parses a 'backupretention' setting. It accepts "default"
+// or "true" for the default policy, or "<per-session>,<days>,<weeks>".
+func ParseRetentionPolicy(value string) (RetentionPolicy, error) {
+	switch strings.TrimSpace(value) {
+	case "default", "true", "on":
+		return DefaultRetentionPolicy(), nil
+	}
+
+	fields := strings.Split(value, ",")
+	if len(fields) != 3 {
+		return RetentionPolicy{}, fmt.Errorf("invalid retention policy %q, use default or <per-session>,<days>,<weeks>", value)
+	}
+	var numbers [3]int
+	for i, field := range fields {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || n < 0 {
+			return RetentionPolicy{}, fmt.Errorf("invalid retention policy %q, values must be numbers >= 0", value)
+		}
+		numbers[i] = n
+	}
+	return RetentionPolicy{PerSession: numbers[0], Days: numbers[1], Weeks: numbers[2]}, nil
+}
+
+// pruneLockName is the lock file that keeps two instances from pruning at the same time
+const pruneLockName = ".prune.lock"
+
+// pruneLockTimeout is the age after which a prune lock is considered stale
+const pruneLockTimeout = 5 * time.Minute
+
+// Prune deletes the backups of originalFile that the policy does not keep.
+// The most recent backup is never deleted. Returns the number of deleted backups.
+func (bm *BackupManager) Prune(originalFile string, policy RetentionPolicy) (int, error) {
+	return bm.pruneAt(originalFile, policy, time.Now())
+}
+
+// pruneAt prunes backups relative to now, so tests can use a fixed time
+func (bm *BackupManager) pruneAt(originalFile string, policy RetentionPolicy, now time.Time) (int, error) {
+	if originalFile == "" {
+		return 0, fmt.Errorf("no file to prune backups for")
+	}
+
+	unlock, err := bm.lockPrune()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	backups, err := bm.FindBackupsForFile(originalFile)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, backup := range backupsToPrune(backups, policy, now) {
+		// Another instance may have removed the file already
+		if err := os.Remove(backup.FilePath); err != nil && !os.IsNotExist(err) {
+			return deleted, fmt.Errorf("failed to delete backup: %w", err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// lockPrune creates the prune lock file, replacing it when it is stale.
+// Returns a function that removes the lock.
+func (bm *BackupManager) lockPrune() (func(), error) {
+	lockPath := filepath.Join(bm.backupDir, pruneLockName)
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock backups: %w", err)
+		}
+
+		// Remove a lock left behind by an instance that crashed while pruning
+		info, statErr := os.Stat(lockPath)
+		if statErr != nil || time.Since(info.ModTime()) < pruneLockTimeout {
+			break
+		}
+		os.Remove(lockPath)
+	}
+	return nil, fmt.Errorf("backups are being pruned by another instance")
+}
+
+// backupsToPrune returns the backups the policy does not keep.
+// Backups must be sorted oldest first, as returned by FindBackupsForFile.
+func backupsToPrune(backups []BackupMetadata, policy RetentionPolicy, now time.Time) []BackupMetadata {
+	if len(backups) == 0 {
+		return nil
+	}
+
+	// Backup timestamps are local wall-clock times parsed as UTC, compare in the same form
+	now, _ = time.Parse("20060102_150405", now.Format("20060102_150405"))
+	dailyCutoff := now.AddDate(0, 0, -policy.Days)
+	var weeklyCutoff time.Time
+	if policy.Weeks > 0 {
+		weeklyCutoff = dailyCutoff.AddDate(0, 0, -7*policy.Weeks)
+	}
+
+	keep := make(map[string]bool)
+	keep[backups[len(backups)-1].FilePath] = true
+
+	sessionCounts := make(map[string]int)
+	seenDays := make(map[string]bool)
+	seenWeeks := make(map[string]bool)
+
+	// Walk newest first so the newest backup of each session, day and week is kept
+	for i := len(backups) - 1; i >= 0; i-- {
+		backup := backups[i]
+		if backup.Timestamp.After(dailyCutoff) {
+			if sessionCounts[backup.SessionID] < policy.PerSession {
+				sessionCounts[backup.SessionID]++
+				keep[backup.FilePath] = true
+			}
+			day := backup.Timestamp.Format("2006-01-02")
+			if !seenDays[day] {
+				seenDays[day] = true
+				keep[backup.FilePath] = true
+			}
+			continue
+		}
+
+		if !weeklyCutoff.IsZero() && !backup.Timestamp.After(weeklyCutoff) {
+			continue
+		}
+		year, week := backup.Timestamp.ISOWeek()
+		weekKey := fmt.Sprintf("%d-%02d", year, week)
+		if !seenWeeks[weekKey] {
+			seenWeeks[weekKey] = true
+			keep[backup.FilePath] = true
+		}
+	}
+
+	var pruned []BackupMetadata
+	for _, backup := range backups {
+		if !keep[backup.FilePath] {
+			pruned = append(pruned, backup)
+		}
+	}
+	return pruned
+}
@@ -1,9 +1,13 @@
 package storage
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -278,3 +282,234 @@ func generateSessionID() string {
 	}
 	return string(b)
 }
+
+func TestBackupsToPrune(t *testing.T) {
+	now := time.Date(2025, 3, 20, 12, 0, 0, 0, time.UTC)
+	backup := func(name, session string, at time.Time) BackupMetadata {
+		return BackupMetadata{FilePath: name, SessionID: session, Timestamp: at}
+	}
+
+	backups := []BackupMetadata{
+		backup("old-week-a", "s1", now.AddDate(0, 0, -30)),
+		backup("old-week-b", "s1", now.AddDate(0, 0, -30).Add(time.Hour)),
+		backup("day-a", "s2", now.AddDate(0, 0, -3)),
+		backup("day-b", "s2", now.AddDate(0, 0, -3).Add(time.Hour)),
+		backup("today-a", "s3", now.Add(-3*time.Hour)),
+		backup("today-b", "s3", now.Add(-2*time.Hour)),
+		backup("today-c", "s3", now.Add(-time.Hour)),
+	}
+
+	pruned := backupsToPrune(backups, RetentionPolicy{PerSession: 2, Days: 7}, now)
+	var names []string
+	for _, b := range pruned {
+		names = append(names, b.FilePath)
+	}
+	expected := []string{"old-week-a", "today-a"}
+	if len(names) != len(expected) || names[0] != expected[0] || names[1] != expected[1] {
+		t.Errorf("Expected %v to be pruned, got %v", expected, names)
+	}
+
+	// The most recent backup survives even when the policy keeps nothing
+	pruned = backupsToPrune(backups, RetentionPolicy{Weeks: 1}, now.AddDate(1, 0, 0))
+	if len(pruned) != len(backups)-1 {
+		t.Fatalf("Expected all but one backup to be pruned, got %d", len(pruned))
+	}
+	for _, b := range pruned {
+		if b.FilePath == "today-c" {
+			t.Error("The most recent backup must never be pruned")
+		}
+	}
+}
+
+func TestPruneDeletesBackupFiles(t *testing.T) {
+	bm := &BackupManager{backupDir: t.TempDir()}
+	originalPath := filepath.Join(t.TempDir(), "notes.json")
+
+	outline := model.NewOutline()
+	outline.OriginalFilename = originalPath
+	data, _ := json.Marshal(outline)
+	now := time.Now()
+	for i, age := range []time.Duration{40 * time.Minute, 30 * time.Minute, 20 * time.Minute, 10 * time.Minute} {
+		name := fmt.Sprintf("%s_sess%04d.tuo", now.Add(-age).Format("20060102_150405"), i%2)
+		if err := os.WriteFile(filepath.Join(bm.backupDir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deleted, err := bm.Prune(originalPath, RetentionPolicy{PerSession: 1, Days: 7})
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("Expected 2 deleted backups, got %d", deleted)
+	}
+	remaining, _ := bm.FindBackupsForFile(originalPath)
+	if len(remaining) != 2 {
+		t.Errorf("Expected 2 remaining backups, got %d", len(remaining))
+	}
+
+	// A prune that is already running blocks a second one
+	lockPath := filepath.Join(bm.backupDir, pruneLockName)
+	if err := os.WriteFile(lockPath, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bm.Prune(originalPath, RetentionPolicy{}); err == nil {
+		t.Error("Expected prune to fail while another instance holds the lock")
+	}
+
+	// A stale lock is taken over
+	stale := time.Now().Add(-2 * pruneLockTimeout)
+	os.Chtimes(lockPath, stale, stale)
+	if _, err := bm.Prune(originalPath, RetentionPolicy{}); err != nil {
+		t.Errorf("Expected stale lock to be replaced, got %v", err)
+	}
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Error("Expected lock file to be removed after pruning")
+	}
+}
+
+func TestParseRetentionPolicy(t *testing.T) {
+	policy, err := ParseRetentionPolicy("default")
+	if err != nil || policy != DefaultRetentionPolicy() {
+		t.Errorf("Expected default policy, got %+v (%v)", policy, err)
+	}
+	policy, err = ParseRetentionPolicy("3, 14, 52")
+	if err != nil || policy != (RetentionPolicy{PerSession: 3, Days: 14, Weeks: 52}) {
+		t.Errorf("Expected 3,14,52 policy, got %+v (%v)", policy, err)
+	}
+	for _, value := range []string{"", "3,14", "a,b,c", "1,-1,0"} {
+		if _, err := ParseRetentionPolicy(value); err == nil {
+			t.Errorf("Expected %q to be invalid", value)
+		}
+	}
+}
+
+func TestCompressedBackups(t *testing.T) {
+	bm := &BackupManager{backupDir: t.TempDir()}
+	originalPath := filepath.Join(t.TempDir(), "notes.json")
+
+	outline := model.NewOutline()
+	outline.Items = append(outline.Items, model.NewItem("Compressed item"))
+
+	// An uncompressed backup written before compression was enabled
+	legacy, _ := json.Marshal(&model.Outline{OriginalFilename: originalPath})
+	legacyName := time.Now().Add(-time.Hour).Format("20060102_150405") + "_legacy01.tuo"
+	if err := os.WriteFile(filepath.Join(bm.backupDir, legacyName), legacy, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	bm.SetCompression(true)
+	if err := bm.CreateBackup(outline, originalPath, "gzip1234"); err != nil {
+		t.Fatalf("Failed to create backup: %v", err)
+	}
+
+	backups, err := bm.FindBackupsForFile(originalPath)
+	if err != nil {
+		t.Fatalf("FindBackupsForFile failed: %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("Expected legacy and compressed backups, got %d", len(backups))
+	}
+
+	compressed := backups[1]
+	if !strings.HasSuffix(compressed.FilePath, ".tuo.gz") || compressed.SessionID != "gzip1234" {
+		t.Fatalf("Expected compressed backup for session gzip1234, got %+v", compressed)
+	}
+	raw, _ := os.ReadFile(compressed.FilePath)
+	if !bytes.HasPrefix(raw, gzipMagic) {
+		t.Error("Expected backup to be gzip compressed")
+	}
+
+	data, err := ReadOutlineFile(compressed.FilePath)
+	if err != nil {
+		t.Fatalf("ReadOutlineFile failed: %v", err)
+	}
+	var restored model.Outline
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("Failed to parse decompressed backup: %v", err)
+	}
+	if len(restored.Items) != 1 || restored.Items[0].Text != "Compressed item" {
+		t.Errorf("Expected decompressed backup to contain the item, got %+v", restored.Items)
+	}
+
+	// Compressed backups open read-only through the store like plain ones
+	store := &JSONStore{FilePath: compressed.FilePath}
+	loaded, err := store.Load()
+	if err != nil || len(loaded.Items) != 1 {
+		t.Errorf("Expected store to load compressed backup, got %v", err)
+	}
+}
+
+// writeTestBackups writes count backups a minute apart to dir, alternating
+// between the original files, as written by CreateBackup and in the older
+// compact and compressed forms
+func writeTestBackups(t testing.TB, dir string, count, items int, originals ...string) {
+	t.Helper()
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+	for i := range count {
+		outline := model.NewOutline()
+		for j := range items {
+			// A name in the text must not be taken for the original file
+			outline.Items = append(outline.Items, model.NewItem(fmt.Sprintf("Item %d\n  \"original_filename\": \"/wrong\"", j)))
+		}
+		outline.OriginalFilename = originals[i%len(originals)]
+		outline.TypeDefinitions = map[string]string{"status": "enum|todo|done"}
+
+		name := fmt.Sprintf("%s_sess%04d", start.Add(time.Duration(i)*time.Minute).Format("20060102_150405"), i)
+		data, _ := json.MarshalIndent(outline, "", "  ")
+		switch i % 3 {
+		case 0:
+			data, _ = json.Marshal(outline)
+			name += backupExt
+		case 1:
+			name += backupExt
+		case 2:
+			var buf bytes.Buffer
+			writer := gzip.NewWriter(&buf)
+			writer.Write(data)
+			writer.Close()
+			data = buf.Bytes()
+			name += compressedBackupExt
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFindBackupsForFileReadsOriginalFile(t *testing.T) {
+	bm := &BackupManager{backupDir: t.TempDir()}
+	writeTestBackups(t, bm.backupDir, 60, 3, "/notes/a.json", "/notes/b.json")
+
+	backups, err := bm.FindBackupsForFile("/notes/a.json")
+	if err != nil {
+		t.Fatalf("FindBackupsForFile failed: %v", err)
+	}
+	if len(backups) != 30 {
+		t.Fatalf("Expected 30 backups of a.json, got %d", len(backups))
+	}
+	for i, backup := range backups {
+		if backup.OriginalFile != "/notes/a.json" {
+			t.Errorf("Expected backup %d of a.json, got %q", i, backup.OriginalFile)
+		}
+		if i > 0 && !backup.Timestamp.After(backups[i-1].Timestamp) {
+			t.Errorf("Expected backups sorted oldest first, got %v after %v", backup.Timestamp, backups[i-1].Timestamp)
+		}
+	}
+
+	all, err := bm.FindBackupsForFile("")
+	if err != nil || len(all) != 60 {
+		t.Errorf("Expected all 60 backups without a file, got %d (%v)", len(all), err)
+	}
+}
+
+func BenchmarkFindBackupsForFile(b *testing.B) {
+	bm := &BackupManager{backupDir: b.TempDir()}
+	writeTestBackups(b, bm.backupDir, 1000, 200, "/notes/a.json", "/notes/b.json")
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := bm.FindBackupsForFile("/notes/a.json"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
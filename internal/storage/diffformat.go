@@ -2,6 +2,7 @@ package storage
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"io"
 	"sort"
@@ -34,10 +35,24 @@ import (
 //   id: created_timestamp modified_timestamp
 //   id: created_timestamp modified_timestamp
 //
+//   [VIRTUAL CHILDREN SECTION]
+//   id: ref_id1,ref_id2
+//
+//   [OUTLINE SECTION]
+//   field: json_value
+//
+// Items keep their IDs, so links and virtual children still point to the
+// same items after decoding. The outline section holds the fields of the
+// outline itself, like the type definitions, by their JSON name.
+//
 // Text escaping:
 //   - \ (backslash) is encoded as \\
 //   - newline is encoded as \n
 //   - Must be decoded in reverse order to handle escapes correctly
+//
+// Tags are escaped like text, and a comma inside a tag is encoded as \,
+// so it is not taken for the separator. Items without tags have no line.
+// Attribute values and virtual child IDs are escaped like tags.
 
 // EncodeDiffFormat encodes an outline to the diff-optimized format
 func EncodeDiffFormat(outline *model.Outline, w io.Writer) error {
@@ -104,7 +119,11 @@ func EncodeDiffFormat(outline *model.Outline, w io.Writer) error {
 
 	for _, item := range allItems {
 		if item.Metadata != nil && len(item.Metadata.Tags) > 0 {
-			tagStr := strings.Join(item.Metadata.Tags, ",")
+			encodedTags := make([]string, len(item.Metadata.Tags))
+			for i, tag := range item.Metadata.Tags {
+				encodedTags[i] = encodeTagValue(tag)
+			}
+			tagStr := strings.Join(encodedTags, ",")
 			line := fmt.Sprintf("%s: %s\n", item.ID, tagStr)
 			if _, err := writer.WriteString(line); err != nil {
 				return err
@@ -128,7 +147,7 @@ func EncodeDiffFormat(outline *model.Outline, w io.Writer) error {
 
 			var pairs []string
 			for _, k := range keys {
-				pairs = append(pairs, fmt.Sprintf("%s=%s", k, item.Metadata.Attributes[k]))
+				pairs = append(pairs, fmt.Sprintf("%s=%s", encodeTagValue(k), encodeTagValue(item.Metadata.Attributes[k])))
 			}
 			attrStr := strings.Join(pairs, ",")
 			line := fmt.Sprintf("%s: %s\n", item.ID, attrStr)
@@ -154,9 +173,64 @@ func EncodeDiffFormat(outline *model.Outline, w io.Writer) error {
 		}
 	}
 
+	// Write VIRTUAL CHILDREN SECTION
+	if _, err := writer.WriteString("\n[VIRTUAL CHILDREN SECTION]\n"); err != nil {
+		return err
+	}
+
+	for _, item := range allItems {
+		if len(item.VirtualChildRefs) > 0 {
+			encodedRefs := make([]string, len(item.VirtualChildRefs))
+			for i, ref := range item.VirtualChildRefs {
+				encodedRefs[i] = encodeTagValue(ref)
+			}
+			line := fmt.Sprintf("%s: %s\n", item.ID, strings.Join(encodedRefs, ","))
+			if _, err := writer.WriteString(line); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Write OUTLINE SECTION
+	if _, err := writer.WriteString("\n[OUTLINE SECTION]\n"); err != nil {
+		return err
+	}
+
+	for _, field := range outlineFields(outline) {
+		if field.empty {
+			continue
+		}
+		value, err := json.Marshal(field.value)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", field.name, err)
+		}
+		line := fmt.Sprintf("%s: %s\n", field.name, value)
+		if _, err := writer.WriteString(line); err != nil {
+			return err
+		}
+	}
+
 	return writer.Flush()
 }
 
+// outlineField is a field of the outline in the OUTLINE SECTION
+type outlineField struct {
+	name  string // JSON name of the field
+	value any    // Pointer to the field
+	empty bool   // The field is left out of the JSON file too
+}
+
+// outlineFields returns the fields of outline, other than the items, in the
+// order they are written
+func outlineFields(outline *model.Outline) []outlineField {
+	return []outlineField{
+		{"original_filename", &outline.OriginalFilename, outline.OriginalFilename == ""},
+		{"type_definitions", &outline.TypeDefinitions, len(outline.TypeDefinitions) == 0},
+		{"type_defaults", &outline.TypeDefaults, len(outline.TypeDefaults) == 0},
+		{"type_templates", &outline.TypeTemplates, len(outline.TypeTemplates) == 0},
+	}
+}
+
 // DecodeDiffFormat decodes an outline from the diff-optimized format
 func DecodeDiffFormat(r io.Reader) (*model.Outline, error) {
 	scanner := bufio.NewScanner(r)
@@ -169,19 +243,21 @@ func DecodeDiffFormat(r io.Reader) (*model.Outline, error) {
 	tagsData := make(map[string][]string)
 	attributesData := make(map[string]map[string]string)
 	timestampsData := make(map[string][2]time.Time)
+	virtualChildrenData := make(map[string][]string)
 
 	var bufferedLine *string // Store a line for the next iteration
 
 	// Helper to read a section, storing the next section header in bufferedLine
 	readSection := func(sectionName string) error {
 		for scanner.Scan() {
-			line := strings.TrimSpace(scanner.Text())
+			// Trailing spaces are kept, the parsers of the lines know which are part of a value
+			line := strings.TrimLeft(strings.TrimRight(scanner.Text(), "\r"), " \t")
 			if strings.HasPrefix(line, "[") {
 				// Start of next section - buffer it
 				bufferedLine = &line
 				return nil
 			}
-			if line == "" {
+			if strings.TrimSpace(line) == "" {
 				continue
 			}
 
@@ -220,6 +296,18 @@ func DecodeDiffFormat(r io.Reader) (*model.Outline, error) {
 					return err
 				}
 				timestampsData[id] = [2]time.Time{created, modified}
+
+			case "VIRTUAL CHILDREN":
+				id, refs, err := parseTagsLine(line)
+				if err != nil {
+					return err
+				}
+				virtualChildrenData[id] = refs
+
+			case "OUTLINE":
+				if err := parseOutlineLine(line, outline); err != nil {
+					return err
+				}
 			}
 		}
 		return scanner.Err()
@@ -258,6 +346,14 @@ func DecodeDiffFormat(r io.Reader) (*model.Outline, error) {
 			if err := readSection("TIMESTAMPS"); err != nil {
 				return nil, fmt.Errorf("error reading TIMESTAMPS SECTION: %w", err)
 			}
+		} else if line == "[VIRTUAL CHILDREN SECTION]" {
+			if err := readSection("VIRTUAL CHILDREN"); err != nil {
+				return nil, fmt.Errorf("error reading VIRTUAL CHILDREN SECTION: %w", err)
+			}
+		} else if line == "[OUTLINE SECTION]" {
+			if err := readSection("OUTLINE"); err != nil {
+				return nil, fmt.Errorf("error reading OUTLINE SECTION: %w", err)
+			}
 		}
 	}
 
@@ -293,10 +389,11 @@ func DecodeDiffFormat(r io.Reader) (*model.Outline, error) {
 		}
 
 		item := &model.Item{
-			ID:       id,
-			Text:     text,
-			Children: make([]*model.Item, 0),
-			Metadata: metadata,
+			ID:               id,
+			Text:             text,
+			Children:         make([]*model.Item, 0),
+			Metadata:         metadata,
+			VirtualChildRefs: virtualChildrenData[id],
 		}
 		itemsByID[id] = item
 	}
@@ -429,6 +526,60 @@ func decodeTextValue(text string) string {
 	return result.String()
 }
 
+// encodeTagValue encodes a tag like encodeTextValue, and also escapes commas
+// because they separate the tags on a line. Leading and trailing spaces are
+// escaped as \s, because unescaped spaces around a tag are not part of it.
+func encodeTagValue(tag string) string {
+	encoded := strings.ReplaceAll(encodeTextValue(tag), ",", "\\,")
+	trimmed := strings.TrimLeft(encoded, " ")
+	leading := len(encoded) - len(trimmed)
+	inner := strings.TrimRight(trimmed, " ")
+	trailing := len(trimmed) - len(inner)
+	return strings.Repeat("\\s", leading) + inner + strings.Repeat("\\s", trailing)
+}
+
+// decodeTagsValue splits a tags value on unescaped commas and decodes each tag.
+// Reads character by character like decodeTextValue, so an escaped comma is kept inside the tag.
+// Unescaped spaces around a tag are dropped, escaped spaces are kept.
+func decodeTagsValue(value string) []string {
+	tags := make([]string, 0)
+	var current strings.Builder
+	escaped := 0 // Length of current up to the last escaped character
+	finish := func() {
+		tag := current.String()
+		tags = append(tags, tag[:escaped]+strings.TrimRight(tag[escaped:], " "))
+		current.Reset()
+		escaped = 0
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			switch value[i+1] {
+			case 'n':
+				current.WriteByte('\n')
+				i++ // Skip the 'n'
+			case 's':
+				current.WriteByte(' ')
+				i++ // Skip the 's'
+			case '\\', ',':
+				current.WriteByte(value[i+1])
+				i++ // Skip the escaped character
+			default:
+				// Unrecognized escape sequence, treat as literal
+				current.WriteByte('\\')
+			}
+			escaped = current.Len()
+		} else if value[i] == ',' {
+			finish()
+		} else if value[i] == ' ' && current.Len() == 0 {
+			// Skip the spaces before a tag
+		} else {
+			current.WriteByte(value[i])
+		}
+	}
+	finish()
+	return tags
+}
+
 // parseTextLine parses a line from the TEXT SECTION
 // Format: id: text
 func parseTextLine(line string) (id string, text string, err error) {
@@ -465,7 +616,7 @@ func parseStructureLine(line string) (id string, parentID string, position strin
 }
 
 // parseTagsLine parses a line from the TAGS SECTION
-// Format: id: tag1,tag2,tag3
+// Format: id: tag1,tag2,tag3 with tags escaped by encodeTagValue
 func parseTagsLine(line string) (id string, tags []string, err error) {
 	parts := strings.SplitN(line, ":", 2)
 	if len(parts) != 2 {
@@ -473,15 +624,12 @@ func parseTagsLine(line string) (id string, tags []string, err error) {
 	}
 
 	id = strings.TrimSpace(parts[0])
-	tagsStr := strings.TrimSpace(parts[1])
+	tagsStr := parts[1]
 
-	if tagsStr == "" {
+	if strings.Trim(tagsStr, " ") == "" {
 		tags = make([]string, 0)
 	} else {
-		tags = strings.Split(tagsStr, ",")
-		for i := range tags {
-			tags[i] = strings.TrimSpace(tags[i])
-		}
+		tags = decodeTagsValue(tagsStr)
 	}
 
 	return id, tags, nil
@@ -500,8 +648,8 @@ func parseAttributesLine(line string) (id string, attrs map[string]string, err e
 	attrs = make(map[string]string)
 
 	if attrStr != "" {
-		pairs := strings.SplitSeq(attrStr, ",")
-		for pair := range pairs {
+		// Keys don't contain "=", so the first one separates the value
+		for _, pair := range decodeTagsValue(attrStr) {
 			kv := strings.SplitN(pair, "=", 2)
 			if len(kv) == 2 {
 				key := strings.TrimSpace(kv[0])
@@ -514,6 +662,26 @@ func parseAttributesLine(line string) (id string, attrs map[string]string, err e
 	return id, attrs, nil
 }
 
+// parseOutlineLine parses a line from the OUTLINE SECTION into the field of outline
+// Format: field: json_value
+// Unknown fields are skipped, like unknown fields in the JSON file.
+func parseOutlineLine(line string, outline *model.Outline) error {
+	name, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return fmt.Errorf("invalid outline line format: %s", line)
+	}
+
+	name = strings.TrimSpace(name)
+	for _, field := range outlineFields(outline) {
+		if field.name == name {
+			if err := json.Unmarshal([]byte(value), field.value); err != nil {
+				return fmt.Errorf("invalid %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
 // parsePosition parses a position string to an integer
 func parsePosition(posStr string) int {
 	var pos int
@@ -2,6 +2,9 @@ package storage
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
 	"testing"
 	"time"
 
@@ -378,3 +381,107 @@ func TestComplexNesting(t *testing.T) {
 		t.Error("Level 2 nesting not preserved")
 	}
 }
+
+func TestTagsRoundTrip(t *testing.T) {
+	tagSets := [][]string{
+		nil,
+		{},
+		{"work"},
+		{"two words", "urgent"},
+		{"a,b", "c"},
+		{"path\\to", "multi\nline", "trailing\\"},
+		{"comma, then space", ",", "\\,"},
+		{" leading", "trailing ", "  both  ", " ", "\\s"},
+	}
+
+	outline := &model.Outline{}
+	parent := model.NewItem("Parent")
+	outline.Items = append(outline.Items, parent)
+	for i, tags := range tagSets {
+		item := model.NewItem(fmt.Sprintf("Item %d", i))
+		item.Metadata.Tags = tags
+		parent.AddChild(item)
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeDiffFormat(outline, &buf); err != nil {
+		t.Fatalf("EncodeDiffFormat failed: %v", err)
+	}
+	encoded := buf.String()
+
+	decoded, err := DecodeDiffFormat(&buf)
+	if err != nil {
+		t.Fatalf("DecodeDiffFormat failed: %v", err)
+	}
+	if len(decoded.Items) != 1 || len(decoded.Items[0].Children) != len(tagSets) {
+		t.Fatalf("Structure not preserved:\n%s", encoded)
+	}
+
+	for i, child := range decoded.Items[0].Children {
+		want := tagSets[i]
+		if want == nil {
+			want = []string{} // Decoded items always have a tags slice
+		}
+		if !slices.Equal(child.Metadata.Tags, want) {
+			t.Errorf("Item %d: tags %q, want %q\n%s", i, child.Metadata.Tags, want, encoded)
+		}
+	}
+}
+
+func TestDecodeTagsValue(t *testing.T) {
+	// Lines written before tags were escaped still decode
+	if tags := decodeTagsValue("work, home"); !slices.Equal(tags, []string{"work", "home"}) {
+		t.Errorf("decodeTagsValue = %q", tags)
+	}
+	if tags := decodeTagsValue(`a\,b,c\\,d`); !slices.Equal(tags, []string{"a,b", `c\`, "d"}) {
+		t.Errorf("decodeTagsValue = %q", tags)
+	}
+	// Escaped spaces are kept, the spaces around them are not
+	if tags := decodeTagsValue(` \sa , b\s  `); !slices.Equal(tags, []string{" a", "b "}) {
+		t.Errorf("decodeTagsValue = %q", tags)
+	}
+}
+
+func TestDiffFormatRoundTripKeepsIDs(t *testing.T) {
+	project := model.NewItem("Project, see [[" + "note-1" + "|the note]]")
+	project.Metadata.Attributes["url"] = "https://example.com/?a=1,b=2"
+	project.Metadata.Attributes["type"] = "project"
+	note := model.NewItem("Note")
+	note.ID = "note-1"
+	project.AddChild(note)
+	search := model.NewItem("Search")
+	search.VirtualChildRefs = []string{"note-1", project.ID}
+
+	outline := &model.Outline{
+		Items:            []*model.Item{project, search},
+		OriginalFilename: "/home/user/notes.json",
+		TypeDefinitions:  map[string]string{"status": "enum|todo,done"},
+		TypeDefaults:     map[string]map[string]string{"project": {"status": "todo"}},
+		TypeTemplates:    map[string]string{"project": project.ID},
+	}
+	want, err := json.Marshal(outline)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeDiffFormat(outline, &buf); err != nil {
+		t.Fatalf("EncodeDiffFormat failed: %v", err)
+	}
+	encoded := buf.String()
+
+	decoded, err := DecodeDiffFormat(&buf)
+	if err != nil {
+		t.Fatalf("DecodeDiffFormat failed: %v", err)
+	}
+	got, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The JSON is the same, with the same IDs, so the link and the virtual
+	// children still point to the same items
+	if string(got) != string(want) {
+		t.Errorf("Round trip changed the outline\nwant %s\ngot  %s\nencoded:\n%s", want, got, encoded)
+	}
+}
@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// EncryptedExt is the extension of encrypted outline files
+const EncryptedExt = ".tuoe"
+
+// Encrypted files start with a header followed by the AES-GCM ciphertext:
+//
+//	magic "TUOE" | version 1 | salt (16 bytes) | nonce (12 bytes) | ciphertext
+//
+// The key is derived from the passphrase and salt with scrypt. The header is
+// authenticated as additional data, so it cannot be changed without detection.
+const (
+	encryptedVersion = 1
+	saltSize         = 16
+	scryptN          = 1 << 15
+	scryptR          = 8
+	scryptP          = 1
+	keySize          = 32 // AES-256
+)
+
+// encryptedMagic are the first bytes of an encrypted outline file
+var encryptedMagic = []byte("TUOE")
+
+var (
+	// ErrPassphraseRequired is returned when encrypted data is read or written without a passphrase
+	ErrPassphraseRequired = errors.New("file is encrypted, a passphrase is required")
+	// ErrWrongPassphrase is returned when encrypted data cannot be decrypted with the passphrase
+	ErrWrongPassphrase = errors.New("wrong passphrase or corrupted file")
+)
+
+// encryptionKey is a key derived from a passphrase with its salt
+type encryptionKey struct {
+	salt []byte
+	key  []byte
+}
+
+// Passphrases set in this process and the keys derived from them. Backups of
+// an encrypted file are read with the passphrase of that file, and key
+// derivation is slow on purpose, so derived keys are cached by salt.
+var (
+	keyringMu   sync.Mutex
+	passphrases []string
+	derivedKeys = make(map[string][]byte)
+)
+
+// IsEncryptedFile reports whether filePath has the encrypted file extension
+func IsEncryptedFile(filePath string) bool {
+	return strings.EqualFold(filepath.Ext(filePath), EncryptedExt)
+}
+
+// IsEncryptedData reports whether data starts with the encrypted file header
+func IsEncryptedData(data []byte) bool {
+	return bytes.HasPrefix(data, encryptedMagic)
+}
+
+// rememberPassphrase adds passphrase to the passphrases tried by ReadOutlineFile
+func rememberPassphrase(passphrase string) {
+	keyringMu.Lock()
+	defer keyringMu.Unlock()
+	for _, p := range passphrases {
+		if p == passphrase {
+			return
+		}
+	}
+	passphrases = append(passphrases, passphrase)
+}
+
+// deriveKey derives the key for passphrase and salt, or returns it from the cache
+func deriveKey(passphrase string, salt []byte) ([]byte, error) {
+	cacheKey := string(salt) + "\x00" + passphrase
+
+	keyringMu.Lock()
+	key, ok := derivedKeys[cacheKey]
+	keyringMu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	keyringMu.Lock()
+	derivedKeys[cacheKey] = key
+	keyringMu.Unlock()
+	return key, nil
+}
+
+// newEncryptionKey derives a key for passphrase with a new random salt
+func newEncryptionKey(passphrase string) (*encryptionKey, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	key, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptionKey{salt: salt, key: key}, nil
+}
+
+// encrypt encrypts data and prepends the header
+func (k *encryptionKey) encrypt(data []byte) ([]byte, error) {
+	gcm, err := newGCM(k.key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, len(encryptedMagic)+1+saltSize+gcm.NonceSize())
+	header = append(header, encryptedMagic...)
+	header = append(header, encryptedVersion)
+	header = append(header, k.salt...)
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	header = append(header, nonce...)
+
+	return gcm.Seal(header, nonce, data, header), nil
+}
+
+// decryptData decrypts data written by encrypt with passphrase
+func decryptData(data []byte, passphrase string) ([]byte, error) {
+	headerSize := len(encryptedMagic) + 1 + saltSize
+	if len(data) < headerSize || !IsEncryptedData(data) {
+		return nil, fmt.Errorf("not an encrypted outline")
+	}
+	if version := data[len(encryptedMagic)]; version != encryptedVersion {
+		return nil, fmt.Errorf("unsupported encryption version %d", version)
+	}
+	salt := data[len(encryptedMagic)+1 : headerSize]
+
+	key, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < headerSize+gcm.NonceSize()+gcm.Overhead() {
+		return nil, ErrWrongPassphrase
+	}
+
+	header := data[:headerSize+gcm.NonceSize()]
+	nonce := header[headerSize:]
+	plain, err := gcm.Open(nil, nonce, data[len(header):], header)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plain, nil
+}
+
+// knownPassphrases returns the passphrases set in this process
+func knownPassphrases() []string {
+	keyringMu.Lock()
+	defer keyringMu.Unlock()
+	return append([]string(nil), passphrases...)
+}
+
+// decryptWithPassphrases decrypts data with the first of candidates that fits
+func decryptWithPassphrases(data []byte, candidates []string) ([]byte, error) {
+	if len(candidates) == 0 {
+		return nil, ErrPassphraseRequired
+	}
+	for _, passphrase := range candidates {
+		plain, err := decryptData(data, passphrase)
+		if err == nil {
+			return plain, nil
+		}
+		if !errors.Is(err, ErrWrongPassphrase) {
+			return nil, err
+		}
+	}
+	return nil, ErrWrongPassphrase
+}
+
+// newGCM creates an AES-GCM cipher for key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return gcm, nil
+}
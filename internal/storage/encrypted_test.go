@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+func TestEncryptedFileRoundTrip(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "secret.tuoe")
+	bm := &BackupManager{backupDir: t.TempDir(), compress: true}
+
+	store := &JSONStore{FilePath: filePath, backupManager: bm, sessionID: "crypt123", Encrypted: IsEncryptedFile(filePath)}
+	if !store.NeedsPassphrase() {
+		t.Fatal("Expected a new .tuoe file to need a passphrase")
+	}
+	if err := store.Save(model.NewOutline()); !errors.Is(err, ErrPassphraseRequired) {
+		t.Fatalf("Expected saving without a passphrase to fail, got %v", err)
+	}
+
+	if err := store.SetPassphrase("correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	outline := model.NewOutline()
+	outline.Items = append(outline.Items, model.NewItem("Private note"))
+	if err := store.Save(outline); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	raw, _ := os.ReadFile(filePath)
+	if !IsEncryptedData(raw) || bytes.Contains(raw, []byte("Private note")) {
+		t.Fatal("Expected the file to be encrypted")
+	}
+
+	// Reopening needs the passphrase, and a wrong one is reported as such
+	reopened := &JSONStore{FilePath: filePath}
+	if !reopened.NeedsPassphrase() {
+		t.Error("Expected an encrypted file to need a passphrase")
+	}
+	if _, err := reopened.Load(); !errors.Is(err, ErrPassphraseRequired) {
+		t.Errorf("Expected ErrPassphraseRequired, got %v", err)
+	}
+	if err := reopened.SetPassphrase("wrong horse"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reopened.Load(); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Expected ErrWrongPassphrase, got %v", err)
+	}
+
+	reopened = &JSONStore{FilePath: filePath}
+	if err := reopened.SetPassphrase("correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := reopened.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(loaded.Items) != 1 || loaded.Items[0].Text != "Private note" || !reopened.Encrypted {
+		t.Errorf("Expected the decrypted outline, got %+v", loaded.Items)
+	}
+
+	// The backup is compressed and encrypted, and readable in this process
+	backups, err := bm.FindBackupsForFile(filePath)
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("Expected one backup, got %d (%v)", len(backups), err)
+	}
+	raw, _ = os.ReadFile(backups[0].FilePath)
+	if !IsEncryptedData(raw) {
+		t.Error("Expected the backup to be encrypted")
+	}
+	data, err := ReadOutlineFile(backups[0].FilePath)
+	if err != nil {
+		t.Fatalf("ReadOutlineFile failed: %v", err)
+	}
+	var backup model.Outline
+	if err := json.Unmarshal(data, &backup); err != nil || len(backup.Items) != 1 {
+		t.Errorf("Expected the backup to decrypt to the outline, got %v", err)
+	}
+}
+
+func TestFileIsEncrypted(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "notes.json")
+	store := &JSONStore{FilePath: filePath}
+	if store.FileIsEncrypted() {
+		t.Error("Expected a missing file not to be encrypted")
+	}
+	if err := store.Save(model.NewOutline()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Switching a plain file to encryption, it stays plain until saved
+	store.Encrypted = true
+	if !store.NeedsPassphrase() || store.FileIsEncrypted() {
+		t.Error("Expected a plain file to need a passphrase without being encrypted")
+	}
+	if err := store.SetPassphrase("correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(model.NewOutline()); err != nil {
+		t.Fatal(err)
+	}
+	if !store.FileIsEncrypted() {
+		t.Error("Expected the saved file to be encrypted")
+	}
+}
+
+func TestDecryptRejectsTampering(t *testing.T) {
+	key, err := newEncryptionKey("passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := key.encrypt([]byte(`{"items":[]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if plain, err := decryptData(data, "passphrase"); err != nil || string(plain) != `{"items":[]}` {
+		t.Fatalf("Expected the data back, got %q, %v", plain, err)
+	}
+	data[len(data)-1] ^= 1
+	if _, err := decryptData(data, "passphrase"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Expected tampered data to be rejected, got %v", err)
+	}
+}
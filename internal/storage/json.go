@@ -2,7 +2,9 @@ package storage
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
@@ -16,6 +18,9 @@ type JSONStore struct {
 	backupManager  *BackupManager
 	sessionID      string
 	ReadOnly       bool
+	Encrypted      bool           // Save encrypted with the passphrase, see SetPassphrase
+	passphrase     string
+	key            *encryptionKey // Derived from the passphrase, nil without a passphrase
 }
 
 // NewJSONStore creates a new JSON store for the given file path
@@ -33,6 +38,7 @@ func NewJSONStore(filePath string) *JSONStore {
 		backupManager: backupManager,
 		sessionID:     "",
 		ReadOnly:      isBackup,
+		Encrypted:     IsEncryptedFile(filePath),
 	}
 }
 
@@ -66,6 +72,62 @@ func (s *JSONStore) SetSessionID(sessionID string) {
 	s.sessionID = sessionID
 }
 
+// SetBackupCompression enables or disables gzip compression of backups
+func (s *JSONStore) SetBackupCompression(enabled bool) {
+	if s.backupManager != nil {
+		s.backupManager.SetCompression(enabled)
+	}
+}
+
+// SetPassphrase sets the passphrase for encrypted files. The file and its
+// backups are encrypted with a key derived from it, and backups of the file
+// can be read with it.
+func (s *JSONStore) SetPassphrase(passphrase string) error {
+	if passphrase == "" {
+		return fmt.Errorf("passphrase must not be empty")
+	}
+	key, err := newEncryptionKey(passphrase)
+	if err != nil {
+		return err
+	}
+	rememberPassphrase(passphrase)
+	s.passphrase = passphrase
+	s.key = key
+	if s.backupManager != nil {
+		s.backupManager.key = key
+	}
+	return nil
+}
+
+// NeedsPassphrase reports whether a passphrase must be set before loading or
+// saving, because the file is encrypted or will be saved encrypted
+func (s *JSONStore) NeedsPassphrase() bool {
+	if s.key != nil || s.FilePath == "" {
+		return false
+	}
+	return s.Encrypted || hasEncryptedHeader(s.FilePath)
+}
+
+// FileIsEncrypted reports whether the file on disk exists and is encrypted.
+// A plain file that will be saved encrypted is not.
+func (s *JSONStore) FileIsEncrypted() bool {
+	return hasEncryptedHeader(s.FilePath)
+}
+
+// hasEncryptedHeader reports whether the file at filePath is encrypted
+func hasEncryptedHeader(filePath string) bool {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	header := make([]byte, len(encryptedMagic))
+	if _, err := io.ReadFull(f, header); err != nil {
+		return false
+	}
+	return IsEncryptedData(header)
+}
+
 // Load loads an outline from a JSON file
 func (s *JSONStore) Load() (*model.Outline, error) {
 	// If no file path specified, return a new empty outline
@@ -73,12 +135,23 @@ func (s *JSONStore) Load() (*model.Outline, error) {
 		return model.NewOutline(), nil
 	}
 
-	data, err := os.ReadFile(s.FilePath)
+	if hasEncryptedHeader(s.FilePath) {
+		if s.key == nil {
+			return nil, ErrPassphraseRequired
+		}
+		// Keep an encrypted file encrypted, whatever its extension
+		s.Encrypted = true
+	}
+
+	data, err := readOutlineFile(s.FilePath, []string{s.passphrase})
 	if err != nil {
 		if os.IsNotExist(err) {
 			// Return empty outline if file doesn't exist
 			return model.NewOutline(), nil
 		}
+		if errors.Is(err, ErrWrongPassphrase) {
+			return nil, ErrWrongPassphrase
+		}
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
@@ -87,6 +160,9 @@ func (s *JSONStore) Load() (*model.Outline, error) {
 		return nil, fmt.Errorf("failed to parse JSON: %w", err)
 	}
 
+	// A null in the items has nothing to keep, drop it before anything walks the tree
+	outline.Items = removeNullItems(outline.Items)
+
 	// Restore parent pointers after deserialization
 	restoreParentPointers(outline.Items)
 
@@ -117,6 +193,11 @@ func (s *JSONStore) SaveToFile(outline *model.Outline, filePath string) error {
 		return fmt.Errorf("cannot write to readonly file")
 	}
 
+	// Never write an encrypted file, or its backup, without the key
+	if (s.Encrypted || IsEncryptedFile(filePath)) && s.key == nil {
+		return ErrPassphraseRequired
+	}
+
 	// Create backup before saving (fail gracefully if backup fails)
 	if s.backupManager != nil && s.sessionID != "" {
 		originalPath := filePath
@@ -144,7 +225,14 @@ func (s *JSONStore) SaveToFile(outline *model.Outline, filePath string) error {
 		return fmt.Errorf("failed to marshal JSON: %w", err)
 	}
 
-	if err := os.WriteFile(filePath, data, 0o644); err != nil {
+	if s.Encrypted || IsEncryptedFile(filePath) {
+		data, err = s.key.encrypt(data)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt file: %w", err)
+		}
+	}
+
+	if err := writeFileAtomic(filePath, data, 0o644); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
@@ -164,6 +252,20 @@ func restoreParentPointers(items []*model.Item) {
 	}
 }
 
+// removeNullItems removes the null entries from items and their children
+func removeNullItems(items []*model.Item) []*model.Item {
+	kept := items[:0]
+	for _, item := range items {
+		if item == nil {
+			log.Println("Dropped a null item from the outline")
+			continue
+		}
+		item.Children = removeNullItems(item.Children)
+		kept = append(kept, item)
+	}
+	return kept
+}
+
 // FileExists checks if the outline file exists
 func (s *JSONStore) FileExists() bool {
 	_, err := os.Stat(s.FilePath)
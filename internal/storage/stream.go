@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+// StreamItems decodes the root items of the outline file one at a time and
+// passes each to fn, with the parent pointers of its descendants set. Only one
+// root item and its descendants are in memory at a time, except for encrypted
+// files, which are decrypted in memory first. Virtual children are not
+// resolved. A file that doesn't exist has no items. Stops at the first error of fn.
+func (s *JSONStore) StreamItems(fn func(item *model.Item) error) error {
+	if s.FilePath == "" {
+		return nil
+	}
+
+	var r io.Reader
+	if hasEncryptedHeader(s.FilePath) {
+		if s.key == nil {
+			return ErrPassphraseRequired
+		}
+		data, err := readOutlineFile(s.FilePath, []string{s.passphrase})
+		if err != nil {
+			if errors.Is(err, ErrWrongPassphrase) {
+				return ErrWrongPassphrase
+			}
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+		r = bytes.NewReader(data)
+	} else {
+		f, err := os.Open(s.FilePath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+		defer f.Close()
+
+		br := bufio.NewReader(f)
+		r = br
+		if magic, _ := br.Peek(len(gzipMagic)); bytes.Equal(magic, gzipMagic) {
+			gz, err := gzip.NewReader(br)
+			if err != nil {
+				return fmt.Errorf("failed to decompress %s: %w", s.FilePath, err)
+			}
+			defer gz.Close()
+			r = gz
+		}
+	}
+
+	return decodeItems(r, fn)
+}
+
+// decodeItems decodes the "items" array of an outline object item by item.
+// The other fields of the outline are skipped.
+func decodeItems(r io.Reader, fn func(item *model.Item) error) error {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		// Keys match without regard to case, like json.Unmarshal
+		if key, _ := token.(string); !strings.EqualFold(key, "items") {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return fmt.Errorf("failed to parse JSON: %w", err)
+			}
+			continue
+		}
+
+		token, err = dec.Token()
+		if err != nil {
+			return fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		if token == nil {
+			continue
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			return fmt.Errorf("failed to parse JSON: items is not an array")
+		}
+		for dec.More() {
+			var item *model.Item
+			if err := dec.Decode(&item); err != nil {
+				return fmt.Errorf("failed to parse JSON: %w", err)
+			}
+			if item == nil {
+				continue
+			}
+			item.Children = removeNullItems(item.Children)
+			restoreParentPointers([]*model.Item{item})
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+
+	return expectDelim(dec, '}')
+}
+
+// expectDelim reads the next token and checks that it is delim
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	if d, ok := token.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("failed to parse JSON: expected %s, got %v", delim, token)
+	}
+	return nil
+}
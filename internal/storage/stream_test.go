@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+// streamTestOutline creates an outline of roots root items with two levels of
+// children each
+func streamTestOutline(roots int) *model.Outline {
+	outline := model.NewOutline()
+	for i := range roots {
+		root := model.NewItem(fmt.Sprintf("Root %d", i))
+		for j := range 3 {
+			child := model.NewItem(fmt.Sprintf("Child %d.%d", i, j))
+			child.AddChild(model.NewItem(fmt.Sprintf("Grandchild %d.%d", i, j)))
+			root.AddChild(child)
+		}
+		outline.Items = append(outline.Items, root)
+	}
+	return outline
+}
+
+func streamTexts(t *testing.T, store *JSONStore) []string {
+	t.Helper()
+	var texts []string
+	err := store.StreamItems(func(item *model.Item) error {
+		texts = append(texts, item.Text)
+		for _, child := range item.Children {
+			if child.Parent != item || child.Children[0].Parent != child {
+				t.Errorf("Expected parent pointers below %q", item.Text)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamItems failed: %v", err)
+	}
+	return texts
+}
+
+func TestStreamItems(t *testing.T) {
+	dir := t.TempDir()
+	outline := streamTestOutline(3)
+	outline.TypeDefinitions = map[string]string{"status": "enum|todo|done"}
+
+	filePath := filepath.Join(dir, "notes.json")
+	if err := (&JSONStore{FilePath: filePath}).Save(outline); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Root 0", "Root 1", "Root 2"}
+	if got := streamTexts(t, &JSONStore{FilePath: filePath}); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// Fields before the items are skipped
+	reordered := filepath.Join(dir, "reordered.json")
+	data := []byte(`{"type_definitions": {"a": "string"}, "items": [{"id": "1", "text": "Only", "children": [{"id": "2", "text": "Child", "children": [{"id": "3", "text": "Deep"}]}]}], "original_filename": "x"}`)
+	if err := os.WriteFile(reordered, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := streamTexts(t, &JSONStore{FilePath: reordered}); fmt.Sprint(got) != "[Only]" {
+		t.Errorf("Expected [Only], got %v", got)
+	}
+
+	// Compressed files stream as well
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write(data)
+	gz.Close()
+	compressed := filepath.Join(dir, "notes.tuo.gz")
+	if err := os.WriteFile(compressed, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := streamTexts(t, &JSONStore{FilePath: compressed}); fmt.Sprint(got) != "[Only]" {
+		t.Errorf("Expected [Only] from the compressed file, got %v", got)
+	}
+
+	if got := streamTexts(t, &JSONStore{FilePath: filepath.Join(dir, "missing.json")}); len(got) != 0 {
+		t.Errorf("Expected no items for a missing file, got %v", got)
+	}
+
+	// An error of fn stops the stream
+	stop := errors.New("stop")
+	count := 0
+	err := (&JSONStore{FilePath: filePath}).StreamItems(func(item *model.Item) error {
+		count++
+		return stop
+	})
+	if !errors.Is(err, stop) || count != 1 {
+		t.Errorf("Expected the stream to stop after one item, got %v after %d", err, count)
+	}
+
+	broken := filepath.Join(dir, "broken.json")
+	os.WriteFile(broken, []byte(`{"items": [{"text": "a"}, `), 0o644)
+	if err := (&JSONStore{FilePath: broken}).StreamItems(func(*model.Item) error { return nil }); err == nil {
+		t.Error("Expected an error for truncated JSON")
+	}
+}
+
+func TestLoadDropsNullItems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outline.json")
+	data := `{"items": [null, {"id": "item_a", "text": "A", "children": [null, {"id": "item_b", "text": "B"}]}]}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	outline, err := NewJSONStore(path).Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(outline.Items) != 1 || len(outline.Items[0].Children) != 1 {
+		t.Fatalf("Expected the null items to be dropped, got %d root items", len(outline.Items))
+	}
+	if b := outline.FindItemByID("item_b"); b == nil || b.Parent != outline.Items[0] {
+		t.Error("Expected B to be indexed under A")
+	}
+}
+
+func TestStreamItemsDropsNullItems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outline.json")
+	data := `{"items": [null, {"id": "item_a", "text": "A", "children": [null, {"id": "item_b", "text": "B", "children": [null]}]}]}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var roots []*model.Item
+	err := NewJSONStore(path).StreamItems(func(item *model.Item) error {
+		roots = append(roots, item)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamItems failed: %v", err)
+	}
+	if len(roots) != 1 || len(roots[0].Children) != 1 {
+		t.Fatalf("Expected the null items to be dropped, got %d root items", len(roots))
+	}
+	b := roots[0].Children[0]
+	if b.ID != "item_b" || b.Parent != roots[0] || len(b.Children) != 0 {
+		t.Error("Expected B under A without children")
+	}
+}
+
+func TestSavedOutlineHasNoProblems(t *testing.T) {
+	store := NewJSONStore(filepath.Join(t.TempDir(), "outline.json"))
+	// Items without attributes are saved without them and load with a nil map
+	if err := store.Save(streamTestOutline(2)); err != nil {
+		t.Fatal(err)
+	}
+	outline, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if problems := outline.Validate(); len(problems) != 0 {
+		t.Errorf("Expected no problems in a saved outline, got %v", problems)
+	}
+}
+
+func TestStreamItemsEncrypted(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "secret.tuoe")
+	store := &JSONStore{FilePath: filePath, Encrypted: true}
+	if err := store.SetPassphrase("correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(streamTestOutline(2)); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened := &JSONStore{FilePath: filePath}
+	if err := reopened.StreamItems(func(*model.Item) error { return nil }); !errors.Is(err, ErrPassphraseRequired) {
+		t.Errorf("Expected ErrPassphraseRequired, got %v", err)
+	}
+	if err := reopened.SetPassphrase("correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	if got := streamTexts(t, reopened); fmt.Sprint(got) != "[Root 0 Root 1]" {
+		t.Errorf("Expected both roots, got %v", got)
+	}
+}
+
+// writeBenchmarkOutline saves an outline of about 200000 items for the load
+// benchmarks
+func writeBenchmarkOutline(b *testing.B) string {
+	b.Helper()
+	filePath := filepath.Join(b.TempDir(), "large.json")
+	if err := (&JSONStore{FilePath: filePath}).Save(streamTestOutline(200000 / 7)); err != nil {
+		b.Fatal(err)
+	}
+	return filePath
+}
+
+func BenchmarkLoad(b *testing.B) {
+	store := &JSONStore{FilePath: writeBenchmarkOutline(b)}
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := store.Load(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStreamItems(b *testing.B) {
+	store := &JSONStore{FilePath: writeBenchmarkOutline(b)}
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := store.StreamItems(func(*model.Item) error { return nil }); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -26,13 +27,15 @@ func init() {
 // TypeSpec represents a type definition for an attribute
 type TypeSpec struct {
 	Name   string   // e.g., "status"
-	Kind   string   // enum, number, date, list, string, reference
+	Kind   string   // enum, bool, number, date, list, string, reference
 	Values []string // For enum: values, for number: [min, max], for list: [itemtype]
 }
 
 // ParseTypeSpec parses a type specification string
 // Format examples:
 // - enum|todo|in-progress|done
+// - enum[todo,in-progress,done]
+// - bool
 // - number|1-5
 // - date
 // - list|string
@@ -43,6 +46,16 @@ func ParseTypeSpec(key string, spec string) (*TypeSpec, error) {
 		return nil, fmt.Errorf("empty type specification")
 	}
 
+	// enum[a,b,c] is the same as enum|a|b|c
+	if values, ok := strings.CutPrefix(spec, "enum["); ok && strings.HasSuffix(values, "]") {
+		spec = "enum"
+		for value := range strings.SplitSeq(strings.TrimSuffix(values, "]"), ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				spec += "|" + value
+			}
+		}
+	}
+
 	parts := strings.Split(spec, "|")
 	kind := parts[0]
 
@@ -55,6 +68,7 @@ func ParseTypeSpec(key string, spec string) (*TypeSpec, error) {
 	// Validate kind
 	validKinds := map[string]bool{
 		"enum":      true,
+		"bool":      true,
 		"number":    true,
 		"date":      true,
 		"list":      true,
@@ -88,6 +102,10 @@ func ParseTypeSpec(key string, spec string) (*TypeSpec, error) {
 		}
 	}
 
+	if kind == "bool" && len(ts.Values) > 0 {
+		return nil, fmt.Errorf("bool type should not have values")
+	}
+
 	if kind == "date" && len(ts.Values) > 0 {
 		return nil, fmt.Errorf("date type should not have values")
 	}
@@ -150,8 +168,14 @@ func (ts *TypeSpec) validateValue(value, fieldName string) error {
 				return nil
 			}
 		}
-		return fmt.Errorf("value '%s' is not a valid %s (must be one of: %s)",
-			value, fieldName, strings.Join(ts.Values, ", "))
+		return fmt.Errorf("%s must be one of %s, got '%s'",
+			fieldName, strings.Join(ts.Values, ", "), value)
+
+	case "bool":
+		if value == "true" || value == "false" {
+			return nil
+		}
+		return fmt.Errorf("%s must be true or false, got '%s'", fieldName, value)
 
 	case "number":
 		num, err := strconv.Atoi(value)
@@ -229,15 +253,31 @@ func (ts *TypeSpec) validateValue(value, fieldName string) error {
 	}
 }
 
+// Options returns the values to pick from for this type: the enum values,
+// true and false for bool, or nil when any value of the right format is allowed
+func (ts *TypeSpec) Options() []string {
+	switch ts.Kind {
+	case "enum":
+		return ts.Values
+	case "bool":
+		return []string{"true", "false"}
+	}
+	return nil
+}
+
 // TypeRegistry manages all type definitions for an outline
 type TypeRegistry struct {
-	types map[string]*TypeSpec
+	types     map[string]*TypeSpec
+	defaults  map[string]map[string]string // Node type -> attribute key -> default value
+	templates map[string]string            // Node type -> template item ID
 }
 
 // NewTypeRegistry creates an empty type registry
 func NewTypeRegistry() *TypeRegistry {
 	return &TypeRegistry{
-		types: make(map[string]*TypeSpec),
+		types:     make(map[string]*TypeSpec),
+		defaults:  make(map[string]map[string]string),
+		templates: make(map[string]string),
 	}
 }
 
@@ -294,6 +334,15 @@ func (tr *TypeRegistry) LoadFromOutline(outline *model.Outline) error {
 
 	typeDebugLog.Printf("LoadFromOutline called, outline has %d type definitions", len(outline.TypeDefinitions))
 
+	for typeName, defaults := range outline.TypeDefaults {
+		for key, value := range defaults {
+			tr.SetDefault(typeName, key, value)
+		}
+	}
+	for typeName, itemID := range outline.TypeTemplates {
+		tr.SetTemplate(typeName, itemID)
+	}
+
 	if len(outline.TypeDefinitions) == 0 {
 		typeDebugLog.Printf("No type definitions in outline")
 		return nil
@@ -342,10 +391,97 @@ func (tr *TypeRegistry) SaveToOutline(outline *model.Outline) error {
 		typeDebugLog.Printf("  Saved type: %s = %s", key, spec)
 	}
 
+	// Node type defaults and templates, nil keeps them out of the saved file
+	outline.TypeDefaults = nil
+	for typeName, defaults := range tr.defaults {
+		if outline.TypeDefaults == nil {
+			outline.TypeDefaults = make(map[string]map[string]string)
+		}
+		outline.TypeDefaults[typeName] = make(map[string]string, len(defaults))
+		for key, value := range defaults {
+			outline.TypeDefaults[typeName][key] = value
+		}
+	}
+	outline.TypeTemplates = nil
+	for typeName, itemID := range tr.templates {
+		if outline.TypeTemplates == nil {
+			outline.TypeTemplates = make(map[string]string)
+		}
+		outline.TypeTemplates[typeName] = itemID
+	}
+
 	typeDebugLog.Printf("SaveToOutline complete, outline.TypeDefinitions now has %d types", len(outline.TypeDefinitions))
 	return nil
 }
 
+// SetDefault sets the default value of an attribute for items of a node type,
+// the node type is the value of the item's "type" attribute
+func (tr *TypeRegistry) SetDefault(typeName, key, value string) {
+	if tr.defaults[typeName] == nil {
+		tr.defaults[typeName] = make(map[string]string)
+	}
+	tr.defaults[typeName][key] = value
+}
+
+// RemoveDefault removes the default value of an attribute for a node type
+func (tr *TypeRegistry) RemoveDefault(typeName, key string) {
+	delete(tr.defaults[typeName], key)
+	if len(tr.defaults[typeName]) == 0 {
+		delete(tr.defaults, typeName)
+	}
+}
+
+// GetDefaults returns the default attributes for a node type, or nil when it has none
+func (tr *TypeRegistry) GetDefaults(typeName string) map[string]string {
+	return tr.defaults[typeName]
+}
+
+// SetTemplate sets the template item of a node type, its children are copied
+// into new items of that type. An empty itemID removes the template.
+func (tr *TypeRegistry) SetTemplate(typeName, itemID string) {
+	if itemID == "" {
+		delete(tr.templates, typeName)
+		return
+	}
+	tr.templates[typeName] = itemID
+}
+
+// GetTemplate returns the ID of the template item of a node type, or "" when it has none
+func (tr *TypeRegistry) GetTemplate(typeName string) string {
+	return tr.templates[typeName]
+}
+
+// ApplyDefaults sets the default attributes of the item's node type that the item
+// doesn't have yet, values the user set are kept. Returns the keys that were set.
+func (tr *TypeRegistry) ApplyDefaults(item *model.Item) []string {
+	if item == nil || item.Metadata == nil {
+		return nil
+	}
+	defaults := tr.defaults[item.Metadata.Attributes["type"]]
+	if len(defaults) == 0 {
+		return nil
+	}
+	if item.Metadata.Attributes == nil {
+		item.Metadata.Attributes = make(map[string]string)
+	}
+
+	keys := make([]string, 0, len(defaults))
+	for key := range defaults {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var set []string
+	for _, key := range keys {
+		if _, exists := item.Metadata.Attributes[key]; exists {
+			continue
+		}
+		item.Metadata.Attributes[key] = defaults[key]
+		set = append(set, key)
+	}
+	return set
+}
+
 // GetAll returns all registered type definitions
 func (tr *TypeRegistry) GetAll() map[string]*TypeSpec {
 	return tr.types
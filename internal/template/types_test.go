@@ -216,6 +216,36 @@ func TestTypeSpecValidate(t *testing.T) {
 			value:   "2025-01-01, 2025-13-01, 2025-02-14",
 			wantErr: true,
 		},
+		{
+			name:    "bracket enum valid",
+			spec:    "enum[todo, doing,done]",
+			value:   "doing",
+			wantErr: false,
+		},
+		{
+			name:    "bracket enum invalid",
+			spec:    "enum[todo,doing,done]",
+			value:   "Done",
+			wantErr: true,
+		},
+		{
+			name:    "bool true",
+			spec:    "bool",
+			value:   "true",
+			wantErr: false,
+		},
+		{
+			name:    "bool false",
+			spec:    "bool",
+			value:   "false",
+			wantErr: false,
+		},
+		{
+			name:    "bool invalid",
+			spec:    "bool",
+			value:   "yes",
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -308,3 +338,100 @@ func TestValidateItem(t *testing.T) {
 		t.Errorf("Invalid item should error")
 	}
 }
+
+func TestValidateErrorMessages(t *testing.T) {
+	tr := NewTypeRegistry()
+	if err := tr.AddType("status", "enum[todo,doing,done]"); err != nil {
+		t.Fatalf("AddType enum failed: %v", err)
+	}
+	if err := tr.AddType("done", "bool"); err != nil {
+		t.Fatalf("AddType bool failed: %v", err)
+	}
+
+	err := tr.Validate("status", "waiting")
+	if err == nil || err.Error() != "status must be one of todo, doing, done, got 'waiting'" {
+		t.Errorf("Unexpected enum error: %v", err)
+	}
+	err = tr.Validate("done", "1")
+	if err == nil || err.Error() != "done must be true or false, got '1'" {
+		t.Errorf("Unexpected bool error: %v", err)
+	}
+
+	if _, err := ParseTypeSpec("done", "bool|yes"); err == nil {
+		t.Error("Expected bool with values to be rejected")
+	}
+	if _, err := ParseTypeSpec("status", "enum[]"); err == nil {
+		t.Error("Expected enum without values to be rejected")
+	}
+}
+
+func TestTypeSpecOptions(t *testing.T) {
+	enum, _ := ParseTypeSpec("status", "enum[todo,done]")
+	if got := enum.Options(); len(got) != 2 || got[0] != "todo" || got[1] != "done" {
+		t.Errorf("Expected enum values as options, got %v", got)
+	}
+	boolean, _ := ParseTypeSpec("done", "bool")
+	if got := boolean.Options(); len(got) != 2 || got[0] != "true" {
+		t.Errorf("Expected true and false as options, got %v", got)
+	}
+	date, _ := ParseTypeSpec("due", "date")
+	if date.Options() != nil {
+		t.Errorf("Expected no options for dates")
+	}
+
+	// Bracket enums are saved in the pipe form and load again
+	tr := NewTypeRegistry()
+	tr.AddType("status", "enum[todo,done]")
+	outline := model.NewOutline()
+	tr.SaveToOutline(outline)
+	if outline.TypeDefinitions["status"] != "enum|todo|done" {
+		t.Errorf("Expected saved spec enum|todo|done, got %q", outline.TypeDefinitions["status"])
+	}
+}
+
+func TestTypeRegistryDefaults(t *testing.T) {
+	tr := NewTypeRegistry()
+	tr.SetDefault("meeting", "attendees", "none")
+	tr.SetDefault("meeting", "location", "office")
+	tr.SetTemplate("meeting", "tmpl-1")
+
+	outline := model.NewOutline()
+	if err := tr.SaveToOutline(outline); err != nil {
+		t.Fatalf("SaveToOutline failed: %v", err)
+	}
+	if len(outline.TypeDefinitions) != 0 {
+		t.Errorf("Defaults should not be saved as type definitions: %v", outline.TypeDefinitions)
+	}
+
+	tr2 := NewTypeRegistry()
+	if err := tr2.LoadFromOutline(outline); err != nil {
+		t.Fatalf("LoadFromOutline failed: %v", err)
+	}
+	if got := tr2.GetDefaults("meeting")["location"]; got != "office" {
+		t.Errorf("Expected default location 'office', got %q", got)
+	}
+	if got := tr2.GetTemplate("meeting"); got != "tmpl-1" {
+		t.Errorf("Expected template tmpl-1, got %q", got)
+	}
+
+	item := model.NewItem("Standup")
+	item.Metadata.Attributes["type"] = "meeting"
+	item.Metadata.Attributes["location"] = "online"
+	set := tr2.ApplyDefaults(item)
+	if len(set) != 1 || set[0] != "attendees" {
+		t.Errorf("Expected only attendees to be set, got %v", set)
+	}
+	if item.Metadata.Attributes["location"] != "online" {
+		t.Errorf("Default should not overwrite location, got %q", item.Metadata.Attributes["location"])
+	}
+
+	tr2.RemoveDefault("meeting", "attendees")
+	tr2.RemoveDefault("meeting", "location")
+	tr2.SetTemplate("meeting", "")
+	if err := tr2.SaveToOutline(outline); err != nil {
+		t.Fatalf("SaveToOutline failed: %v", err)
+	}
+	if outline.TypeDefaults != nil || outline.TypeTemplates != nil {
+		t.Errorf("Expected no defaults or templates, got %v and %v", outline.TypeDefaults, outline.TypeTemplates)
+	}
+}
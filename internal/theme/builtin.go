@@ -0,0 +1,198 @@
+package theme
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// builtinThemes are the themes that are available without a theme file
+var builtinThemes = map[string]func() *Theme{
+	"default":   Default,
+	"dark":      Dark,
+	"light":     Light,
+	"solarized": Solarized,
+}
+
+// BuiltinNames returns the names of the built-in themes in sorted order
+func BuiltinNames() []string {
+	names := make([]string, 0, len(builtinThemes))
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Builtin returns the built-in theme with the given name
+func Builtin(name string) (*Theme, bool) {
+	newTheme, ok := builtinThemes[strings.ToLower(name)]
+	if !ok {
+		return nil, false
+	}
+	return newTheme(), true
+}
+
+// LoadThemeByName loads a theme from a theme file, or one of the built-in
+// themes when there is no file with that name. A theme file takes precedence,
+// so a built-in theme can be customized by a file with the same name.
+func LoadThemeByName(name string) (*Theme, error) {
+	if t, err := LoadTheme(name); err == nil {
+		return t, nil
+	}
+	if t, ok := Builtin(name); ok {
+		return t, nil
+	}
+	return nil, fmt.Errorf("unknown theme '%s' (built-in themes: %s)", name, strings.Join(BuiltinNames(), ", "))
+}
+
+// Dark returns the dark theme, which is the default theme
+func Dark() *Theme {
+	t := Default()
+	t.Name = "dark"
+	return t
+}
+
+// Light returns a theme with dark text on a white background
+func Light() *Theme {
+	t := Default()
+	t.Name = "light"
+	c := &t.Colors
+
+	c.Background = tcell.ColorWhite
+	c.TreeNormalText = tcell.ColorBlack
+	c.TreeSelectedItem = tcell.ColorWhite
+	c.TreeSelectedBg = tcell.NewRGBColor(40, 80, 160) // Dark blue (#2850A0)
+	c.TreeNewItem = tcell.ColorGray
+	c.TreeLeafArrow = tcell.ColorGray
+	c.TreeExpandableArrow = tcell.ColorBlack
+	c.TreeExpandedArrow = tcell.ColorBlack
+	c.TreeCollapsedArrow = tcell.ColorBlack
+	c.TreeVisualSelection = tcell.ColorBlack
+	c.TreeVisualSelectionBg = tcell.NewRGBColor(173, 216, 230) // Light blue (#ADD8E6)
+	c.TreeVisualCursor = tcell.ColorWhite
+	c.TreeVisualCursorBg = tcell.NewRGBColor(70, 130, 180)   // Steel blue (#4682B4)
+	c.TreeAttributeIndicator = tcell.NewRGBColor(0, 128, 96) // Dark teal (#008060)
+	c.TreeAttributeValue = tcell.ColorGray
+	c.TreeTagValue = tcell.NewRGBColor(40, 80, 200) // Blue (#2850C8)
+	c.TreeLinkText = tcell.NewRGBColor(0, 128, 128) // Teal (#008080)
+
+	c.ColorYellow = tcell.NewRGBColor(176, 128, 0) // Dark yellow, readable on white (#B08000)
+	c.ColorOrange = tcell.NewRGBColor(204, 102, 0) // Dark orange (#CC6600)
+	c.ColorRed = tcell.NewRGBColor(192, 0, 0)
+	c.ColorGreen = tcell.NewRGBColor(0, 128, 0)
+	c.ColorBlue = tcell.NewRGBColor(0, 0, 192)
+	c.ColorPurple = tcell.NewRGBColor(128, 0, 128)
+	c.ColorGray = tcell.ColorGray
+
+	c.EditorText = tcell.ColorBlack
+	c.EditorCursor = tcell.ColorWhite
+	c.EditorCursorBg = tcell.ColorBlack
+	c.SearchLabel = tcell.ColorBlack
+	c.SearchText = tcell.ColorBlack
+	c.SearchCursor = tcell.ColorWhite
+	c.SearchCursorBg = tcell.ColorBlack
+	c.SearchResultCount = tcell.ColorBlack
+	c.SearchHighlight = tcell.ColorBlack
+	c.SearchHighlightBg = tcell.NewRGBColor(255, 230, 100) // Soft yellow (#FFE664)
+	c.CommandPrompt = tcell.ColorBlack
+	c.CommandText = tcell.ColorBlack
+	c.CommandCursor = tcell.ColorWhite
+	c.CommandCursorBg = tcell.ColorBlack
+	c.HelpBackground = tcell.ColorWhite
+	c.HelpBorder = tcell.ColorBlack
+	c.HelpTitle = tcell.ColorBlack
+	c.HelpContent = tcell.ColorBlack
+	c.StatusMode = tcell.ColorBlack
+	c.StatusModeBg = tcell.NewRGBColor(220, 220, 220) // Light gray (#DCDCDC)
+	c.StatusMessage = tcell.ColorBlack
+	c.StatusModified = tcell.ColorBlack
+	c.HeaderTitle = tcell.ColorBlack
+	c.HeaderBg = tcell.NewRGBColor(220, 220, 220)
+	c.CalendarDayText = tcell.ColorBlack
+	c.CalendarDayBg = tcell.ColorWhite
+	c.CalendarInactiveDayText = tcell.ColorGray
+	c.CalendarInactiveDayBg = tcell.ColorWhite
+	return t
+}
+
+// Solarized returns a theme with the dark Solarized palette
+func Solarized() *Theme {
+	var (
+		base03  = HexToColor("#002b36")
+		base02  = HexToColor("#073642")
+		base01  = HexToColor("#586e75")
+		base0   = HexToColor("#839496")
+		base1   = HexToColor("#93a1a1")
+		yellow  = HexToColor("#b58900")
+		orange  = HexToColor("#cb4b16")
+		red     = HexToColor("#dc322f")
+		magenta = HexToColor("#d33682")
+		violet  = HexToColor("#6c71c4")
+		blue    = HexToColor("#268bd2")
+		cyan    = HexToColor("#2aa198")
+		green   = HexToColor("#859900")
+	)
+
+	t := Default()
+	t.Name = "solarized"
+	c := &t.Colors
+
+	c.Background = base03
+	c.TreeNormalText = base0
+	c.TreeSelectedItem = base03
+	c.TreeSelectedBg = base1
+	c.TreeNewItem = base01
+	c.TreeLeafArrow = base01
+	c.TreeExpandableArrow = base1
+	c.TreeExpandedArrow = base1
+	c.TreeCollapsedArrow = base1
+	c.TreeVisualSelection = base1
+	c.TreeVisualSelectionBg = base02
+	c.TreeVisualCursor = base03
+	c.TreeVisualCursorBg = blue
+	c.TreeAttributeIndicator = cyan
+	c.TreeAttributeValue = base01
+	c.TreeTagValue = violet
+	c.TreeLinkText = cyan
+
+	c.ColorYellow = yellow
+	c.ColorOrange = orange
+	c.ColorRed = red
+	c.ColorGreen = green
+	c.ColorBlue = blue
+	c.ColorPurple = magenta
+	c.ColorGray = base01
+
+	c.EditorText = base1
+	c.EditorCursor = base03
+	c.EditorCursorBg = base1
+	c.SearchLabel = base1
+	c.SearchText = base0
+	c.SearchCursor = base03
+	c.SearchCursorBg = base1
+	c.SearchResultCount = base0
+	c.SearchHighlight = base03
+	c.SearchHighlightBg = yellow
+	c.CommandPrompt = base1
+	c.CommandText = base0
+	c.CommandCursor = base03
+	c.CommandCursorBg = base1
+	c.HelpBackground = base02
+	c.HelpBorder = base01
+	c.HelpTitle = base1
+	c.HelpContent = base0
+	c.StatusMode = base03
+	c.StatusModeBg = blue
+	c.StatusMessage = base0
+	c.StatusModified = orange
+	c.HeaderTitle = base1
+	c.HeaderBg = base02
+	c.CalendarDayText = base0
+	c.CalendarDayBg = base03
+	c.CalendarInactiveDayText = base01
+	c.CalendarInactiveDayBg = base03
+	return t
+}
@@ -0,0 +1,46 @@
+package theme
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuiltinThemes(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	for _, name := range []string{"dark", "light", "solarized"} {
+		theme, err := LoadThemeByName(name)
+		if err != nil {
+			t.Fatalf("Expected built-in theme %s: %v", name, err)
+		}
+		if theme.Name != name {
+			t.Errorf("Expected theme name %s, got %s", name, theme.Name)
+		}
+	}
+
+	if light, _ := Builtin("light"); light.Colors.Background == Default().Colors.Background {
+		t.Error("Expected the light theme to have its own background")
+	}
+	if _, err := LoadThemeByName("no-such-theme"); err == nil {
+		t.Error("Expected an error for an unknown theme")
+	}
+}
+
+func TestThemeFileOverridesBuiltin(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, ".config", "tui-outliner", "themes")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	data := "name = \"my-light\"\n[colors]\nbackground = \"#fdf6e3\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "light.toml"), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	theme := LoadThemeOrDefault("light")
+	if theme.Name != "my-light" || theme.Colors.Background != HexToColor("#fdf6e3") {
+		t.Errorf("Expected the theme file to be used, got %s", theme.Name)
+	}
+}
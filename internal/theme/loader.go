@@ -260,7 +260,7 @@ func LoadThemeOrDefault(themeName string) *Theme {
 		return Default()
 	}
 
-	theme, err := LoadTheme(themeName)
+	theme, err := LoadThemeByName(themeName)
 	if err != nil {
 		// Fall back to Default
 		return Default()
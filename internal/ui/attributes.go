@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"time"
 
@@ -265,8 +266,60 @@ func (ae *AttributeEditor) handleViewMode(ev *tcell.EventKey) bool {
 	return false
 }
 
+// valueOptions returns the allowed values of the attribute being edited,
+// or nil when its type allows free text
+func (ae *AttributeEditor) valueOptions() []string {
+	if ae.typeRegistry == nil {
+		return nil
+	}
+	ts := ae.typeRegistry.GetType(ae.editingKey)
+	if ts == nil {
+		return nil
+	}
+	return ts.Options()
+}
+
+// pickValueOption handles Up and Down by replacing the value with the previous
+// or next allowed value. Returns false for other keys or free text attributes.
+func (ae *AttributeEditor) pickValueOption(ev *tcell.EventKey) bool {
+	delta := 0
+	switch ev.Key() {
+	case tcell.KeyUp:
+		delta = -1
+	case tcell.KeyDown:
+		delta = 1
+	default:
+		return false
+	}
+
+	options := ae.valueOptions()
+	if len(options) == 0 {
+		return false
+	}
+
+	idx := slices.Index(options, ae.valueEditor.GetText())
+	if idx < 0 {
+		// Start from the first or last option
+		if delta > 0 {
+			idx = 0
+		} else {
+			idx = len(options) - 1
+		}
+	} else {
+		idx = (idx + delta + len(options)) % len(options)
+	}
+
+	// Restart the editor so the cursor is at the end of the picked value
+	ae.valueEditor.SetText(options[idx])
+	ae.valueEditor.Start()
+	return true
+}
+
 // handleEditMode handles keys while editing an attribute value
 func (ae *AttributeEditor) handleEditMode(ev *tcell.EventKey) bool {
+	if ae.pickValueOption(ev) {
+		return true
+	}
 	ae.valueEditor.HandleKey(ev)
 
 	if ae.valueEditor.WasEnterPressed() {
@@ -339,6 +392,9 @@ func (ae *AttributeEditor) handleAddKeyMode(ev *tcell.EventKey) bool {
 
 // handleAddValueMode handles keys while entering the value for a new attribute
 func (ae *AttributeEditor) handleAddValueMode(ev *tcell.EventKey) bool {
+	if ae.pickValueOption(ev) {
+		return true
+	}
 	ae.valueEditor.HandleKey(ev)
 
 	if ae.valueEditor.WasEnterPressed() {
@@ -571,6 +627,8 @@ func (ae *AttributeEditor) renderEditMode(screen *Screen, startX, startY, boxWid
 	screen.SetCell(startX+boxWidth-1, y, '│', borderStyle)
 	y++
 
+	y = ae.renderValueOptions(screen, startX, y, boxWidth, startY+height-2, contentStyle, borderStyle)
+
 	// Fill empty lines with borders
 	for y < startY+height-2 {
 		screen.SetCell(startX, y, '│', borderStyle)
@@ -579,7 +637,9 @@ func (ae *AttributeEditor) renderEditMode(screen *Screen, startX, startY, boxWid
 	}
 
 	// Update status message with help text
-	if ae.calendarWidget != nil {
+	if len(ae.valueOptions()) > 0 {
+		ae.statusMessage = "[Up/Down]Pick [Enter]Save [Escape]Cancel"
+	} else if ae.calendarWidget != nil {
 		ae.statusMessage = "[Enter]Save [Escape]Cancel [Ctrl+D]Calendar"
 	} else {
 		ae.statusMessage = "[Enter]Save [Escape]Cancel"
@@ -621,6 +681,8 @@ func (ae *AttributeEditor) renderAddMode(screen *Screen, startX, startY, boxWidt
 		ae.valueEditor.Render(screen, startX+1+valuePrefixWidth, y, maxValueWidth)
 		screen.SetCell(startX+boxWidth-1, y, '│', borderStyle)
 		y++
+
+		y = ae.renderValueOptions(screen, startX, y, boxWidth, startY+height-2, contentStyle, borderStyle)
 	}
 
 	// Fill empty lines with borders
@@ -633,7 +695,35 @@ func (ae *AttributeEditor) renderAddMode(screen *Screen, startX, startY, boxWidt
 	// Update status message with help text
 	if ae.mode == "add_key" {
 		ae.statusMessage = "[Enter]Next [Escape]Cancel"
+	} else if len(ae.valueOptions()) > 0 {
+		ae.statusMessage = "[Up/Down]Pick [Enter]Save [Escape]Cancel"
 	} else {
 		ae.statusMessage = "[Enter]Save [Escape]Cancel"
 	}
 }
+
+// renderValueOptions draws the allowed values of a typed attribute below the
+// value line, marking the current value. Returns the next free line.
+func (ae *AttributeEditor) renderValueOptions(screen *Screen, startX, y, boxWidth, maxY int, contentStyle, borderStyle tcell.Style) int {
+	options := ae.valueOptions()
+	if len(options) == 0 {
+		return y
+	}
+
+	current := ae.valueEditor.GetText()
+	for _, option := range options {
+		if y >= maxY {
+			break
+		}
+		marker := "   "
+		if option == current {
+			marker = " > "
+		}
+		line := TruncateToWidth(marker+option, boxWidth-2)
+		screen.SetCell(startX, y, '│', borderStyle)
+		screen.DrawString(startX+1, y, line, contentStyle)
+		screen.SetCell(startX+boxWidth-1, y, '│', borderStyle)
+		y++
+	}
+	return y
+}
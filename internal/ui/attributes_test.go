@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/pstuifzand/tui-outliner/internal/model"
+	tmpl "github.com/pstuifzand/tui-outliner/internal/template"
+)
+
+func TestAttributeEditorPicksEnumValues(t *testing.T) {
+	registry := tmpl.NewTypeRegistry()
+	if err := registry.AddType("status", "enum[todo,doing,done]"); err != nil {
+		t.Fatalf("AddType failed: %v", err)
+	}
+
+	item := model.NewItem("Task")
+	item.Metadata.Attributes["status"] = "todo"
+
+	ae := NewAttributeEditor()
+	ae.SetTypeRegistry(registry)
+	ae.Show(item)
+	ae.HandleKeyEvent(tcell.NewEventKey(tcell.KeyRune, 'e', tcell.ModNone))
+
+	down := tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+	ae.HandleKeyEvent(down)
+	ae.HandleKeyEvent(down)
+	if got := ae.valueEditor.GetText(); got != "done" {
+		t.Fatalf("Expected Down to pick done, got %q", got)
+	}
+
+	// Picking wraps around
+	ae.HandleKeyEvent(down)
+	if got := ae.valueEditor.GetText(); got != "todo" {
+		t.Errorf("Expected Down to wrap to todo, got %q", got)
+	}
+	ae.HandleKeyEvent(tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone))
+	ae.HandleKeyEvent(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	if item.Metadata.Attributes["status"] != "done" {
+		t.Errorf("Expected status done to be saved, got %q", item.Metadata.Attributes["status"])
+	}
+}
@@ -3,7 +3,6 @@ package ui
 import (
 	"encoding/json"
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/gdamore/tcell/v2"
@@ -165,7 +164,7 @@ func (bs *BackupSelectorWidget) updateDiffPreview() {
 		backupOutlineToUse = bs.currentOutline
 	} else {
 		// Load the backup file
-		backupData, err2 := os.ReadFile(backup.FilePath)
+		backupData, err2 := storage.ReadOutlineFile(backup.FilePath)
 		if err2 != nil {
 			bs.diffLines = []diff.DiffLine{}
 			bs.backupOutline = nil
@@ -225,7 +224,7 @@ func (bs *BackupSelectorWidget) loadBackupOutline(actualIdx int) {
 	}
 
 	// Load the backup file
-	backupData, err := os.ReadFile(backup.FilePath)
+	backupData, err := storage.ReadOutlineFile(backup.FilePath)
 	if err != nil {
 		bs.backupOutline = nil
 		return
@@ -260,7 +259,7 @@ func (bs *BackupSelectorWidget) preloadAllDiffs() {
 			}
 		} else {
 			// Load the backup file
-			backupData, err2 := os.ReadFile(backup.FilePath)
+			backupData, err2 := storage.ReadOutlineFile(backup.FilePath)
 			if err2 != nil {
 				continue
 			}
@@ -492,7 +491,7 @@ func (bs *BackupSelectorWidget) updateVisualModeDiff() {
 
 	// Load first selected backup
 	firstBackup := bs.backups[firstActualIdx]
-	firstData, err := os.ReadFile(firstBackup.FilePath)
+	firstData, err := storage.ReadOutlineFile(firstBackup.FilePath)
 	if err != nil {
 		bs.diffLines = []diff.DiffLine{}
 		return
@@ -506,7 +505,7 @@ func (bs *BackupSelectorWidget) updateVisualModeDiff() {
 
 	// Load last selected backup
 	lastBackup := bs.backups[lastActualIdx]
-	lastData, err := os.ReadFile(lastBackup.FilePath)
+	lastData, err := storage.ReadOutlineFile(lastBackup.FilePath)
 	if err != nil {
 		bs.diffLines = []diff.DiffLine{}
 		return
@@ -13,6 +13,7 @@ type CommandMode struct {
 	input     string
 	cursorPos int
 	history   *History
+	question  string // Question of a yes/no prompt, empty when entering a command
 }
 
 // NewCommandMode creates a new CommandMode without history persistence
@@ -46,9 +47,29 @@ func (c *CommandMode) Start() {
 	c.active = true
 	c.input = ""
 	c.cursorPos = 0
+	c.question = ""
 	c.history.Reset()
 }
 
+// StartConfirm asks a yes/no question in the command line. The next key
+// answers it: HandleKey returns "y" for y and "n" for any other key.
+func (c *CommandMode) StartConfirm(question string) {
+	c.Start()
+	c.question = question
+}
+
+// IsConfirming returns whether the command line shows a yes/no question
+func (c *CommandMode) IsConfirming() bool {
+	return c.active && c.question != ""
+}
+
+// StartWithInput enters command mode with input already typed
+func (c *CommandMode) StartWithInput(input string) {
+	c.Start()
+	c.input = input
+	c.cursorPos = len(input)
+}
+
 // Stop exits command mode
 func (c *CommandMode) Stop() {
 	c.active = false
@@ -86,6 +107,15 @@ func (c *CommandMode) DeleteWordBackwards() {
 
 // HandleKey processes a key press in command mode
 func (c *CommandMode) HandleKey(ev *tcell.EventKey) (command string, done bool) {
+	if c.question != "" {
+		c.question = ""
+		c.Stop()
+		if ev.Key() == tcell.KeyRune && (ev.Rune() == 'y' || ev.Rune() == 'Y') {
+			return "y", true
+		}
+		return "n", true
+	}
+
 	switch ev.Key() {
 	case tcell.KeyCtrlW:
 		// Check for Ctrl+W - delete word backwards
@@ -187,11 +217,14 @@ func (c *CommandMode) Render(screen *Screen, y int) {
 	cursorStyle := screen.CommandCursorStyle()
 	screenWidth := screen.GetWidth()
 
-	// Draw colon and input
+	// Draw colon and input, or the question
 	prefix := ":"
+	if c.question != "" {
+		prefix = c.question + " (y/n) "
+	}
 	x := 0
 	screen.DrawString(x, y, prefix, promptStyle)
-	x += len(prefix)
+	x += StringWidth(prefix)
 
 	// Draw input with cursor
 	for i, r := range c.input {
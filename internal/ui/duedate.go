@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/pstuifzand/tui-outliner/internal/config"
+	"github.com/pstuifzand/tui-outliner/internal/model"
+	"github.com/pstuifzand/tui-outliner/internal/search"
+)
+
+// DueState tells how the due date of an item relates to today
+type DueState int
+
+const (
+	DueNone    DueState = iota // No due date, or done
+	DueSoon                    // Due within the duesoon window after today
+	DueToday                   // Due today
+	DueOverdue                 // Due before today
+)
+
+// Defaults of the duedateattr and duesoon settings
+const (
+	DefaultDueDateAttr = "deadline"
+	DefaultDueSoonDays = 3
+)
+
+// DueDates holds the due date settings used for highlighting
+type DueDates struct {
+	Keys       []string // Attributes holding due dates, the earliest date counts
+	SoonDays   int      // Days after today that count as soon
+	DoneStatus string   // Items with this status are never due
+}
+
+// DueDatesFromConfig returns the due date settings from duedateattr, duesoon
+// and todostatuses. Returns false when duedateattr is "none".
+func DueDatesFromConfig(cfg *config.Config) (DueDates, bool) {
+	due := DueDates{Keys: []string{DefaultDueDateAttr}, SoonDays: DefaultDueSoonDays, DoneStatus: "done"}
+	if cfg == nil {
+		return due, true
+	}
+
+	if attrs := strings.TrimSpace(cfg.Get("duedateattr")); attrs == "none" {
+		return due, false
+	} else if attrs != "" {
+		due.Keys = nil
+		for key := range strings.SplitSeq(attrs, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				due.Keys = append(due.Keys, key)
+			}
+		}
+	}
+	if days, err := strconv.Atoi(cfg.Get("duesoon")); err == nil && days >= 0 {
+		due.SoonDays = days
+	}
+	if statuses := cfg.Get("todostatuses"); statuses != "" {
+		parts := strings.Split(statuses, ",")
+		due.DoneStatus = strings.TrimSpace(parts[len(parts)-1])
+	}
+	return due, true
+}
+
+// State returns the due state of item on the day of now
+func (d DueDates) State(item *model.Item, now time.Time) DueState {
+	if item.Metadata == nil || len(item.Metadata.Attributes) == 0 {
+		return DueNone
+	}
+	if status, ok := item.Metadata.Attributes["status"]; ok && status == d.DoneStatus {
+		return DueNone
+	}
+
+	var earliest time.Time
+	for _, key := range d.Keys {
+		value, ok := item.Metadata.Attributes[key]
+		if !ok {
+			continue
+		}
+		date := search.ParseDate(value)
+		if date.IsZero() {
+			continue
+		}
+		// Compare whole days in local time
+		date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local)
+		if earliest.IsZero() || date.Before(earliest) {
+			earliest = date
+		}
+	}
+	if earliest.IsZero() {
+		return DueNone
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	switch {
+	case earliest.Before(today):
+		return DueOverdue
+	case earliest.Equal(today):
+		return DueToday
+	case earliest.Before(today.AddDate(0, 0, d.SoonDays+1)):
+		return DueSoon
+	}
+	return DueNone
+}
+
+// DueColor returns the theme color for a due state: red for overdue, orange
+// for today and yellow for soon
+func (s *Screen) DueColor(state DueState) tcell.Color {
+	switch state {
+	case DueOverdue:
+		return s.Theme.Colors.ColorRed
+	case DueToday:
+		return s.Theme.Colors.ColorOrange
+	}
+	return s.Theme.Colors.ColorYellow
+}
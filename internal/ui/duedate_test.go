@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pstuifzand/tui-outliner/internal/config"
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+func TestDueDatesState(t *testing.T) {
+	now := time.Date(2026, 10, 16, 15, 0, 0, 0, time.Local)
+	due, ok := DueDatesFromConfig(&config.Config{})
+	if !ok {
+		t.Fatal("Expected due dates to be shown by default")
+	}
+
+	item := func(attrs ...string) *model.Item {
+		item := model.NewItem("Task")
+		for i := 0; i+1 < len(attrs); i += 2 {
+			item.Metadata.Attributes[attrs[i]] = attrs[i+1]
+		}
+		return item
+	}
+
+	tests := []struct {
+		name string
+		item *model.Item
+		want DueState
+	}{
+		{"no deadline", item(), DueNone},
+		{"overdue", item("deadline", "2026-10-15"), DueOverdue},
+		{"today", item("deadline", "2026-10-16"), DueToday},
+		{"within window", item("deadline", "2026-10-19"), DueSoon},
+		{"after window", item("deadline", "2026-10-20"), DueNone},
+		{"done", item("deadline", "2026-10-01", "status", "done"), DueNone},
+		{"not a date", item("deadline", "soon"), DueNone},
+		{"date is not a due date by default", item("date", "2026-10-01"), DueNone},
+	}
+	for _, tt := range tests {
+		if got := due.State(tt.item, now); got != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.want, got)
+		}
+	}
+
+	cfg := &config.Config{}
+	cfg.Set("duedateattr", "date, deadline")
+	cfg.Set("duesoon", "0")
+	cfg.Set("todostatuses", "open,closed")
+	due, _ = DueDatesFromConfig(cfg)
+	if got := due.State(item("date", "2026-10-20", "deadline", "2026-10-10"), now); got != DueOverdue {
+		t.Errorf("Expected the earliest date to count, got %d", got)
+	}
+	if got := due.State(item("date", "2026-10-17"), now); got != DueNone {
+		t.Errorf("Expected no soon window with duesoon 0, got %d", got)
+	}
+	if got := due.State(item("date", "2026-10-01", "status", "closed"), now); got != DueNone {
+		t.Errorf("Expected the configured done status to count, got %d", got)
+	}
+
+	cfg.Set("duedateattr", "none")
+	if _, ok := DueDatesFromConfig(cfg); ok {
+		t.Error("Expected duedateattr none to turn highlighting off")
+	}
+}
@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+)
+
+// EmphasisRange is a range of display text that is drawn bold or italic
+type EmphasisRange struct {
+	Start  int // Start position in display text (rune index)
+	End    int // End position in display text (rune index, exclusive)
+	Bold   bool
+	Italic bool
+}
+
+// ParseEmphasis finds *bold* and _italic_ markup in text and removes the
+// markers. A marker opens at the start of a word and closes at the end of one,
+// so snake_case names and 2*3*4 are left alone. Markup can be nested, like
+// *_both_*. Returns the text without markers, the emphasized ranges and the
+// rune positions in text of the removed markers, in order.
+func ParseEmphasis(text string) (string, []EmphasisRange, []int) {
+	if !strings.ContainsAny(text, "*_") {
+		return text, nil, nil
+	}
+	runes := []rune(text)
+	var result []rune
+	var ranges []EmphasisRange
+	var removed []int
+	parseEmphasisRunes(runes, 0, &result, &ranges, &removed)
+	return string(result), ranges, removed
+}
+
+// parseEmphasisRunes appends runes without markers to result. offset is the
+// position of runes in the original text.
+func parseEmphasisRunes(runes []rune, offset int, result *[]rune, ranges *[]EmphasisRange, removed *[]int) {
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '*' || r == '_' {
+			if end := emphasisEnd(runes, i); end != -1 {
+				start := len(*result)
+				*removed = append(*removed, offset+i)
+				parseEmphasisRunes(runes[i+1:end], offset+i+1, result, ranges, removed)
+				*removed = append(*removed, offset+end)
+				*ranges = append(*ranges, EmphasisRange{
+					Start:  start,
+					End:    len(*result),
+					Bold:   r == '*',
+					Italic: r == '_',
+				})
+				i = end
+				continue
+			}
+		}
+		*result = append(*result, r)
+	}
+}
+
+// emphasisEnd returns the position of the marker closing the marker at start,
+// or -1 when it doesn't open emphasis
+func emphasisEnd(runes []rune, start int) int {
+	marker := runes[start]
+	if start > 0 && isWordRune(runes[start-1]) {
+		return -1
+	}
+	if start+1 >= len(runes) || unicode.IsSpace(runes[start+1]) || runes[start+1] == marker {
+		return -1
+	}
+	for i := start + 2; i < len(runes); i++ {
+		if runes[i] != marker || unicode.IsSpace(runes[i-1]) {
+			continue
+		}
+		if i+1 < len(runes) && isWordRune(runes[i+1]) {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// shiftLinkRanges moves link ranges to their position in text after the
+// markers at the removed positions are taken out
+func shiftLinkRanges(linkRanges []LinkRange, removed []int) []LinkRange {
+	if len(removed) == 0 {
+		return linkRanges
+	}
+	shift := func(pos int) int {
+		n := 0
+		for _, p := range removed {
+			if p < pos {
+				n++
+			}
+		}
+		return pos - n
+	}
+	shifted := make([]LinkRange, len(linkRanges))
+	for i, link := range linkRanges {
+		shifted[i] = LinkRange{Start: shift(link.Start), End: shift(link.End), ID: link.ID}
+	}
+	return shifted
+}
+
+// adjustEmphasisRangesForLine returns the emphasis ranges within [lineStart,
+// lineEnd), relative to the line's start
+func adjustEmphasisRangesForLine(ranges []EmphasisRange, lineStart, lineEnd int) []EmphasisRange {
+	var result []EmphasisRange
+	for _, r := range ranges {
+		if r.End <= lineStart || r.Start >= lineEnd {
+			continue
+		}
+		r.Start = max(r.Start, lineStart) - lineStart
+		r.End = min(r.End, lineEnd) - lineStart
+		result = append(result, r)
+	}
+	return result
+}
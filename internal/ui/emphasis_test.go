@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/pstuifzand/tui-outliner/internal/model"
+	"github.com/pstuifzand/tui-outliner/internal/theme"
+)
+
+func TestParseEmphasis(t *testing.T) {
+	tests := []struct {
+		text   string
+		want   string
+		ranges string
+	}{
+		{"plain text", "plain text", "[]"},
+		{"a *bold* word", "a bold word", "[{2 6 true false}]"},
+		{"an _italic_ word", "an italic word", "[{3 9 false true}]"},
+		{"*_both_* here", "both here", "[{0 4 false true} {0 4 true false}]"},
+		{"*bold words*, _and more_.", "bold words, and more.", "[{0 10 true false} {12 20 false true}]"},
+		{"snake_case_name", "snake_case_name", "[]"},
+		{"2*3*4", "2*3*4", "[]"},
+		{"a * b * c", "a * b * c", "[]"},
+		{"**", "**", "[]"},
+		{"*unclosed", "*unclosed", "[]"},
+		{"*日本*語", "*日本*語", "[]"},
+		{"*日本* 語", "日本 語", "[{0 2 true false}]"},
+	}
+	for _, tt := range tests {
+		got, ranges, _ := ParseEmphasis(tt.text)
+		if got != tt.want || fmt.Sprint(ranges) != tt.ranges {
+			t.Errorf("ParseEmphasis(%q) = %q, %v, want %q, %s", tt.text, got, ranges, tt.want, tt.ranges)
+		}
+	}
+}
+
+func TestEmphasisWithLinksAndWrapping(t *testing.T) {
+	item := model.NewItem("*See* [[abc|the _notes_]] for *the long explanation*")
+	tv := NewTreeView([]*model.Item{item})
+	tv.SetRenderEmphasis(true)
+	tv.SetWrapWidth(20, 0)
+
+	var texts []string
+	var spans []string
+	for _, line := range tv.displayLines {
+		texts = append(texts, line.TextLine)
+		runes := []rune(line.TextLine)
+		for _, r := range line.LinkRanges {
+			spans = append(spans, "link:"+string(runes[r.Start:r.End]))
+		}
+		for _, r := range line.EmphasisRanges {
+			spans = append(spans, fmt.Sprintf("%v/%v:%s", r.Bold, r.Italic, string(runes[r.Start:r.End])))
+		}
+	}
+	if want := `["See the notes for" "the long explanation"]`; fmt.Sprintf("%q", texts) != want {
+		t.Errorf("Expected lines %s, got %q", want, texts)
+	}
+	want := "[link:the notes true/false:See false/true:notes true/false:the long explanation]"
+	if fmt.Sprint(spans) != want {
+		t.Errorf("Expected spans %s, got %v", want, spans)
+	}
+
+	// Without the setting the markers stay and take up room
+	tv.SetRenderEmphasis(false)
+	if got := tv.displayLines[0].TextLine; got != "*See* the _notes_" {
+		t.Errorf("Expected the markers without renderemphasis, got %q", got)
+	}
+}
+
+func TestRenderEmphasisStyles(t *testing.T) {
+	sim := tcell.NewSimulationScreen("")
+	if err := sim.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Fini()
+	sim.SetSize(40, 5)
+	screen := &Screen{tcellScreen: sim, width: 40, height: 5, Theme: theme.Default()}
+
+	tv := NewTreeView(nil)
+	ranges := []EmphasisRange{{Start: 0, End: 1, Bold: true}, {Start: 2, End: 3, Italic: true}}
+	tv.drawTextWithLinksAndSearch(screen, 0, 0, "a b c", nil, ranges, screen.TreeNormalStyle(), screen.SearchHighlightStyle(), screen.TreeNormalStyle(), "b")
+	sim.Show()
+
+	attrs := func(x int) tcell.AttrMask {
+		_, _, style, _ := sim.GetContent(x, 0)
+		_, _, attr := style.Decompose()
+		return attr
+	}
+	if attrs(0)&tcell.AttrBold == 0 || attrs(0)&tcell.AttrItalic != 0 {
+		t.Errorf("Expected 'a' bold, got %v", attrs(0))
+	}
+	if attrs(2)&tcell.AttrItalic == 0 {
+		t.Errorf("Expected the highlighted 'b' to stay italic, got %v", attrs(2))
+	}
+	if attrs(4)&(tcell.AttrBold|tcell.AttrItalic) != 0 {
+		t.Errorf("Expected plain 'c', got %v", attrs(4))
+	}
+}
@@ -42,42 +42,13 @@ func EditItemInExternalEditor(item *model.Item, cfg *config.Config, validateAttr
 	}
 	tmpFile.Close()
 
-	// Get original content to detect changes
-	originalContent, err := os.ReadFile(tmpPath)
-	if err != nil {
-		return fmt.Errorf("failed to read temp file: %w", err)
-	}
-
-	// Resolve editor command
-	editorCmd := resolveEditor(cfg)
-
-	// Launch editor using shell to properly handle commands with arguments like "vim --clean"
-	// We use sh -c to allow complex editor commands with flags
-	// The terminal is temporarily released for the editor to use
-	cmd := exec.Command("sh", "-c", editorCmd+" "+tmpPath)
-
-	// Inherit stdin/stdout/stderr from the current process for proper terminal interaction
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	// Set process group to ensure proper signal handling
-	// This allows the editor to function properly in the terminal
-	if err := cmd.Run(); err != nil {
-		// Editor exited with error, but we should still try to read the file
-		if _, ok := err.(*exec.ExitError); !ok {
-			return fmt.Errorf("failed to launch editor: %w", err)
-		}
-	}
-
-	// Read edited content
-	editedContent, err := os.ReadFile(tmpPath)
+	editedContent, err := runExternalEditor(tmpPath, cfg)
 	if err != nil {
-		return fmt.Errorf("failed to read edited file: %w", err)
+		return err
 	}
 
 	// Check if file was unchanged or empty (editor was closed without saving)
-	if bytes.Equal(originalContent, editedContent) || len(editedContent) == 0 {
+	if editedContent == nil {
 		// No changes - keep original
 		return nil
 	}
@@ -109,6 +80,49 @@ func EditItemInExternalEditor(item *model.Item, cfg *config.Config, validateAttr
 	return nil
 }
 
+// runExternalEditor opens the file at path in the external editor and returns
+// the edited content, or nil when the file was unchanged or emptied
+func runExternalEditor(path string, cfg *config.Config) ([]byte, error) {
+	// Get original content to detect changes
+	originalContent, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read temp file: %w", err)
+	}
+
+	// Resolve editor command
+	editorCmd := resolveEditor(cfg)
+
+	// Launch editor using shell to properly handle commands with arguments like "vim --clean"
+	// We use sh -c to allow complex editor commands with flags
+	// The terminal is temporarily released for the editor to use
+	cmd := exec.Command("sh", "-c", editorCmd+" "+path)
+
+	// Inherit stdin/stdout/stderr from the current process for proper terminal interaction
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	// Set process group to ensure proper signal handling
+	// This allows the editor to function properly in the terminal
+	if err := cmd.Run(); err != nil {
+		// Editor exited with error, but we should still try to read the file
+		if _, ok := err.(*exec.ExitError); !ok {
+			return nil, fmt.Errorf("failed to launch editor: %w", err)
+		}
+	}
+
+	// Read edited content
+	editedContent, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read edited file: %w", err)
+	}
+
+	if bytes.Equal(originalContent, editedContent) || len(editedContent) == 0 {
+		return nil, nil
+	}
+	return editedContent, nil
+}
+
 // serializeItemToFile writes the item to a temp file with TOML frontmatter
 func serializeItemToFile(file *os.File, item *model.Item) error {
 	// Create the frontmatter data
@@ -0,0 +1,278 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pstuifzand/tui-outliner/internal/config"
+	"github.com/pstuifzand/tui-outliner/internal/export"
+	import_parser "github.com/pstuifzand/tui-outliner/internal/import"
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+// subtreeIDProperty is the Org property that carries the item IDs through an
+// external edit of a subtree
+const subtreeIDProperty = "ID"
+
+// orgTagsSuffix matches heading text that the Org importer would read as tags
+var orgTagsSuffix = regexp.MustCompile(`(?:^|\s):[A-Za-z0-9_@#%:]+:\s*$`)
+
+// EditSubtreeInExternalEditor opens item and its descendants as an Org outline
+// in an external editor. It returns the edited items, or nil when the file was
+// not changed. The items still have the ID property, pass them to
+// MergeEditedSubtree to put them in the place of item.
+func EditSubtreeInExternalEditor(item *model.Item, cfg *config.Config, todoStatuses []string, validateAttrs ValidateAttributesFunc) ([]*model.Item, error) {
+	tmpFile, err := os.CreateTemp("", "tuo-edit-*.org")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath)
+
+	_, err = tmpFile.Write(serializeSubtree(item, todoStatuses))
+	tmpFile.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize subtree: %w", err)
+	}
+
+	editedContent, err := runExternalEditor(tmpPath, cfg)
+	if err != nil || editedContent == nil {
+		return nil, err
+	}
+
+	return parseEditedSubtree(editedContent, validateAttrs)
+}
+
+// serializeSubtree writes item and its descendants as Org headings, with the
+// item ID in the property drawer of each heading
+func serializeSubtree(item *model.Item, todoStatuses []string) []byte {
+	clone := model.CloneItemTree(item)
+	setSubtreeIDs(item, clone)
+	escapeHeadings(clone, todoStatuses)
+
+	var buf bytes.Buffer
+	// Writing to a bytes.Buffer never fails
+	_ = export.ExportToOrgWriterWithOptions(&model.Outline{Items: []*model.Item{clone}}, &buf, export.OrgOptions{TodoStatuses: todoStatuses})
+	return buf.Bytes()
+}
+
+// setSubtreeIDs stores the IDs of the original items as a property of their clones
+func setSubtreeIDs(original, clone *model.Item) {
+	clone.Metadata.Attributes[subtreeIDProperty] = original.ID
+	for i, child := range original.Children {
+		setSubtreeIDs(child, clone.Children[i])
+	}
+}
+
+// escapeHeadings marks the first line of text that the Org importer would read
+// as a todo keyword or tags with a backslash at the start or end, so the text
+// comes back unchanged. Text that starts or ends with a backslash is escaped
+// too, so unescapeHeadings can always remove one.
+func escapeHeadings(item *model.Item, todoStatuses []string) {
+	first, rest, multiline := strings.Cut(item.Text, "\n")
+	if word, _, _ := strings.Cut(first, " "); strings.HasPrefix(first, `\`) || isOrgKeyword(word, todoStatuses) {
+		first = `\` + first
+	}
+	if strings.HasSuffix(first, `\`) || orgTagsSuffix.MatchString(first) {
+		first += `\`
+	}
+	item.Text = first
+	if multiline {
+		item.Text += "\n" + rest
+	}
+	for _, child := range item.Children {
+		escapeHeadings(child, todoStatuses)
+	}
+}
+
+// isOrgKeyword reports whether word would be read as a todo keyword, either a
+// default Org keyword or one of the statuses in the #+TODO line
+func isOrgKeyword(word string, todoStatuses []string) bool {
+	if word == "TODO" || word == "DONE" {
+		return true
+	}
+	return word != "" && word == strings.ToUpper(word) && slices.Contains(todoStatuses, strings.ToLower(word))
+}
+
+// unescapeHeadings removes the backslashes added by escapeHeadings
+func unescapeHeadings(item *model.Item) {
+	first, rest, multiline := strings.Cut(item.Text, "\n")
+	first = strings.TrimPrefix(first, `\`)
+	first = strings.TrimSuffix(first, `\`)
+	item.Text = first
+	if multiline {
+		item.Text += "\n" + rest
+	}
+	for _, child := range item.Children {
+		unescapeHeadings(child)
+	}
+}
+
+// parseEditedSubtree reads the edited Org outline and validates the attributes
+// of every item
+func parseEditedSubtree(content []byte, validateAttrs ValidateAttributesFunc) ([]*model.Item, error) {
+	parser := &import_parser.OrgParser{}
+	items, err := parser.Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse edited content: %w (keeping original)", err)
+	}
+	for _, item := range items {
+		unescapeHeadings(item)
+	}
+
+	if validateAttrs != nil {
+		var validate func(items []*model.Item) string
+		validate = func(items []*model.Item) string {
+			for _, item := range items {
+				attributes := maps.Clone(item.Metadata.Attributes)
+				delete(attributes, subtreeIDProperty)
+				if errMsg := validateAttrs(attributes); errMsg != "" {
+					return errMsg
+				}
+				if errMsg := validate(item.Children); errMsg != "" {
+					return errMsg
+				}
+			}
+			return ""
+		}
+		if errMsg := validate(items); errMsg != "" {
+			return nil, fmt.Errorf("attribute validation failed: %s (keeping original)", errMsg)
+		}
+	}
+
+	return items, nil
+}
+
+// MergeEditedSubtree merges the items from EditSubtreeInExternalEditor into the
+// subtree of original and returns the items that take the place of original.
+//
+// Edited items with the ID of an original item update that item, so it keeps
+// its ID, links and virtual children. Other edited items are new, and original
+// items that are no longer there are dropped.
+//
+// Only what was changed in the editor is copied. The edited items are compared
+// with the Org outline the editor was opened with, so text, tags and attributes
+// that Org can't write exactly stay as they were unless the user changed them.
+func MergeEditedSubtree(original *model.Item, edited []*model.Item, todoStatuses []string) []*model.Item {
+	originals := make(map[string]*model.Item)
+	var collect func(item *model.Item)
+	collect = func(item *model.Item) {
+		originals[item.ID] = item
+		for _, child := range item.Children {
+			collect(child)
+		}
+	}
+	collect(original)
+
+	// The items as they were written to the editor, by ID
+	unedited := make(map[string]*model.Item)
+	serialized, _ := parseEditedSubtree(serializeSubtree(original, todoStatuses), nil)
+	var collectUnedited func(item *model.Item)
+	collectUnedited = func(item *model.Item) {
+		unedited[item.Metadata.Attributes[subtreeIDProperty]] = item
+		delete(item.Metadata.Attributes, subtreeIDProperty)
+		for _, child := range item.Children {
+			collectUnedited(child)
+		}
+	}
+	for _, item := range serialized {
+		collectUnedited(item)
+	}
+
+	var merge func(item, parent *model.Item) *model.Item
+	merge = func(item, parent *model.Item) *model.Item {
+		id := item.Metadata.Attributes[subtreeIDProperty]
+		delete(item.Metadata.Attributes, subtreeIDProperty)
+
+		result := item
+		if existing, ok := originals[id]; ok {
+			// A copied heading only keeps the ID once
+			delete(originals, id)
+			mergeEditedItem(existing, item, unedited[id])
+			result = existing
+		}
+
+		children := make([]*model.Item, 0, len(item.Children))
+		for _, child := range item.Children {
+			children = append(children, merge(child, result))
+		}
+		result.Children = children
+		result.Parent = parent
+		return result
+	}
+
+	merged := make([]*model.Item, 0, len(edited))
+	for _, item := range edited {
+		merged = append(merged, merge(item, original.Parent))
+	}
+	return merged
+}
+
+// mergeEditedItem copies the text, tags and attributes that differ between
+// edited and unedited, the item as it was written to the editor, to item
+func mergeEditedItem(item, edited, unedited *model.Item) {
+	if unedited == nil {
+		unedited = model.NewItem("")
+	}
+	if item.Metadata == nil {
+		item.Metadata = &model.Metadata{Created: time.Now()}
+	}
+	changed := false
+
+	if edited.Text != unedited.Text {
+		item.Text = edited.Text
+		changed = true
+	}
+
+	if !slices.Equal(edited.GetTags(), unedited.GetTags()) {
+		// Keep the tags that Org can't write, they were not in the editor
+		tags := slices.Clone(edited.GetTags())
+		for _, tag := range item.GetTags() {
+			if !export.IsValidOrgTag(tag) {
+				tags = append(tags, tag)
+			}
+		}
+		slices.Sort(tags)
+		item.Metadata.Tags = slices.Compact(tags)
+		changed = true
+	}
+
+	for key := range joinKeys(edited.Metadata.Attributes, unedited.Metadata.Attributes) {
+		value, ok := edited.Metadata.Attributes[key]
+		oldValue, oldOk := unedited.Metadata.Attributes[key]
+		if ok == oldOk && value == oldValue {
+			continue
+		}
+		if item.Metadata.Attributes == nil {
+			item.Metadata.Attributes = make(map[string]string)
+		}
+		if ok {
+			item.Metadata.Attributes[key] = value
+		} else {
+			delete(item.Metadata.Attributes, key)
+		}
+		changed = true
+	}
+
+	if changed {
+		item.Metadata.Modified = time.Now()
+	}
+}
+
+// joinKeys returns the keys of a and b
+func joinKeys(a, b map[string]string) map[string]struct{} {
+	keys := make(map[string]struct{}, len(a)+len(b))
+	for key := range maps.Keys(a) {
+		keys[key] = struct{}{}
+	}
+	for key := range maps.Keys(b) {
+		keys[key] = struct{}{}
+	}
+	return keys
+}
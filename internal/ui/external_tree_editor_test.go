@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+func TestEditSubtreeRoundTrip(t *testing.T) {
+	parent := model.NewItem("Parent")
+	root := model.NewItem("Project")
+	root.AddTag("work")
+	root.AddTag("needs-review")
+	root.Metadata.Attributes["owner"] = "me"
+	task := model.NewItem("Task A\nwith notes")
+	task.Metadata.Attributes["type"] = "todo"
+	task.Metadata.Attributes["status"] = "doing"
+	sub := model.NewItem("Sub")
+	task.AddChild(sub)
+	root.AddChild(task)
+	taskB := model.NewItem("Task B")
+	root.AddChild(taskB)
+	parent.AddChild(root)
+
+	content := string(serializeSubtree(root, []string{"todo", "doing", "done"}))
+	if !strings.Contains(content, ":ID: "+task.ID+"\n") || !strings.Contains(content, "** DOING Task A") {
+		t.Fatalf("Expected headings with IDs, got:\n%s", content)
+	}
+
+	// Rename Task B, move Sub up a level, mark Task A done and add a new item
+	edited := fmt.Sprintf(`#+TODO: TODO DOING | DONE
+
+* Project :work:
+:PROPERTIES:
+:ID: %s
+:owner: me
+:END:
+** DONE Task A
+:PROPERTIES:
+:ID: %s
+:type: todo
+:END:
+with notes
+** Sub
+:PROPERTIES:
+:ID: %s
+:END:
+** Task B renamed
+:PROPERTIES:
+:ID: %s
+:END:
+** New item
+`, root.ID, task.ID, sub.ID, taskB.ID)
+
+	items, err := parseEditedSubtree([]byte(edited), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	merged := MergeEditedSubtree(root, items, []string{"todo", "doing", "done"})
+
+	if len(merged) != 1 || merged[0] != root || root.Parent != parent {
+		t.Fatal("Expected the original root item to be kept")
+	}
+	if len(root.Children) != 4 {
+		t.Fatalf("Expected 4 children, got %d", len(root.Children))
+	}
+	if root.Children[0] != task || task.Text != "Task A\nwith notes" || task.Metadata.Attributes["status"] != "done" {
+		t.Errorf("Expected Task A to be done, got %q %v", task.Text, task.Metadata.Attributes)
+	}
+	if len(task.Children) != 0 || root.Children[1] != sub || sub.Parent != root {
+		t.Error("Expected Sub to move up and keep its ID")
+	}
+	if root.Children[2] != taskB || taskB.Text != "Task B renamed" {
+		t.Errorf("Expected Task B to be renamed, got %q", taskB.Text)
+	}
+	if newItem := root.Children[3]; newItem.Text != "New item" || newItem.Parent != root {
+		t.Errorf("Expected a new item, got %q", newItem.Text)
+	}
+	if _, ok := taskB.Metadata.Attributes[subtreeIDProperty]; ok {
+		t.Error("Expected the ID property to be removed")
+	}
+	if got := strings.Join(root.GetTags(), ","); got != "needs-review,work" {
+		t.Errorf("Expected tags Org can't write to be kept, got %q", got)
+	}
+	if root.Metadata.Attributes["owner"] != "me" {
+		t.Error("Expected the owner attribute to be kept")
+	}
+}
+
+func TestEditSubtreeKeepsUneditedItems(t *testing.T) {
+	statuses := []string{"todo", "doing", "done"}
+	root := model.NewItem("notes :work:")
+	call := model.NewItem("TODO call mom")
+	odd := model.NewItem(`\ends with a backslash\`)
+	odd.AddTag("two words")
+	plain := model.NewItem("Plain")
+	plain.Metadata.Attributes = nil
+	root.AddChild(call)
+	root.AddChild(odd)
+	root.AddChild(plain)
+
+	content := string(serializeSubtree(root, statuses))
+	items, err := parseEditedSubtree([]byte(strings.Replace(content, "* Plain", "* Plain edited", 1)), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	MergeEditedSubtree(root, items, statuses)
+
+	if root.Text != "notes :work:" || len(root.GetTags()) != 0 {
+		t.Errorf("Expected the text with a colon suffix to stay text, got %q %v", root.Text, root.GetTags())
+	}
+	if call.Text != "TODO call mom" || call.Metadata.Attributes["type"] != "" {
+		t.Errorf("Expected the keyword to stay text, got %q %v", call.Text, call.Metadata.Attributes)
+	}
+	if odd.Text != `\ends with a backslash\` || len(odd.GetTags()) != 1 {
+		t.Errorf("Expected the backslashes and the tag to be kept, got %q %v", odd.Text, odd.GetTags())
+	}
+	if plain.Text != "Plain edited" {
+		t.Errorf("Expected the edited item to change, got %q", plain.Text)
+	}
+
+	// Changing an escaped heading keeps the escape working
+	items, _ = parseEditedSubtree([]byte(strings.Replace(content, "call mom", "call dad", 1)), nil)
+	MergeEditedSubtree(root, items, statuses)
+	if call.Text != "TODO call dad" {
+		t.Errorf("Expected the edited text with the keyword, got %q", call.Text)
+	}
+}
+
+func TestEditSubtreeValidation(t *testing.T) {
+	validate := func(attributes map[string]string) string {
+		if attributes["priority"] == "urgent" {
+			return "invalid priority"
+		}
+		return ""
+	}
+
+	if _, err := parseEditedSubtree([]byte("* Item\n:PROPERTIES:\n:ID: x\n:priority: high\n:END:\n"), validate); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	_, err := parseEditedSubtree([]byte("* Item\n** Child\n:PROPERTIES:\n:priority: urgent\n:END:\n"), validate)
+	if err == nil || !strings.Contains(err.Error(), "invalid priority") {
+		t.Errorf("Expected a validation error for the child, got %v", err)
+	}
+}
@@ -87,6 +87,7 @@ func (h *HelpScreen) GetKeybindings() []string {
 	result = append(result, "  Ctrl+U      - Page up (scroll viewport)")
 	result = append(result, "  Ctrl+D      - Page down (scroll viewport)")
 	result = append(result, "  Ctrl+S      - Save")
+	result = append(result, "  Ctrl+R      - Redo last undone change")
 	result = append(result, "  Escape      - Exit edit mode")
 	result = append(result, "  Enter       - Confirm/Exit edit mode")
 	result = append(result, "  Arrow Keys  - Navigate (alternative to hjkl)")
@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"strings"
+
 	"github.com/pstuifzand/tui-outliner/internal/history"
 )
 
@@ -138,6 +140,18 @@ func (h *History) Next() (string, bool) {
 	return h.entries[h.currentIndex], true
 }
 
+// SearchBackward returns the newest entry before index before that contains
+// term, ignoring case, and its index. Pass Len() to search all entries.
+func (h *History) SearchBackward(term string, before int) (string, int, bool) {
+	term = strings.ToLower(term)
+	for i := min(before, len(h.entries)) - 1; i >= 0; i-- {
+		if strings.Contains(strings.ToLower(h.entries[i]), term) {
+			return h.entries[i], i, true
+		}
+	}
+	return "", -1, false
+}
+
 // Reset resets the navigation state
 // Call this when entering input mode or exiting history navigation
 func (h *History) Reset() {
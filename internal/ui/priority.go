@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/pstuifzand/tui-outliner/internal/config"
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+// DefaultPriorityColors is used when the prioritycolors setting is not set
+const DefaultPriorityColors = "high|1=red,medium|2=yellow,low|3=blue"
+
+// PriorityLevel is a level of the priority attribute, most important first
+type PriorityLevel struct {
+	Values []string // Attribute values of this level, like "high" and "1"
+	Color  string   // Theme color name, see PriorityColorNames
+}
+
+// PriorityColorNames are the theme colors a priority level can use
+var PriorityColorNames = []string{"red", "orange", "yellow", "green", "blue", "purple", "gray"}
+
+// ParsePriorityColors parses a prioritycolors value like "high|1=red,low|3=blue".
+// Levels are separated by commas and listed from most to least important, the
+// values of a level are separated by |.
+func ParsePriorityColors(spec string) ([]PriorityLevel, error) {
+	var levels []PriorityLevel
+	for entry := range strings.SplitSeq(spec, ",") {
+		values, color, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		color = strings.ToLower(strings.TrimSpace(color))
+		if !ok || values == "" {
+			return nil, fmt.Errorf("invalid priority level '%s' (use value|value=color)", entry)
+		}
+		if !slices.Contains(PriorityColorNames, color) {
+			return nil, fmt.Errorf("unknown color '%s' (use %s)", color, strings.Join(PriorityColorNames, ", "))
+		}
+
+		level := PriorityLevel{Color: color}
+		for value := range strings.SplitSeq(values, "|") {
+			if value = strings.ToLower(strings.TrimSpace(value)); value != "" {
+				level.Values = append(level.Values, value)
+			}
+		}
+		levels = append(levels, level)
+	}
+	return levels, nil
+}
+
+// PriorityLevelsFromConfig returns the levels of the prioritycolors setting,
+// or the default levels when it is not set or invalid
+func PriorityLevelsFromConfig(cfg *config.Config) []PriorityLevel {
+	if cfg != nil {
+		if spec := cfg.Get("prioritycolors"); spec != "" {
+			if levels, err := ParsePriorityColors(spec); err == nil {
+				return levels
+			}
+		}
+	}
+	levels, _ := ParsePriorityColors(DefaultPriorityColors)
+	return levels
+}
+
+// PriorityRank returns the index of the level of the item's priority
+// attribute. Returns false for items without a known priority.
+func PriorityRank(levels []PriorityLevel, item *model.Item) (int, bool) {
+	if item.Metadata == nil {
+		return 0, false
+	}
+	value := strings.ToLower(strings.TrimSpace(item.Metadata.Attributes["priority"]))
+	if value == "" {
+		return 0, false
+	}
+	for rank, level := range levels {
+		for _, v := range level.Values {
+			if v == value {
+				return rank, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// PriorityColor returns the theme color for a priority color name
+func (s *Screen) PriorityColor(name string) tcell.Color {
+	colors := s.Theme.Colors
+	switch name {
+	case "red":
+		return colors.ColorRed
+	case "orange":
+		return colors.ColorOrange
+	case "yellow":
+		return colors.ColorYellow
+	case "green":
+		return colors.ColorGreen
+	case "blue":
+		return colors.ColorBlue
+	case "purple":
+		return colors.ColorPurple
+	}
+	return colors.ColorGray
+}
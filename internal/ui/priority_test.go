@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+func TestParsePriorityColors(t *testing.T) {
+	levels, err := ParsePriorityColors(DefaultPriorityColors)
+	if err != nil {
+		t.Fatalf("Default prioritycolors invalid: %v", err)
+	}
+	if len(levels) != 3 || levels[0].Color != "red" || fmt.Sprint(levels[1].Values) != "[medium 2]" {
+		t.Errorf("Unexpected levels %+v", levels)
+	}
+
+	for _, spec := range []string{"high", "high=pink", "=red", "high=red,"} {
+		if _, err := ParsePriorityColors(spec); err == nil {
+			t.Errorf("Expected %q to be invalid", spec)
+		}
+	}
+}
+
+func TestSortChildrenByPriority(t *testing.T) {
+	var items []*model.Item
+	for _, priority := range []string{"low", "", "High", "2", "someday", "1"} {
+		item := model.NewItem("p:" + priority)
+		if priority != "" {
+			item.Metadata.Attributes["priority"] = priority
+		}
+		items = append(items, item)
+	}
+	tv := NewTreeView(items)
+	levels := PriorityLevelsFromConfig(nil)
+
+	texts := func() string {
+		var got []string
+		for _, item := range items {
+			got = append(got, item.Text)
+		}
+		return fmt.Sprint(got)
+	}
+
+	// Most important first, unknown and missing priorities sink
+	tv.SortChildrenByPriority(nil, levels, false)
+	if got := texts(); got != "[p:High p:1 p:2 p:low p: p:someday]" {
+		t.Errorf("Unexpected ascending order %s", got)
+	}
+	tv.SortChildrenByPriority(nil, levels, true)
+	if got := texts(); got != "[p:low p:2 p:High p:1 p: p:someday]" {
+		t.Errorf("Unexpected descending order %s", got)
+	}
+}
@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pstuifzand/tui-outliner/internal/config"
+)
+
+// progressMiddleColors are the default colors of the statuses between the first
+// and the last, in order
+var progressMiddleColors = []string{"orange", "yellow", "blue", "purple", "red"}
+
+// DefaultProgressChar is the glyph of a progress bar block when the
+// progresschars setting is not set
+const DefaultProgressChar = '■'
+
+// ProgressBarTheme holds the glyph and color of the progress bar blocks, one
+// per todo status in the order of todostatuses
+type ProgressBarTheme struct {
+	Chars  []rune
+	Colors []string // Theme color names, see PriorityColorNames
+}
+
+// ParseProgressChars parses a progresschars value like "○,◐,●" with one
+// single-width glyph per todo status
+func ParseProgressChars(spec string, statuses []string) ([]rune, error) {
+	parts := strings.Split(spec, ",")
+	if len(parts) != len(statuses) {
+		return nil, fmt.Errorf("expected %d characters, one per todo status (%s)", len(statuses), strings.Join(statuses, ","))
+	}
+	chars := make([]rune, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		runes := []rune(part)
+		if len(runes) != 1 || StringWidth(part) != 1 {
+			return nil, fmt.Errorf("'%s' is not a single character", part)
+		}
+		chars = append(chars, runes[0])
+	}
+	return chars, nil
+}
+
+// ParseProgressColors parses a progresscolors value like "gray,orange,green"
+// with one color name per todo status
+func ParseProgressColors(spec string, statuses []string) ([]string, error) {
+	parts := strings.Split(spec, ",")
+	if len(parts) != len(statuses) {
+		return nil, fmt.Errorf("expected %d colors, one per todo status (%s)", len(statuses), strings.Join(statuses, ","))
+	}
+	colors := make([]string, 0, len(parts))
+	for _, part := range parts {
+		color := strings.ToLower(strings.TrimSpace(part))
+		if !slices.Contains(PriorityColorNames, color) {
+			return nil, fmt.Errorf("unknown color '%s' (use %s)", color, strings.Join(PriorityColorNames, ", "))
+		}
+		colors = append(colors, color)
+	}
+	return colors, nil
+}
+
+// ProgressBarThemeFromConfig returns the glyphs and colors of the progresschars
+// and progresscolors settings. Settings that are not set, invalid or do not
+// match the number of statuses fall back to the defaults: ■ blocks in gray for
+// the first status, green for the last and orange, yellow, blue, purple and red
+// for the statuses in between.
+func ProgressBarThemeFromConfig(cfg *config.Config, statuses []string) ProgressBarTheme {
+	var barTheme ProgressBarTheme
+	if cfg != nil {
+		if spec := cfg.Get("progresschars"); spec != "" {
+			barTheme.Chars, _ = ParseProgressChars(spec, statuses)
+		}
+		if spec := cfg.Get("progresscolors"); spec != "" {
+			barTheme.Colors, _ = ParseProgressColors(spec, statuses)
+		}
+	}
+
+	if barTheme.Chars == nil {
+		barTheme.Chars = make([]rune, len(statuses))
+		for i := range barTheme.Chars {
+			barTheme.Chars[i] = DefaultProgressChar
+		}
+	}
+	if barTheme.Colors == nil {
+		barTheme.Colors = make([]string, len(statuses))
+		for i := range barTheme.Colors {
+			switch i {
+			case len(statuses) - 1:
+				barTheme.Colors[i] = "green"
+			case 0:
+				barTheme.Colors[i] = "gray"
+			default:
+				barTheme.Colors[i] = progressMiddleColors[(i-1)%len(progressMiddleColors)]
+			}
+		}
+	}
+	return barTheme
+}
+
+// Block returns the glyph and color name for a todo status. Unknown statuses
+// count as not started, like the first status.
+func (t ProgressBarTheme) Block(status string, statuses []string) (rune, string) {
+	if len(statuses) == 0 {
+		return DefaultProgressChar, "gray"
+	}
+	i := TodoStatusIndex(status, statuses)
+	return t.Chars[i], t.Colors[i]
+}
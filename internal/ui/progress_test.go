@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/pstuifzand/tui-outliner/internal/config"
+)
+
+func TestProgressBarTheme(t *testing.T) {
+	statuses := []string{"todo", "doing", "done"}
+
+	barTheme := ProgressBarThemeFromConfig(nil, statuses)
+	for _, tc := range []struct {
+		status string
+		color  string
+	}{{"todo", "gray"}, {"doing", "orange"}, {"done", "green"}, {"", "gray"}} {
+		char, color := barTheme.Block(tc.status, statuses)
+		if char != DefaultProgressChar || color != tc.color {
+			t.Errorf("Block(%q) = %q, %q, want %q, %q", tc.status, char, color, DefaultProgressChar, tc.color)
+		}
+	}
+
+	cfg := &config.Config{}
+	cfg.Set("progresschars", "○,◐,●")
+	cfg.Set("progresscolors", "blue, Yellow ,purple")
+	barTheme = ProgressBarThemeFromConfig(cfg, statuses)
+	if char, color := barTheme.Block("done", statuses); char != '●' || color != "purple" {
+		t.Errorf("Unexpected done block %q, %q", char, color)
+	}
+	if char, color := barTheme.Block("doing", statuses); char != '◐' || color != "yellow" {
+		t.Errorf("Unexpected doing block %q, %q", char, color)
+	}
+
+	// With two statuses an unknown status is not done
+	twoStatuses := []string{"todo", "done"}
+	barTheme = ProgressBarThemeFromConfig(nil, twoStatuses)
+	if char, color := barTheme.Block("waiting", twoStatuses); char != DefaultProgressChar || color != "gray" {
+		t.Errorf("Expected an unknown status to look like todo, got %q, %q", char, color)
+	}
+
+	// Settings that do not match the statuses fall back to the defaults
+	fourStatuses := []string{"todo", "doing", "review", "done"}
+	barTheme = ProgressBarThemeFromConfig(cfg, fourStatuses)
+	if char, color := barTheme.Block("doing", fourStatuses); char != DefaultProgressChar || color != "orange" {
+		t.Errorf("Expected default doing block, got %q, %q", char, color)
+	}
+	if char, color := barTheme.Block("review", fourStatuses); char != DefaultProgressChar || color != "yellow" {
+		t.Errorf("Expected default review block, got %q, %q", char, color)
+	}
+}
+
+func TestParseProgressSettings(t *testing.T) {
+	statuses := []string{"todo", "doing", "done"}
+	for _, spec := range []string{"a,b", "a,b,c,d", "a,bb,c", "a,,c", "a,b,漢"} {
+		if _, err := ParseProgressChars(spec, statuses); err == nil {
+			t.Errorf("Expected progresschars %q to be invalid", spec)
+		}
+	}
+	for _, spec := range []string{"gray,green", "gray,pink,green", "gray,,green"} {
+		if _, err := ParseProgressColors(spec, statuses); err == nil {
+			t.Errorf("Expected progresscolors %q to be invalid", spec)
+		}
+	}
+}
@@ -52,6 +52,13 @@ func NewScreenWithTheme(t *theme.Theme) (*Screen, error) {
 	}, nil
 }
 
+// SetTheme switches to theme t and repaints the whole terminal. The style
+// methods read the theme on every call, so the next render uses its colors.
+func (s *Screen) SetTheme(t *theme.Theme) {
+	s.Theme = t
+	s.tcellScreen.Sync()
+}
+
 // Close closes the screen
 func (s *Screen) Close() error {
 	s.tcellScreen.Fini()
@@ -3,6 +3,8 @@ package ui
 import (
 	"fmt"
 	"log"
+	"slices"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/pstuifzand/tui-outliner/internal/history"
@@ -10,6 +12,11 @@ import (
 	"github.com/pstuifzand/tui-outliner/internal/search"
 )
 
+// defaultScanBudget is how long a query may match items before the search
+// yields, so typing stays responsive on large outlines. Continue matches the
+// remaining items.
+const defaultScanBudget = 10 * time.Millisecond
+
 // Search manages search and filter functionality
 type Search struct {
 	query           string
@@ -22,17 +29,27 @@ type Search struct {
 	filterExpr      search.FilterExpr // Parsed filter expression
 	parseError      string            // Error from parsing the query
 	history         *History          // Search history manager
+	scanPos         int               // Index in allItems of the next item to match
+	scanBudget      time.Duration     // Time to match items before yielding
+
+	// Reverse search through the history with Ctrl-R
+	reverseSearch bool
+	reverseTerm   string
+	reverseIndex  int    // History index of the current match, -1 for none
+	reverseFailed bool   // No entry matches reverseTerm
+	reverseQuery  string // Query before the reverse search, restored on Escape
 }
 
 // NewSearch creates a new Search without history persistence
 func NewSearch(items []*model.Item) *Search {
 	return &Search{
-		query:     "",
-		results:   items,
-		cursorPos: 0,
-		active:    false,
-		allItems:  items,
-		history:   NewHistory(50),
+		query:      "",
+		results:    items,
+		cursorPos:  0,
+		active:     false,
+		allItems:   items,
+		history:    NewHistory(50),
+		scanBudget: defaultScanBudget,
 	}
 }
 
@@ -45,12 +62,13 @@ func NewSearchWithHistory(items []*model.Item, manager *history.Manager) (*Searc
 	}
 
 	return &Search{
-		query:     "",
-		results:   items,
-		cursorPos: 0,
-		active:    false,
-		allItems:  items,
-		history:   h,
+		query:      "",
+		results:    items,
+		cursorPos:  0,
+		active:     false,
+		allItems:   items,
+		history:    h,
+		scanBudget: defaultScanBudget,
 	}, nil
 }
 
@@ -61,15 +79,22 @@ func (s *Search) Start() {
 	s.cursorPos = 0
 	s.matchIndices = nil
 	s.currentMatchIdx = 0
+	s.reverseSearch = false
 	s.history.Reset()
 }
 
 // Stop stops search mode
 func (s *Search) Stop() {
 	s.active = false
+	s.reverseSearch = false
 	s.history.Reset()
 }
 
+// IsReverseSearching returns whether a Ctrl-R search through the history is active
+func (s *Search) IsReverseSearching() bool {
+	return s.reverseSearch
+}
+
 // IsActive returns whether search mode is active
 func (s *Search) IsActive() bool {
 	return s.active
@@ -88,13 +113,24 @@ func (s *Search) HandleKey(ev *tcell.EventKey) bool {
 		return false
 	}
 
+	if s.reverseSearch && s.handleReverseSearchKey(ev) {
+		return false
+	}
+
 	switch ev.Key() {
+	case tcell.KeyCtrlR:
+		s.reverseSearch = true
+		s.reverseTerm = ""
+		s.reverseIndex = -1
+		s.reverseFailed = false
+		s.reverseQuery = s.query
+		return false
 	case tcell.KeyEscape:
 		s.Stop()
 		return false
 	case tcell.KeyEnter:
-		// Enter key updates results and adds to history, then exits search mode
-		s.updateResults()
+		// Enter key completes the results and adds to history, then exits search mode
+		s.finishScan()
 		s.history.Add(s.query)
 		s.Stop()
 		// If there are matches, navigate to first one
@@ -182,12 +218,74 @@ func (s *Search) HandleKey(ev *tcell.EventKey) bool {
 	}
 }
 
-// updateResults filters results based on query and tracks match indices
+// handleReverseSearchKey handles a key during a reverse search. Ctrl-R finds the
+// next older match, typing and Backspace change the search term and Escape
+// restores the query. Other keys accept the match and return false, so they are
+// handled like in the search bar.
+func (s *Search) handleReverseSearchKey(ev *tcell.EventKey) bool {
+	switch ev.Key() {
+	case tcell.KeyCtrlR:
+		if s.reverseIndex >= 0 {
+			s.findReverseMatch(s.reverseIndex)
+		} else {
+			s.findReverseMatch(s.history.Len())
+		}
+		return true
+	case tcell.KeyEscape, tcell.KeyCtrlG:
+		s.reverseSearch = false
+		s.query = s.reverseQuery
+		s.cursorPos = len(s.query)
+		s.updateResults()
+		return true
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		runes := []rune(s.reverseTerm)
+		if len(runes) > 0 {
+			s.reverseTerm = string(runes[:len(runes)-1])
+			s.findReverseMatch(s.history.Len())
+		}
+		return true
+	case tcell.KeyRune:
+		s.reverseTerm += string(ev.Rune())
+		// Keep the current match while it still contains the term
+		if s.reverseIndex >= 0 {
+			s.findReverseMatch(s.reverseIndex + 1)
+		} else {
+			s.findReverseMatch(s.history.Len())
+		}
+		return true
+	default:
+		s.reverseSearch = false
+		return false
+	}
+}
+
+// findReverseMatch shows the newest history entry before index before that
+// contains the reverse search term
+func (s *Search) findReverseMatch(before int) {
+	if s.reverseTerm == "" {
+		s.reverseFailed = false
+		return
+	}
+	match, index, ok := s.history.SearchBackward(s.reverseTerm, before)
+	s.reverseFailed = !ok
+	if !ok {
+		return
+	}
+	s.reverseIndex = index
+	s.query = match
+	s.cursorPos = len(s.query)
+	s.updateResults()
+}
+
+// updateResults filters results based on query and tracks match indices.
+// Items that can't be matched within the scan budget are matched by Continue.
 func (s *Search) updateResults() {
 	s.matchIndices = nil
 	s.currentMatchIdx = 0
 	s.parseError = ""
 	s.filterExpr = nil
+	s.results = nil
+	s.scanPos = len(s.allItems)
 
 	if s.query == "" {
 		s.results = s.allItems
@@ -210,18 +308,68 @@ func (s *Search) updateResults() {
 		return
 	}
 
-	// Apply the filter to all items
-	var filtered []*model.Item
-	for idx, item := range s.allItems {
-		debugInfo := search.DebugMatch(item, s.filterExpr)
-		log.Println(search.ExpressionString(s.filterExpr))
-		log.Println(search.FormatDebugInfo(debugInfo))
+	log.Println(search.ExpressionString(s.filterExpr))
+
+	// Apply the filter to the items, starting with the first
+	s.scanPos = 0
+	s.scan(s.scanBudget)
+}
+
+// scan matches items from scanPos until all items are matched or budget has
+// passed. A budget of 0 matches all remaining items.
+func (s *Search) scan(budget time.Duration) {
+	defer s.rankResults()
+	start := time.Now()
+	for s.scanPos < len(s.allItems) {
+		item := s.allItems[s.scanPos]
 		if s.filterExpr.Matches(item) {
-			filtered = append(filtered, item)
-			s.matchIndices = append(s.matchIndices, idx)
+			s.results = append(s.results, item)
+			s.matchIndices = append(s.matchIndices, s.scanPos)
 		}
+		s.scanPos++
+
+		// Checking the clock for every item would slow down the scan
+		if budget > 0 && s.scanPos%256 == 0 && time.Since(start) > budget {
+			return
+		}
+	}
+}
+
+// rankResults puts the best matches of a single fuzzy term first, so n and
+// Enter go to the closest match
+func (s *Search) rankResults() {
+	if !search.RankByFuzzyScore(s.results, s.filterExpr) {
+		return
+	}
+	indices := make(map[*model.Item]int, len(s.matchIndices))
+	for _, idx := range s.matchIndices {
+		indices[s.allItems[idx]] = idx
+	}
+	for i, item := range s.results {
+		s.matchIndices[i] = indices[item]
+	}
+}
+
+// finishScan matches all items that are left
+func (s *Search) finishScan() {
+	if s.IsScanning() {
+		s.scan(0)
 	}
-	s.results = filtered
+}
+
+// IsScanning returns true while not all items are matched against the query
+func (s *Search) IsScanning() bool {
+	return s.filterExpr != nil && s.scanPos < len(s.allItems)
+}
+
+// Continue matches the next batch of items of an unfinished query.
+// Returns true when it matched items.
+func (s *Search) Continue() bool {
+	if !s.IsScanning() {
+		return false
+	}
+	s.scan(s.scanBudget)
+	return true
 }
 
 // GetResults returns the current search results
@@ -229,12 +377,23 @@ func (s *Search) GetResults() []*model.Item {
 	return s.results
 }
 
+// Matches returns the items matching the query, matching the items that are
+// left first. Returns nil for an empty or invalid query.
+func (s *Search) Matches() []*model.Item {
+	if s.query == "" || s.parseError != "" {
+		return nil
+	}
+	s.finishScan()
+	return slices.Clone(s.results)
+}
+
 // GetQuery returns the current search query
 func (s *Search) GetQuery() string {
 	return s.query
 }
 
-// SetAllItems sets the items to search in
+// SetAllItems sets the items to search in and restarts the query, call it
+// when the outline changes so the results don't point to stale items
 func (s *Search) SetAllItems(items []*model.Item) {
 	s.allItems = items
 	s.updateResults()
@@ -318,12 +477,18 @@ func (s *Search) Render(screen *Screen, y int) {
 	cursorStyle := screen.SearchCursorStyle()
 	resultStyle := screen.SearchResultCountStyle()
 
-	const Label = "Search: "
+	label := "Search: "
+	if s.reverseSearch {
+		label = "(reverse-i-search)`" + s.reverseTerm + "': "
+		if s.reverseFailed {
+			label = "(failing " + label[1:]
+		}
+	}
 	// Draw label
-	screen.DrawString(0, y, Label, labelStyle)
+	screen.DrawString(0, y, label, labelStyle)
 
 	// Draw query
-	x := len(Label)
+	x := StringWidth(label)
 	maxWidth := screen.GetWidth() - x
 	displayQuery := s.query
 	if len(displayQuery) > maxWidth {
@@ -355,13 +520,19 @@ func (s *Search) Render(screen *Screen, y int) {
 	if s.parseError != "" {
 		// Display parse error
 		resultText = " (error: " + s.parseError + ")"
+	} else if len(s.results) == 0 && s.IsScanning() {
+		resultText = " (searching...)"
 	} else if len(s.results) == 0 {
 		resultText = " (no matches)"
 	} else {
 		currentNum := s.GetCurrentMatchNumber()
 		totalCount := s.GetMatchCount()
-		// Format: (1 of 5 matches)
-		resultText = " (" + fmt.Sprintf("%d", currentNum) + " of " + fmt.Sprintf("%d", totalCount) + " matches)"
+		// Format: (1 of 5 matches), or (1 of 5+ matches) while items are left to match
+		more := ""
+		if s.IsScanning() {
+			more = "+"
+		}
+		resultText = " (" + fmt.Sprintf("%d", currentNum) + " of " + fmt.Sprintf("%d", totalCount) + more + " matches)"
 	}
 	// Truncate error message if it's too long
 	if len(resultText) > screen.GetWidth()/2 {
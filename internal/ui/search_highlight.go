@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/pstuifzand/tui-outliner/internal/search"
+)
+
+// searchHighlightMask returns for each rune of the display text whether it is
+// part of a match of the search query. Text terms are matched as
+// case-insensitive substrings and ~fuzzy terms character by character in
+// order. Filters like @status=todo and negated terms are not highlighted.
+//
+// Links are already replaced by their display text, so positions in the mask
+// are the rune positions of LinkRange and terms inside links are found too.
+func searchHighlightMask(text string, query string) []bool {
+	runes := []rune(strings.ToLower(text))
+	if len([]rune(text)) != len(runes) {
+		// Lowercasing changed the number of runes, fold rune by rune instead
+		runes = []rune(text)
+		for i, r := range runes {
+			runes[i] = unicode.ToLower(r)
+		}
+	}
+
+	mask := make([]bool, len(runes))
+	negated := false
+	for _, token := range search.NewTokenizer(query).AllTokens() {
+		switch {
+		case token.Type == search.TokenNot:
+			negated = true
+			continue
+		case negated:
+		case token.Type == search.TokenText:
+			markSubstringMatches(mask, runes, []rune(strings.ToLower(token.Value)))
+		case token.Type == search.TokenFilter && strings.HasPrefix(token.Value, "~"):
+			markFuzzyMatches(mask, runes, []rune(strings.ToLower(token.Value[1:])))
+		}
+		negated = false
+	}
+	return mask
+}
+
+// markSubstringMatches marks all non-overlapping occurrences of term in runes
+func markSubstringMatches(mask []bool, runes, term []rune) {
+	if len(term) == 0 {
+		return
+	}
+	for i := 0; i+len(term) <= len(runes); {
+		if !hasRunePrefix(runes[i:], term) {
+			i++
+			continue
+		}
+		for j := range term {
+			mask[i+j] = true
+		}
+		i += len(term)
+	}
+}
+
+// markFuzzyMatches marks the first occurrence of each rune of term in order,
+// or nothing when term doesn't match
+func markFuzzyMatches(mask []bool, runes, term []rune) {
+	positions := make([]int, 0, len(term))
+	i := 0
+	for _, r := range term {
+		for i < len(runes) && runes[i] != r {
+			i++
+		}
+		if i == len(runes) {
+			return
+		}
+		positions = append(positions, i)
+		i++
+	}
+	for _, pos := range positions {
+		mask[pos] = true
+	}
+}
+
+// hasRunePrefix reports whether runes starts with prefix
+func hasRunePrefix(runes, prefix []rune) bool {
+	if len(prefix) > len(runes) {
+		return false
+	}
+	for i, r := range prefix {
+		if runes[i] != r {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/pstuifzand/tui-outliner/internal/theme"
+)
+
+// maskString renders a highlight mask as ^ for highlighted and . for other runes
+func maskString(mask []bool) string {
+	var s []byte
+	for _, highlighted := range mask {
+		if highlighted {
+			s = append(s, '^')
+		} else {
+			s = append(s, '.')
+		}
+	}
+	return string(s)
+}
+
+func TestSearchHighlightMask(t *testing.T) {
+	tests := []struct {
+		text  string
+		query string
+		want  string
+	}{
+		{"Read the Report", "report", ".........^^^^^^"},
+		{"aaaa", "aa", "^^^^"},
+		{"See Budget plan", "budget @type=todo", "....^^^^^^....."},
+		{"Café menu", "FÉ", "..^^....."},
+		{"Write report", "~wrt", "^^.^........"},
+		{"Write report", "~xyz", "............"},
+		{"Report draft", "draft -report", ".......^^^^^"},
+	}
+
+	for _, tt := range tests {
+		if got := maskString(searchHighlightMask(tt.text, tt.query)); got != tt.want {
+			t.Errorf("searchHighlightMask(%q, %q) = %s, want %s", tt.text, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestDrawTextWithLinksAndSearchHighlightsInsideLinks(t *testing.T) {
+	sim := tcell.NewSimulationScreen("")
+	if err := sim.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Fini()
+	sim.SetSize(40, 1)
+	screen := &Screen{tcellScreen: sim, width: 40, height: 1, Theme: theme.Default()}
+
+	// "See Budget plan" where "Budget plan" is the display text of a link
+	text := "See Budget plan"
+	links := []LinkRange{{Start: 4, End: 15, ID: "budget"}}
+	highlightStyle := screen.SearchHighlightStyle()
+	linkStyle := screen.TreeLinkStyle()
+
+	tv := &TreeView{}
+	tv.drawTextWithLinksAndSearch(screen, 0, 0, text, links, nil, screen.TreeNormalStyle(), highlightStyle, linkStyle, "budget")
+
+	_, highlightBg, _ := highlightStyle.Decompose()
+	linkFg, _, _ := linkStyle.Decompose()
+	for i := range []rune(text) {
+		_, _, style, _ := sim.GetContent(i, 0)
+		fg, bg, attrs := style.Decompose()
+		inMatch := i >= 4 && i < 10
+		if (bg == highlightBg) != inMatch {
+			t.Errorf("Column %d: expected highlight %v, got background %v", i, inMatch, bg)
+		}
+		if i >= 4 && (fg != linkFg || attrs&tcell.AttrUnderline == 0) {
+			t.Errorf("Column %d: expected the link style to be kept", i)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package ui
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+func TestSearchScansIncrementally(t *testing.T) {
+	var items []*model.Item
+	for i := range 2000 {
+		kind := "note"
+		if i%2 == 1 {
+			kind = "task"
+		}
+		items = append(items, model.NewItem(fmt.Sprintf("%s %d", kind, i)))
+	}
+
+	s := NewSearch(items)
+	s.Start()
+	s.SetAllItems(items)
+	// Yield after the first batch of items
+	s.scanBudget = 1
+
+	s.SetQuery("note")
+	if !s.IsScanning() {
+		t.Fatal("Expected the search to continue after the first batch")
+	}
+	partial := s.GetMatchCount()
+
+	for s.Continue() {
+	}
+	if got := s.GetMatchCount(); got != 1000 || got <= partial {
+		t.Errorf("Expected 1000 matches after the scan, got %d (partial %d)", got, partial)
+	}
+	if s.GetCurrentMatch() != items[0] {
+		t.Errorf("Expected the first match to stay current, got %v", s.GetCurrentMatch())
+	}
+
+	// Changing the items restarts the query
+	items[0].Text = "changed"
+	s.SetAllItems(items)
+	s.finishScan()
+	if got := s.GetMatchCount(); got != 999 {
+		t.Errorf("Expected 999 matches after the change, got %d", got)
+	}
+}
+
+func TestSearchRanksFuzzyMatches(t *testing.T) {
+	items := []*model.Item{
+		model.NewItem("the amazing sky kite"),
+		model.NewItem("unrelated"),
+		model.NewItem("task"),
+	}
+
+	s := NewSearch(items)
+	s.Start()
+	s.SetQuery("~task")
+	if s.GetMatchCount() != 2 || s.GetCurrentMatch() != items[2] {
+		t.Fatalf("Expected the closest match first, got %v of %d", s.GetCurrentMatch(), s.GetMatchCount())
+	}
+	if results := s.GetResults(); results[0] != items[2] || results[1] != items[0] {
+		t.Errorf("Expected ranked results, got %v", results)
+	}
+	s.NextMatch()
+	if s.GetCurrentMatchIndex() != 0 {
+		t.Errorf("Expected the second match to be item 0, got %d", s.GetCurrentMatchIndex())
+	}
+}
+
+func TestSearchReverseSearchHistory(t *testing.T) {
+	items := []*model.Item{model.NewItem("alpha task"), model.NewItem("beta note")}
+	s := NewSearch(items)
+	for _, query := range []string{"alpha", "beta", "@type=task", "alpine"} {
+		s.history.Add(query)
+	}
+	key := func(k tcell.Key) *tcell.EventKey { return tcell.NewEventKey(k, 0, tcell.ModNone) }
+	typeRunes := func(text string) {
+		for _, r := range text {
+			s.HandleKey(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+		}
+	}
+
+	s.Start()
+	typeRunes("be")
+	s.HandleKey(key(tcell.KeyCtrlR))
+	if !s.IsReverseSearching() {
+		t.Fatal("Expected Ctrl-R to start a reverse search")
+	}
+
+	typeRunes("al")
+	if s.GetQuery() != "alpine" {
+		t.Errorf("Expected the newest match 'alpine', got %q", s.GetQuery())
+	}
+	typeRunes("p")
+	if s.GetQuery() != "alpine" {
+		t.Errorf("Expected the match to stay 'alpine', got %q", s.GetQuery())
+	}
+	s.HandleKey(key(tcell.KeyCtrlR))
+	if s.GetQuery() != "alpha" {
+		t.Errorf("Expected Ctrl-R to find the older 'alpha', got %q", s.GetQuery())
+	}
+	s.HandleKey(key(tcell.KeyCtrlR))
+	if s.GetQuery() != "alpha" || !s.reverseFailed {
+		t.Errorf("Expected a failing search to keep 'alpha', got %q", s.GetQuery())
+	}
+
+	// Escape restores the query from before the reverse search
+	s.HandleKey(key(tcell.KeyEscape))
+	if s.IsReverseSearching() || !s.IsActive() || s.GetQuery() != "be" {
+		t.Errorf("Expected Escape to restore 'be' in the search bar, got %q", s.GetQuery())
+	}
+
+	// Enter accepts the match and finishes the search
+	s.HandleKey(key(tcell.KeyCtrlR))
+	typeRunes("TYPE")
+	s.HandleKey(key(tcell.KeyEnter))
+	if s.IsActive() || s.GetQuery() != "@type=task" {
+		t.Errorf("Expected the search to finish with '@type=task', got %q", s.GetQuery())
+	}
+	if history := s.GetHistory(); history[len(history)-1] != "@type=task" {
+		t.Errorf("Expected the accepted query to be the newest entry, got %v", history)
+	}
+}
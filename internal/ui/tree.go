@@ -1,9 +1,12 @@
 package ui
 
 import (
+	"cmp"
 	"fmt"
 	"log"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -107,6 +110,46 @@ func RenderProgressBar(item *model.Item, todoStatuses []string) []ProgressBarBlo
 	return blocks
 }
 
+// TodoStatusIndex returns the position of status in todoStatuses. Unknown
+// statuses count as not started, like the first status, so they are never
+// shown as done.
+func TodoStatusIndex(status string, todoStatuses []string) int {
+	return max(slices.Index(todoStatuses, status), 0)
+}
+
+// ParentTodoStatus returns the status a todo parent gets from its todo children.
+// When all children are done it is the last status. Otherwise it is the lowest
+// status any child has, but once some child moved past the first status the
+// parent is at least in the second status, and it is never done while a child
+// is not. Returns false when the item has no todo children.
+func ParentTodoStatus(item *model.Item, todoStatuses []string) (string, bool) {
+	if len(todoStatuses) == 0 {
+		return "", false
+	}
+
+	last := len(todoStatuses) - 1
+	lowest, highest := last, 0
+	found := false
+	for _, child := range item.Children {
+		if child.Metadata == nil || child.Metadata.Attributes["type"] != "todo" {
+			continue
+		}
+		found = true
+		i := TodoStatusIndex(child.Metadata.Attributes["status"], todoStatuses)
+		lowest = min(lowest, i)
+		highest = max(highest, i)
+	}
+	if !found {
+		return "", false
+	}
+
+	if lowest < last && highest > 0 {
+		// Some children made progress, others are not done yet
+		lowest = min(max(lowest, 1), max(last-1, 0))
+	}
+	return todoStatuses[lowest], true
+}
+
 // UpdateParentStatusIfTodo updates parent item's status if it has type=todo
 // Implements progressive status matching based on children's statuses
 // Recursively updates ancestors that also have type=todo
@@ -127,34 +170,14 @@ func UpdateParentStatusIfTodo(item *model.Item, todoStatuses []string) {
 	}
 
 	// Calculate progress from children
-	total, done, doing := CalculateProgressFromChildren(parent, todoStatuses)
+	total, done, _ := CalculateProgressFromChildren(parent, todoStatuses)
 
-	if total == 0 {
+	newStatus, ok := ParentTodoStatus(parent, todoStatuses)
+	if !ok {
 		// No todo children, don't update
 		return
 	}
 
-	firstStatus := todoStatuses[0]
-	lastStatus := todoStatuses[len(todoStatuses)-1]
-	middleStatus := firstStatus
-	if len(todoStatuses) > 1 {
-		middleStatus = todoStatuses[1]
-	}
-
-	var newStatus string
-
-	// Simple status matching logic based on all children
-	if done == total {
-		// All children are done
-		newStatus = lastStatus
-	} else if done == 0 && doing == 0 {
-		// All children are todo (no progress)
-		newStatus = firstStatus
-	} else {
-		// Mix of statuses
-		newStatus = middleStatus
-	}
-
 	// Update parent status if changed
 	if parent.Metadata.Attributes["status"] != newStatus {
 		parent.Metadata.Attributes["status"] = newStatus
@@ -182,6 +205,14 @@ type TreeView struct {
 	displayLines   []*DisplayLine // Multi-line aware display for rendering
 	viewportOffset int            // Index of first visible display line in the viewport
 	maxWidth       int            // Maximum width for text wrapping (0 = no wrapping)
+	screenWidth    int            // Width of the screen lines are wrapped for (0 = unknown), see WrapWidth
+	indentWidth    int            // Columns of indentation per depth level (0 = DefaultIndentWidth)
+	rawLinks       bool           // Show the [[id|text]] syntax of links instead of their display text
+	renderEmphasis bool           // Draw *bold* and _italic_ markup without the markers
+
+	// Wrapped lines of the item text lines of the last RebuildView, so lines
+	// that didn't change are not wrapped and measured again
+	wrapCache map[wrapKey][]wrappedTextLine
 
 	// Hoisting state
 	hoistedItem   *model.Item   // Current hoisted node (nil if not hoisted)
@@ -207,18 +238,44 @@ type LinkRange struct {
 // DisplayLine represents a single visual line in the tree view
 // Multiple DisplayLines can belong to the same Item if it has multiple lines of text
 type DisplayLine struct {
-	Item              *model.Item   // The underlying item
-	TextLineIndex     int           // Which line within the item's text (0-based, split by \n)
-	TextLine          string        // The actual text to display for this line (formatted, with links converted to display text)
-	LinkRanges        []LinkRange   // Ranges in TextLine that should be styled as links
-	ItemStartLine     bool          // True if this is the first line of the item (shows indent/arrow/metadata)
-	IsWrapped         bool          // True if this is a wrapped continuation of a long line
+	Item              *model.Item     // The underlying item
+	TextLineIndex     int             // Which line within the item's text (0-based, split by \n)
+	TextLine          string          // The actual text to display for this line (formatted, with links converted to display text)
+	Width             int             // Display width of TextLine
+	LinkRanges        []LinkRange     // Ranges in TextLine that should be styled as links
+	EmphasisRanges    []EmphasisRange // Ranges in TextLine that should be drawn bold or italic
+	ItemStartLine     bool            // True if this is the first line of the item (shows indent/arrow/metadata)
+	IsWrapped         bool            // True if this is a wrapped continuation of a long line
 	Depth             int
 	IsVirtual         bool
 	OriginalItem      *model.Item
 	SearchNodeParent  *model.Item
 	VirtualAncestors  []*model.Item
-	ParentDisplayItem *displayItem  // Reference to parent displayItem (for comparing selected items)
+	ParentDisplayItem *displayItem // Reference to parent displayItem (for comparing selected items)
+}
+
+// truncatedText returns the text and link ranges of the line truncated to
+// maxWidth, see truncateDisplayLine, and the display width of the text
+func (l *DisplayLine) truncatedText(maxWidth int) (string, []LinkRange, int) {
+	if l.Width <= maxWidth {
+		return l.TextLine, l.LinkRanges, l.Width
+	}
+	text, linkRanges := truncateDisplayLine(l.TextLine, l.LinkRanges, maxWidth)
+	return text, linkRanges, StringWidth(text)
+}
+
+// wrapKey identifies the wrapped lines of a line of item text
+type wrapKey struct {
+	text     string
+	maxWidth int
+}
+
+// wrappedTextLine is one wrapped line of a line of item text
+type wrappedTextLine struct {
+	Text           string
+	Width          int // Display width of Text
+	LinkRanges     []LinkRange
+	EmphasisRanges []EmphasisRange
 }
 
 // NewTreeView creates a new TreeView
@@ -282,10 +339,31 @@ func convertLinksToDisplayText(text string) (displayText string, linkRanges []Li
 	return result.String(), linkRanges
 }
 
+// rawLinkRanges returns the link ranges of text with the link syntax kept, so
+// raw links are styled like links
+func rawLinkRanges(text string) []LinkRange {
+	parsedLinks := links.ParseLinks(text)
+	if len(parsedLinks) == 0 {
+		return nil
+	}
+
+	linkRanges := make([]LinkRange, 0, len(parsedLinks))
+	for _, link := range parsedLinks {
+		start := utf8.RuneCountInString(text[:link.StartPos])
+		linkRanges = append(linkRanges, LinkRange{
+			Start: start,
+			End:   start + utf8.RuneCountInString(text[link.StartPos:link.EndPos]),
+			ID:    link.ID,
+		})
+	}
+	return linkRanges
+}
+
 // WrappedLine represents a single wrapped line with its link ranges
 type WrappedLine struct {
 	Text       string
 	LinkRanges []LinkRange
+	Start      int // Position of the line in the wrapped text (rune index)
 }
 
 // wrapTextAtWidth wraps text without link awareness (for simple cases like editor)
@@ -353,6 +431,7 @@ func wrapTextWithLinks(displayText string, linkRanges []LinkRange, maxWidth int)
 			result = append(result, WrappedLine{
 				Text:       remaining,
 				LinkRanges: lineLinkRanges,
+				Start:      runePos,
 			})
 			break
 		}
@@ -387,6 +466,7 @@ func wrapTextWithLinks(displayText string, linkRanges []LinkRange, maxWidth int)
 		result = append(result, WrappedLine{
 			Text:       lineText,
 			LinkRanges: lineLinkRanges,
+			Start:      runePos,
 		})
 
 		// Move to next line
@@ -447,22 +527,26 @@ func adjustLinkRangesForLine(linkRanges []LinkRange, lineStart, lineEnd int) []L
 
 // buildDisplayLines converts a list of displayItems into display lines,
 // expanding multi-line items into multiple DisplayLine entries with word wrapping
-// maxWidth specifies the maximum width for text wrapping (0 = no wrapping)
-func (tv *TreeView) buildDisplayLines(displayItems []*displayItem, maxWidth int) []*DisplayLine {
+// Lines are wrapped at the WrapWidth of their depth (0 = no wrapping)
+func (tv *TreeView) buildDisplayLines(displayItems []*displayItem) []*DisplayLine {
+	// Only keep the wrapped lines that are still shown, so edited text and
+	// old widths drop out of the cache
+	wrapCache := make(map[wrapKey][]wrappedTextLine)
 	var lines []*DisplayLine
 	for _, dispItem := range displayItems {
+		maxWidth := tv.WrapWidth(dispItem.Depth)
+
 		// Split item text by hard newlines first
 		textLines := strings.Split(dispItem.Item.Text, "\n")
 		for lineIdx, textLine := range textLines {
-			// Convert links to display text BEFORE wrapping
-			displayText, linkRanges := convertLinksToDisplayText(textLine)
-
-			// Apply word wrapping if maxWidth is specified
-			var wrappedLines []WrappedLine
-			if maxWidth > 0 {
-				wrappedLines = wrapTextWithLinks(displayText, linkRanges, maxWidth)
-			} else {
-				wrappedLines = []WrappedLine{{Text: displayText, LinkRanges: linkRanges}}
+			key := wrapKey{text: textLine, maxWidth: maxWidth}
+			wrappedLines, ok := wrapCache[key]
+			if !ok {
+				wrappedLines, ok = tv.wrapCache[key]
+				if !ok {
+					wrappedLines = tv.wrapTextLine(textLine, maxWidth)
+				}
+				wrapCache[key] = wrappedLines
 			}
 
 			// Create display lines for each wrapped portion
@@ -474,7 +558,9 @@ func (tv *TreeView) buildDisplayLines(displayItems []*displayItem, maxWidth int)
 					Item:              dispItem.Item,
 					TextLineIndex:     lineIdx,
 					TextLine:          wrapped.Text,
+					Width:             wrapped.Width,
 					LinkRanges:        wrapped.LinkRanges,
+					EmphasisRanges:    wrapped.EmphasisRanges,
 					ItemStartLine:     isFirstLine,
 					IsWrapped:         isWrapped,
 					Depth:             dispItem.Depth,
@@ -488,9 +574,47 @@ func (tv *TreeView) buildDisplayLines(displayItems []*displayItem, maxWidth int)
 			}
 		}
 	}
+	tv.wrapCache = wrapCache
 	return lines
 }
 
+// wrapTextLine converts the links and emphasis of a line of item text and
+// wraps it at maxWidth (0 = no wrapping)
+func (tv *TreeView) wrapTextLine(textLine string, maxWidth int) []wrappedTextLine {
+	// Convert links to display text BEFORE wrapping
+	displayText, linkRanges := convertLinksToDisplayText(textLine)
+	if tv.rawLinks {
+		displayText, linkRanges = textLine, rawLinkRanges(textLine)
+	}
+
+	// Take out emphasis markers before wrapping too, so the width is right
+	var emphasisRanges []EmphasisRange
+	if tv.renderEmphasis {
+		var removed []int
+		displayText, emphasisRanges, removed = ParseEmphasis(displayText)
+		linkRanges = shiftLinkRanges(linkRanges, removed)
+	}
+
+	// Apply word wrapping if maxWidth is specified
+	var wrappedLines []WrappedLine
+	if maxWidth > 0 {
+		wrappedLines = wrapTextWithLinks(displayText, linkRanges, maxWidth)
+	} else {
+		wrappedLines = []WrappedLine{{Text: displayText, LinkRanges: linkRanges}}
+	}
+
+	result := make([]wrappedTextLine, 0, len(wrappedLines))
+	for _, wrapped := range wrappedLines {
+		result = append(result, wrappedTextLine{
+			Text:           wrapped.Text,
+			Width:          StringWidth(wrapped.Text),
+			LinkRanges:     wrapped.LinkRanges,
+			EmphasisRanges: adjustEmphasisRangesForLine(emphasisRanges, wrapped.Start, wrapped.Start+utf8.RuneCountInString(wrapped.Text)),
+		})
+	}
+	return result
+}
+
 // getFirstDisplayLineForItem returns the index of the first display line for a given item
 // Returns -1 if item not found
 func (tv *TreeView) getFirstDisplayLineForItem(item *model.Item) int {
@@ -541,7 +665,7 @@ func (tv *TreeView) GetItemFromDisplayLine(displayLineIdx int) int {
 // RebuildView rebuilds the filtered/display view
 func (tv *TreeView) RebuildView() {
 	tv.filteredView = tv.buildDisplayItems(tv.items, 0)
-	tv.displayLines = tv.buildDisplayLines(tv.filteredView, tv.maxWidth)
+	tv.displayLines = tv.buildDisplayLines(tv.filteredView)
 	if tv.selectedIdx >= len(tv.filteredView) && len(tv.filteredView) > 0 {
 		tv.selectedIdx = len(tv.filteredView) - 1
 	}
@@ -560,6 +684,96 @@ func (tv *TreeView) SetMaxWidth(width int) {
 	}
 }
 
+// SetWrapWidth sets the maximum width for text wrapping and the width of the
+// screen, and rebuilds the view when either changed
+func (tv *TreeView) SetWrapWidth(maxWidth, screenWidth int) {
+	maxWidth = max(maxWidth, 0)
+	if tv.maxWidth != maxWidth || tv.screenWidth != screenWidth {
+		tv.maxWidth = maxWidth
+		tv.screenWidth = screenWidth
+		tv.RebuildView()
+	}
+}
+
+// DefaultIndentWidth is the number of columns each depth level is indented
+// when the indentwidth setting is not set
+const DefaultIndentWidth = 3
+
+// MaxIndentWidth is the largest value of the indentwidth setting
+const MaxIndentWidth = 8
+
+// IndentWidthFromConfig returns the indentwidth setting, or DefaultIndentWidth
+// when it is not set or invalid
+func IndentWidthFromConfig(cfg *config.Config) int {
+	if cfg != nil {
+		if width, err := strconv.Atoi(cfg.Get("indentwidth")); err == nil && width >= 1 && width <= MaxIndentWidth {
+			return width
+		}
+	}
+	return DefaultIndentWidth
+}
+
+// IndentWidth returns the number of columns each depth level is indented
+func (tv *TreeView) IndentWidth() int {
+	if tv.indentWidth == 0 {
+		return DefaultIndentWidth
+	}
+	return tv.indentWidth
+}
+
+// SetIndentWidth sets the number of columns each depth level is indented and
+// rebuilds the view when it changed, as the wrap widths depend on it
+func (tv *TreeView) SetIndentWidth(width int) {
+	if width != tv.IndentWidth() {
+		tv.indentWidth = width
+		tv.RebuildView()
+	}
+}
+
+// SetRawLinks sets whether links show their [[id|text]] syntax instead of
+// their display text, and rebuilds the view when it changed
+func (tv *TreeView) SetRawLinks(raw bool) {
+	if tv.rawLinks != raw {
+		tv.rawLinks = raw
+		tv.wrapCache = nil
+		tv.RebuildView()
+	}
+}
+
+// SetRenderEmphasis sets whether *bold* and _italic_ markup is drawn bold and
+// italic without the markers, and rebuilds the view when it changed
+func (tv *TreeView) SetRenderEmphasis(render bool) {
+	if tv.renderEmphasis != render {
+		tv.renderEmphasis = render
+		tv.wrapCache = nil
+		tv.RebuildView()
+	}
+}
+
+// ArrowX returns the column of the arrow of items at depth
+func (tv *TreeView) ArrowX(depth int) int {
+	return depth * tv.IndentWidth()
+}
+
+// TextX returns the column where the text of items at depth starts, after the
+// indentation, arrow, attribute indicator and space. The editor is placed here
+// too, so the cursor lines up with the text.
+func (tv *TreeView) TextX(depth int) int {
+	return tv.ArrowX(depth) + 3
+}
+
+// WrapWidth returns the width at which text of items at depth is wrapped: the
+// maximum width, narrowed to the columns left on the screen after the
+// indentation, arrow and indicator, so wrapped lines never need truncating.
+// Returns 0 when wrapping is disabled.
+func (tv *TreeView) WrapWidth(depth int) int {
+	width := tv.maxWidth
+	if width > 0 && tv.screenWidth > 0 {
+		width = min(width, max(tv.screenWidth-tv.TextX(depth), 1))
+	}
+	return width
+}
+
 func (tv *TreeView) buildDisplayItems(items []*model.Item, depth int) []*displayItem {
 	return tv.buildDisplayItemsInternal(items, depth, false, nil, nil, nil)
 }
@@ -692,6 +906,61 @@ func (tv *TreeView) ScrollPageDown(pageSize int) {
 	}
 }
 
+// CenterSelection scrolls so the first line of the selected item is in the middle of the viewport
+func (tv *TreeView) CenterSelection(viewportHeight int) {
+	tv.ScrollSelectionToRow(viewportHeight, viewportHeight/2)
+}
+
+// ScrollSelectionToTop scrolls so the selected item is at the top of the viewport
+func (tv *TreeView) ScrollSelectionToTop(viewportHeight int) {
+	tv.ScrollSelectionToRow(viewportHeight, 0)
+}
+
+// ScrollSelectionToBottom scrolls so the last line of the selected item is at the bottom of the viewport
+func (tv *TreeView) ScrollSelectionToBottom(viewportHeight int) {
+	selectedItem := tv.GetSelected()
+	if selectedItem == nil {
+		return
+	}
+	firstLineIdx := tv.getFirstDisplayLineForItem(selectedItem)
+	lastLineIdx := tv.getLastDisplayLineForItem(selectedItem)
+	if firstLineIdx < 0 || lastLineIdx < 0 {
+		return
+	}
+	// Items taller than the viewport keep their first line visible
+	row := viewportHeight - 1 - (lastLineIdx - firstLineIdx)
+	tv.ScrollSelectionToRow(viewportHeight, max(row, 0))
+}
+
+// SelectedRow returns the viewport row of the first line of the selected item, or -1
+func (tv *TreeView) SelectedRow() int {
+	selectedItem := tv.GetSelected()
+	if selectedItem == nil {
+		return -1
+	}
+	firstLineIdx := tv.getFirstDisplayLineForItem(selectedItem)
+	if firstLineIdx < 0 {
+		return -1
+	}
+	return firstLineIdx - tv.viewportOffset
+}
+
+// ScrollSelectionToRow sets the viewport offset so the first line of the selected
+// item is shown at the given row, without scrolling past the display lines
+func (tv *TreeView) ScrollSelectionToRow(viewportHeight int, row int) {
+	selectedItem := tv.GetSelected()
+	if selectedItem == nil || viewportHeight <= 0 {
+		return
+	}
+	firstLineIdx := tv.getFirstDisplayLineForItem(selectedItem)
+	if firstLineIdx < 0 {
+		return
+	}
+
+	maxOffset := max(len(tv.displayLines)-viewportHeight, 0)
+	tv.viewportOffset = min(max(firstLineIdx-row, 0), maxOffset)
+}
+
 // ensureVisible keeps the selected item within the visible viewport
 func (tv *TreeView) ensureVisible() {
 	// This would need to know the viewport size, which we'll handle in Render
@@ -866,6 +1135,75 @@ func (tv *TreeView) expandItemRecursive(item *model.Item) {
 	}
 }
 
+// CollapseSubtree collapses item and all its descendants. The selection stays
+// on item.
+func (tv *TreeView) CollapseSubtree(item *model.Item) {
+	if item == nil {
+		return
+	}
+	tv.collapseItemRecursive(item)
+	tv.RebuildView()
+	tv.selectDisplayedItem(item)
+}
+
+// ExpandSubtree expands item and all its descendants. The selection stays on
+// item, or moves to its first child when selectFirstChild is set.
+func (tv *TreeView) ExpandSubtree(item *model.Item, selectFirstChild bool) {
+	if item == nil {
+		return
+	}
+	tv.expandItemRecursive(item)
+	tv.RebuildView()
+	if selectFirstChild && len(item.Children) > 0 {
+		item = item.Children[0]
+	}
+	tv.selectDisplayedItem(item)
+}
+
+// selectDisplayedItem selects item when it is in the view
+func (tv *TreeView) selectDisplayedItem(item *model.Item) {
+	for idx, dispItem := range tv.filteredView {
+		if dispItem.Item == item {
+			tv.SelectItem(idx)
+			return
+		}
+	}
+}
+
+// CollapseToLevel expands items at a depth below level and collapses the rest.
+// Depth is measured from the current root (the children of the hoisted item when
+// hoisted), so level 0 collapses everything. The selection moves to the nearest
+// visible ancestor of the previously selected item.
+func (tv *TreeView) CollapseToLevel(level int) {
+	selected := tv.GetSelected()
+
+	for _, item := range tv.items {
+		tv.collapseItemToLevel(item, 0, level)
+	}
+	tv.RebuildView()
+
+	// Keep the selection on the item or its nearest still-visible ancestor
+	for current := selected; current != nil; current = current.Parent {
+		for idx, dispItem := range tv.filteredView {
+			if dispItem.Item == current {
+				tv.SelectItem(idx)
+				return
+			}
+		}
+	}
+}
+
+// collapseItemToLevel is a helper that sets the expanded state of an item and its descendants by depth
+func (tv *TreeView) collapseItemToLevel(item *model.Item, depth int, level int) {
+	if item == nil {
+		return
+	}
+	item.Expanded = depth < level && len(item.Children) > 0
+	for _, child := range item.Children {
+		tv.collapseItemToLevel(child, depth+1, level)
+	}
+}
+
 // CollapseAllChildren collapses all direct children of the selected item
 func (tv *TreeView) CollapseAllChildren() {
 	if len(tv.filteredView) > 0 && tv.selectedIdx < len(tv.filteredView) {
@@ -1047,6 +1385,59 @@ func (tv *TreeView) SendItemToNode(destination *model.Item) bool {
 	return true
 }
 
+// MoveItemTo moves item to position index among the children of newParent.
+// The index is clamped to the valid range, so a large index appends the item.
+// Like SendItemToNode it refuses to move an item into itself or its descendants.
+// The selected item stays selected when it is still visible.
+func (tv *TreeView) MoveItemTo(item, newParent *model.Item, index int) bool {
+	if item == nil || newParent == nil {
+		return false
+	}
+
+	// Prevent moving an item into itself
+	if item.ID == newParent.ID {
+		return false
+	}
+
+	// Prevent creating circular references (moving parent into its own descendant)
+	if isDescendant(item, newParent) {
+		return false
+	}
+
+	// Remember the selection to restore it after rebuilding the view
+	selected := tv.GetSelected()
+
+	// Remove from current parent or root
+	if item.Parent != nil {
+		item.Parent.RemoveChild(item)
+	} else {
+		idx := slices.Index(tv.items, item)
+		if idx < 0 {
+			return false
+		}
+		tv.items = slices.Delete(tv.items, idx, idx+1)
+	}
+
+	index = max(0, min(index, len(newParent.Children)))
+	item.Parent = newParent
+	newParent.Children = slices.Insert(newParent.Children, index, item)
+
+	// When hoisted, the visible items are the children of the hoisted item
+	if tv.hoistedItem != nil {
+		tv.items = tv.hoistedItem.Children
+	}
+
+	// Expand the new parent to show the moved item
+	newParent.Expanded = true
+
+	tv.RebuildView()
+	if selected != nil {
+		tv.SelectItemByID(selected.ID)
+	}
+
+	return true
+}
+
 // isDescendant checks if potentialDescendant is a descendant of ancestor
 func isDescendant(ancestor *model.Item, potentialDescendant *model.Item) bool {
 	for _, child := range ancestor.Children {
@@ -1313,108 +1704,56 @@ type Position struct {
 // Each position is defined as (parent, index) where index is the position in parent's children array
 func (tv *TreeView) buildAllPositions() []Position {
 	var positions []Position
-	tv.buildPositionsRecursive(nil, &positions)
+	tv.buildPositionsRecursive(nil, nil, &positions)
 	return positions
 }
 
 // buildAllPositionsForItem builds a list of all valid positions for moving a specific item
-// It excludes positions where the item would become its own parent or ancestor
-// It builds positions as if the item was temporarily removed from the tree (to get stable ordering)
+// The positions are those of the tree without the item (to get stable ordering
+// that doesn't depend on where the item currently is). Leaving out the item
+// also leaves out its descendants, so it can't become its own parent or ancestor.
+// The tree is only read, not changed.
 func (tv *TreeView) buildAllPositionsForItem(item *model.Item) []Position {
-	// Temporarily remove the item from its current location to get stable positions
-	// that don't depend on where the item currently is
-	var wasParent *model.Item
-	var wasIndex int
-	var wasRemoved bool
-
-	if item.Parent != nil {
-		wasParent = item.Parent
-		for i, child := range item.Parent.Children {
-			if child.ID == item.ID {
-				wasIndex = i
-				wasParent.Children = append(wasParent.Children[:i], wasParent.Children[i+1:]...)
-				wasRemoved = true
-				break
-			}
-		}
-	} else {
-		for i, rootItem := range tv.items {
-			if rootItem.ID == item.ID {
-				wasIndex = i
-				tv.items = append(tv.items[:i], tv.items[i+1:]...)
-				wasRemoved = true
-				break
-			}
-		}
-	}
-
-	// Build positions for the tree WITHOUT the item
-	allPositions := tv.buildAllPositions()
-
-	// Restore the item to its original position
-	if wasRemoved {
-		if wasParent != nil {
-			newChildren := make([]*model.Item, 0, len(wasParent.Children)+1)
-			newChildren = append(newChildren, wasParent.Children[:wasIndex]...)
-			newChildren = append(newChildren, item)
-			newChildren = append(newChildren, wasParent.Children[wasIndex:]...)
-			wasParent.Children = newChildren
-		} else {
-			newItems := make([]*model.Item, 0, len(tv.items)+1)
-			newItems = append(newItems, tv.items[:wasIndex]...)
-			newItems = append(newItems, item)
-			newItems = append(newItems, tv.items[wasIndex:]...)
-			tv.items = newItems
-		}
-	}
-
-	// Build set of descendants of the item (items we can't move it to)
-	descendants := make(map[*model.Item]bool)
-	tv.collectDescendants(item, descendants)
-
-	// Filter out invalid positions
-	var validPositions []Position
-	for _, pos := range allPositions {
-		// Can't move item to be a child of itself or any of its descendants
-		if pos.Parent != item && !descendants[pos.Parent] {
-			validPositions = append(validPositions, pos)
-		}
-	}
-
-	return validPositions
-}
-
-// collectDescendants recursively collects all descendants of an item
-func (tv *TreeView) collectDescendants(item *model.Item, descendants map[*model.Item]bool) {
-	for _, child := range item.Children {
-		descendants[child] = true
-		tv.collectDescendants(child, descendants)
-	}
+	var positions []Position
+	tv.buildPositionsRecursive(nil, item, &positions)
+	return positions
 }
 
 // buildPositionsRecursive recursively builds all positions starting from a parent
-func (tv *TreeView) buildPositionsRecursive(parent *model.Item, positions *[]Position) {
+// The skip item and its descendants are left out, and the indexes after it
+// are those it would have without the skip item
+func (tv *TreeView) buildPositionsRecursive(parent, skip *model.Item, positions *[]Position) {
 	var children []*model.Item
 	if parent == nil {
 		children = tv.items
 	} else {
-		// Only include positions for expanded nodes with children
-		// If a node is Expanded but has no children, treat it as if it's collapsed
-		if !parent.Expanded || len(parent.Children) == 0 {
-			// Don't create any positions for collapsed nodes or expanded nodes with no children
-			return
-		}
 		children = parent.Children
 	}
 
+	count := len(children)
+	if skip != nil && slices.Contains(children, skip) {
+		count--
+	}
+
+	// Only include positions for expanded nodes with children
+	// If a node is Expanded but has no children, treat it as if it's collapsed
+	if parent != nil && (!parent.Expanded || count == 0) {
+		// Don't create any positions for collapsed nodes or expanded nodes with no children
+		return
+	}
+
 	// Add positions for all insertion points in this parent's children
-	for i := 0; i <= len(children); i++ {
-		*positions = append(*positions, Position{Parent: parent, Index: i})
-		// Recursively add positions for the child at this index
-		if i < len(children) {
-			tv.buildPositionsRecursive(children[i], positions)
+	index := 0
+	for _, child := range children {
+		if child == skip {
+			continue
 		}
+		*positions = append(*positions, Position{Parent: parent, Index: index})
+		// Recursively add positions for the child at this index
+		tv.buildPositionsRecursive(child, skip, positions)
+		index++
 	}
+	*positions = append(*positions, Position{Parent: parent, Index: index})
 }
 
 // logPositions logs all positions in a readable format with the current position marked as [CUR]
@@ -1607,6 +1946,142 @@ func (tv *TreeView) DeleteSelected() bool {
 	return true
 }
 
+// siblingsOf returns the slice that holds item, the children of its parent or
+// the root items, and the index of item in it, -1 when it isn't there
+func (tv *TreeView) siblingsOf(item *model.Item) ([]*model.Item, int) {
+	siblings := tv.items
+	if item.Parent != nil {
+		siblings = item.Parent.Children
+	}
+	return siblings, slices.Index(siblings, item)
+}
+
+// selectedSiblings returns the selected item with its siblings and index as
+// siblingsOf does. The item is nil when no real item is selected.
+func (tv *TreeView) selectedSiblings() (*model.Item, []*model.Item, int) {
+	if tv.selectedIdx < 0 || tv.selectedIdx >= len(tv.filteredView) {
+		return nil, nil, -1
+	}
+	displayItem := tv.filteredView[tv.selectedIdx]
+	if displayItem.IsVirtual {
+		return nil, nil, -1
+	}
+	siblings, idx := tv.siblingsOf(displayItem.Item)
+	if idx < 0 {
+		return nil, nil, -1
+	}
+	return displayItem.Item, siblings, idx
+}
+
+// MoveToTopOfSiblings moves the selected item to the start of its parent's children
+func (tv *TreeView) MoveToTopOfSiblings() bool {
+	return tv.moveWithinSiblings(true)
+}
+
+// MoveToBottomOfSiblings moves the selected item to the end of its parent's children
+func (tv *TreeView) MoveToBottomOfSiblings() bool {
+	return tv.moveWithinSiblings(false)
+}
+
+// moveWithinSiblings moves the selected item to the first or last position
+// among its siblings. Returns false when the item is already there.
+func (tv *TreeView) moveWithinSiblings(toTop bool) bool {
+	current, siblings, idx := tv.selectedSiblings()
+	if current == nil {
+		return false
+	}
+
+	// Rotate the item to the edge, the slice keeps its length so no reassignment is needed
+	if toTop {
+		if idx == 0 {
+			return false
+		}
+		copy(siblings[1:idx+1], siblings[:idx])
+		siblings[0] = current
+	} else {
+		if idx == len(siblings)-1 {
+			return false
+		}
+		copy(siblings[idx:], siblings[idx+1:])
+		siblings[len(siblings)-1] = current
+	}
+
+	tv.RebuildView()
+	tv.SelectItemByID(current.ID)
+	return true
+}
+
+// SwapWithPrevSibling exchanges the selected item with its previous sibling.
+// Unlike MoveItemUp the item never changes parent or depth.
+func (tv *TreeView) SwapWithPrevSibling() bool {
+	return tv.swapWithSibling(-1)
+}
+
+// SwapWithNextSibling exchanges the selected item with its next sibling.
+// Unlike MoveItemDown the item never changes parent or depth.
+func (tv *TreeView) SwapWithNextSibling() bool {
+	return tv.swapWithSibling(1)
+}
+
+// swapWithSibling exchanges the selected item with the sibling at offset from it.
+// Returns false when there is no such sibling.
+func (tv *TreeView) swapWithSibling(offset int) bool {
+	current, siblings, idx := tv.selectedSiblings()
+	other := idx + offset
+	if current == nil || other < 0 || other >= len(siblings) {
+		return false
+	}
+
+	siblings[idx], siblings[other] = siblings[other], siblings[idx]
+
+	tv.RebuildView()
+	tv.SelectItemByID(current.ID)
+	return true
+}
+
+// JoinWithNext merges the next sibling into the selected item. The texts are
+// joined with a space, the children of the sibling move to the selected item
+// and the sibling is removed. Returns false when there is no next sibling.
+func (tv *TreeView) JoinWithNext() bool {
+	current, siblings, idx := tv.selectedSiblings()
+	if current == nil || idx+1 >= len(siblings) {
+		return false
+	}
+	next := siblings[idx+1]
+
+	switch {
+	case current.Text == "":
+		current.Text = next.Text
+	case next.Text != "":
+		current.Text = current.Text + " " + next.Text
+	}
+
+	// Move the children of the joined item over
+	for _, child := range next.Children {
+		child.Parent = current
+	}
+	current.Children = append(current.Children, next.Children...)
+	next.Children = nil
+
+	if current.Metadata != nil {
+		current.Metadata.Modified = time.Now()
+	}
+
+	if current.Parent != nil {
+		current.Parent.RemoveChild(next)
+		// When hoisted, the root items are the children of the hoisted item
+		if tv.hoistedItem != nil && current.Parent == tv.hoistedItem {
+			tv.items = current.Parent.Children
+		}
+	} else {
+		tv.items = slices.Delete(tv.items, idx+1, idx+2)
+	}
+
+	tv.RebuildView()
+	tv.SelectItemByID(current.ID)
+	return true
+}
+
 // DeleteItem removes a specific item by reference
 func (tv *TreeView) DeleteItem(item *model.Item) bool {
 	if item == nil {
@@ -1638,6 +2113,41 @@ func (tv *TreeView) DeleteItem(item *model.Item) bool {
 	return true
 }
 
+// ReplaceItem puts replacements in the place of item among its siblings
+func (tv *TreeView) ReplaceItem(item *model.Item, replacements []*model.Item) bool {
+	if item == nil {
+		return false
+	}
+
+	parent := item.Parent
+	if parent != nil {
+		idx := slices.Index(parent.Children, item)
+		if idx < 0 {
+			return false
+		}
+		parent.Children = slices.Concat(parent.Children[:idx], replacements, parent.Children[idx+1:])
+		// When hoisted and we modify the hoisted node's children, update tv.items
+		if tv.hoistedItem != nil && parent == tv.hoistedItem {
+			tv.items = parent.Children
+		}
+	} else {
+		idx := slices.Index(tv.items, item)
+		if idx < 0 {
+			return false
+		}
+		tv.items = slices.Concat(tv.items[:idx], replacements, tv.items[idx+1:])
+	}
+	for _, replacement := range replacements {
+		replacement.Parent = parent
+	}
+
+	tv.RebuildView()
+	if tv.selectedIdx >= len(tv.filteredView) && len(tv.filteredView) > 0 {
+		tv.selectedIdx = len(tv.filteredView) - 1
+	}
+	return true
+}
+
 // PasteAfter pastes an item after the selected item and returns the pasted item (or nil on failure)
 func (tv *TreeView) PasteAfter(item *model.Item) *model.Item {
 	if item == nil || len(tv.filteredView) == 0 || tv.selectedIdx >= len(tv.filteredView) {
@@ -1799,21 +2309,35 @@ func (tv *TreeView) Render(screen *Screen, startY, endY int, visualAnchor int, c
 	tv.RenderWithSearchQuery(screen, startY, endY, visualAnchor, "", nil, cfg)
 }
 
+// drawIndentGuides draws a vertical guide in the indentation of each parent
+// level of an item at depth
+func (tv *TreeView) drawIndentGuides(screen *Screen, y, depth int, style tcell.Style) {
+	for level := range depth {
+		screen.SetCell(tv.ArrowX(level), y, '│', style)
+	}
+}
+
 // RenderWithSearchQuery renders the tree with optional search query highlighting
 func (tv *TreeView) RenderWithSearchQuery(screen *Screen, startY, endY int, visualAnchor int, searchQuery string, currentMatchItem *model.Item, cfg *config.Config) {
 	screenWidth := screen.GetWidth()
 	screenHeight := screen.GetHeight()
 
+	// Apply the indentation width before the wrap widths, which depend on it
+	tv.SetIndentWidth(IndentWidthFromConfig(cfg))
+	tv.SetRawLinks(cfg != nil && cfg.Get("showrawlinks") == "true")
+	tv.SetRenderEmphasis(cfg != nil && cfg.Get("renderemphasis") == "true")
+	showGuides := cfg != nil && cfg.Get("indentguides") == "true"
+
 	// Calculate max width for text wrapping
-	// Reserve space for indentation (max 6 levels * 3 chars) and arrow/indicator/space (3 chars)
+	// Reserve space for indentation (max 6 levels) and arrow/indicator/space (3 chars)
 	// This ensures we have at least some reasonable width for text
-	maxTextWidth := screenWidth - 21 // 6 levels * 3 + 3 for arrow area
+	maxTextWidth := screenWidth - tv.TextX(6)
 	if maxTextWidth < 20 {
 		maxTextWidth = 20 // Minimum wrap width
 	}
 
-	// Update max width if it changed
-	tv.SetMaxWidth(maxTextWidth)
+	// Update wrap widths if they changed
+	tv.SetWrapWidth(maxTextWidth, screenWidth)
 
 	defaultStyle := screen.TreeNormalStyle()
 	selectedStyle := screen.TreeSelectedStyle()
@@ -1827,6 +2351,17 @@ func (tv *TreeView) RenderWithSearchQuery(screen *Screen, startY, endY int, visu
 	defaultStyle = defaultStyle.Background(bgColor)
 	newItemStyle = newItemStyle.Background(bgColor)
 
+	// Priority levels for coloring prioritized items, nil when disabled
+	var priorityLevels []PriorityLevel
+	if cfg != nil && cfg.Get("showpriority") == "true" {
+		priorityLevels = PriorityLevelsFromConfig(cfg)
+	}
+
+	// Due date settings for flagging overdue and upcoming items
+	dueDates, showDue := DueDatesFromConfig(cfg)
+	showDue = showDue && cfg != nil
+	now := time.Now()
+
 	// Calculate available viewport height
 	viewportHeight := endY
 
@@ -1939,14 +2474,38 @@ func (tv *TreeView) RenderWithSearchQuery(screen *Screen, startY, endY int, visu
 			expandableArrowStyle = screen.TreeExpandableArrowStyle().Background(bgColor)
 		}
 
+		// Color the text of unselected prioritized items
+		var priorityColor tcell.Color
+		hasPriority := false
+		if priorityLevels != nil && !inVisualRange && !isLinePartOfSelected {
+			if rank, ok := PriorityRank(priorityLevels, displayLine.Item); ok {
+				priorityColor = screen.PriorityColor(priorityLevels[rank].Color)
+				hasPriority = true
+				style = style.Foreground(priorityColor)
+			}
+		}
+
+		// Flag overdue and upcoming items, this wins over the priority color
+		if showDue && !inVisualRange && !isLinePartOfSelected {
+			if state := dueDates.State(displayLine.Item, now); state != DueNone {
+				style = style.Foreground(screen.DueColor(state))
+				if state == DueOverdue {
+					style = style.Bold(true)
+				}
+			}
+		}
+
 		// Only render item metadata (indent, arrow, attributes, progress) on the first line
 		if displayLine.ItemStartLine {
-			// Add indentation for parent levels (3 spaces per level)
-			prefix := strings.Repeat("   ", displayLine.Depth)
+			// Add indentation for parent levels
+			prefix := strings.Repeat(" ", tv.ArrowX(displayLine.Depth))
 
 			// Draw indentation
 			if displayLine.Depth > 0 {
 				screen.DrawString(0, y, prefix, style)
+				if showGuides {
+					tv.drawIndentGuides(screen, y, displayLine.Depth, screen.GrayStyle().Background(lineBackgroundColor))
+				}
 			}
 
 			// Always draw an arrow
@@ -1980,7 +2539,7 @@ func (tv *TreeView) RenderWithSearchQuery(screen *Screen, startY, endY int, visu
 				arrow = "▼"
 			}
 
-			prefixX := displayLine.Depth * 3
+			prefixX := tv.ArrowX(displayLine.Depth)
 			screen.DrawString(prefixX, y, arrow, arrowStyle)
 
 			// Draw attribute indicator or space to maintain alignment
@@ -1990,6 +2549,9 @@ func (tv *TreeView) RenderWithSearchQuery(screen *Screen, startY, endY int, visu
 			}
 
 			hasAttributes := displayLine.Item.Metadata != nil && len(displayLine.Item.Metadata.Attributes) > 0
+			if hasPriority {
+				indicatorStyle = indicatorStyle.Foreground(priorityColor)
+			}
 			if hasAttributes {
 				screen.SetCell(prefixX+1, y, '●', indicatorStyle) // Filled circle for items with attributes
 			} else {
@@ -2007,41 +2569,16 @@ func (tv *TreeView) RenderWithSearchQuery(screen *Screen, startY, endY int, visu
 			}
 
 			// Truncate with ellipsis if text exceeds max width
-			// Use StringWidth for proper Unicode-aware truncation
-			text := displayLine.TextLine
-			linkRanges := displayLine.LinkRanges
-			if StringWidth(text) > maxTextWidth {
-				// Reserve space for ellipsis (1 column)
-				if maxTextWidth > 1 {
-					truncatedLen := len([]rune(TruncateToWidth(text, maxTextWidth-1)))
-					text = TruncateToWidth(text, maxTextWidth-1) + "…"
-					// Filter out link ranges that are beyond truncation point
-					var truncatedLinks []LinkRange
-					for _, lr := range linkRanges {
-						if lr.Start < truncatedLen {
-							newLink := lr
-							if lr.End > truncatedLen {
-								newLink.End = truncatedLen
-							}
-							truncatedLinks = append(truncatedLinks, newLink)
-						}
-					}
-					linkRanges = truncatedLinks
-				} else {
-					text = "…"
-					linkRanges = nil
-				}
-			}
+			text, linkRanges, displayLen := displayLine.truncatedText(maxTextWidth)
 
 			// Draw the text with link and search highlighting
 			// Links are always highlighted, search highlighting is applied only to current match
 			// Don't highlight virtual references (items shown in search nodes)
 			linkStyle := screen.TreeLinkStyle()
-			var displayLen int
 			if searchQuery != "" && currentMatchItem != nil && displayLine.Item == currentMatchItem && !displayLine.IsVirtual {
-				displayLen = tv.drawTextWithLinksAndSearch(screen, textX, y, text, linkRanges, style, highlightStyle, linkStyle, searchQuery)
+				tv.drawTextWithLinksAndSearch(screen, textX, y, text, linkRanges, displayLine.EmphasisRanges, style, highlightStyle, linkStyle, searchQuery)
 			} else {
-				displayLen = tv.drawTextWithLinksAndSearch(screen, textX, y, text, linkRanges, style, highlightStyle, linkStyle, "")
+				tv.drawTextWithLinksAndSearch(screen, textX, y, text, linkRanges, displayLine.EmphasisRanges, style, highlightStyle, linkStyle, "")
 			}
 
 			// Draw visible attributes if configured (only on item start line)
@@ -2148,9 +2685,8 @@ func (tv *TreeView) RenderWithSearchQuery(screen *Screen, startY, endY int, visu
 						screen.SetCell(barStartX-2, y, ' ', style)
 						screen.SetCell(barStartX-1, y, ' ', style)
 
-						// Draw each block with appropriate color
-						firstStatus := statuses[0]
-						lastStatus := statuses[len(statuses)-1]
+						// Draw each block with the glyph and color of its status
+						barTheme := ProgressBarThemeFromConfig(cfg, statuses)
 
 						for j, block := range blocks {
 							blockX := barStartX + j
@@ -2158,29 +2694,28 @@ func (tv *TreeView) RenderWithSearchQuery(screen *Screen, startY, endY int, visu
 								break
 							}
 
-							blockStyle := screen.GrayStyle().Background(lineBackgroundColor) // Default to gray for todo
-							switch block.Status {
-							case lastStatus:
-								blockStyle = screen.GreenStyle().Background(lineBackgroundColor) // Green for done
-							case firstStatus:
-								blockStyle = screen.GrayStyle().Background(lineBackgroundColor) // Gray for todo
-							default:
-								blockStyle = screen.OrangeStyle().Background(lineBackgroundColor) // Orange for doing/in-progress
-							}
-
-							screen.SetCell(blockX, y, '■', blockStyle)
+							char, color := barTheme.Block(block.Status, statuses)
+							blockStyle := tcell.StyleDefault.Foreground(screen.PriorityColor(color)).Background(lineBackgroundColor)
+							screen.SetCell(blockX, y, char, blockStyle)
 						}
 						totalLen = barStartX + len(blocks)
+
+						// Show the percentage done after the bar
+						if cfg.Get("showprogresspct") != "false" {
+							total, done, _ := CalculateProgressFromChildren(displayLine.Item, statuses)
+							pctStr := fmt.Sprintf(" %d%%", done*100/total)
+							if pctWidth := StringWidth(pctStr); totalLen+pctWidth <= screenWidth {
+								screen.DrawString(totalLen, y, pctStr, screen.GrayStyle().Background(lineBackgroundColor))
+								totalLen += pctWidth
+							}
+						}
 					}
 				}
 			}
 
 			// Pad to wrap width with background color on first line only
 			// Use the same wrap width that the editor uses for consistent alignment
-			wrapEndX := textX + tv.maxWidth
-			if wrapEndX > screenWidth {
-				wrapEndX = screenWidth
-			}
+			wrapEndX := min(textX+tv.WrapWidth(displayLine.Depth), screenWidth)
 			bgStyle := screen.BackgroundStyle()
 			for x := totalLen; x < wrapEndX; x++ {
 				padStyle := bgStyle
@@ -2192,14 +2727,11 @@ func (tv *TreeView) RenderWithSearchQuery(screen *Screen, startY, endY int, visu
 			}
 		} else {
 			// Align with first line's text position
-			textX := displayLine.Depth*3 + 3
+			textX := tv.TextX(displayLine.Depth)
 
 			// Calculate wrap width for continuation lines
 			// Use the same wrap width that the editor uses for consistent alignment
-			wrapEndX := textX + tv.maxWidth
-			if wrapEndX > screenWidth {
-				wrapEndX = screenWidth
-			}
+			wrapEndX := min(textX+tv.WrapWidth(displayLine.Depth), screenWidth)
 
 			// For continuation lines, fill entire wrap width with background or selection color first
 			bgStyle := screen.BackgroundStyle()
@@ -2214,27 +2746,21 @@ func (tv *TreeView) RenderWithSearchQuery(screen *Screen, startY, endY int, visu
 			if isLinePartOfSelected {
 				lineStyle = selectedStyle
 			}
-
-			// Render continuation line text with full width available
-			maxTextWidth := wrapEndX - textX
-			if maxTextWidth < 0 {
-				maxTextWidth = 0
+			if showGuides {
+				tv.drawIndentGuides(screen, y, displayLine.Depth, screen.GrayStyle().Background(lineBackgroundColor))
 			}
 
-			// Truncate with ellipsis if text exceeds max width
-			// Use StringWidth for proper Unicode-aware truncation
-			text := displayLine.TextLine
-			if StringWidth(text) > maxTextWidth {
-				// Reserve space for ellipsis (1 column)
-				if maxTextWidth > 1 {
-					text = TruncateToWidth(text, maxTextWidth-1) + "…"
-				} else {
-					text = "…"
-				}
-			}
+			// The line was wrapped at the wrap width, so it fits. Only a link
+			// that is wider than the screen is truncated.
+			text, linkRanges, _ := displayLine.truncatedText(max(screenWidth-textX, 0))
 
-			// Draw continuation line text
-			screen.DrawString(textX, y, text, lineStyle)
+			// Draw continuation line text with the same link and search highlighting as the first line
+			linkStyle := screen.TreeLinkStyle()
+			if searchQuery != "" && currentMatchItem != nil && displayLine.Item == currentMatchItem && !displayLine.IsVirtual {
+				tv.drawTextWithLinksAndSearch(screen, textX, y, text, linkRanges, displayLine.EmphasisRanges, lineStyle, highlightStyle, linkStyle, searchQuery)
+			} else {
+				tv.drawTextWithLinksAndSearch(screen, textX, y, text, linkRanges, displayLine.EmphasisRanges, lineStyle, highlightStyle, linkStyle, "")
+			}
 		}
 
 		screenY++ // Move to next screen line
@@ -2253,6 +2779,105 @@ func (tv *TreeView) GetItemCount() int {
 	return len(tv.filteredView)
 }
 
+// IsValidSortKey reports whether key can be used with SortChildren
+func IsValidSortKey(key string) bool {
+	switch key {
+	case "text", "created", "modified":
+		return true
+	}
+	return strings.HasPrefix(key, "attr:") && len(key) > len("attr:")
+}
+
+// SortChildren sorts the children of parent (or the root items when parent is nil)
+// by key: "text", "created", "modified" or "attr:<name>". The sort is stable and
+// items without the attribute always sink to the bottom. The children slice is
+// reordered in place so the new order is saved.
+func (tv *TreeView) SortChildren(parent *model.Item, key string, descending bool) {
+	tv.sortChildren(parent, key, descending, nil)
+}
+
+// SortChildrenByPriority sorts the children of parent like SortChildren with
+// key attr:priority, but in the order of levels, most important first. Items
+// without a priority from levels sink to the bottom.
+func (tv *TreeView) SortChildrenByPriority(parent *model.Item, levels []PriorityLevel, descending bool) {
+	tv.sortChildren(parent, "attr:priority", descending, levels)
+}
+
+// sortChildren sorts the children of parent by key, ranking priority values by
+// priorityLevels when it is not nil
+func (tv *TreeView) sortChildren(parent *model.Item, key string, descending bool, priorityLevels []PriorityLevel) {
+	children := tv.items
+	if parent != nil {
+		children = parent.Children
+	}
+
+	sortKey := func(item *model.Item) (string, time.Time, bool) {
+		switch key {
+		case "text":
+			return strings.ToLower(item.Text), time.Time{}, true
+		case "created":
+			if item.Metadata == nil {
+				return "", time.Time{}, false
+			}
+			return "", item.Metadata.Created, true
+		case "modified":
+			if item.Metadata == nil {
+				return "", time.Time{}, false
+			}
+			return "", item.Metadata.Modified, true
+		}
+		if priorityLevels != nil {
+			rank, ok := PriorityRank(priorityLevels, item)
+			return strconv.Itoa(rank), time.Time{}, ok
+		}
+		attrName := strings.TrimPrefix(key, "attr:")
+		if item.Metadata == nil || item.Metadata.Attributes == nil {
+			return "", time.Time{}, false
+		}
+		value, ok := item.Metadata.Attributes[attrName]
+		return value, time.Time{}, ok
+	}
+
+	slices.SortStableFunc(children, func(a, b *model.Item) int {
+		aStr, aTime, aOk := sortKey(a)
+		bStr, bTime, bOk := sortKey(b)
+
+		// Missing values sink to the bottom in both directions
+		if !aOk || !bOk {
+			switch {
+			case aOk:
+				return -1
+			case bOk:
+				return 1
+			}
+			return 0
+		}
+
+		var result int
+		if key == "created" || key == "modified" {
+			result = aTime.Compare(bTime)
+		} else {
+			result = compareSortValues(aStr, bStr)
+		}
+		if descending {
+			result = -result
+		}
+		return result
+	})
+
+	tv.RebuildView()
+}
+
+// compareSortValues compares two values numerically when both are numbers, otherwise as strings
+func compareSortValues(a, b string) int {
+	aNum, aErr := strconv.ParseFloat(a, 64)
+	bNum, bErr := strconv.ParseFloat(b, 64)
+	if aErr == nil && bErr == nil {
+		return cmp.Compare(aNum, bNum)
+	}
+	return strings.Compare(a, b)
+}
+
 // GetItemsInRange returns all items in the range from start to end index (inclusive)
 func (tv *TreeView) GetItemsInRange(start, end int) []*model.Item {
 	if start < 0 || end < 0 || start >= len(tv.filteredView) || end >= len(tv.filteredView) {
@@ -2348,6 +2973,47 @@ func (tv *TreeView) OutdentItem(item *model.Item) bool {
 	return true
 }
 
+// PromoteAboveParent swaps item with its parent: item takes the place of its
+// parent, and the parent becomes the last child of item, keeping its other
+// children. Root items and the children of the hoisted item can't be promoted.
+func (tv *TreeView) PromoteAboveParent(item *model.Item) bool {
+	if item == nil || item.Parent == nil || item.Parent == tv.hoistedItem {
+		return false
+	}
+
+	parent := item.Parent
+	grandparent := parent.Parent
+
+	// Find the place of the parent before it is moved
+	siblings := tv.items
+	if grandparent != nil {
+		siblings = grandparent.Children
+	}
+	parentIdx := -1
+	for idx, sibling := range siblings {
+		if sibling == parent {
+			parentIdx = idx
+			break
+		}
+	}
+	if parentIdx < 0 {
+		return false
+	}
+
+	parent.RemoveChild(item)
+
+	// Item takes the place of the parent
+	siblings[parentIdx] = item
+	item.Parent = grandparent
+
+	item.AddChild(parent)
+	item.Expanded = true
+
+	tv.RebuildView()
+	tv.selectDisplayedItem(item)
+	return true
+}
+
 // SelectFirst moves selection to the first item
 func (tv *TreeView) SelectFirst() {
 	tv.selectedIdx = 0
@@ -2578,36 +3244,46 @@ func (tv *TreeView) FindPrevDateItem() bool {
 	return false
 }
 
-// FindNextItemWithDateInterval finds next item within specified interval (day, week, month, year)
-// Interval: "day", "week", "month", "year"
-func (tv *TreeView) FindNextItemWithDateInterval(interval string) bool {
-	if len(tv.filteredView) == 0 || tv.selectedIdx >= len(tv.filteredView) {
-		return false
-	}
-
-	now := time.Now()
-	var targetStart, targetEnd time.Time
+// DateIntervalRange returns the start and end of the interval (day, week, month, year)
+// containing now. Weeks start on Monday. The end is exclusive.
+func DateIntervalRange(interval string, now time.Time) (time.Time, time.Time, bool) {
+	var start, end time.Time
 
 	switch interval {
 	case "day":
-		targetStart = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-		targetEnd = targetStart.AddDate(0, 0, 1)
+		start = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		end = start.AddDate(0, 0, 1)
 	case "week":
 		// Start of current week (Monday)
 		days := int(now.Weekday()) - 1
 		if days < 0 {
 			days = 6
 		}
-		targetStart = now.AddDate(0, 0, -days)
-		targetStart = time.Date(targetStart.Year(), targetStart.Month(), targetStart.Day(), 0, 0, 0, 0, targetStart.Location())
-		targetEnd = targetStart.AddDate(0, 0, 7)
+		start = now.AddDate(0, 0, -days)
+		start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+		end = start.AddDate(0, 0, 7)
 	case "month":
-		targetStart = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-		targetEnd = targetStart.AddDate(0, 1, 0)
+		start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		end = start.AddDate(0, 1, 0)
 	case "year":
-		targetStart = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
-		targetEnd = targetStart.AddDate(1, 0, 0)
+		start = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+		end = start.AddDate(1, 0, 0)
 	default:
+		return time.Time{}, time.Time{}, false
+	}
+
+	return start, end, true
+}
+
+// FindNextItemWithDateInterval finds next item within specified interval (day, week, month, year)
+// Interval: "day", "week", "month", "year"
+func (tv *TreeView) FindNextItemWithDateInterval(interval string) bool {
+	if len(tv.filteredView) == 0 || tv.selectedIdx >= len(tv.filteredView) {
+		return false
+	}
+
+	targetStart, targetEnd, ok := DateIntervalRange(interval, time.Now())
+	if !ok {
 		return false
 	}
 
@@ -2637,29 +3313,8 @@ func (tv *TreeView) FindPrevItemWithDateInterval(interval string) bool {
 		return false
 	}
 
-	now := time.Now()
-	var targetStart, targetEnd time.Time
-
-	switch interval {
-	case "day":
-		targetStart = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-		targetEnd = targetStart.AddDate(0, 0, 1)
-	case "week":
-		// Start of current week (Monday)
-		days := int(now.Weekday()) - 1
-		if days < 0 {
-			days = 6
-		}
-		targetStart = now.AddDate(0, 0, -days)
-		targetStart = time.Date(targetStart.Year(), targetStart.Month(), targetStart.Day(), 0, 0, 0, 0, targetStart.Location())
-		targetEnd = targetStart.AddDate(0, 0, 7)
-	case "month":
-		targetStart = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-		targetEnd = targetStart.AddDate(0, 1, 0)
-	case "year":
-		targetStart = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
-		targetEnd = targetStart.AddDate(1, 0, 0)
-	default:
+	targetStart, targetEnd, ok := DateIntervalRange(interval, time.Now())
+	if !ok {
 		return false
 	}
 
@@ -2895,18 +3550,54 @@ func (tv *TreeView) drawTextWithHighlight(screen *Screen, x int, y int, text str
 	}
 }
 
-// drawTextWithLinksAndSearch draws display text with link highlighting
+// truncateDisplayLine truncates text with an ellipsis when it is wider than
+// maxWidth (in display width) and drops or shortens the link ranges past the cut
+func truncateDisplayLine(text string, linkRanges []LinkRange, maxWidth int) (string, []LinkRange) {
+	if StringWidth(text) <= maxWidth {
+		return text, linkRanges
+	}
+	// Reserve space for ellipsis (1 column)
+	if maxWidth <= 1 {
+		return "…", nil
+	}
+
+	truncated := TruncateToWidth(text, maxWidth-1)
+	truncatedLen := len([]rune(truncated))
+	// Filter out link ranges that are beyond truncation point
+	var truncatedLinks []LinkRange
+	for _, lr := range linkRanges {
+		if lr.Start < truncatedLen {
+			newLink := lr
+			if lr.End > truncatedLen {
+				newLink.End = truncatedLen
+			}
+			truncatedLinks = append(truncatedLinks, newLink)
+		}
+	}
+	return truncated + "…", truncatedLinks
+}
+
+// drawTextWithLinksAndSearch draws display text with link and search highlighting
 // text should already be formatted (links converted to display text)
 // linkRanges specifies which character ranges should be styled as links
-// Returns: display text length
+// Search matches inside a link keep the link color and underline on the highlight background
+// emphasisRanges adds bold or italic to the other styling
 func (tv *TreeView) drawTextWithLinksAndSearch(screen *Screen, x int, y int, text string, linkRanges []LinkRange,
-	defaultStyle tcell.Style, highlightStyle tcell.Style, linkStyle tcell.Style, searchQuery string) int {
+	emphasisRanges []EmphasisRange, defaultStyle tcell.Style, highlightStyle tcell.Style, linkStyle tcell.Style, searchQuery string) {
 
-	// If no links and no search, just draw normally
-	if len(linkRanges) == 0 && searchQuery == "" {
+	// If no links, emphasis and search, just draw normally
+	if len(linkRanges) == 0 && len(emphasisRanges) == 0 && searchQuery == "" {
 		screen.DrawString(x, y, text, defaultStyle)
-		return StringWidth(text)
+		return
+	}
+
+	// Positions are rune indexes in the display text, like the link ranges
+	var highlighted []bool
+	if searchQuery != "" {
+		highlighted = searchHighlightMask(text, searchQuery)
 	}
+	linkFg, _, _ := linkStyle.Decompose()
+	_, highlightBg, _ := highlightStyle.Decompose()
 
 	// Draw character by character with appropriate styling
 	currentX := x
@@ -2914,34 +3605,39 @@ func (tv *TreeView) drawTextWithLinksAndSearch(screen *Screen, x int, y int, tex
 
 	for i, r := range textRunes {
 		charStyle := defaultStyle
+		isHighlighted := i < len(highlighted) && highlighted[i]
 
 		// Check if this position is in a link
 		inLink := false
 		for _, linkRange := range linkRanges {
 			if i >= linkRange.Start && i < linkRange.End {
-				// Apply link color with underline
-				fg, _, _ := linkStyle.Decompose()
-				charStyle = defaultStyle.Foreground(fg).Underline(true)
 				inLink = true
 				break
 			}
 		}
 
-		// Apply search highlighting if not in a link
-		if !inLink && searchQuery != "" {
-			lowerText := strings.ToLower(text)
-			lowerQuery := strings.ToLower(searchQuery)
-			// Simple search highlighting in display text
-			if strings.Contains(lowerText, lowerQuery) {
-				// This is simplified - ideally we'd track exact positions
-				// For now, just highlight if query is in text
-				// A more sophisticated version would track exact match positions
+		if inLink {
+			// Apply link color with underline, on the highlight background for matches
+			charStyle = defaultStyle.Foreground(linkFg).Underline(true)
+			if isHighlighted {
+				charStyle = charStyle.Background(highlightBg)
+			}
+		} else if isHighlighted {
+			charStyle = highlightStyle
+		}
+
+		for _, emphasis := range emphasisRanges {
+			if i >= emphasis.Start && i < emphasis.End {
+				if emphasis.Bold {
+					charStyle = charStyle.Bold(true)
+				}
+				if emphasis.Italic {
+					charStyle = charStyle.Italic(true)
+				}
 			}
 		}
 
 		screen.SetCell(currentX, y, r, charStyle)
 		currentX += RuneWidth(r)
 	}
-
-	return StringWidth(text)
 }
@@ -2,10 +2,14 @@ package ui
 
 import (
 	"fmt"
+	"os"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/pstuifzand/tui-outliner/internal/config"
 	"github.com/pstuifzand/tui-outliner/internal/model"
+	"github.com/pstuifzand/tui-outliner/internal/storage"
 )
 
 func TestAddItemAfter(t *testing.T) {
@@ -693,3 +697,678 @@ func TestPositionBuildingForCollapsedNodes(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildAllPositionsForItemLeavesTreeUnchanged(t *testing.T) {
+	// A
+	//   B
+	//   C
+	//     D
+	// E
+	a := model.NewItem("A")
+	b := model.NewItem("B")
+	c := model.NewItem("C")
+	d := model.NewItem("D")
+	e := model.NewItem("E")
+	c.AddChild(d)
+	a.AddChild(b)
+	a.AddChild(c)
+	a.Expanded = true
+	c.Expanded = true
+
+	tv := NewTreeView([]*model.Item{a, e})
+	rootItems := tv.items
+	aChildren := a.Children
+
+	format := func(positions []Position) string {
+		var parts []string
+		for _, p := range positions {
+			parent := "root"
+			if p.Parent != nil {
+				parent = p.Parent.Text
+			}
+			parts = append(parts, fmt.Sprintf("(%s,%d)", parent, p.Index))
+		}
+		return strings.Join(parts, " ")
+	}
+
+	tests := []struct {
+		item *model.Item
+		want string
+	}{
+		// Positions after B are numbered as if B was removed
+		{b, "(root,0) (A,0) (C,0) (C,1) (A,1) (root,1) (root,2)"},
+		// D is left out with C
+		{c, "(root,0) (A,0) (A,1) (root,1) (root,2)"},
+		// C has no positions as its only child is left out
+		{d, "(root,0) (A,0) (A,1) (A,2) (root,1) (root,2)"},
+		{e, "(root,0) (A,0) (A,1) (C,0) (C,1) (A,2) (root,1)"},
+	}
+	for _, tt := range tests {
+		// Building the positions twice gives the same positions
+		for range 2 {
+			if got := format(tv.buildAllPositionsForItem(tt.item)); got != tt.want {
+				t.Errorf("Positions for %s: expected %s, got %s", tt.item.Text, tt.want, got)
+			}
+		}
+	}
+
+	// The slices are not changed, not even temporarily in their backing arrays
+	if !slices.Equal(rootItems, []*model.Item{a, e}) || &tv.items[0] != &rootItems[0] {
+		t.Errorf("Root items changed")
+	}
+	if !slices.Equal(aChildren, []*model.Item{b, c}) || &a.Children[0] != &aChildren[0] {
+		t.Errorf("Children of A changed")
+	}
+	if len(c.Children) != 1 || c.Children[0] != d || d.Parent != c {
+		t.Errorf("Children of C changed")
+	}
+}
+
+func TestMoveItemWhileHoistedKeepsItems(t *testing.T) {
+	// The hoisted items share their backing array with the children of the
+	// hoisted item. Building positions must not shift them.
+	a := model.NewItem("A")
+	b := model.NewItem("B")
+	c := model.NewItem("C")
+	d := model.NewItem("D")
+	a.AddChild(b)
+	a.AddChild(c)
+	a.AddChild(d)
+
+	tv := NewTreeView([]*model.Item{a})
+	if !tv.Hoist() {
+		t.Fatal("Expected to hoist A")
+	}
+	tv.SelectItemByID(b.ID)
+	tv.MoveItemDown()
+
+	var got []string
+	for _, item := range tv.items {
+		got = append(got, item.Text)
+	}
+	for _, item := range a.Children {
+		got = append(got, item.Text)
+	}
+	if want := "[B C D B C D]"; fmt.Sprint(got) != want {
+		t.Errorf("Expected the hoisted items and the children of A to be B C D, got %v", got)
+	}
+}
+
+func TestCollapseToLevel(t *testing.T) {
+	// A
+	//   B
+	//     C
+	//       D
+	a := model.NewItem("A")
+	b := model.NewItem("B")
+	c := model.NewItem("C")
+	d := model.NewItem("D")
+	c.AddChild(d)
+	b.AddChild(c)
+	a.AddChild(b)
+
+	tv := NewTreeView([]*model.Item{a})
+	tv.ExpandRecursive()
+
+	// Select the deepest item, then fold so it is hidden
+	tv.SelectItemByID(d.ID)
+	tv.CollapseToLevel(2)
+
+	if !a.Expanded || !b.Expanded || c.Expanded {
+		t.Errorf("Unexpected expanded state: A=%v B=%v C=%v", a.Expanded, b.Expanded, c.Expanded)
+	}
+	if len(tv.filteredView) != 3 {
+		t.Errorf("Expected 3 visible items, got %d", len(tv.filteredView))
+	}
+	if selected := tv.GetSelected(); selected != c {
+		t.Errorf("Expected selection on nearest visible ancestor C, got %v", selected)
+	}
+
+	tv.CollapseToLevel(0)
+	if len(tv.filteredView) != 1 {
+		t.Errorf("Expected only the root to be visible, got %d", len(tv.filteredView))
+	}
+	if selected := tv.GetSelected(); selected != a {
+		t.Errorf("Expected selection on root A, got %v", selected)
+	}
+}
+
+func TestCollapseToLevelWhenHoisted(t *testing.T) {
+	root := model.NewItem("Root")
+	child := model.NewItem("Child")
+	grandchild := model.NewItem("Grandchild")
+	child.AddChild(grandchild)
+	root.AddChild(child)
+
+	tv := NewTreeView([]*model.Item{root})
+	tv.ExpandRecursive()
+	tv.SelectItemByID(root.ID)
+	if !tv.Hoist() {
+		t.Fatal("Hoist failed")
+	}
+
+	// Level 1 is measured from the hoisted root's children
+	tv.CollapseToLevel(1)
+	if !child.Expanded {
+		t.Error("Expected child of hoisted item to be expanded at level 1")
+	}
+	if len(tv.filteredView) != 2 {
+		t.Errorf("Expected 2 visible items, got %d", len(tv.filteredView))
+	}
+}
+
+func TestSortChildren(t *testing.T) {
+	parent := model.NewItem("Parent")
+	banana := model.NewItem("banana")
+	banana.Metadata.Attributes["priority"] = "2"
+	apple := model.NewItem("Apple")
+	apple.Metadata.Attributes["priority"] = "10"
+	cherry := model.NewItem("cherry")
+	date := model.NewItem("date")
+	date.Metadata.Attributes["priority"] = "1"
+	parent.AddChild(banana)
+	parent.AddChild(apple)
+	parent.AddChild(cherry)
+	parent.AddChild(date)
+	parent.Expanded = true
+
+	tv := NewTreeView([]*model.Item{parent})
+
+	childTexts := func() []string {
+		var texts []string
+		for _, child := range parent.Children {
+			texts = append(texts, child.Text)
+		}
+		return texts
+	}
+
+	tests := []struct {
+		key        string
+		descending bool
+		expected   []string
+	}{
+		{"text", false, []string{"Apple", "banana", "cherry", "date"}},
+		{"text", true, []string{"date", "cherry", "banana", "Apple"}},
+		// Numeric attribute values compare as numbers, missing values sink
+		{"attr:priority", false, []string{"date", "banana", "Apple", "cherry"}},
+		{"attr:priority", true, []string{"Apple", "banana", "date", "cherry"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s desc=%v", tt.key, tt.descending), func(t *testing.T) {
+			tv.SortChildren(parent, tt.key, tt.descending)
+			got := childTexts()
+			if fmt.Sprint(got) != fmt.Sprint(tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	// The view follows the new order
+	if tv.filteredView[1].Item != parent.Children[0] {
+		t.Error("Expected view to be rebuilt after sorting")
+	}
+}
+
+func TestSortChildrenIsStable(t *testing.T) {
+	first := model.NewItem("first")
+	second := model.NewItem("second")
+	third := model.NewItem("third")
+	third.Metadata.Attributes["status"] = "done"
+
+	items := []*model.Item{first, second, third}
+	tv := NewTreeView(items)
+
+	// Items without the attribute keep their relative order at the bottom
+	tv.SortChildren(nil, "attr:status", false)
+	if items[0] != third || items[1] != first || items[2] != second {
+		t.Errorf("Expected stable order [third first second], got [%s %s %s]", items[0].Text, items[1].Text, items[2].Text)
+	}
+}
+
+func TestIsValidSortKey(t *testing.T) {
+	for _, key := range []string{"text", "created", "modified", "attr:priority"} {
+		if !IsValidSortKey(key) {
+			t.Errorf("Expected %q to be a valid sort key", key)
+		}
+	}
+	for _, key := range []string{"", "attr:", "size"} {
+		if IsValidSortKey(key) {
+			t.Errorf("Expected %q to be an invalid sort key", key)
+		}
+	}
+}
+
+func TestScrollSelectionPositions(t *testing.T) {
+	var items []*model.Item
+	for i := range 20 {
+		items = append(items, model.NewItem(fmt.Sprintf("item %d", i)))
+	}
+	tv := NewTreeView(items)
+
+	tv.SelectItem(10)
+	tv.CenterSelection(6)
+	if got := tv.GetViewportOffset(); got != 7 {
+		t.Errorf("Expected zz to put item 10 in row 3 (offset 7), got offset %d", got)
+	}
+
+	tv.ScrollSelectionToTop(6)
+	if got := tv.GetViewportOffset(); got != 10 {
+		t.Errorf("Expected zt offset 10, got %d", got)
+	}
+
+	tv.ScrollSelectionToBottom(6)
+	if got := tv.GetViewportOffset(); got != 5 {
+		t.Errorf("Expected zb offset 5, got %d", got)
+	}
+
+	// Never scroll past the end of the display lines
+	tv.SelectItem(18)
+	tv.ScrollSelectionToTop(6)
+	if got := tv.GetViewportOffset(); got != 14 {
+		t.Errorf("Expected zt near the end to clamp to offset 14, got %d", got)
+	}
+
+	// Or before the start
+	tv.SelectItem(1)
+	tv.CenterSelection(6)
+	if got := tv.GetViewportOffset(); got != 0 {
+		t.Errorf("Expected zz near the start to clamp to offset 0, got %d", got)
+	}
+}
+
+func TestJoinWithNext(t *testing.T) {
+	first := model.NewItem("First part")
+	second := model.NewItem("second part")
+	grandchild := model.NewItem("Detail")
+	second.AddChild(grandchild)
+	last := model.NewItem("Last")
+
+	items := []*model.Item{first, second, last}
+	tv := NewTreeView(items)
+	tv.SelectItem(0)
+
+	if !tv.JoinWithNext() {
+		t.Fatal("JoinWithNext failed")
+	}
+
+	if first.Text != "First part second part" {
+		t.Errorf("Expected joined text, got %q", first.Text)
+	}
+	roots := tv.GetItems()
+	if len(roots) != 2 || roots[0] != first || roots[1] != last {
+		t.Fatalf("Expected the joined sibling to be removed, got %d root items", len(roots))
+	}
+	if len(first.Children) != 1 || first.Children[0] != grandchild || grandchild.Parent != first {
+		t.Errorf("Expected children to be moved to the joined item")
+	}
+	if selected := tv.GetSelected(); selected != first {
+		t.Errorf("Expected joined item to stay selected, got %v", selected)
+	}
+
+	// The last sibling has nothing to join with
+	tv.SelectItemByID(last.ID)
+	if tv.JoinWithNext() {
+		t.Error("Expected JoinWithNext to fail without a next sibling")
+	}
+}
+
+func TestMoveToTopAndBottomOfSiblings(t *testing.T) {
+	parent := model.NewItem("Parent")
+	for _, text := range []string{"a", "b", "c", "d"} {
+		parent.AddChild(model.NewItem(text))
+	}
+	parent.Expanded = true
+	other := model.NewItem("Other")
+
+	tv := NewTreeView([]*model.Item{parent, other})
+	c := parent.Children[2]
+	tv.SelectItemByID(c.ID)
+
+	if !tv.MoveToTopOfSiblings() {
+		t.Fatal("MoveToTopOfSiblings failed")
+	}
+	if got := itemTextsOf(parent.Children); got != "c a b d" {
+		t.Errorf("Expected [c a b d], got [%s]", got)
+	}
+	if tv.GetSelected() != c {
+		t.Error("Expected selection to follow the moved item")
+	}
+	if tv.MoveToTopOfSiblings() {
+		t.Error("Expected no move for the first sibling")
+	}
+
+	if !tv.MoveToBottomOfSiblings() {
+		t.Fatal("MoveToBottomOfSiblings failed")
+	}
+	if got := itemTextsOf(parent.Children); got != "a b d c" {
+		t.Errorf("Expected [a b d c], got [%s]", got)
+	}
+	if c.Parent != parent || len(tv.GetItems()) != 2 {
+		t.Error("Expected the item to stay within its parent")
+	}
+}
+
+func TestSwapWithSiblings(t *testing.T) {
+	parent := model.NewItem("Parent")
+	for _, text := range []string{"a", "b", "c"} {
+		parent.AddChild(model.NewItem(text))
+	}
+	parent.Expanded = true
+	// A collapsed previous sibling with children, MoveItemUp would move into it
+	a := parent.Children[0]
+	a.AddChild(model.NewItem("a1"))
+
+	tv := NewTreeView([]*model.Item{parent})
+	b := parent.Children[1]
+	tv.SelectItemByID(b.ID)
+
+	if !tv.SwapWithPrevSibling() {
+		t.Fatal("SwapWithPrevSibling failed")
+	}
+	if got := itemTextsOf(parent.Children); got != "b a c" {
+		t.Errorf("Expected [b a c], got [%s]", got)
+	}
+	if tv.GetSelected() != b || b.Parent != parent {
+		t.Error("Expected selection to follow the swapped item at the same depth")
+	}
+	if tv.SwapWithPrevSibling() {
+		t.Error("Expected no swap for the first sibling")
+	}
+
+	if !tv.SwapWithNextSibling() || !tv.SwapWithNextSibling() {
+		t.Fatal("SwapWithNextSibling failed")
+	}
+	if got := itemTextsOf(parent.Children); got != "a c b" {
+		t.Errorf("Expected [a c b], got [%s]", got)
+	}
+	if tv.SwapWithNextSibling() {
+		t.Error("Expected no swap for the last sibling")
+	}
+}
+
+func itemTextsOf(items []*model.Item) string {
+	var texts []string
+	for _, item := range items {
+		texts = append(texts, item.Text)
+	}
+	return strings.Join(texts, " ")
+}
+
+func TestMoveItemTo(t *testing.T) {
+	source := model.NewItem("Source")
+	for _, text := range []string{"a", "b"} {
+		source.AddChild(model.NewItem(text))
+	}
+	source.Expanded = true
+	target := model.NewItem("Target")
+	for _, text := range []string{"x", "y", "z"} {
+		target.AddChild(model.NewItem(text))
+	}
+
+	tv := NewTreeView([]*model.Item{source, target})
+	b := source.Children[1]
+	tv.SelectItemByID(b.ID)
+
+	if !tv.MoveItemTo(b, target, 1) {
+		t.Fatal("MoveItemTo failed")
+	}
+	if got := itemTextsOf(target.Children); got != "x b y z" {
+		t.Errorf("Expected [x b y z], got [%s]", got)
+	}
+	if b.Parent != target || !target.Expanded {
+		t.Errorf("Expected b under the expanded target")
+	}
+	if selected := tv.GetSelected(); selected != b {
+		t.Errorf("Expected the moved item to stay selected, got %v", selected)
+	}
+
+	// The index is clamped, a root item can be moved too
+	if !tv.MoveItemTo(source, target, 99) {
+		t.Fatal("MoveItemTo with large index failed")
+	}
+	if got := itemTextsOf(target.Children); got != "x b y z Source" {
+		t.Errorf("Expected Source to be appended, got [%s]", got)
+	}
+	if roots := tv.GetItems(); len(roots) != 1 || roots[0] != target {
+		t.Errorf("Expected Source to be removed from the root items")
+	}
+
+	// Moving an item into its own subtree is refused
+	if tv.MoveItemTo(target, source, 0) || tv.MoveItemTo(target, target, 0) {
+		t.Error("Expected circular moves to be refused")
+	}
+}
+
+func TestMoveItemToWhileHoisted(t *testing.T) {
+	root := model.NewItem("Root")
+	for _, text := range []string{"a", "b", "c"} {
+		root.AddChild(model.NewItem(text))
+	}
+	a, b, c := root.Children[0], root.Children[1], root.Children[2]
+
+	tv := NewTreeView([]*model.Item{root})
+	tv.SelectItemByID(root.ID)
+	if !tv.Hoist() {
+		t.Fatal("Expected to hoist Root")
+	}
+
+	// Out of the hoisted item into a sibling
+	if !tv.MoveItemTo(c, a, 0) {
+		t.Fatal("MoveItemTo failed")
+	}
+	if got := itemTextsOf(tv.items); got != "a b" {
+		t.Errorf("Expected the hoisted items [a b], got [%s]", got)
+	}
+	if len(tv.filteredView) != 3 {
+		t.Errorf("Expected a, c and b to be visible once, got %d items", len(tv.filteredView))
+	}
+
+	// Back into the hoisted item
+	if !tv.MoveItemTo(c, root, 1) {
+		t.Fatal("MoveItemTo failed")
+	}
+	if got := itemTextsOf(tv.items); got != "a c b" {
+		t.Errorf("Expected the hoisted items [a c b], got [%s]", got)
+	}
+	if got := itemTextsOf(root.Children); got != "a c b" {
+		t.Errorf("Expected the children of Root [a c b], got [%s]", got)
+	}
+	if b.Parent != root || len(a.Children) != 0 {
+		t.Error("Expected c to have left a")
+	}
+}
+
+func TestExpandAndCollapseSubtree(t *testing.T) {
+	// A
+	//   B
+	//     C
+	// D
+	//   E
+	a := model.NewItem("A")
+	b := model.NewItem("B")
+	c := model.NewItem("C")
+	d := model.NewItem("D")
+	e := model.NewItem("E")
+	b.AddChild(c)
+	a.AddChild(b)
+	d.AddChild(e)
+
+	tv := NewTreeView([]*model.Item{a, d})
+	tv.SelectItemByID(a.ID)
+
+	tv.ExpandSubtree(a, false)
+	if !a.Expanded || !b.Expanded || d.Expanded {
+		t.Errorf("Expected only A and B to be expanded: A=%v B=%v D=%v", a.Expanded, b.Expanded, d.Expanded)
+	}
+	if selected := tv.GetSelected(); selected != a {
+		t.Errorf("Expected selection to stay on A, got %v", selected)
+	}
+
+	tv.CollapseSubtree(a)
+	tv.ExpandSubtree(a, true)
+	if selected := tv.GetSelected(); selected != b {
+		t.Errorf("Expected selection on first child B, got %v", selected)
+	}
+
+	tv.ExpandSubtree(d, false)
+	tv.SelectItemByID(a.ID)
+	tv.CollapseSubtree(a)
+	if a.Expanded || b.Expanded || !d.Expanded {
+		t.Errorf("Expected only D to stay expanded: A=%v B=%v D=%v", a.Expanded, b.Expanded, d.Expanded)
+	}
+	if selected := tv.GetSelected(); selected != a {
+		t.Errorf("Expected selection to stay on A, got %v", selected)
+	}
+}
+
+func TestPromoteAboveParent(t *testing.T) {
+	// A
+	//   B
+	//     C
+	//   D
+	// E
+	a := model.NewItem("A")
+	b := model.NewItem("B")
+	c := model.NewItem("C")
+	d := model.NewItem("D")
+	e := model.NewItem("E")
+	b.AddChild(c)
+	a.AddChild(b)
+	a.AddChild(d)
+
+	tv := NewTreeView([]*model.Item{a, e})
+	if tv.PromoteAboveParent(a) {
+		t.Error("Expected a root item not to be promoted")
+	}
+
+	// The parent is a root item, so B becomes a root item
+	tv.ExpandRecursive()
+	tv.SelectItemByID(b.ID)
+	if !tv.PromoteAboveParent(b) {
+		t.Fatal("Expected B to be promoted")
+	}
+	items := tv.GetItems()
+	if len(items) != 2 || items[0] != b || items[1] != e || b.Parent != nil {
+		t.Fatalf("Expected B in the place of A at the root, got %v", items)
+	}
+	if len(b.Children) != 2 || b.Children[0] != c || b.Children[1] != a || a.Parent != b {
+		t.Errorf("Expected C and A as children of B, got %v", b.Children)
+	}
+	if len(a.Children) != 1 || a.Children[0] != d || d.Parent != a {
+		t.Errorf("Expected A to keep D, got %v", a.Children)
+	}
+	if selected := tv.GetSelected(); selected != b {
+		t.Errorf("Expected selection to stay on B, got %v", selected)
+	}
+
+	// D takes the place of A under B
+	if !tv.PromoteAboveParent(d) {
+		t.Fatal("Expected D to be promoted")
+	}
+	if b.Children[1] != d || d.Parent != b || a.Parent != d || len(a.Children) != 0 {
+		t.Errorf("Expected D under B with A as its child, got %v", b.Children)
+	}
+}
+
+func TestParentTodoStatus(t *testing.T) {
+	statuses := []string{"todo", "doing", "review", "testing", "done"}
+	for _, tc := range []struct {
+		children []string
+		want     string
+	}{
+		{[]string{"todo", "todo"}, "todo"},
+		{[]string{"done", "done"}, "done"},
+		{[]string{"todo", "done"}, "doing"},
+		{[]string{"review", "done"}, "review"},
+		{[]string{"testing", "done", "review"}, "review"},
+		{[]string{"testing", "done"}, "testing"},
+		{[]string{"doing", "testing"}, "doing"},
+		{[]string{"unknown", "done"}, "doing"},
+	} {
+		parent := model.NewItem("Parent")
+		parent.Metadata.Attributes["type"] = "todo"
+		for _, status := range tc.children {
+			child := model.NewItem("Child")
+			child.Metadata.Attributes["type"] = "todo"
+			child.Metadata.Attributes["status"] = status
+			parent.AddChild(child)
+		}
+		if got, ok := ParentTodoStatus(parent, statuses); !ok || got != tc.want {
+			t.Errorf("ParentTodoStatus(%v) = %q, %v, want %q", tc.children, got, ok, tc.want)
+		}
+	}
+
+	// Updating a child propagates to todo ancestors
+	root := model.NewItem("Root")
+	root.Metadata.Attributes["type"] = "todo"
+	parent := model.NewItem("Parent")
+	parent.Metadata.Attributes["type"] = "todo"
+	root.AddChild(parent)
+	child := model.NewItem("Child")
+	child.Metadata.Attributes["type"] = "todo"
+	child.Metadata.Attributes["status"] = "review"
+	parent.AddChild(child)
+	UpdateParentStatusIfTodo(child, statuses)
+	if parent.Metadata.Attributes["status"] != "review" || root.Metadata.Attributes["status"] != "review" {
+		t.Errorf("Expected parent and root in review, got %q and %q",
+			parent.Metadata.Attributes["status"], root.Metadata.Attributes["status"])
+	}
+	if _, ok := ParentTodoStatus(child, statuses); ok {
+		t.Error("Expected no status for an item without todo children")
+	}
+}
+
+// benchmarkOutlineItems returns the root items of TUO_BENCH_FILE, or of a
+// generated outline of about 100000 items
+func benchmarkOutlineItems(b *testing.B) []*model.Item {
+	if path := os.Getenv("TUO_BENCH_FILE"); path != "" {
+		outline, err := storage.NewJSONStore(path).Load()
+		if err != nil {
+			b.Fatalf("Failed to load %s: %v", path, err)
+		}
+		return outline.Items
+	}
+
+	var roots []*model.Item
+	for i := range 1000 {
+		root := model.NewItem(fmt.Sprintf("Root %d", i))
+		for j := range 10 {
+			child := model.NewItem(fmt.Sprintf("Child %d.%d", i, j))
+			for k := range 9 {
+				child.AddChild(model.NewItem(fmt.Sprintf("Grandchild %d.%d.%d", i, j, k)))
+			}
+			root.AddChild(child)
+		}
+		roots = append(roots, root)
+	}
+	return roots
+}
+
+// setExpanded sets the expanded state of items and all their descendants
+func setExpanded(items []*model.Item, expanded bool) {
+	for _, item := range items {
+		item.Expanded = expanded
+		setExpanded(item.Children, expanded)
+	}
+}
+
+// The view of a collapsed outline only costs its visible items, however large
+// the collapsed subtrees are
+func BenchmarkNewTreeViewCollapsed(b *testing.B) {
+	items := benchmarkOutlineItems(b)
+	setExpanded(items, false)
+	b.ReportAllocs()
+	for b.Loop() {
+		NewTreeView(items)
+	}
+}
+
+func BenchmarkNewTreeViewExpanded(b *testing.B) {
+	items := benchmarkOutlineItems(b)
+	setExpanded(items, true)
+	b.ReportAllocs()
+	for b.Loop() {
+		NewTreeView(items)
+	}
+}
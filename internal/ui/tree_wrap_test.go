@@ -1,7 +1,14 @@
 package ui
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/pstuifzand/tui-outliner/internal/config"
+	"github.com/pstuifzand/tui-outliner/internal/model"
+	"github.com/pstuifzand/tui-outliner/internal/theme"
 )
 
 func TestWrapTextWithLinks_PreservesLinks(t *testing.T) {
@@ -128,3 +135,225 @@ func TestConvertLinksToDisplayText(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderWrapsDeepCJKTextWithoutTruncating(t *testing.T) {
+	sim := tcell.NewSimulationScreen("")
+	if err := sim.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Fini()
+	sim.SetSize(30, 10)
+	screen := &Screen{tcellScreen: sim, width: 30, height: 10, Theme: theme.Default()}
+
+	// At depth 3 the text starts at column 12, leaving 18 columns, less than
+	// the minimum wrap width of 20
+	text := "日本語のテキストを折り返して表示するテストです"
+	root := model.NewItem("Root")
+	parent := root
+	for _, name := range []string{"A", "B"} {
+		child := model.NewItem(name)
+		parent.AddChild(child)
+		parent.Expanded = true
+		parent = child
+	}
+	deep := model.NewItem(text)
+	parent.AddChild(deep)
+	parent.Expanded = true
+
+	tv := NewTreeView([]*model.Item{root})
+	tv.RenderWithSearchQuery(screen, 0, 10, -1, "", nil, nil)
+
+	var wrapped strings.Builder
+	for _, line := range tv.displayLines {
+		if line.Item != deep {
+			continue
+		}
+		if width := StringWidth(line.TextLine); width > 18 {
+			t.Errorf("Wrapped line %q is %d columns wide, only 18 fit", line.TextLine, width)
+		}
+		wrapped.WriteString(line.TextLine)
+	}
+	if wrapped.String() != text {
+		t.Errorf("Expected the wrapped lines to hold the whole text, got %q", wrapped.String())
+	}
+
+	for y := range 10 {
+		for x := range 30 {
+			if r, _, _, _ := sim.GetContent(x, y); r == '…' {
+				t.Fatalf("Unexpected ellipsis at %d,%d", x, y)
+			}
+		}
+	}
+}
+
+func TestRenderIndentWidthAndGuides(t *testing.T) {
+	sim := tcell.NewSimulationScreen("")
+	if err := sim.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Fini()
+	sim.SetSize(40, 10)
+	screen := &Screen{tcellScreen: sim, width: 40, height: 10, Theme: theme.Default()}
+
+	root := model.NewItem("Root")
+	child := model.NewItem("Child")
+	root.AddChild(child)
+	root.Expanded = true
+	deep := model.NewItem("Deep text that wraps onto a second line here")
+	child.AddChild(deep)
+	child.Expanded = true
+
+	cfg := &config.Config{}
+	cfg.Set("indentwidth", "2")
+	cfg.Set("indentguides", "true")
+	tv := NewTreeView([]*model.Item{root})
+	tv.RenderWithSearchQuery(screen, 0, 10, -1, "", nil, cfg)
+	sim.Show()
+
+	if tv.IndentWidth() != 2 || tv.TextX(2) != 7 {
+		t.Fatalf("Expected indent width 2 and text at column 7, got %d and %d", tv.IndentWidth(), tv.TextX(2))
+	}
+	// The wrap width leaves room for six levels of indentation at this width
+	if got := tv.WrapWidth(2); got != 40-tv.TextX(6) {
+		t.Errorf("Expected wrap width %d, got %d", 40-tv.TextX(6), got)
+	}
+
+	cell := func(x, y int) rune {
+		r, _, _, _ := sim.GetContent(x, y)
+		return r
+	}
+	if cell(4, 2) != '▶' || cell(7, 2) != 'D' {
+		t.Errorf("Expected the deep item's arrow at 4 and text at 7, got %q and %q", cell(4, 2), cell(7, 2))
+	}
+	if cell(0, 1) != '│' || cell(0, 2) != '│' || cell(2, 2) != '│' || cell(1, 2) != ' ' {
+		t.Errorf("Expected guides at columns 0 and 2, got %q%q%q", cell(0, 2), cell(1, 2), cell(2, 2))
+	}
+	if cell(0, 0) == '│' {
+		t.Error("Expected no guide for a root item")
+	}
+
+	// Without the settings the default width is used and no guides are drawn
+	tv.RenderWithSearchQuery(screen, 0, 10, -1, "", nil, nil)
+	sim.Show()
+	if tv.TextX(2) != 9 || cell(0, 2) == '│' || cell(6, 2) != '▶' {
+		t.Errorf("Expected the default indentation without guides, text at %d", tv.TextX(2))
+	}
+}
+
+func TestRawLinks(t *testing.T) {
+	item := model.NewItem("See [[abc|the notes]] and [[déf]] now")
+	tv := NewTreeView([]*model.Item{item})
+
+	line := tv.displayLines[0]
+	if line.TextLine != "See the notes and déf now" {
+		t.Errorf("Expected display text, got %q", line.TextLine)
+	}
+
+	tv.SetRawLinks(true)
+	line = tv.displayLines[0]
+	if line.TextLine != item.Text {
+		t.Errorf("Expected the raw text, got %q", line.TextLine)
+	}
+	runes := []rune(line.TextLine)
+	var got []string
+	for _, r := range line.LinkRanges {
+		got = append(got, r.ID+"="+string(runes[r.Start:r.End]))
+	}
+	if want := "[abc=[[abc|the notes]] déf=[[déf]]]"; fmt.Sprint(got) != want {
+		t.Errorf("Expected link ranges %s, got %v", want, got)
+	}
+
+	tv.SetRawLinks(false)
+	if tv.displayLines[0].TextLine != "See the notes and déf now" {
+		t.Errorf("Expected display text again, got %q", tv.displayLines[0].TextLine)
+	}
+}
+
+func TestRebuildViewWrapCache(t *testing.T) {
+	item := model.NewItem("日本語のテキスト [[abc|リンク]] と *絵文字* 😀👍 を折り返す")
+	other := model.NewItem("日本語のテキスト [[abc|リンク]] と *絵文字* 😀👍 を折り返す")
+	tv := NewTreeView([]*model.Item{item, other})
+	tv.SetRenderEmphasis(true)
+	tv.SetMaxWidth(12)
+
+	// The cached lines are the lines of a tree view without a cache
+	check := func(name string) {
+		t.Helper()
+		fresh := NewTreeView(tv.items)
+		fresh.SetRenderEmphasis(true)
+		fresh.SetMaxWidth(tv.maxWidth)
+		if len(tv.displayLines) != len(fresh.displayLines) {
+			t.Fatalf("%s: expected %d lines, got %d", name, len(fresh.displayLines), len(tv.displayLines))
+		}
+		for i, line := range tv.displayLines {
+			want := fresh.displayLines[i]
+			if line.Item != want.Item || line.TextLine != want.TextLine || line.Width != StringWidth(want.TextLine) ||
+				fmt.Sprint(line.LinkRanges, line.EmphasisRanges) != fmt.Sprint(want.LinkRanges, want.EmphasisRanges) {
+				t.Errorf("%s: line %d is %+v, expected %+v", name, i, *line, *want)
+			}
+		}
+	}
+
+	check("wrapped")
+	if len(tv.wrapCache) != 1 {
+		t.Errorf("Expected the equal texts to share a cache entry, got %d entries", len(tv.wrapCache))
+	}
+
+	tv.RebuildView()
+	check("rebuilt")
+
+	item.Text = "変更された [[abc|テキスト]]\n二行目"
+	tv.RebuildView()
+	check("edited")
+	if len(tv.wrapCache) != 3 {
+		t.Errorf("Expected the old text to drop out of the cache, got %d entries", len(tv.wrapCache))
+	}
+
+	tv.SetMaxWidth(6)
+	check("narrower")
+}
+
+// wideTextTree returns a tree view of expanded items with long CJK and emoji
+// text and links, wrapped for a terminal 200 columns wide
+func wideTextTree(b *testing.B) (*TreeView, *Screen) {
+	sim := tcell.NewSimulationScreen("")
+	if err := sim.Init(); err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(sim.Fini)
+	sim.SetSize(200, 60)
+	screen := &Screen{tcellScreen: sim, width: 200, height: 60, Theme: theme.Default()}
+
+	var roots []*model.Item
+	for i := range 200 {
+		root := model.NewItem(fmt.Sprintf("%d 日本語のテキストを折り返して表示する 🎉 [[item_%d|リンク]] ", i, i) + strings.Repeat("絵文字 😀👍 と漢字 ", 20))
+		root.Expanded = true
+		for j := range 9 {
+			root.AddChild(model.NewItem(fmt.Sprintf("Child %d 子供 🌸 ", j) + strings.Repeat("テキスト ✨ ", 15)))
+		}
+		roots = append(roots, root)
+	}
+
+	tv := NewTreeView(roots)
+	tv.SetWrapWidth(200-tv.TextX(6), 200)
+	return tv, screen
+}
+
+// RebuildView runs after every structural change, like moving or expanding
+// an item. The text of most items is unchanged.
+func BenchmarkRebuildViewWideText(b *testing.B) {
+	tv, _ := wideTextTree(b)
+	b.ReportAllocs()
+	for b.Loop() {
+		tv.RebuildView()
+	}
+}
+
+// Render runs on every frame, with the display lines of the last RebuildView
+func BenchmarkRenderWideText(b *testing.B) {
+	tv, screen := wideTextTree(b)
+	b.ReportAllocs()
+	for b.Loop() {
+		tv.RenderWithSearchQuery(screen, 0, 60, -1, "", nil, nil)
+	}
+}
@@ -1,15 +1,22 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/pstuifzand/tui-outliner/internal/app"
+	"github.com/pstuifzand/tui-outliner/internal/config"
 	"github.com/pstuifzand/tui-outliner/internal/export"
+	import_parser "github.com/pstuifzand/tui-outliner/internal/import"
+	"github.com/pstuifzand/tui-outliner/internal/links"
 	"github.com/pstuifzand/tui-outliner/internal/model"
 	"github.com/pstuifzand/tui-outliner/internal/search"
 	"github.com/pstuifzand/tui-outliner/internal/socket"
@@ -38,6 +45,27 @@ func main() {
 		case "search":
 			handleSearchCommand()
 			return
+		case "stats":
+			handleStatsCommand()
+			return
+		case "todo":
+			handleTodoCommand()
+			return
+		case "dump":
+			handleDumpCommand()
+			return
+		case "watch":
+			handleWatchCommand()
+			return
+		case "merge":
+			handleMergeCommand()
+			return
+		case "hash":
+			handleHashCommand()
+			return
+		case "completion":
+			handleCompletionCommand()
+			return
 		case "help", "--help", "-h":
 			printUsage()
 			return
@@ -91,26 +119,38 @@ func handleAddCommand() {
 	var todoFlag bool
 	var runningFlag bool
 	var fileFlag string
+	var parentFlag string
+	var parentQueryFlag string
+	var stdinFlag bool
 	addCmd := flag.NewFlagSet("add", flag.ExitOnError)
 	addCmd.Var(&attrs, "attr", "Set an attribute (key=value, can be used multiple times)")
 	addCmd.Var(&attrs, "a", "Set an attribute (key=value, shorthand)")
 	addCmd.BoolVar(&todoFlag, "t", false, "Add as a todo item (sets type=todo)")
 	addCmd.BoolVar(&runningFlag, "r", false, "Add to running tuo instance")
 	addCmd.StringVar(&fileFlag, "f", "", "Add to file")
+	addCmd.StringVar(&parentFlag, "parent", "", "Add under the item with this ID instead of the inbox")
+	addCmd.StringVar(&parentQueryFlag, "parent-query", "", "Add under the first item matching this query instead of the inbox")
+	addCmd.BoolVar(&stdinFlag, "stdin", false, "Add a node for every line of standard input, in one request (with -r)")
 	addCmd.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: tuo add [options] <text>\n")
+		fmt.Fprintf(os.Stderr, "       tuo add -r --stdin [options] < lines.txt\n")
 		fmt.Fprintf(os.Stderr, "Add a node to the inbox of a running tuo instance or to a file\n\n")
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		fmt.Fprintf(os.Stderr, "  -r                      Add to running tuo instance\n")
 		fmt.Fprintf(os.Stderr, "  -f file                 Add to file\n")
 		fmt.Fprintf(os.Stderr, "  -a, --attr key=value    Set an attribute (can be used multiple times)\n")
-		fmt.Fprintf(os.Stderr, "  -t                      Add as todo item (sets type=todo)\n\n")
+		fmt.Fprintf(os.Stderr, "  -t                      Add as todo item (sets type=todo)\n")
+		fmt.Fprintf(os.Stderr, "  --parent id             Add under the item with this ID instead of the inbox\n")
+		fmt.Fprintf(os.Stderr, "  --parent-query query    Add under the first item matching the query\n")
+		fmt.Fprintf(os.Stderr, "  --stdin                 Add every line of standard input, indented lines become children (with -r)\n\n")
 		fmt.Fprintf(os.Stderr, "Examples:\n")
 		fmt.Fprintf(os.Stderr, "  tuo add -r \"Buy milk\"                         # Add to running instance\n")
 		fmt.Fprintf(os.Stderr, "  tuo add -r -t \"Call dentist\"                  # Add as todo to running instance\n")
 		fmt.Fprintf(os.Stderr, "  tuo add -f notes.json \"Buy milk\"              # Add to file\n")
 		fmt.Fprintf(os.Stderr, "  tuo add -f notes.json -t \"Call dentist\"       # Add as todo to file\n")
 		fmt.Fprintf(os.Stderr, "  tuo add -r -a priority=high \"Important task\"\n")
+		fmt.Fprintf(os.Stderr, "  tuo add -f notes.json --parent-query \"@type=project\" \"Next step\"\n")
+		fmt.Fprintf(os.Stderr, "  xclip -o | tuo add -r -t --stdin                # Add a pasted list as todos\n")
 	}
 
 	if err := addCmd.Parse(os.Args[2:]); err != nil {
@@ -121,7 +161,12 @@ func handleAddCommand() {
 	text := strings.Join(addCmd.Args(), " ")
 	text = strings.TrimSpace(text)
 
-	if text == "" {
+	if stdinFlag && text != "" {
+		fmt.Fprintf(os.Stderr, "Error: cannot specify text with --stdin\n\n")
+		addCmd.Usage()
+		os.Exit(1)
+	}
+	if text == "" && !stdinFlag {
 		fmt.Fprintf(os.Stderr, "Error: node text cannot be empty\n\n")
 		addCmd.Usage()
 		os.Exit(1)
@@ -138,6 +183,16 @@ func handleAddCommand() {
 		addCmd.Usage()
 		os.Exit(1)
 	}
+	if parentFlag != "" && parentQueryFlag != "" {
+		fmt.Fprintf(os.Stderr, "Error: cannot specify both --parent and --parent-query\n\n")
+		addCmd.Usage()
+		os.Exit(1)
+	}
+	if stdinFlag && !runningFlag {
+		fmt.Fprintf(os.Stderr, "Error: --stdin only works with -r\n\n")
+		addCmd.Usage()
+		os.Exit(1)
+	}
 
 	// Parse attributes
 	attributes := make(map[string]string)
@@ -164,21 +219,412 @@ func handleAddCommand() {
 	}
 
 	// Check if we should add to a file or running instance
-	if fileFlag != "" {
+	if stdinFlag {
+		// Add the lines of standard input to the running instance at once
+		count, err := sendAddNodesFromReader(os.Stdin, attributes, parentFlag, parentQueryFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if parentFlag != "" || parentQueryFlag != "" {
+			fmt.Printf("%d nodes added under parent\n", count)
+		} else {
+			fmt.Printf("%d nodes added to inbox\n", count)
+		}
+	} else if fileFlag != "" {
 		// Add to file
-		if err := addToFile(fileFlag, text, attributes); err != nil {
+		if err := addToFile(fileFlag, text, attributes, parentFlag, parentQueryFlag); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 		fmt.Printf("Node added to %s\n", fileFlag)
 	} else {
 		// Add to running instance
-		if err := sendAddNode(text, attributes); err != nil {
+		if err := sendAddNode(text, attributes, parentFlag, parentQueryFlag); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Println("Node added to inbox")
+		if parentFlag != "" || parentQueryFlag != "" {
+			fmt.Println("Node added under parent")
+		} else {
+			fmt.Println("Node added to inbox")
+		}
+	}
+}
+
+// handleTodoCommand handles the 'todo' subcommand
+func handleTodoCommand() {
+	var runningFlag bool
+	var idFlag string
+	todoCmd := flag.NewFlagSet("todo", flag.ExitOnError)
+	todoCmd.BoolVar(&runningFlag, "r", false, "Change a todo in the running tuo instance")
+	todoCmd.StringVar(&idFlag, "id", "", "ID of the item to change")
+	todoCmd.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: tuo todo -r --id <id>\n")
+		fmt.Fprintf(os.Stderr, "Rotate the status of a todo in a running tuo instance through the\n")
+		fmt.Fprintf(os.Stderr, "configured todostatuses, like pressing x on the item\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fmt.Fprintf(os.Stderr, "  -r                      Change the todo in the running tuo instance\n")
+		fmt.Fprintf(os.Stderr, "  --id id                 ID of the item to change\n\n")
+		fmt.Fprintf(os.Stderr, "Examples:\n")
+		fmt.Fprintf(os.Stderr, "  tuo todo -r --id item_20240101120000_abc   # todo -> doing -> done\n")
+	}
+
+	if err := todoCmd.Parse(os.Args[2:]); err != nil {
+		os.Exit(1)
+	}
+
+	if !runningFlag {
+		fmt.Fprintf(os.Stderr, "Error: must specify -r\n\n")
+		todoCmd.Usage()
+		os.Exit(1)
+	}
+	if idFlag == "" {
+		fmt.Fprintf(os.Stderr, "Error: --id is required\n\n")
+		todoCmd.Usage()
+		os.Exit(1)
+	}
+
+	status, err := sendToggleTodo(idFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Status: %s\n", status)
+}
+
+// sendToggleTodo rotates the todo status of an item in the running instance and returns the new status
+func sendToggleTodo(id string) (string, error) {
+	// Find running instance
+	socketPath, pid, err := socket.FindRunningInstance()
+	if err != nil {
+		return "", fmt.Errorf("no running tuo instance found: %w", err)
+	}
+
+	log.Printf("Found running instance at PID %d: %s", pid, socketPath)
+
+	client, err := socket.NewClient(socketPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect: %w", err)
+	}
+
+	response, err := client.SendToggleTodo(id)
+	if err != nil {
+		return "", fmt.Errorf("failed to send command: %w", err)
+	}
+	if !response.Success {
+		return "", fmt.Errorf("server error: %s", response.Message)
+	}
+
+	log.Printf("Toggled todo %s to %s", id, response.Message)
+	return response.Message, nil
+}
+
+// handleDumpCommand handles the 'dump' subcommand
+func handleDumpCommand() {
+	var runningFlag bool
+	var queryFlag string
+	dumpCmd := flag.NewFlagSet("dump", flag.ExitOnError)
+	dumpCmd.BoolVar(&runningFlag, "r", false, "Dump the outline of the running tuo instance")
+	dumpCmd.StringVar(&queryFlag, "query", "", "Only dump the subtrees of items matching this query")
+	dumpCmd.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: tuo dump -r [--query query]\n")
+		fmt.Fprintf(os.Stderr, "Print the in-memory outline of a running tuo instance as JSON,\n")
+		fmt.Fprintf(os.Stderr, "including changes that were not saved yet\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fmt.Fprintf(os.Stderr, "  -r                      Dump the outline of the running tuo instance\n")
+		fmt.Fprintf(os.Stderr, "  --query query           Only dump the subtrees of items matching the query\n\n")
+		fmt.Fprintf(os.Stderr, "Examples:\n")
+		fmt.Fprintf(os.Stderr, "  tuo dump -r > snapshot.json\n")
+		fmt.Fprintf(os.Stderr, "  tuo dump -r --query \"@type=project\"\n")
+	}
+
+	if err := dumpCmd.Parse(os.Args[2:]); err != nil {
+		os.Exit(1)
+	}
+
+	if !runningFlag {
+		fmt.Fprintf(os.Stderr, "Error: must specify -r\n\n")
+		dumpCmd.Usage()
+		os.Exit(1)
+	}
+
+	data, err := fetchOutline(queryFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid outline JSON: %v\n", err)
+		os.Exit(1)
+	}
+	out.WriteByte('\n')
+	os.Stdout.Write(out.Bytes())
+}
+
+// handleWatchCommand handles the 'watch' subcommand
+func handleWatchCommand() {
+	var runningFlag bool
+	var jsonFlag bool
+	watchCmd := flag.NewFlagSet("watch", flag.ExitOnError)
+	watchCmd.BoolVar(&runningFlag, "r", false, "Watch the running tuo instance")
+	watchCmd.BoolVar(&jsonFlag, "json", false, "Print each event as a line of JSON")
+	watchCmd.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: tuo watch -r [--json]\n")
+		fmt.Fprintf(os.Stderr, "Print a line when the outline of a running tuo instance changes or is saved,\n")
+		fmt.Fprintf(os.Stderr, "until the instance quits\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fmt.Fprintf(os.Stderr, "  -r                      Watch the running tuo instance\n")
+		fmt.Fprintf(os.Stderr, "  --json                  Print each event as a line of JSON\n\n")
+		fmt.Fprintf(os.Stderr, "Output:\n")
+		fmt.Fprintf(os.Stderr, "  <time> changed <id>... -<id>...   Changed or added items, and removed items with a -\n")
+		fmt.Fprintf(os.Stderr, "  <time> saved <file>\n\n")
+		fmt.Fprintf(os.Stderr, "Examples:\n")
+		fmt.Fprintf(os.Stderr, "  tuo watch -r\n")
+		fmt.Fprintf(os.Stderr, "  tuo watch -r --json | jq -r '.ids[]?'\n")
+	}
+
+	if err := watchCmd.Parse(os.Args[2:]); err != nil {
+		os.Exit(1)
+	}
+
+	if !runningFlag {
+		fmt.Fprintf(os.Stderr, "Error: must specify -r\n\n")
+		watchCmd.Usage()
+		os.Exit(1)
+	}
+
+	socketPath, pid, err := socket.FindRunningInstance()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: no running tuo instance found: %v\n", err)
+		os.Exit(1)
+	}
+	log.Printf("Found running instance at PID %d: %s", pid, socketPath)
+
+	client, err := socket.NewClient(socketPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to connect: %v\n", err)
+		os.Exit(1)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	err = client.Watch(func(event socket.Event) error {
+		if jsonFlag {
+			return encoder.Encode(event)
+		}
+		_, err := fmt.Println(formatWatchEvent(event))
+		return err
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// formatWatchEvent formats an event as a line with its time, type and the
+// changed items, removed items prefixed with a -
+func formatWatchEvent(event socket.Event) string {
+	fields := []string{event.Time.Local().Format("2006-01-02 15:04:05"), event.Event}
+	switch event.Event {
+	case socket.EventSaved:
+		fields = append(fields, event.File)
+	default:
+		fields = append(fields, event.IDs...)
+		for _, id := range event.Removed {
+			fields = append(fields, "-"+id)
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// handleMergeCommand handles the 'merge' subcommand
+func handleMergeCommand() {
+	mergeCmd := flag.NewFlagSet("merge", flag.ExitOnError)
+	fileFlag := mergeCmd.String("f", "", "Base outline file")
+	inputFlag := mergeCmd.String("i", "", "Outline file to merge into the base file")
+	outputFlag := mergeCmd.String("o", "", "Output file (defaults to the base file)")
+	parentFlag := mergeCmd.String("parent", "", "Merge under the item with this ID instead of a new item")
+	mergeCmd.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: tuo merge -f <base.json> -i <other.json> [--parent id] [-o out.json]\n")
+		fmt.Fprintf(os.Stderr, "Add the items of another outline file under a new \"Merged <date>\" item\n")
+		fmt.Fprintf(os.Stderr, "of the base file. Items with an ID that is already used get a new ID\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fmt.Fprintf(os.Stderr, "  -f file       Base outline file\n")
+		fmt.Fprintf(os.Stderr, "  -i file       Outline file to merge into the base file\n")
+		fmt.Fprintf(os.Stderr, "  --parent id   Merge under the item with this ID instead of a new item\n")
+		fmt.Fprintf(os.Stderr, "  -o file       Output file (defaults to the base file)\n\n")
+		fmt.Fprintf(os.Stderr, "Examples:\n")
+		fmt.Fprintf(os.Stderr, "  tuo merge -f notes.json -i inbox-laptop.json\n")
+		fmt.Fprintf(os.Stderr, "  tuo merge -f base.json -i other.json -o out.json\n")
+		fmt.Fprintf(os.Stderr, "  tuo merge -f notes.json -i inbox-laptop.json --parent item_123\n")
+	}
+
+	if err := mergeCmd.Parse(os.Args[2:]); err != nil {
+		os.Exit(1)
+	}
+
+	if *fileFlag == "" || *inputFlag == "" {
+		fmt.Fprintf(os.Stderr, "Error: -f and -i flags are required\n\n")
+		mergeCmd.Usage()
+		os.Exit(1)
+	}
+
+	if err := mergeFiles(*fileFlag, *inputFlag, *outputFlag, *parentFlag); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// handleHashCommand handles the 'hash' subcommand
+func handleHashCommand() {
+	hashCmd := flag.NewFlagSet("hash", flag.ExitOnError)
+	fileFlag := hashCmd.String("f", "", "Outline file to hash")
+	hashCmd.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: tuo hash -f <file>\n")
+		fmt.Fprintf(os.Stderr, "Print a SHA-256 hash of the content of an outline file. The hash only\n")
+		fmt.Fprintf(os.Stderr, "changes when items, attributes, tags or type definitions change, not\n")
+		fmt.Fprintf(os.Stderr, "when the file is saved again or timestamps change\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fmt.Fprintf(os.Stderr, "  -f file       Outline file to hash\n\n")
+		fmt.Fprintf(os.Stderr, "Examples:\n")
+		fmt.Fprintf(os.Stderr, "  tuo hash -f notes.json\n")
+	}
+
+	if err := hashCmd.Parse(os.Args[2:]); err != nil {
+		os.Exit(1)
+	}
+
+	if *fileFlag == "" {
+		fmt.Fprintf(os.Stderr, "Error: -f flag is required\n\n")
+		hashCmd.Usage()
+		os.Exit(1)
 	}
+
+	store := storage.NewJSONStore(*fileFlag)
+	if err := app.PromptPassphrase(store); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	outline, err := store.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading outline: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(outline.ContentHash())
+}
+
+// handleCompletionCommand handles the 'completion' subcommand
+func handleCompletionCommand() {
+	usage := func() {
+		fmt.Fprintf(os.Stderr, "Usage: tuo completion bash|zsh|fish\n")
+		fmt.Fprintf(os.Stderr, "Print a shell completion script for tuo\n\n")
+		fmt.Fprintf(os.Stderr, "Examples:\n")
+		fmt.Fprintf(os.Stderr, "  source <(tuo completion bash)                             # In ~/.bashrc\n")
+		fmt.Fprintf(os.Stderr, "  tuo completion zsh > \"${fpath[1]}/_tuo\"                   # Or source <(tuo completion zsh)\n")
+		fmt.Fprintf(os.Stderr, "  tuo completion fish > ~/.config/fish/completions/tuo.fish\n")
+	}
+
+	if len(os.Args) != 3 {
+		usage()
+		os.Exit(1)
+	}
+
+	switch os.Args[2] {
+	case "bash":
+		fmt.Print(bashCompletion)
+	case "zsh":
+		fmt.Print(zshCompletion)
+	case "fish":
+		fmt.Print(fishCompletion)
+	case "-h", "--help", "help":
+		usage()
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown shell '%s'\n\n", os.Args[2])
+		usage()
+		os.Exit(1)
+	}
+}
+
+// mergeFiles merges the outline in inputPath into the outline in basePath and
+// saves the result to outputPath, or to basePath when it is empty
+func mergeFiles(basePath, inputPath, outputPath, parentID string) error {
+	baseStore := storage.NewJSONStore(basePath)
+	if err := app.PromptPassphrase(baseStore); err != nil {
+		return err
+	}
+	base, err := baseStore.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", basePath, err)
+	}
+
+	inputStore := storage.NewJSONStore(inputPath)
+	if err := app.PromptPassphrase(inputStore); err != nil {
+		return err
+	}
+	other, err := inputStore.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", inputPath, err)
+	}
+
+	var parent *model.Item
+	if parentID != "" {
+		base.BuildIndex()
+		parent = base.FindItemByID(parentID)
+		if parent == nil {
+			return fmt.Errorf("item not found: %s", parentID)
+		}
+	} else {
+		parent = model.NewItem("Merged " + time.Now().Format("2006-01-02"))
+		base.Items = append(base.Items, parent)
+	}
+	count := base.Merge(other, parent)
+
+	outputStore := baseStore
+	if outputPath != "" && outputPath != basePath {
+		outputStore = storage.NewJSONStore(outputPath)
+		if err := app.PromptPassphrase(outputStore); err != nil {
+			return err
+		}
+	}
+	if err := outputStore.Save(base); err != nil {
+		return fmt.Errorf("failed to save outline: %w", err)
+	}
+
+	fmt.Printf("Merged %d items from %s into %s", len(other.Items), inputPath, outputStore.FilePath)
+	if count > 0 {
+		fmt.Printf(", reassigned %d duplicate IDs", count)
+	}
+	fmt.Println()
+	return nil
+}
+
+// fetchOutline returns the outline of the running instance as JSON, limited to
+// the subtrees of items matching query when it is not empty
+func fetchOutline(query string) ([]byte, error) {
+	// Find running instance
+	socketPath, pid, err := socket.FindRunningInstance()
+	if err != nil {
+		return nil, fmt.Errorf("no running tuo instance found: %w", err)
+	}
+
+	log.Printf("Found running instance at PID %d: %s", pid, socketPath)
+
+	client, err := socket.NewClient(socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+
+	response, err := client.SendGetOutline(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send command: %w", err)
+	}
+	if !response.Success {
+		return nil, fmt.Errorf("server error: %s", response.Message)
+	}
+
+	return response.Outline, nil
 }
 
 // handleExportCommand handles the 'export' subcommand
@@ -186,15 +632,33 @@ func handleExportCommand() {
 	exportCmd := flag.NewFlagSet("export", flag.ExitOnError)
 	fileFlag := exportCmd.String("f", "", "Input outline file to export")
 	outputFlag := exportCmd.String("o", "", "Output file (defaults to stdout)")
+	ffFlag := exportCmd.String("ff", "markdown", "Output format: markdown, opml, html, org, text")
+	nodeFlag := exportCmd.String("node", "", "Export only the item with this ID and its descendants")
+	checkboxesFlag := exportCmd.Bool("checkboxes", false, "Render todo items as markdown checkboxes")
+	attrsFlag := exportCmd.String("attrs", "", "Comma-separated attributes to append to items")
+	frontmatterFlag := exportCmd.Bool("frontmatter", false, "Emit YAML front matter from the first root item")
+	idsFlag := exportCmd.Bool("ids", false, "Append a {#id} marker to each item, for importing with --ids")
 	exportCmd.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: tuo export -f <input.json> [-o output.md]\n")
-		fmt.Fprintf(os.Stderr, "Export an outline file to markdown format\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: tuo export -f <input.json> [-ff format] [--node id] [-o output.md]\n")
+		fmt.Fprintf(os.Stderr, "Export an outline file to markdown, OPML, HTML, Org or indented text format\n\n")
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		fmt.Fprintf(os.Stderr, "  -f file      Input outline file to export\n")
+		fmt.Fprintf(os.Stderr, "  -ff format   Output format: markdown, opml, html, org, text (default: markdown)\n")
+		fmt.Fprintf(os.Stderr, "  --node id    Export only this item and its descendants\n")
+		fmt.Fprintf(os.Stderr, "  --checkboxes Render type=todo items as - [ ] / - [x] (markdown only)\n")
+		fmt.Fprintf(os.Stderr, "  --attrs list Append these attributes as (key: value) (markdown only)\n")
+		fmt.Fprintf(os.Stderr, "  --frontmatter Emit YAML front matter from the first root item (markdown only)\n")
+		fmt.Fprintf(os.Stderr, "  --ids        Append a {#id} marker to each item, :import --ids keeps them (markdown and text only)\n")
 		fmt.Fprintf(os.Stderr, "  -o file      Output file (defaults to stdout)\n\n")
 		fmt.Fprintf(os.Stderr, "Examples:\n")
 		fmt.Fprintf(os.Stderr, "  tuo export -f notes.json              # Output to stdout\n")
 		fmt.Fprintf(os.Stderr, "  tuo export -f notes.json -o notes.md  # Output to file\n")
+		fmt.Fprintf(os.Stderr, "  tuo export -f notes.json -ff opml -o notes.opml  # Export to OPML\n")
+		fmt.Fprintf(os.Stderr, "  tuo export -f notes.json -ff html -o notes.html  # Export to a self-contained web page\n")
+		fmt.Fprintf(os.Stderr, "  tuo export -f notes.json -ff org -o notes.org    # Export to Emacs org-mode\n")
+		fmt.Fprintf(os.Stderr, "  tuo export -f notes.json -ff text --node item_123  # Subtree as indented text\n")
+		fmt.Fprintf(os.Stderr, "  tuo export -f notes.json --node item_123 -o project.md  # Export one subtree\n")
+		fmt.Fprintf(os.Stderr, "  tuo export -f notes.json --checkboxes --attrs priority,due  # Include metadata\n")
 		fmt.Fprintf(os.Stderr, "  tuo export -f notes.json | less       # Pipe to pager\n")
 	}
 
@@ -216,14 +680,106 @@ func handleExportCommand() {
 		os.Exit(1)
 	}
 
+	// Select the export functions for the requested format
+	var exportToFile func(*model.Outline, string) error
+	var exportToWriter func(*model.Outline, io.Writer) error
+	var streamMarkdown *export.MarkdownOptions
+	switch *ffFlag {
+	case "markdown", "md":
+		opts := markdownOptionsFromFlags(*checkboxesFlag, *attrsFlag, *frontmatterFlag)
+		opts.IDs = *idsFlag
+		streamMarkdown = &opts
+		exportToFile = func(outline *model.Outline, filePath string) error {
+			return export.ExportToMarkdownWithOptions(outline, filePath, opts)
+		}
+		exportToWriter = func(outline *model.Outline, w io.Writer) error {
+			return export.ExportToMarkdownWriterWithOptions(outline, w, opts)
+		}
+	case "opml":
+		exportToFile = export.ExportToOPML
+		exportToWriter = export.ExportToOPMLWriter
+	case "html":
+		var opts export.HTMLOptions
+		if cfg, err := config.Load(); err == nil && cfg.Get("todostatuses") != "" {
+			opts.TodoStatuses = strings.Split(cfg.Get("todostatuses"), ",")
+		}
+		exportToFile = func(outline *model.Outline, filePath string) error {
+			return export.ExportToHTMLWithOptions(outline, filePath, opts)
+		}
+		exportToWriter = func(outline *model.Outline, w io.Writer) error {
+			return export.ExportToHTMLWriterWithOptions(outline, w, opts)
+		}
+	case "org":
+		var opts export.OrgOptions
+		if cfg, err := config.Load(); err == nil && cfg.Get("todostatuses") != "" {
+			opts.TodoStatuses = strings.Split(cfg.Get("todostatuses"), ",")
+		}
+		exportToFile = func(outline *model.Outline, filePath string) error {
+			return export.ExportToOrgWithOptions(outline, filePath, opts)
+		}
+		exportToWriter = func(outline *model.Outline, w io.Writer) error {
+			return export.ExportToOrgWriterWithOptions(outline, w, opts)
+		}
+	case "text", "txt":
+		opts := export.TextOptions{IDs: *idsFlag}
+		exportToFile = func(outline *model.Outline, filePath string) error {
+			return export.ExportToTextWithOptions(outline, filePath, opts)
+		}
+		exportToWriter = func(outline *model.Outline, w io.Writer) error {
+			return export.ExportToTextWriterWithOptions(outline, w, opts)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown export format '%s'\n\n", *ffFlag)
+		exportCmd.Usage()
+		os.Exit(1)
+	}
+
 	// Load the outline from the input file
 	store := storage.NewJSONStore(inputFile)
+	if err := app.PromptPassphrase(store); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// The whole outline is written to markdown while it is read, so large
+	// outlines don't have to fit in memory
+	if streamMarkdown != nil && *nodeFlag == "" {
+		outputFile := strings.TrimSpace(*outputFlag)
+		if *outputFlag != "" && outputFile == "" {
+			fmt.Fprintf(os.Stderr, "Error: output filename cannot be empty\n\n")
+			exportCmd.Usage()
+			os.Exit(1)
+		}
+		if err := streamMarkdownExport(store, outputFile, *streamMarkdown); err != nil {
+			fmt.Fprintf(os.Stderr, "Error exporting to %s: %v\n", *ffFlag, err)
+			os.Exit(1)
+		}
+		if outputFile != "" {
+			fmt.Fprintf(os.Stderr, "Exported %s to %s\n", inputFile, outputFile)
+		}
+		return
+	}
+
 	outline, err := store.Load()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading outline: %v\n", err)
 		os.Exit(1)
 	}
 
+	// Narrow the export down to a single subtree
+	if *nodeFlag != "" {
+		outline.BuildIndex()
+		node := outline.FindItemByID(*nodeFlag)
+		if node == nil {
+			fmt.Fprintf(os.Stderr, "Error: item not found: %s\n", *nodeFlag)
+			os.Exit(1)
+		}
+		outline = &model.Outline{
+			Items:            []*model.Item{node},
+			OriginalFilename: outline.OriginalFilename,
+		}
+	}
+
 	// Determine output destination
 	if *outputFlag != "" {
 		// Output to file
@@ -234,21 +790,61 @@ func handleExportCommand() {
 			os.Exit(1)
 		}
 
-		if err := export.ExportToMarkdown(outline, outputFile); err != nil {
-			fmt.Fprintf(os.Stderr, "Error exporting to markdown: %v\n", err)
+		if err := exportToFile(outline, outputFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error exporting to %s: %v\n", *ffFlag, err)
 			os.Exit(1)
 		}
 
 		fmt.Fprintf(os.Stderr, "Exported %s to %s\n", inputFile, outputFile)
 	} else {
 		// Output to stdout
-		if err := export.ExportToMarkdownWriter(outline, os.Stdout); err != nil {
-			fmt.Fprintf(os.Stderr, "Error exporting to markdown: %v\n", err)
+		if err := exportToWriter(outline, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error exporting to %s: %v\n", *ffFlag, err)
 			os.Exit(1)
 		}
 	}
 }
 
+// streamMarkdownExport writes the outline of store to outputFile as markdown,
+// one root item at a time. An empty outputFile writes to stdout.
+func streamMarkdownExport(store *storage.JSONStore, outputFile string, opts export.MarkdownOptions) error {
+	var w io.Writer = os.Stdout
+	if outputFile != "" {
+		f, err := os.OpenFile(outputFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to write markdown file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	mw := export.NewMarkdownWriter(w, opts)
+	if err := store.StreamItems(mw.WriteItem); err != nil {
+		return err
+	}
+	return mw.Flush()
+}
+
+// markdownOptionsFromFlags builds the markdown export options from the export flags.
+// Todo statuses are read from the config file so checkboxes match the app.
+func markdownOptionsFromFlags(checkboxes bool, attrs string, frontmatter bool) export.MarkdownOptions {
+	opts := export.MarkdownOptions{
+		TodoCheckboxes: checkboxes,
+		Frontmatter:    frontmatter,
+	}
+	if checkboxes {
+		if cfg, err := config.Load(); err == nil && cfg.Get("todostatuses") != "" {
+			opts.TodoStatuses = strings.Split(cfg.Get("todostatuses"), ",")
+		}
+	}
+	for name := range strings.SplitSeq(attrs, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			opts.Attributes = append(opts.Attributes, name)
+		}
+	}
+	return opts
+}
+
 // handleSearchCommand handles the 'search' subcommand
 func handleSearchCommand() {
 	searchCmd := flag.NewFlagSet("search", flag.ExitOnError)
@@ -257,15 +853,19 @@ func handleSearchCommand() {
 	jsonFlag := searchCmd.Bool("json", false, "Output results as JSON (legacy, use -ff json)")
 	ffFlag := searchCmd.String("ff", "", "Output format: text, fields, json, jsonl, markdown, list")
 	fieldsFlag := searchCmd.String("fields", "", "Comma-separated fields: id,text,created,etc")
+	sortFlag := searchCmd.String("sort", "", "Sort results by field[:desc]: id, text, created, modified, depth")
+	limitFlag := searchCmd.Int("limit", 0, "Maximum number of results (applied after sorting)")
 	searchCmd.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: tuo search -f|-r [options] <query>\n")
+		fmt.Fprintf(os.Stderr, "Usage: tuo search -f|-r [options] <query> [--sort field[:desc]] [--limit n]\n")
 		fmt.Fprintf(os.Stderr, "Search for nodes matching the query\n\n")
-		fmt.Fprintf(os.Stderr, "Note: flags must come before the query argument\n\n")
+		fmt.Fprintf(os.Stderr, "Note: flags must come before the query argument, except --sort and --limit\n\n")
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		fmt.Fprintf(os.Stderr, "  -r               Search in running tuo instance\n")
 		fmt.Fprintf(os.Stderr, "  -f file          Search in file\n")
 		fmt.Fprintf(os.Stderr, "  -ff format       Output format: text, fields, json, jsonl, markdown, list (default: text)\n")
 		fmt.Fprintf(os.Stderr, "  --fields list    Comma-separated fields to include in results\n")
+		fmt.Fprintf(os.Stderr, "  --sort field     Sort by id, text, created, modified or depth, add :desc to reverse\n")
+		fmt.Fprintf(os.Stderr, "  --limit n        Show at most n results, after sorting\n")
 		fmt.Fprintf(os.Stderr, "  -json            Output results as JSON (deprecated, use -ff json)\n\n")
 		fmt.Fprintf(os.Stderr, "Output Formats:\n")
 		fmt.Fprintf(os.Stderr, "  text     - Human-readable text format (default)\n")
@@ -293,6 +893,7 @@ func handleSearchCommand() {
 		fmt.Fprintf(os.Stderr, "  tuo search -f work.json -ff json --fields id,text,path,parent_id \"feature\"\n")
 		fmt.Fprintf(os.Stderr, "  tuo search -f notes.json -ff markdown \"@type=project\" > project.md\n")
 		fmt.Fprintf(os.Stderr, "  tuo search -r -ff list \"important\" > important.md\n")
+		fmt.Fprintf(os.Stderr, "  tuo search -f notes.json \"task\" --sort modified:desc --limit 20\n")
 	}
 
 	if err := searchCmd.Parse(os.Args[2:]); err != nil {
@@ -308,6 +909,29 @@ func handleSearchCommand() {
 
 	query := args[0]
 
+	// Allow --sort and --limit after the query
+	if err := searchCmd.Parse(args[1:]); err != nil {
+		os.Exit(1)
+	}
+	if len(searchCmd.Args()) > 0 {
+		fmt.Fprintf(os.Stderr, "Error: unexpected arguments after query: %s\n\n", strings.Join(searchCmd.Args(), " "))
+		searchCmd.Usage()
+		os.Exit(1)
+	}
+
+	if *sortFlag != "" {
+		if err := search.ValidateSortSpec(*sortFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n\n", err)
+			searchCmd.Usage()
+			os.Exit(1)
+		}
+	}
+	if *limitFlag < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --limit cannot be negative\n\n")
+		searchCmd.Usage()
+		os.Exit(1)
+	}
+
 	// Validate that exactly one of -r or -f is specified
 	if *runningFlag && *fileFlag != "" {
 		fmt.Fprintf(os.Stderr, "Error: cannot specify both -r and -f\n\n")
@@ -332,21 +956,182 @@ func handleSearchCommand() {
 
 	if *runningFlag {
 		// Search in running instance
-		if err := searchRunningInstance(query, outputFormat, *fieldsFlag); err != nil {
+		if err := searchRunningInstance(query, outputFormat, *fieldsFlag, *sortFlag, *limitFlag); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 	} else {
 		// Search in file
-		if err := searchFile(query, *fileFlag, outputFormat, *fieldsFlag); err != nil {
+		if err := searchFile(query, *fileFlag, outputFormat, *fieldsFlag, *sortFlag, *limitFlag); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 	}
 }
 
+// outlineStats holds the metrics reported by the 'stats' subcommand
+type outlineStats struct {
+	Nodes               int            `json:"nodes"`
+	MaxDepth            int            `json:"max_depth"`
+	TodosByStatus       map[string]int `json:"todos_by_status"`
+	ItemsWithAttributes int            `json:"items_with_attributes"`
+	Links               int            `json:"links"`
+	Words               int            `json:"words"`
+	Characters          int            `json:"characters"`
+	Attribute           string         `json:"attribute,omitempty"`
+	AttributeValues     map[string]int `json:"attribute_values,omitempty"`
+}
+
+// handleStatsCommand handles the 'stats' subcommand
+func handleStatsCommand() {
+	statsCmd := flag.NewFlagSet("stats", flag.ExitOnError)
+	fileFlag := statsCmd.String("f", "", "Outline file to analyze")
+	ffFlag := statsCmd.String("ff", "text", "Output format: text, json")
+	attrFlag := statsCmd.String("attr", "", "Break down counts by the values of this attribute")
+	statsCmd.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: tuo stats -f <file> [-ff format] [--attr name]\n")
+		fmt.Fprintf(os.Stderr, "Print metrics about an outline file\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fmt.Fprintf(os.Stderr, "  -f file      Outline file to analyze\n")
+		fmt.Fprintf(os.Stderr, "  -ff format   Output format: text, json (default: text)\n")
+		fmt.Fprintf(os.Stderr, "  --attr name  Count items by the distinct values of an attribute\n\n")
+		fmt.Fprintf(os.Stderr, "Examples:\n")
+		fmt.Fprintf(os.Stderr, "  tuo stats -f notes.json\n")
+		fmt.Fprintf(os.Stderr, "  tuo stats -f notes.json -ff json\n")
+		fmt.Fprintf(os.Stderr, "  tuo stats -f notes.json --attr priority\n")
+	}
+
+	if err := statsCmd.Parse(os.Args[2:]); err != nil {
+		os.Exit(1)
+	}
+
+	inputFile := strings.TrimSpace(*fileFlag)
+	if inputFile == "" {
+		fmt.Fprintf(os.Stderr, "Error: -f flag is required\n\n")
+		statsCmd.Usage()
+		os.Exit(1)
+	}
+
+	if *ffFlag != "text" && *ffFlag != "json" {
+		fmt.Fprintf(os.Stderr, "Error: unknown output format '%s'\n\n", *ffFlag)
+		statsCmd.Usage()
+		os.Exit(1)
+	}
+
+	store := storage.NewJSONStore(inputFile)
+	if err := app.PromptPassphrase(store); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	outline, err := store.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading outline: %v\n", err)
+		os.Exit(1)
+	}
+
+	stats := computeOutlineStats(outline, strings.TrimSpace(*attrFlag))
+	if err := writeOutlineStats(os.Stdout, stats, *ffFlag); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// writeOutlineStats writes the stats to w as text or as JSON
+func writeOutlineStats(w io.Writer, stats *outlineStats, format string) error {
+	if format == "json" {
+		data, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+
+	fmt.Fprintf(w, "Nodes:                 %d\n", stats.Nodes)
+	fmt.Fprintf(w, "Max depth:             %d\n", stats.MaxDepth)
+	fmt.Fprintf(w, "Items with attributes: %d\n", stats.ItemsWithAttributes)
+	fmt.Fprintf(w, "Wiki links:            %d\n", stats.Links)
+	fmt.Fprintf(w, "Words:                 %d\n", stats.Words)
+	fmt.Fprintf(w, "Characters:            %d\n", stats.Characters)
+	fmt.Fprintf(w, "Todo items by status:\n")
+	printCounts(w, stats.TodosByStatus)
+	if stats.Attribute != "" {
+		fmt.Fprintf(w, "Items by %s:\n", stats.Attribute)
+		printCounts(w, stats.AttributeValues)
+	}
+	return nil
+}
+
+// computeOutlineStats walks all items in the outline and collects the metrics.
+// When attrName is set, items are also counted by the values of that attribute.
+func computeOutlineStats(outline *model.Outline, attrName string) *outlineStats {
+	stats := &outlineStats{
+		TodosByStatus: make(map[string]int),
+	}
+	if attrName != "" {
+		stats.Attribute = attrName
+		stats.AttributeValues = make(map[string]int)
+	}
+
+	counts := model.CountText(outline.Items)
+	stats.Words = counts.Words
+	stats.Characters = counts.Characters
+
+	for _, item := range outline.GetAllItems() {
+		stats.Nodes++
+		stats.Links += len(links.ParseLinks(item.Text))
+
+		// Depth is counted like the d: search filter, root items are depth 0
+		depth := 0
+		for parent := item.Parent; parent != nil; parent = parent.Parent {
+			depth++
+		}
+		if depth > stats.MaxDepth {
+			stats.MaxDepth = depth
+		}
+
+		if item.Metadata == nil || len(item.Metadata.Attributes) == 0 {
+			continue
+		}
+		attrs := item.Metadata.Attributes
+		stats.ItemsWithAttributes++
+
+		if attrs["type"] == "todo" {
+			status := attrs["status"]
+			if status == "" {
+				status = "(none)"
+			}
+			stats.TodosByStatus[status]++
+		}
+
+		if attrName != "" {
+			if value, ok := attrs[attrName]; ok {
+				stats.AttributeValues[value]++
+			}
+		}
+	}
+
+	return stats
+}
+
+// printCounts prints a map of counts sorted by key
+func printCounts(w io.Writer, counts map[string]int) {
+	if len(counts) == 0 {
+		fmt.Fprintf(w, "  (none)\n")
+		return
+	}
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		fmt.Fprintf(w, "  %-20s %d\n", key+":", counts[key])
+	}
+}
+
 // searchRunningInstance searches in a running tuo instance via socket
-func searchRunningInstance(query string, outputFormat string, fieldsStr string) error {
+func searchRunningInstance(query string, outputFormat string, fieldsStr string, sortSpec string, limit int) error {
 	// Find running instance
 	socketPath, pid, err := socket.FindRunningInstance()
 	if err != nil {
@@ -365,7 +1150,7 @@ func searchRunningInstance(query string, outputFormat string, fieldsStr string)
 	fields := ui.ParseFieldsFlag(fieldsStr)
 
 	// Send search command
-	response, err := client.SendSearch(query, fields, outputFormat)
+	response, err := client.SendSearch(query, fields, outputFormat, sortSpec, limit)
 	if err != nil {
 		return fmt.Errorf("failed to send search: %w", err)
 	}
@@ -403,79 +1188,36 @@ func searchRunningInstance(query string, outputFormat string, fieldsStr string)
 			return fmt.Errorf("failed to export markdown: %w", err)
 		}
 
-	case "fields":
-		// Tab-separated format with requested fields
+	case "fields", "json", "jsonl":
 		if len(response.Results) == 0 {
 			fmt.Println("No matches found")
-		} else {
-			for _, result := range response.Results {
-				var parts []string
-				// Output fields in the order they were requested
-				for _, fieldName := range fields {
-					value, exists := result[fieldName]
-					if !exists {
-						parts = append(parts, "")
-						continue
-					}
+			return nil
+		}
 
-					// Format the value based on type
-					switch v := value.(type) {
-					case string:
-						parts = append(parts, v)
-					case []interface{}:
-						// For path field
-						if fieldName == "path" {
-							var pathTexts []string
-							for _, p := range v {
-								if node, ok := p.(map[string]interface{}); ok {
-									if text, ok := node["text"].(string); ok {
-										pathTexts = append(pathTexts, text)
-									}
-								}
-							}
-							parts = append(parts, strings.Join(pathTexts, " > "))
-						} else {
-							// For tags or other arrays
-							var strItems []string
-							for _, item := range v {
-								strItems = append(strItems, fmt.Sprintf("%v", item))
-							}
-							parts = append(parts, strings.Join(strItems, ","))
-						}
-					case map[string]interface{}:
-						// For attributes map
-						var attrs []string
-						for k, val := range v {
-							attrs = append(attrs, fmt.Sprintf("@%s=%v", k, val))
-						}
-						parts = append(parts, strings.Join(attrs, " "))
-					default:
-						parts = append(parts, fmt.Sprintf("%v", v))
-					}
-				}
-				fmt.Println(strings.Join(parts, "\t"))
+		// Rebuild the matched items and format them like file search results
+		var items []*model.Item
+		for _, result := range response.Results {
+			if item := resultToItem(result); item != nil {
+				items = append(items, item)
 			}
 		}
 
-	case "json":
-		encoder := json.NewEncoder(os.Stdout)
-		encoder.SetIndent("", "  ")
-		if err := encoder.Encode(response.Results); err != nil {
-			return fmt.Errorf("failed to encode results: %w", err)
+		// Keep the legacy default fields of running instance searches
+		if len(fields) == 0 {
+			fields = []string{"text", "path", "attributes"}
 		}
 
-	case "jsonl":
-		// JSON Lines format - one result per line
-		if len(response.Results) == 0 {
-			fmt.Println("No matches found")
-		} else {
-			for _, result := range response.Results {
-				data, err := json.Marshal(result)
-				if err != nil {
-					return fmt.Errorf("failed to encode result: %w", err)
-				}
-				fmt.Println(string(data))
-			}
+		format, err := ui.ParseFormatFlag(outputFormat)
+		if err != nil {
+			return err
+		}
+		formatter := ui.NewSearchOutputFormatter()
+		output, err := formatter.FormatResults(items, format, fields, nil)
+		if err != nil {
+			return fmt.Errorf("failed to format results: %w", err)
+		}
+		if output != "" {
+			fmt.Println(output)
 		}
 
 	default:
@@ -534,9 +1276,12 @@ func searchRunningInstance(query string, outputFormat string, fieldsStr string)
 }
 
 // searchFile searches in an outline file
-func searchFile(query, filePath string, outputFormat string, fieldsStr string) error {
+func searchFile(query, filePath string, outputFormat string, fieldsStr string, sortSpec string, limit int) error {
 	// Load the outline file
 	store := storage.NewJSONStore(filePath)
+	if err := app.PromptPassphrase(store); err != nil {
+		return err
+	}
 	outline, err := store.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load outline: %w", err)
@@ -559,6 +1304,14 @@ func searchFile(query, filePath string, outputFormat string, fieldsStr string) e
 		return nil
 	}
 
+	// Sort before limiting, so the limit keeps the first results in sort order
+	if sortSpec != "" {
+		if err := search.SortItems(matches, sortSpec); err != nil {
+			return err
+		}
+	}
+	matches = search.LimitItems(matches, limit)
+
 	// Ensure outline has indexed items for proper parent references
 	outline.BuildIndex()
 
@@ -662,7 +1415,8 @@ func searchFile(query, filePath string, outputFormat string, fieldsStr string) e
 	return nil
 }
 
-// resultToItem converts a search result back to a model.Item for export
+// resultToItem converts a search result back to a model.Item for export and formatting.
+// The parent chain is rebuilt from the path, so depth and parent_id can be derived again.
 func resultToItem(result socket.SearchResult) *model.Item {
 	text, ok := result["text"].(string)
 	if !ok {
@@ -678,12 +1432,46 @@ func resultToItem(result socket.SearchResult) *model.Item {
 
 	// Set attributes if available
 	if attrs, ok := result["attributes"].(map[string]interface{}); ok {
-		if item.Metadata.Attributes == nil {
-			item.Metadata.Attributes = make(map[string]string)
+		setResultAttributes(item, attrs)
+	}
+
+	// Set tags and timestamps if available
+	if tags, ok := result["tags"].([]interface{}); ok {
+		for _, tag := range tags {
+			item.AddTag(fmt.Sprintf("%v", tag))
+		}
+	}
+	if created, ok := result["created"].(string); ok {
+		if t, err := time.Parse(time.RFC3339, created); err == nil {
+			item.Metadata.Created = t
 		}
-		for k, v := range attrs {
-			item.Metadata.Attributes[k] = fmt.Sprintf("%v", v)
+	}
+	if modified, ok := result["modified"].(string); ok {
+		if t, err := time.Parse(time.RFC3339, modified); err == nil {
+			item.Metadata.Modified = t
+		}
+	}
+
+	// Rebuild the ancestors from the path (root first, the item itself last)
+	if path, ok := result["path"].([]interface{}); ok && len(path) > 1 {
+		var parent *model.Item
+		for _, p := range path[:len(path)-1] {
+			node, ok := p.(map[string]interface{})
+			if !ok {
+				break
+			}
+			nodeText, _ := node["text"].(string)
+			ancestor := model.NewItem(nodeText)
+			if id, ok := node["id"].(string); ok {
+				ancestor.ID = id
+			}
+			if attrs, ok := node["attributes"].(map[string]interface{}); ok {
+				setResultAttributes(ancestor, attrs)
+			}
+			ancestor.Parent = parent
+			parent = ancestor
 		}
+		item.Parent = parent
 	}
 
 	// Recursively build children if available
@@ -700,6 +1488,16 @@ func resultToItem(result socket.SearchResult) *model.Item {
 	return item
 }
 
+// setResultAttributes copies decoded JSON attributes onto an item
+func setResultAttributes(item *model.Item, attrs map[string]interface{}) {
+	if item.Metadata.Attributes == nil {
+		item.Metadata.Attributes = make(map[string]string)
+	}
+	for k, v := range attrs {
+		item.Metadata.Attributes[k] = fmt.Sprintf("%v", v)
+	}
+}
+
 // buildItemPathForCLI constructs a path array for an item showing its hierarchy with full node objects
 func buildItemPathForCLI(item *model.Item) []interface{} {
 	var path []interface{}
@@ -724,8 +1522,15 @@ func printUsage() {
 	fmt.Fprintf(os.Stderr, "Usage:\n")
 	fmt.Fprintf(os.Stderr, "  tuo [options] [file]                      Start tuo with optional file\n")
 	fmt.Fprintf(os.Stderr, "  tuo add -r|-f <file> [options] <text>     Add node to running instance or file\n")
-	fmt.Fprintf(os.Stderr, "  tuo export -f <file> [-o output]          Export outline to markdown\n")
+	fmt.Fprintf(os.Stderr, "  tuo export -f <file> [-ff fmt] [-o out]   Export outline to markdown or OPML\n")
 	fmt.Fprintf(os.Stderr, "  tuo search [options] <query>              Search for nodes (outputs to stdout)\n")
+	fmt.Fprintf(os.Stderr, "  tuo stats -f <file> [-ff json]            Print outline metrics\n")
+	fmt.Fprintf(os.Stderr, "  tuo todo -r --id <id>                     Rotate a todo's status in running instance\n")
+	fmt.Fprintf(os.Stderr, "  tuo dump -r [--query query]               Print the outline of running instance as JSON\n")
+	fmt.Fprintf(os.Stderr, "  tuo watch -r [--json]                     Print changes of running instance as they happen\n")
+	fmt.Fprintf(os.Stderr, "  tuo merge -f <file> -i <other> [-o out]   Merge another outline file into a file\n")
+	fmt.Fprintf(os.Stderr, "  tuo hash -f <file>                        Print a hash of the outline content\n")
+	fmt.Fprintf(os.Stderr, "  tuo completion bash|zsh|fish              Print a shell completion script\n")
 	fmt.Fprintf(os.Stderr, "  tuo help                                  Show this help message\n\n")
 	fmt.Fprintf(os.Stderr, "Options:\n")
 	fmt.Fprintf(os.Stderr, "  --debug                                   Enable debug mode\n\n")
@@ -737,6 +1542,8 @@ func printUsage() {
 	fmt.Fprintf(os.Stderr, "  tuo add -f notes.json \"Buy milk\"          Add item to file\n")
 	fmt.Fprintf(os.Stderr, "  tuo export -f notes.json                  Export to stdout\n")
 	fmt.Fprintf(os.Stderr, "  tuo export -f notes.json -o notes.md      Export to file\n")
+	fmt.Fprintf(os.Stderr, "  tuo stats -f notes.json --attr status     Show metrics with a breakdown by status\n")
+	fmt.Fprintf(os.Stderr, "  tuo todo -r --id item_123                 Move item_123 to its next todo status\n")
 	fmt.Fprintf(os.Stderr, "  tuo search -f notes.json \"todo\"           Search file for 'todo'\n")
 	fmt.Fprintf(os.Stderr, "  tuo search -f notes.json -ff fields \"@status=done\"  Tab-separated output\n")
 	fmt.Fprintf(os.Stderr, "  tuo search -r -ff json \"@type=todo\"       JSON output from running instance\n")
@@ -745,10 +1552,14 @@ func printUsage() {
 	fmt.Fprintf(os.Stderr, "For more info on search, run: tuo search -h\n")
 }
 
-// addToFile adds a node directly to a file's inbox
-func addToFile(filePath, text string, attributes map[string]string) error {
+// addToFile adds a node directly to a file's inbox, or under the parent given
+// by ID or query. A parent that cannot be found is an error.
+func addToFile(filePath, text string, attributes map[string]string, parentID, parentQuery string) error {
 	// Load the outline from file
 	store := storage.NewJSONStore(filePath)
+	if err := app.PromptPassphrase(store); err != nil {
+		return err
+	}
 	outline, err := store.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load outline: %w", err)
@@ -759,17 +1570,25 @@ func addToFile(filePath, text string, attributes map[string]string) error {
 		outline.Items = []*model.Item{}
 	}
 
-	// Find or create inbox node
-	inbox := findInboxInOutline(outline)
-	if inbox == nil {
+	// Find the requested parent, or find or create the inbox node
+	var parent *model.Item
+	if parentID != "" || parentQuery != "" {
+		parent, err = search.FindParent(outline, parentID, parentQuery)
+		if err != nil {
+			return err
+		}
+	} else {
+		parent = findInboxInOutline(outline)
+	}
+	if parent == nil {
 		// Create new inbox at root level
-		inbox = model.NewItem("Inbox")
-		if inbox.Metadata.Attributes == nil {
-			inbox.Metadata.Attributes = make(map[string]string)
+		parent = model.NewItem("Inbox")
+		if parent.Metadata.Attributes == nil {
+			parent.Metadata.Attributes = make(map[string]string)
 		}
-		inbox.Metadata.Attributes["type"] = "inbox"
-		inbox.Expanded = true
-		outline.Items = append(outline.Items, inbox)
+		parent.Metadata.Attributes["type"] = "inbox"
+		parent.Expanded = true
+		outline.Items = append(outline.Items, parent)
 	}
 
 	// Create new item
@@ -783,8 +1602,8 @@ func addToFile(filePath, text string, attributes map[string]string) error {
 		}
 	}
 
-	// Add to inbox
-	inbox.AddChild(newItem)
+	// Add to the inbox or the requested parent
+	parent.AddChild(newItem)
 
 	// Save the file
 	if err := store.Save(outline); err != nil {
@@ -794,6 +1613,61 @@ func addToFile(filePath, text string, attributes map[string]string) error {
 	return nil
 }
 
+// sendAddNodesFromReader reads indented lines from r and adds them to a running
+// tuo instance in one add_nodes command. Indented lines become children of the
+// line above them, and every node gets the attributes. Returns the number of nodes.
+func sendAddNodesFromReader(r io.Reader, attributes map[string]string, parentID, parentQuery string) (int, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read standard input: %w", err)
+	}
+	items, err := import_parser.ImportFile(string(data), import_parser.FormatIndentedText)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse standard input: %w", err)
+	}
+	if len(items) == 0 {
+		return 0, fmt.Errorf("no lines on standard input")
+	}
+
+	count := 0
+	var nodeSpecs func(items []*model.Item) []socket.NodeSpec
+	nodeSpecs = func(items []*model.Item) []socket.NodeSpec {
+		nodes := make([]socket.NodeSpec, 0, len(items))
+		for _, item := range items {
+			count++
+			nodes = append(nodes, socket.NodeSpec{
+				Text:       item.Text,
+				Attributes: attributes,
+				Children:   nodeSpecs(item.Children),
+			})
+		}
+		return nodes
+	}
+	nodes := nodeSpecs(items)
+
+	socketPath, pid, err := socket.FindRunningInstance()
+	if err != nil {
+		return 0, fmt.Errorf("no running tuo instance found: %w", err)
+	}
+	log.Printf("Found running instance at PID %d: %s", pid, socketPath)
+
+	client, err := socket.NewClient(socketPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect: %w", err)
+	}
+
+	response, err := client.SendAddNodes(nodes, parentID, parentQuery)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send command: %w", err)
+	}
+	if !response.Success {
+		return 0, fmt.Errorf("server error: %s", response.Message)
+	}
+
+	log.Printf("Successfully sent add_nodes command with %d nodes", count)
+	return count, nil
+}
+
 // findInboxInOutline searches for a node marked with type=inbox attribute
 func findInboxInOutline(outline *model.Outline) *model.Item {
 	var search func([]*model.Item) *model.Item
@@ -815,8 +1689,9 @@ func findInboxInOutline(outline *model.Outline) *model.Item {
 	return search(outline.Items)
 }
 
-// sendAddNode sends an add_node command to a running tuo instance
-func sendAddNode(text string, attributes map[string]string) error {
+// sendAddNode sends an add_node command to a running tuo instance.
+// When parentID or parentQuery is set the node is added under that parent instead of the inbox.
+func sendAddNode(text string, attributes map[string]string, parentID, parentQuery string) error {
 	text = strings.TrimSpace(text)
 	if text == "" {
 		return fmt.Errorf("node text cannot be empty")
@@ -837,7 +1712,12 @@ func sendAddNode(text string, attributes map[string]string) error {
 	}
 
 	// Send add_node command
-	response, err := client.SendAddNode(text, "inbox", attributes)
+	var response *socket.Response
+	if parentID != "" || parentQuery != "" {
+		response, err = client.SendAddNodeToParent(text, parentID, parentQuery, attributes)
+	} else {
+		response, err = client.SendAddNode(text, "inbox", attributes)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to send command: %w", err)
 	}
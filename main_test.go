@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/pstuifzand/tui-outliner/internal/model"
+)
+
+// statsTestOutline creates an outline with todos, attributes and a link
+//
+//	Project (type=project, priority=high)
+//	  Write report (todo, done, priority=high)
+//	    Draft intro (todo, no status)
+//	  Call [[link]] (todo, open)
+//	Notes
+func statsTestOutline() *model.Outline {
+	project := model.NewItem("Project")
+	project.Metadata.Attributes["type"] = "project"
+	project.Metadata.Attributes["priority"] = "high"
+
+	report := model.NewItem("Write report")
+	report.Metadata.Attributes["type"] = "todo"
+	report.Metadata.Attributes["status"] = "done"
+	report.Metadata.Attributes["priority"] = "high"
+	intro := model.NewItem("Draft intro")
+	intro.Metadata.Attributes["type"] = "todo"
+	report.AddChild(intro)
+	project.AddChild(report)
+
+	call := model.NewItem("Call [[" + report.ID + "]]")
+	call.Metadata.Attributes["type"] = "todo"
+	call.Metadata.Attributes["status"] = "open"
+	project.AddChild(call)
+
+	outline := model.NewOutline()
+	outline.Items = []*model.Item{project, model.NewItem("Notes")}
+	outline.BuildIndex()
+	return outline
+}
+
+func TestComputeOutlineStats(t *testing.T) {
+	stats := computeOutlineStats(statsTestOutline(), "priority")
+
+	if stats.Nodes != 5 {
+		t.Errorf("Expected 5 nodes, got %d", stats.Nodes)
+	}
+	if stats.MaxDepth != 2 {
+		t.Errorf("Expected max depth 2, got %d", stats.MaxDepth)
+	}
+	if stats.ItemsWithAttributes != 4 {
+		t.Errorf("Expected 4 items with attributes, got %d", stats.ItemsWithAttributes)
+	}
+	if stats.Links != 1 {
+		t.Errorf("Expected 1 link, got %d", stats.Links)
+	}
+	wantTodos := map[string]int{"done": 1, "open": 1, "(none)": 1}
+	if !maps.Equal(stats.TodosByStatus, wantTodos) {
+		t.Errorf("Expected todos %v, got %v", wantTodos, stats.TodosByStatus)
+	}
+	if want := map[string]int{"high": 2}; !maps.Equal(stats.AttributeValues, want) {
+		t.Errorf("Expected priorities %v, got %v", want, stats.AttributeValues)
+	}
+
+	// Without --attr there is no breakdown
+	if stats := computeOutlineStats(model.NewOutline(), ""); stats.Nodes != 0 || stats.AttributeValues != nil {
+		t.Errorf("Expected empty stats for an empty outline, got %+v", stats)
+	}
+}
+
+func TestWriteOutlineStatsJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeOutlineStats(&buf, computeOutlineStats(statsTestOutline(), ""), "json"); err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", buf.String(), err)
+	}
+	if got["nodes"] != 5.0 || got["max_depth"] != 2.0 || got["items_with_attributes"] != 4.0 {
+		t.Errorf("Unexpected counts in %s", buf.String())
+	}
+	if todos, _ := got["todos_by_status"].(map[string]any); todos["done"] != 1.0 {
+		t.Errorf("Expected the todos by status, got %v", got["todos_by_status"])
+	}
+	if _, ok := got["attribute_values"]; ok {
+		t.Error("Expected no attribute values without --attr")
+	}
+}
+
+func TestWriteOutlineStatsText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeOutlineStats(&buf, computeOutlineStats(statsTestOutline(), "priority"), "text"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Nodes:                 5\n", "Max depth:             2\n", "  done:                1\n", "Items by priority:\n  high:                2\n"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Expected %q in\n%s", want, buf.String())
+		}
+	}
+}